@@ -3,7 +3,9 @@ package main
 import (
 	"archive/zip"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -16,12 +18,14 @@ import (
 type ActionService struct {
 	ctx     *App
 	storage *Storage
+	engine  *Engine
 }
 
-func NewActionService(app *App, storage *Storage) *ActionService {
+func NewActionService(app *App, storage *Storage, engine *Engine) *ActionService {
 	return &ActionService{
 		ctx:     app,
 		storage: storage,
+		engine:  engine,
 	}
 }
 
@@ -268,13 +272,64 @@ func (as *ActionService) Extract(src, dest string) error {
 }
 
 // HTTP Operations
+
+// HTTPRequest performs an HTTP call for the editor executor, which sends the
+// traceparent of its run as a header. With tracing enabled the request is
+// recorded as a client span of that trace and propagates its own context.
 func (as *ActionService) HTTPRequest(method, url string, headers map[string]string, body string) (map[string]interface{}, error) {
+	ctx := context.Background()
+	if traceparent, ok := headers["traceparent"]; ok && as.engine.activeTracer() != nil {
+		forwarded := make(map[string]string, len(headers))
+		for key, value := range headers {
+			if key != "traceparent" {
+				forwarded[key] = value
+			}
+		}
+		headers = forwarded
+		var done func()
+		ctx, done = as.engine.editorSpan(ctx, traceparent)
+		defer done()
+	}
+	return doHTTPRequest(ctx, method, url, headers, body)
+}
+
+// doHTTPRequest performs an HTTP call. Inside an engine execution it logs the
+// request and records a client span propagated as a traceparent header.
+func doHTTPRequest(ctx context.Context, method, url string, headers map[string]string, body string) (map[string]interface{}, error) {
+	lg := loggerFromContext(ctx)
+
+	var span *Span
+	if parent := spanFromContext(ctx); parent != nil {
+		span = parent.StartChild("HTTP "+method, SpanKindClient)
+		span.SetAttribute("http.request.method", method)
+		span.SetAttribute("url.full", url)
+		defer span.End()
+		ctx = contextWithSpan(ctx, span)
+	}
+
+	result, err := sendHTTPRequest(ctx, method, url, headers, body)
+	if err != nil {
+		lg.Error("HTTP request failed", "method", method, "url", url, "error", err.Error())
+		if span != nil {
+			span.SetError(err.Error())
+		}
+		return nil, err
+	}
+
+	lg.Debug("HTTP request", "method", method, "url", url, "status", result["status"])
+	if span != nil {
+		span.SetAttribute("http.response.status_code", result["status"])
+	}
+	return result, nil
+}
+
+func sendHTTPRequest(ctx context.Context, method, url string, headers map[string]string, body string) (map[string]interface{}, error) {
 	var reqBody io.Reader
 	if body != "" && method != "GET" {
 		reqBody = bytes.NewBufferString(body)
 	}
 
-	req, err := http.NewRequest(method, url, reqBody)
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -282,8 +337,20 @@ func (as *ActionService) HTTPRequest(method, url string, headers map[string]stri
 	for key, value := range headers {
 		req.Header.Set(key, value)
 	}
+	injectTraceparent(ctx, req.Header)
 
 	client := &http.Client{Timeout: 30 * time.Second}
+	if allowed := urlPolicyFromContext(ctx); allowed != nil {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			if !allowed(req.URL.String()) {
+				return fmt.Errorf("permission denied: redirect to %s", req.URL)
+			}
+			return nil
+		}
+	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -311,9 +378,27 @@ func (as *ActionService) HTTPRequest(method, url string, headers map[string]stri
 	return result, nil
 }
 
+type urlPolicyKey struct{}
+
+// contextWithURLPolicy restricts the HTTP requests made with ctx, redirects
+// included, to the URLs allowed reports true for
+func contextWithURLPolicy(ctx context.Context, allowed func(string) bool) context.Context {
+	return context.WithValue(ctx, urlPolicyKey{}, allowed)
+}
+
+func urlPolicyFromContext(ctx context.Context) func(string) bool {
+	allowed, _ := ctx.Value(urlPolicyKey{}).(func(string) bool)
+	return allowed
+}
+
 // Shell Operations
 func (as *ActionService) RunCommand(command string, args []string, workDir string) (map[string]interface{}, error) {
-	cmd := exec.Command(command, args...)
+	return runCommand(context.Background(), command, args, workDir)
+}
+
+// runCommand runs a program to completion, killing it if ctx is done first
+func runCommand(ctx context.Context, command string, args []string, workDir string) (map[string]interface{}, error) {
+	cmd := exec.CommandContext(ctx, command, args...)
 
 	if workDir != "" {
 		cmd.Dir = workDir
@@ -324,6 +409,9 @@ func (as *ActionService) RunCommand(command string, args []string, workDir strin
 	cmd.Stderr = &stderr
 
 	err := cmd.Run()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 	exitCode := 0
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
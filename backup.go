@@ -0,0 +1,926 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A backup is a zip archive of everything needed to set ForgeFlow up again:
+//
+//	manifest.json             BackupManifest
+//	settings.json
+//	flows/<id>.json           and flows/<id>.tests.json for test cases
+//	custom-nodes/<type>.json
+//	executions/<id>.json      optional
+//	secrets/vault.json        optional: the key settings of the backup, and
+//	secrets/<name>.json       each secret sealed under the backup passphrase
+//
+// Secrets are re-encrypted under a key derived from a passphrase chosen for
+// the backup, so an archive never holds a usable key and can be restored
+// into any vault.
+const (
+	backupFormat       = "forgeflow-backup"
+	backupVersion      = 1
+	backupManifestFile = "manifest.json"
+	backupVaultFile    = "secrets/vault.json"
+
+	// backupPassphraseEnv provides the backup passphrase to the backup and
+	// restore commands and to scheduled backups, which then include secrets
+	backupPassphraseEnv = "FORGEFLOW_BACKUP_PASSPHRASE"
+
+	// scheduledBackupPrefix names scheduled backups; only these are rotated
+	scheduledBackupPrefix = "forgeflow-auto-"
+)
+
+// Restore modes, and what to do with items that exist in both the backup
+// and the data directory
+const (
+	RestoreMerge   = "merge"   // add the backup to the current data
+	RestoreReplace = "replace" // delete the current data of the restored kinds first
+
+	ConflictSkip      = "skip"      // keep the current item
+	ConflictOverwrite = "overwrite" // replace it with the backup's
+	ConflictCopy      = "copy"      // restore flows under a new ID; other items are skipped
+)
+
+type BackupOptions struct {
+	Executions bool   `json:"executions"`
+	Secrets    bool   `json:"secrets"`
+	Passphrase string `json:"passphrase,omitempty"` // required with Secrets
+}
+
+type BackupManifest struct {
+	Format      string `json:"format"`
+	Version     int    `json:"version"`
+	CreatedAt   string `json:"createdAt"`
+	Backend     string `json:"backend"`
+	Flows       int    `json:"flows"`
+	CustomNodes int    `json:"customNodes"`
+	Executions  int    `json:"executions"`
+	Secrets     int    `json:"secrets"`
+	Settings    bool   `json:"settings"`
+}
+
+// BackupItem is a flow, custom node or secret in a backup
+type BackupItem struct {
+	ID     string `json:"id"`
+	Name   string `json:"name,omitempty"`
+	Exists bool   `json:"exists"` // an item with the same ID is stored already
+}
+
+// BackupPreview lists what a restore would bring in and what it collides with
+type BackupPreview struct {
+	Manifest           BackupManifest `json:"manifest"`
+	Flows              []BackupItem   `json:"flows"`
+	CustomNodes        []BackupItem   `json:"customNodes"`
+	Secrets            []BackupItem   `json:"secrets"`
+	Executions         int            `json:"executions"`
+	ExecutionConflicts int            `json:"executionConflicts"`
+}
+
+// RestoreOptions selects what RestoreBackup restores. Flows and custom nodes
+// are always restored; settings, executions and secrets only when asked.
+type RestoreOptions struct {
+	Mode       string `json:"mode"`       // merge (default) or replace
+	OnConflict string `json:"onConflict"` // skip (default), overwrite or copy
+	Settings   bool   `json:"settings"`
+	Executions bool   `json:"executions"`
+	Secrets    bool   `json:"secrets"`
+	Passphrase string `json:"passphrase,omitempty"` // the backup's, to restore secrets
+}
+
+type RestoreReport struct {
+	Flows        int               `json:"flows"`
+	CustomNodes  int               `json:"customNodes"`
+	Executions   int               `json:"executions"`
+	Secrets      int               `json:"secrets"`
+	Settings     bool              `json:"settings"`
+	Skipped      int               `json:"skipped"`
+	Renamed      map[string]string `json:"renamed,omitempty"`      // old flow ID to the new one, for copies
+	SafetyBackup string            `json:"safetyBackup,omitempty"` // the data a replace removed
+}
+
+// CreateBackup writes a backup archive to path. Secrets are only included
+// with a passphrase, and need the vault to be unlocked.
+func (s *Storage) CreateBackup(path string, options BackupOptions) (*BackupManifest, error) {
+	if options.Secrets {
+		if options.Passphrase == "" {
+			return nil, errors.New("a passphrase is required to back up secrets")
+		}
+		if err := s.initVault(); err != nil {
+			return nil, err
+		}
+	}
+	store, unlock, err := s.reader()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return s.writeBackup(store, path, options)
+}
+
+// writeBackup archives the data under a storage lock held by the caller.
+// The archive is written next to path and renamed into place when complete.
+func (s *Storage) writeBackup(store StorageBackend, path string, options BackupOptions) (*BackupManifest, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	manifest := &BackupManifest{
+		Format:    backupFormat,
+		Version:   backupVersion,
+		CreatedAt: time.Now().Format(time.RFC3339),
+		Backend:   store.Name(),
+	}
+	archive := zip.NewWriter(tmp)
+	add := func(name string, data []byte) error {
+		w, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+
+	if settings, err := store.GetSettings(); err == nil {
+		if err := add("settings.json", settings); err != nil {
+			return nil, err
+		}
+		manifest.Settings = true
+	}
+
+	flows, err := store.ListFlows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list flows: %w", err)
+	}
+	for _, summary := range flows {
+		data, err := store.GetFlow(summary.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read flow %s: %w", summary.ID, err)
+		}
+		if err := add("flows/"+summary.ID+".json", data); err != nil {
+			return nil, err
+		}
+		if tests, err := os.ReadFile(s.getFlowTestsPath(summary.ID)); err == nil {
+			if err := add("flows/"+summary.ID+flowTestsSuffix, tests); err != nil {
+				return nil, err
+			}
+		}
+		manifest.Flows++
+	}
+
+	entries, err := os.ReadDir(s.getCustomNodesDir())
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.getCustomNodesDir(), entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if err := add("custom-nodes/"+entry.Name(), data); err != nil {
+			return nil, err
+		}
+		manifest.CustomNodes++
+	}
+
+	if options.Executions {
+		executions, err := store.ListExecutions(0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list executions: %w", err)
+		}
+		for _, summary := range executions {
+			data, err := store.GetExecution(summary.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read execution %s: %w", summary.ID, err)
+			}
+			if err := add("executions/"+summary.ID+".json", data); err != nil {
+				return nil, err
+			}
+			manifest.Executions++
+		}
+	}
+
+	if options.Secrets {
+		count, err := s.backupSecrets(store, options.Passphrase, add)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Secrets = count
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := add(backupManifestFile, data); err != nil {
+		return nil, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	if err := tmp.Sync(); err != nil {
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	// Settings may hold API keys
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// backupSecrets decrypts every secret and seals it again under a new key
+// derived from the backup passphrase
+func (s *Storage) backupSecrets(store StorageBackend, passphrase string, add func(string, []byte) error) (int, error) {
+	names, err := store.ListSecrets()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	if len(names) == 0 {
+		return 0, nil
+	}
+	config, err := s.readVaultConfig()
+	if err != nil {
+		return 0, err
+	}
+	if config == nil {
+		return 0, errors.New("the secrets vault is missing")
+	}
+	keys, err := s.vaultKeys(config)
+	if err != nil {
+		return 0, err
+	}
+	backupKey, backupConfig, err := newVaultKey(passphrase)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, name := range names {
+		stored, err := store.GetSecret(name)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read secret %s: %w", name, err)
+		}
+		value, err := keys.open(name, stored)
+		if err != nil {
+			return 0, err
+		}
+		var previous sealedSecret
+		json.Unmarshal(stored, &previous)
+		sealed, err := sealSecret(backupKey, backupConfig.KeyID, name, value, previous.CreatedAt)
+		if err != nil {
+			return 0, err
+		}
+		if err := add("secrets/"+name+".json", sealed); err != nil {
+			return 0, err
+		}
+	}
+
+	data, err := json.MarshalIndent(backupConfig, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	return len(names), add(backupVaultFile, data)
+}
+
+// backupArchive is an open backup with its entries by name
+type backupArchive struct {
+	*zip.ReadCloser
+	manifest BackupManifest
+	files    map[string]*zip.File
+}
+
+func openBackup(path string) (*backupArchive, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	a := &backupArchive{ReadCloser: r, files: make(map[string]*zip.File)}
+	for _, f := range r.File {
+		a.files[f.Name] = f
+	}
+
+	data, err := a.read(backupManifestFile)
+	if err != nil {
+		r.Close()
+		return nil, fmt.Errorf("not a ForgeFlow backup: %w", err)
+	}
+	if err := json.Unmarshal(data, &a.manifest); err != nil || a.manifest.Format != backupFormat {
+		r.Close()
+		return nil, errors.New("not a ForgeFlow backup")
+	}
+	if a.manifest.Version > backupVersion {
+		r.Close()
+		return nil, fmt.Errorf("backup uses format version %d, but this version of ForgeFlow only supports up to %d", a.manifest.Version, backupVersion)
+	}
+	return a, nil
+}
+
+func (a *backupArchive) read(name string) ([]byte, error) {
+	f, ok := a.files[name]
+	if !ok {
+		return nil, fmt.Errorf("%s is missing: %w", name, os.ErrNotExist)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func (a *backupArchive) has(name string) bool {
+	_, ok := a.files[name]
+	return ok
+}
+
+// ids returns the sorted names of the dir/<id>.json entries
+func (a *backupArchive) ids(dir string) []string {
+	var ids []string
+	for name := range a.files {
+		rest, ok := strings.CutPrefix(name, dir+"/")
+		if !ok || strings.Contains(rest, "/") || !strings.HasSuffix(rest, ".json") {
+			continue
+		}
+		if dir == "flows" && strings.HasSuffix(rest, flowTestsSuffix) {
+			continue
+		}
+		if dir == "secrets" && name == backupVaultFile {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(rest, ".json"))
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// PreviewBackup describes the contents of a backup and which of its items
+// already exist, without changing anything
+func (s *Storage) PreviewBackup(path string) (*BackupPreview, error) {
+	archive, err := openBackup(path)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	store, unlock, err := s.reader()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	preview := &BackupPreview{
+		Manifest:    archive.manifest,
+		Flows:       []BackupItem{},
+		CustomNodes: []BackupItem{},
+		Secrets:     []BackupItem{},
+	}
+	for _, id := range archive.ids("flows") {
+		item := BackupItem{ID: id}
+		if data, err := archive.read("flows/" + id + ".json"); err == nil {
+			var flow struct {
+				Name string `json:"name"`
+			}
+			json.Unmarshal(data, &flow)
+			item.Name = flow.Name
+		}
+		_, err := store.GetFlow(id)
+		item.Exists = err == nil
+		preview.Flows = append(preview.Flows, item)
+	}
+	for _, nodeType := range archive.ids("custom-nodes") {
+		item := BackupItem{ID: nodeType}
+		if data, err := archive.read("custom-nodes/" + nodeType + ".json"); err == nil {
+			var node struct {
+				Name string `json:"name"`
+			}
+			json.Unmarshal(data, &node)
+			item.Name = node.Name
+		}
+		_, err := os.Stat(filepath.Join(s.getCustomNodesDir(), nodeType+".json"))
+		item.Exists = err == nil
+		preview.CustomNodes = append(preview.CustomNodes, item)
+	}
+	for _, name := range archive.ids("secrets") {
+		_, err := store.GetSecret(name)
+		preview.Secrets = append(preview.Secrets, BackupItem{ID: name, Exists: err == nil})
+	}
+	for _, id := range archive.ids("executions") {
+		preview.Executions++
+		if _, err := store.GetExecution(id); err == nil {
+			preview.ExecutionConflicts++
+		}
+	}
+	return preview, nil
+}
+
+// restoredFlow is a flow from a backup, migrated and ready to store
+type restoredFlow struct {
+	flowData map[string]interface{}
+	original []byte
+	from     int
+	changed  bool
+	tests    []byte
+}
+
+// RestoreBackup restores a backup made by CreateBackup. Everything is read
+// and checked before the data is touched. In replace mode the current data
+// is deleted first, after saving it to a safety backup in the data
+// directory.
+func (s *Storage) RestoreBackup(path string, options RestoreOptions) (*RestoreReport, error) {
+	if options.Mode == "" {
+		options.Mode = RestoreMerge
+	}
+	if options.OnConflict == "" {
+		options.OnConflict = ConflictSkip
+	}
+	if options.Mode != RestoreMerge && options.Mode != RestoreReplace {
+		return nil, fmt.Errorf("unknown restore mode: %s", options.Mode)
+	}
+	switch options.OnConflict {
+	case ConflictSkip, ConflictOverwrite, ConflictCopy:
+	default:
+		return nil, fmt.Errorf("unknown conflict handling: %s", options.OnConflict)
+	}
+
+	archive, err := openBackup(path)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	flows := make(map[string]*restoredFlow)
+	for _, id := range archive.ids("flows") {
+		if err := checkFlowID(id); err != nil {
+			return nil, err
+		}
+		data, err := archive.read("flows/" + id + ".json")
+		if err != nil {
+			return nil, err
+		}
+		migrated, from, changed, err := upgradeFlowJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("flow %s: %w", id, err)
+		}
+		flow := &restoredFlow{original: data, from: from, changed: changed}
+		if err := json.Unmarshal(migrated, &flow.flowData); err != nil {
+			return nil, fmt.Errorf("flow %s: %w", id, err)
+		}
+		flow.flowData["id"] = id
+		if archive.has("flows/" + id + flowTestsSuffix) {
+			if flow.tests, err = archive.read("flows/" + id + flowTestsSuffix); err != nil {
+				return nil, err
+			}
+		}
+		flows[id] = flow
+	}
+
+	customNodes := make(map[string][]byte)
+	for _, nodeType := range archive.ids("custom-nodes") {
+		if err := checkCustomNodeType(nodeType); err != nil {
+			return nil, err
+		}
+		if customNodes[nodeType], err = archive.read("custom-nodes/" + nodeType + ".json"); err != nil {
+			return nil, err
+		}
+	}
+
+	executions := make(map[string][]byte)
+	if options.Executions {
+		for _, id := range archive.ids("executions") {
+			if err := checkFlowID(id); err != nil {
+				return nil, fmt.Errorf("invalid execution ID: %q", id)
+			}
+			if executions[id], err = archive.read("executions/" + id + ".json"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var secrets map[string]secretBackupEntry
+	if options.Secrets && len(archive.ids("secrets")) > 0 {
+		if secrets, err = readBackupSecrets(archive, options.Passphrase); err != nil {
+			return nil, err
+		}
+		if err := s.initVault(); err != nil {
+			return nil, err
+		}
+	}
+
+	var settings []byte
+	if options.Settings && archive.has("settings.json") {
+		if settings, err = archive.read("settings.json"); err != nil {
+			return nil, err
+		}
+		if !json.Valid(settings) {
+			return nil, errors.New("the backup's settings.json is invalid")
+		}
+	}
+
+	report, changedFlows, err := s.restoreData(options, flows, customNodes, executions, secrets, settings)
+	if err != nil {
+		return report, err
+	}
+	if len(changedFlows) > 0 && s.onFlowsChanged != nil {
+		s.onFlowsChanged(changedFlows)
+	}
+	return report, nil
+}
+
+type secretBackupEntry struct {
+	value     []byte
+	createdAt string
+}
+
+// readBackupSecrets decrypts the secrets of a backup with its passphrase
+func readBackupSecrets(archive *backupArchive, passphrase string) (map[string]secretBackupEntry, error) {
+	if passphrase == "" {
+		return nil, errors.New("the backup passphrase is required to restore secrets")
+	}
+	data, err := archive.read(backupVaultFile)
+	if err != nil {
+		return nil, err
+	}
+	var config vaultConfig
+	if err := json.Unmarshal(data, &config); err != nil || config.Mode != SecretsPassphrase {
+		return nil, fmt.Errorf("invalid %s in backup", backupVaultFile)
+	}
+	key := deriveVaultKey(&config, passphrase)
+	if verifyVaultKey(&config, key) != nil {
+		return nil, errors.New("wrong backup passphrase")
+	}
+
+	secrets := make(map[string]secretBackupEntry)
+	for _, name := range archive.ids("secrets") {
+		if err := checkSecretName(name); err != nil {
+			return nil, err
+		}
+		stored, err := archive.read("secrets/" + name + ".json")
+		if err != nil {
+			return nil, err
+		}
+		value, err := openSecret(key, config.KeyID, name, stored)
+		if err != nil {
+			return nil, err
+		}
+		var sealed sealedSecret
+		json.Unmarshal(stored, &sealed)
+		secrets[name] = secretBackupEntry{value: value, createdAt: sealed.CreatedAt}
+	}
+	return secrets, nil
+}
+
+// restoreData writes the checked contents of a backup under the storage
+// write lock. It returns the IDs of the flows it created, changed or
+// deleted, for their triggers to be reconciled.
+func (s *Storage) restoreData(options RestoreOptions, flows map[string]*restoredFlow, customNodes map[string][]byte, executions map[string][]byte, secrets map[string]secretBackupEntry, settings []byte) (*RestoreReport, []string, error) {
+	store, unlock, err := s.writer()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer unlock()
+
+	report := &RestoreReport{}
+	var changedFlows []string
+
+	if options.Mode == RestoreReplace {
+		safety := filepath.Join(s.dataDir, "backups", "pre-restore-"+time.Now().Format("20060102-150405")+".zip")
+		_, err := s.writeBackup(store, safety, BackupOptions{
+			Executions: options.Executions,
+			Secrets:    secrets != nil,
+			Passphrase: options.Passphrase,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to back up the current data: %w", err)
+		}
+		report.SafetyBackup = safety
+
+		deleted, err := s.clearForRestore(store, options, secrets != nil)
+		if err != nil {
+			return report, deleted, err
+		}
+		changedFlows = deleted
+	}
+
+	ids := make([]string, 0, len(flows))
+	for id := range flows {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		flow := flows[id]
+		if _, err := store.GetFlow(id); err == nil {
+			switch options.OnConflict {
+			case ConflictSkip:
+				report.Skipped++
+				continue
+			case ConflictCopy:
+				newID := fmt.Sprintf("flow-%d", time.Now().UnixNano())
+				flow.flowData["id"] = newID
+				if report.Renamed == nil {
+					report.Renamed = make(map[string]string)
+				}
+				report.Renamed[id] = newID
+				if flow.tests != nil {
+					var suite map[string]interface{}
+					if json.Unmarshal(flow.tests, &suite) == nil {
+						suite["flowId"] = newID
+						flow.tests, _ = json.MarshalIndent(suite, "", "  ")
+					}
+				}
+			}
+		}
+		flowID := flow.flowData["id"].(string)
+
+		if flow.changed {
+			if err := s.backupFlow(flowID, flow.from, flow.original); err != nil {
+				return report, changedFlows, fmt.Errorf("failed to back up flow %s before migrating it: %w", flowID, err)
+			}
+		}
+		data, err := json.MarshalIndent(flow.flowData, "", "  ")
+		if err != nil {
+			return report, changedFlows, err
+		}
+		if err := store.PutFlow(flowSummary(flow.flowData), data); err != nil {
+			return report, changedFlows, fmt.Errorf("failed to restore flow %s: %w", flowID, err)
+		}
+		if flow.tests != nil {
+			if err := writeFileAtomic(s.getFlowTestsPath(flowID), flow.tests, 0644); err != nil {
+				return report, changedFlows, err
+			}
+		}
+		s.indexFlow(flow.flowData)
+		if err := s.recordFlowRevision(flow.flowData, data, "Restored from backup"); err != nil {
+			fmt.Printf("Failed to record revision of flow %s: %v\n", flowID, err)
+		}
+		s.commitFlowChange(store, flowID, gitFlowMessage("Restore", flow.flowData))
+		changedFlows = append(changedFlows, flowID)
+		report.Flows++
+	}
+
+	for nodeType, data := range customNodes {
+		path := filepath.Join(s.getCustomNodesDir(), nodeType+".json")
+		if _, err := os.Stat(path); err == nil && options.OnConflict != ConflictOverwrite {
+			report.Skipped++
+			continue
+		}
+		if err := writeFileAtomic(path, data, 0644); err != nil {
+			return report, changedFlows, err
+		}
+		report.CustomNodes++
+	}
+
+	for id, data := range executions {
+		if _, err := store.GetExecution(id); err == nil && options.OnConflict != ConflictOverwrite {
+			report.Skipped++
+			continue
+		}
+		var execution map[string]interface{}
+		if err := json.Unmarshal(data, &execution); err != nil {
+			return report, changedFlows, fmt.Errorf("invalid execution %s: %w", id, err)
+		}
+		execution["id"] = id
+		// Runs of a copied flow belong to the copy
+		if flowID, _ := execution["flowId"].(string); report.Renamed[flowID] != "" {
+			execution["flowId"] = report.Renamed[flowID]
+		}
+		if data, err = json.Marshal(execution); err != nil {
+			return report, changedFlows, err
+		}
+		if err := store.PutExecution(executionSummary(execution), data); err != nil {
+			return report, changedFlows, fmt.Errorf("failed to restore execution %s: %w", id, err)
+		}
+		report.Executions++
+	}
+
+	if secrets != nil {
+		masterKey, config, err := s.ensureVault(store)
+		if err != nil {
+			return report, changedFlows, err
+		}
+		for name, secret := range secrets {
+			if _, err := store.GetSecret(name); err == nil && options.OnConflict != ConflictOverwrite {
+				report.Skipped++
+				continue
+			}
+			sealed, err := sealSecret(masterKey, config.KeyID, name, secret.value, secret.createdAt)
+			if err != nil {
+				return report, changedFlows, err
+			}
+			if err := store.PutSecret(name, sealed); err != nil {
+				return report, changedFlows, fmt.Errorf("failed to restore secret %s: %w", name, err)
+			}
+			report.Secrets++
+		}
+	}
+
+	if settings != nil {
+		if options.Mode == RestoreMerge {
+			if settings, err = mergeSettings(store, settings, options.OnConflict == ConflictOverwrite); err != nil {
+				return report, changedFlows, err
+			}
+		}
+		if err := store.PutSettings(settings); err != nil {
+			return report, changedFlows, err
+		}
+		s.providerCache.clear()
+		report.Settings = true
+	}
+
+	return report, changedFlows, nil
+}
+
+// clearForRestore deletes the data a replace restores: flows and custom
+// node definitions always, executions and secrets when they are restored too
+func (s *Storage) clearForRestore(store StorageBackend, options RestoreOptions, withSecrets bool) ([]string, error) {
+	var deleted []string
+	flows, err := store.ListFlows()
+	if err != nil {
+		return nil, err
+	}
+	for _, summary := range flows {
+		if err := store.DeleteFlow(summary.ID); err != nil {
+			return deleted, err
+		}
+		os.Remove(s.getFlowTestsPath(summary.ID))
+		s.unindexFlow(summary.ID)
+		s.commitFlowChange(store, summary.ID, fmt.Sprintf("Delete flow %s", summary.ID))
+		deleted = append(deleted, summary.ID)
+	}
+
+	entries, err := os.ReadDir(s.getCustomNodesDir())
+	if err != nil {
+		return deleted, err
+	}
+	// Only the definitions: their version history isn't in backups, and
+	// stays for the restored nodes
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.getCustomNodesDir(), entry.Name())); err != nil {
+			return deleted, err
+		}
+	}
+
+	if options.Executions {
+		executions, err := store.ListExecutions(0)
+		if err != nil {
+			return deleted, err
+		}
+		for _, summary := range executions {
+			if err := store.DeleteExecution(summary.ID); err != nil {
+				return deleted, err
+			}
+			os.Remove(s.getExecutionLogPath(summary.ID))
+		}
+	}
+
+	if withSecrets {
+		names, err := store.ListSecrets()
+		if err != nil {
+			return deleted, err
+		}
+		for _, name := range names {
+			if err := store.DeleteSecret(name); err != nil {
+				return deleted, err
+			}
+		}
+	}
+	return deleted, nil
+}
+
+// mergeSettings adds the backup's settings to the current ones. Keys set in
+// both keep the current value unless overwrite is set.
+func mergeSettings(store StorageBackend, backup []byte, overwrite bool) ([]byte, error) {
+	merged := make(map[string]json.RawMessage)
+	if current, err := store.GetSettings(); err == nil {
+		json.Unmarshal(current, &merged)
+	}
+	var restored map[string]json.RawMessage
+	if err := json.Unmarshal(backup, &restored); err != nil {
+		return nil, fmt.Errorf("the backup's settings.json is invalid: %w", err)
+	}
+	for key, value := range restored {
+		if _, exists := merged[key]; !exists || overwrite {
+			merged[key] = value
+		}
+	}
+	return json.MarshalIndent(merged, "", "  ")
+}
+
+// backupSchedule is read from the backup* settings
+type backupSchedule struct {
+	Enabled       bool   `json:"backupEnabled"`
+	IntervalHours int    `json:"backupIntervalHours"` // defaults to 24
+	Keep          int    `json:"backupKeep"`          // defaults to 7
+	Dir           string `json:"backupDir"`           // defaults to <data dir>/backups
+	Executions    bool   `json:"backupExecutions"`
+}
+
+// backupIfDue writes a scheduled backup when the newest one is older than
+// the interval, then deletes all but the newest scheduled backups. It
+// returns the path of the new backup, or "" when none was due.
+func (s *Storage) backupIfDue() (string, error) {
+	settingsJSON, err := s.LoadSettings()
+	if err != nil {
+		return "", err
+	}
+	var schedule backupSchedule
+	json.Unmarshal([]byte(settingsJSON), &schedule)
+	if !schedule.Enabled {
+		return "", nil
+	}
+	if schedule.IntervalHours <= 0 {
+		schedule.IntervalHours = 24
+	}
+	if schedule.Keep <= 0 {
+		schedule.Keep = 7
+	}
+	if schedule.Dir == "" {
+		schedule.Dir = filepath.Join(s.dataDir, "backups")
+	}
+
+	existing, err := scheduledBackups(schedule.Dir)
+	if err != nil {
+		return "", err
+	}
+	interval := time.Duration(schedule.IntervalHours) * time.Hour
+	if len(existing) > 0 && time.Since(existing[0].modTime) < interval {
+		return "", nil
+	}
+
+	passphrase := os.Getenv(backupPassphraseEnv)
+	path := filepath.Join(schedule.Dir, scheduledBackupPrefix+time.Now().Format("20060102-150405")+".zip")
+	_, err = s.CreateBackup(path, BackupOptions{
+		Executions: schedule.Executions,
+		Secrets:    passphrase != "",
+		Passphrase: passphrase,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	existing, err = scheduledBackups(schedule.Dir)
+	if err != nil {
+		return path, err
+	}
+	for _, old := range existing[min(schedule.Keep, len(existing)):] {
+		if err := os.Remove(old.path); err != nil {
+			return path, err
+		}
+	}
+	return path, nil
+}
+
+type backupFile struct {
+	path    string
+	modTime time.Time
+}
+
+// scheduledBackups lists the scheduled backups in dir, newest first
+func scheduledBackups(dir string) ([]backupFile, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var backups []backupFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, scheduledBackupPrefix) || filepath.Ext(name) != ".zip" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{path: filepath.Join(dir, name), modTime: info.ModTime()})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].modTime.After(backups[j].modTime) })
+	return backups, nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+const testCustomNode = `{"type":"custom_ping","name":"Ping","actionType":"http","actionConfig":{"url":"https://example.com"}}`
+
+// newBackupStorage opens a storage holding a flow, a custom node saved
+// twice, an execution and a secret
+func newBackupStorage(t *testing.T) *Storage {
+	t.Helper()
+	s := NewStorageAt(t.TempDir())
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.close)
+	saveTestFlow(t, s, "flow-1", "Original")
+	for i := 0; i < 2; i++ {
+		if _, err := s.SaveCustomNode(testCustomNode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	execution := `{"id":"exec-1","flowId":"flow-1","status":"success","startedAt":"2026-01-01T00:00:00Z"}`
+	if err := s.SaveExecution(execution); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveSecret("api_key", "k-123456"); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func createTestBackup(t *testing.T, s *Storage) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "backup.zip")
+	manifest, err := s.CreateBackup(path, BackupOptions{Executions: true, Secrets: true, Passphrase: "backup pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Flows != 1 || manifest.CustomNodes != 1 || manifest.Executions != 1 || manifest.Secrets != 1 {
+		t.Fatalf("manifest = %+v", manifest)
+	}
+	return path
+}
+
+func flowName(t *testing.T, s *Storage, id string) string {
+	t.Helper()
+	data, err := s.LoadFlow(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var flow Flow
+	json.Unmarshal([]byte(data), &flow)
+	return flow.Name
+}
+
+func TestBackupRoundTrip(t *testing.T) {
+	path := createTestBackup(t, newBackupStorage(t))
+
+	s := NewStorageAt(t.TempDir())
+	t.Cleanup(s.close)
+	report, err := s.RestoreBackup(path, RestoreOptions{Settings: true, Executions: true, Secrets: true, Passphrase: "backup pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Flows != 1 || report.CustomNodes != 1 || report.Executions != 1 || report.Secrets != 1 || report.Skipped != 0 {
+		t.Errorf("report = %+v", report)
+	}
+	if name := flowName(t, s, "flow-1"); name != "Original" {
+		t.Errorf("flow name = %q", name)
+	}
+	if _, err := s.LoadCustomNode("custom_ping"); err != nil {
+		t.Error(err)
+	}
+	if _, err := s.loadExecution("exec-1"); err != nil {
+		t.Error(err)
+	}
+	if value, err := s.GetSecret("api_key"); err != nil || value != "k-123456" {
+		t.Errorf("secret = %q, %v", value, err)
+	}
+
+	if _, err := s.RestoreBackup(path, RestoreOptions{Secrets: true, Passphrase: "wrong"}); err == nil {
+		t.Error("restored secrets with a wrong passphrase")
+	}
+}
+
+func TestRestoreConflicts(t *testing.T) {
+	tests := []struct {
+		onConflict string
+		wantName   string
+		wantFlows  int
+		skipped    int
+		renamed    bool
+	}{
+		{ConflictSkip, "Changed", 0, 2, false},
+		{ConflictOverwrite, "Original", 1, 0, false},
+		{ConflictCopy, "Changed", 1, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.onConflict, func(t *testing.T) {
+			s := newBackupStorage(t)
+			path := createTestBackup(t, s)
+			saveTestFlow(t, s, "flow-1", "Changed")
+
+			report, err := s.RestoreBackup(path, RestoreOptions{OnConflict: tt.onConflict})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Flows != tt.wantFlows || report.Skipped != tt.skipped {
+				t.Errorf("report = %+v", report)
+			}
+			if name := flowName(t, s, "flow-1"); name != tt.wantName {
+				t.Errorf("flow-1 is %q, want %q", name, tt.wantName)
+			}
+			copyID := report.Renamed["flow-1"]
+			if tt.renamed != (copyID != "") {
+				t.Fatalf("renamed = %v", report.Renamed)
+			}
+			if copyID != "" && flowName(t, s, copyID) != "Original" {
+				t.Errorf("the copy is %q", flowName(t, s, copyID))
+			}
+		})
+	}
+}
+
+func TestRestoreReplaceKeepsVersions(t *testing.T) {
+	s := newBackupStorage(t)
+	path := createTestBackup(t, s)
+	saveTestFlow(t, s, "flow-2", "Extra")
+
+	report, err := s.RestoreBackup(path, RestoreOptions{Mode: RestoreReplace})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.SafetyBackup == "" || report.Flows != 1 || report.CustomNodes != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	if _, err := s.LoadFlow("flow-2"); err == nil {
+		t.Error("replace kept a flow the backup doesn't have")
+	}
+	versions, err := s.ListCustomNodeVersions("custom_ping")
+	if err != nil || len(versions) != 2 {
+		t.Errorf("custom node history lost: %v, %v", versions, err)
+	}
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// runCLI handles headless subcommands. It returns false when the arguments
+// don't name a subcommand and the GUI should start instead.
+func runCLI(args []string) (bool, int) {
+	if len(args) == 0 {
+		return false, 0
+	}
+
+	switch args[0] {
+	case "test":
+		return true, runTestCommand(args[1:])
+	case "migrate-storage":
+		return true, runMigrateStorageCommand(args[1:])
+	case "backup":
+		return true, runBackupCommand(args[1:])
+	case "restore":
+		return true, runRestoreCommand(args[1:])
+	case "diagram":
+		return true, runDiagramCommand(args[1:])
+	default:
+		return false, 0
+	}
+}
+
+// runTestCommand runs flow test cases without the GUI:
+//
+//	ForgeFlow test [-data-dir DIR] [-junit FILE] [flowID ...]
+//
+// With no flow IDs, every flow that has test cases is run.
+func runTestCommand(args []string) int {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	dataDir := fs.String("data-dir", "", "ForgeFlow data directory (defaults to the user config dir)")
+	junitPath := fs.String("junit", "", "write a JUnit XML report to this file (- for stdout)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	storage := NewStorage()
+	if *dataDir != "" {
+		storage = NewStorageAt(*dataDir)
+	}
+	if err := storage.Init(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open data directory: %v\n", err)
+		return 2
+	}
+	engine := NewEngine(storage)
+	engine.initPlugins()
+	defer engine.closePlugins()
+
+	flowIDs := fs.Args()
+	if len(flowIDs) == 0 {
+		flows, err := storage.ListFlows()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to list flows: %v\n", err)
+			return 2
+		}
+		for _, f := range flows {
+			flowID, _ := f["id"].(string)
+			if flowID == "" {
+				continue
+			}
+			if suite, err := storage.loadFlowTestSuite(flowID); err == nil && len(suite.Cases) > 0 {
+				flowIDs = append(flowIDs, flowID)
+			}
+		}
+	}
+
+	var reports []*FlowTestReport
+	exitCode := 0
+	for _, flowID := range flowIDs {
+		report, err := engine.RunFlowTests(flowID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %s: %v\n", flowID, err)
+			exitCode = 1
+			continue
+		}
+		reports = append(reports, report)
+
+		for _, r := range report.Results {
+			mark := "✅"
+			if !r.Passed {
+				mark = "❌"
+				exitCode = 1
+			}
+			fmt.Fprintf(os.Stderr, "%s %s / %s (%dms)\n", mark, report.FlowName, r.Name, r.Duration)
+			for _, f := range r.Failures {
+				fmt.Fprintf(os.Stderr, "    %s\n", f)
+			}
+		}
+	}
+
+	if *junitPath != "" {
+		var w io.Writer = os.Stdout
+		if *junitPath != "-" {
+			f, err := os.Create(*junitPath)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to create JUnit report: %v\n", err)
+				return 2
+			}
+			defer f.Close()
+			w = f
+		}
+		if err := writeJUnitReport(w, reports); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write JUnit report: %v\n", err)
+			return 2
+		}
+	}
+
+	return exitCode
+}
+
+// runMigrateStorageCommand moves all data to another storage backend:
+//
+//	ForgeFlow migrate-storage -to sqlite|json [-data-dir DIR]
+func runMigrateStorageCommand(args []string) int {
+	fs := flag.NewFlagSet("migrate-storage", flag.ContinueOnError)
+	dataDir := fs.String("data-dir", "", "ForgeFlow data directory (defaults to the user config dir)")
+	target := fs.String("to", "", "storage backend to migrate to (json or sqlite)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *target == "" {
+		fmt.Fprintln(os.Stderr, "Missing -to backend")
+		return 2
+	}
+
+	storage := NewStorage()
+	if *dataDir != "" {
+		storage = NewStorageAt(*dataDir)
+	}
+	if err := storage.Init(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open data directory: %v\n", err)
+		return 2
+	}
+	defer storage.close()
+
+	report, err := storage.MigrateStorage(*target)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "✅ Migrated %d flows, %d executions and %d secrets from %s to %s\n",
+		report.Flows, report.Executions, report.Secrets, report.From, report.To)
+	return 0
+}
+
+// runBackupCommand writes a backup archive:
+//
+//	ForgeFlow backup [-data-dir DIR] [-executions] [-secrets] FILE
+//
+// Secrets are sealed under the passphrase in FORGEFLOW_BACKUP_PASSPHRASE.
+func runBackupCommand(args []string) int {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	dataDir := fs.String("data-dir", "", "ForgeFlow data directory (defaults to the user config dir)")
+	executions := fs.Bool("executions", false, "include the execution history")
+	secrets := fs.Bool("secrets", false, "include secrets, encrypted with $"+backupPassphraseEnv)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: ForgeFlow backup [-data-dir DIR] [-executions] [-secrets] FILE")
+		return 2
+	}
+
+	storage := NewStorage()
+	if *dataDir != "" {
+		storage = NewStorageAt(*dataDir)
+	}
+	if err := storage.Init(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open data directory: %v\n", err)
+		return 2
+	}
+	defer storage.close()
+
+	manifest, err := storage.CreateBackup(fs.Arg(0), BackupOptions{
+		Executions: *executions,
+		Secrets:    *secrets,
+		Passphrase: os.Getenv(backupPassphraseEnv),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "✅ Backed up %d flows, %d custom nodes, %d executions and %d secrets to %s\n",
+		manifest.Flows, manifest.CustomNodes, manifest.Executions, manifest.Secrets, fs.Arg(0))
+	return 0
+}
+
+// runRestoreCommand restores a backup archive:
+//
+//	ForgeFlow restore [-data-dir DIR] [-mode merge|replace] [-on-conflict skip|overwrite|copy]
+//	                  [-settings] [-executions] [-secrets] [-dry-run] FILE
+//
+// The backup passphrase for secrets is read from FORGEFLOW_BACKUP_PASSPHRASE.
+func runRestoreCommand(args []string) int {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	dataDir := fs.String("data-dir", "", "ForgeFlow data directory (defaults to the user config dir)")
+	mode := fs.String("mode", RestoreMerge, "merge into the current data, or replace it")
+	onConflict := fs.String("on-conflict", ConflictSkip, "for existing IDs: skip, overwrite, or copy flows under a new ID")
+	settings := fs.Bool("settings", false, "restore settings")
+	executions := fs.Bool("executions", false, "restore the execution history")
+	secrets := fs.Bool("secrets", false, "restore secrets, decrypted with $"+backupPassphraseEnv)
+	dryRun := fs.Bool("dry-run", false, "only list what the backup contains")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: ForgeFlow restore [flags] FILE")
+		return 2
+	}
+
+	storage := NewStorage()
+	if *dataDir != "" {
+		storage = NewStorageAt(*dataDir)
+	}
+	if err := storage.Init(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open data directory: %v\n", err)
+		return 2
+	}
+	defer storage.close()
+
+	if *dryRun {
+		preview, err := storage.PreviewBackup(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "Backup of %s\n", preview.Manifest.CreatedAt)
+		for _, group := range []struct {
+			kind  string
+			items []BackupItem
+		}{{"flow", preview.Flows}, {"custom node", preview.CustomNodes}, {"secret", preview.Secrets}} {
+			for _, item := range group.items {
+				mark := "  "
+				if item.Exists {
+					mark = "! "
+				}
+				fmt.Fprintf(os.Stderr, "%s%s %s %s\n", mark, group.kind, item.ID, item.Name)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "%d executions, %d already stored\n", preview.Executions, preview.ExecutionConflicts)
+		return 0
+	}
+
+	report, err := storage.RestoreBackup(fs.Arg(0), RestoreOptions{
+		Mode:       *mode,
+		OnConflict: *onConflict,
+		Settings:   *settings,
+		Executions: *executions,
+		Secrets:    *secrets,
+		Passphrase: os.Getenv(backupPassphraseEnv),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	if report.SafetyBackup != "" {
+		fmt.Fprintf(os.Stderr, "Saved the replaced data to %s\n", report.SafetyBackup)
+	}
+	fmt.Fprintf(os.Stderr, "✅ Restored %d flows, %d custom nodes, %d executions and %d secrets; skipped %d existing items\n",
+		report.Flows, report.CustomNodes, report.Executions, report.Secrets, report.Skipped)
+	return 0
+}
+
+// runDiagramCommand renders a flow as a diagram, to stdout unless -o is set:
+//
+//	ForgeFlow diagram [-data-dir DIR] [-format mermaid|dot|svg] [-o FILE] FLOW_ID
+func runDiagramCommand(args []string) int {
+	fs := flag.NewFlagSet("diagram", flag.ContinueOnError)
+	dataDir := fs.String("data-dir", "", "ForgeFlow data directory (defaults to the user config dir)")
+	format := fs.String("format", DiagramMermaid, "diagram format: mermaid, dot or svg")
+	output := fs.String("o", "", "write the diagram to this file")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: ForgeFlow diagram [-data-dir DIR] [-format mermaid|dot|svg] [-o FILE] FLOW_ID")
+		return 2
+	}
+
+	storage := NewStorage()
+	if *dataDir != "" {
+		storage = NewStorageAt(*dataDir)
+	}
+	if err := storage.Init(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open data directory: %v\n", err)
+		return 2
+	}
+	defer storage.close()
+
+	diagram, err := storage.ExportFlowDiagram(fs.Arg(0), *format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	if *output == "" {
+		fmt.Print(diagram)
+		return 0
+	}
+	if err := os.WriteFile(*output, []byte(diagram), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "✅ Wrote %s\n", *output)
+	return 0
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// customNodePrefix marks node types created in the Custom Node Builder
+const customNodePrefix = "custom_"
+
+// customNodeVar matches {{field}} placeholders in custom node actions
+var customNodeVar = regexp.MustCompile(`\{\{(\w+)\}\}`)
+
+// CustomNodeDefinition is the part of a stored custom node the engine needs
+// to validate and run it. The full definition (color, handles, ...) is kept
+// as written by the frontend.
+type CustomNodeDefinition struct {
+	Type         string                 `json:"type"`
+	Name         string                 `json:"name"`
+	Category     string                 `json:"category"`
+	Icon         string                 `json:"icon,omitempty"`
+	Description  string                 `json:"description,omitempty"`
+	Fields       []NodeFieldSpec        `json:"fields"`
+	DefaultData  map[string]interface{} `json:"defaultData"`
+	ActionType   string                 `json:"actionType"` // "shell", "http" or "script"
+	ActionConfig CustomNodeAction       `json:"actionConfig"`
+	Version      int                    `json:"version"`
+}
+
+type CustomNodeAction struct {
+	// shell
+	Command string `json:"command,omitempty"`
+	Args    string `json:"args,omitempty"`
+	WorkDir string `json:"workDir,omitempty"`
+	// http
+	Method  string `json:"method,omitempty"`
+	URL     string `json:"url,omitempty"`
+	Headers string `json:"headers,omitempty"` // JSON object
+	Body    string `json:"body,omitempty"`
+	// script
+	Script string `json:"script,omitempty"`
+}
+
+func isCustomNodeType(nodeType string) bool {
+	return strings.HasPrefix(nodeType, customNodePrefix)
+}
+
+// customNodeTypes returns the custom node types a flow uses, in order of
+// first use
+func customNodeTypes(flow *Flow) []string {
+	seen := make(map[string]bool)
+	var types []string
+	for _, node := range flow.Nodes {
+		nodeType := node.Data.NodeType
+		if isCustomNodeType(nodeType) && !seen[nodeType] {
+			seen[nodeType] = true
+			types = append(types, nodeType)
+		}
+	}
+	return types
+}
+
+// executeCustomNode runs a custom node's action. Placeholders are filled
+// from the node's config, plus _previousOutput for the upstream output.
+func (e *Engine) executeCustomNode(ctx context.Context, def *CustomNodeDefinition, config map[string]interface{}, input interface{}) (interface{}, error) {
+	vars := make(map[string]interface{}, len(config)+1)
+	for key, value := range config {
+		vars[key] = value
+	}
+	vars["_previousOutput"] = input
+
+	lg := loggerFromContext(ctx)
+	lg.Info("Custom node: "+def.Name, "actionType", def.ActionType, "version", def.Version)
+
+	action := def.ActionConfig
+	switch def.ActionType {
+	case "shell":
+		command := interpolateCustomNode(action.Command, vars)
+		if command == "" {
+			return nil, errors.New("no command specified")
+		}
+		args := strings.Fields(interpolateCustomNode(action.Args, vars))
+		result, err := runCommand(ctx, command, args, interpolateCustomNode(action.WorkDir, vars))
+		if err != nil {
+			return nil, err
+		}
+		if exitCode := result["exitCode"].(int); exitCode != 0 {
+			lg.Warn("Command exited with an error", "exitCode", exitCode, "stderr", truncate(result["stderr"].(string), 200))
+		}
+		return result, nil
+
+	case "http":
+		method := action.Method
+		if method == "" {
+			method = "GET"
+		}
+		url := interpolateCustomNode(action.URL, vars)
+		if url == "" {
+			return nil, errors.New("no URL specified")
+		}
+		headers := map[string]string{}
+		if raw := interpolateCustomNode(action.Headers, vars); strings.TrimSpace(raw) != "" {
+			if err := json.Unmarshal([]byte(raw), &headers); err != nil {
+				lg.Warn("Invalid headers JSON, using empty headers", "error", err.Error())
+				headers = map[string]string{}
+			}
+		}
+		resp, err := doHTTPRequest(ctx, method, url, headers, interpolateCustomNode(action.Body, vars))
+		if err != nil {
+			return nil, err
+		}
+		if body, ok := resp["json"]; ok && body != nil {
+			return body, nil
+		}
+		return resp["body"], nil
+
+	case "script":
+		script := action.Script
+		if strings.TrimSpace(script) == "" {
+			script = "return input;"
+		}
+		result, err := e.executeScript(ctx, script, vars)
+		if err != nil {
+			return nil, err
+		}
+		return result.Output, nil
+	}
+	return nil, fmt.Errorf("unknown action type: %s", def.ActionType)
+}
+
+// interpolateCustomNode replaces {{field}} placeholders the way the
+// frontend executor does: missing values become empty and objects are
+// inserted as JSON
+func interpolateCustomNode(template string, vars map[string]interface{}) string {
+	return customNodeVar.ReplaceAllStringFunc(template, func(match string) string {
+		switch value := vars[match[2:len(match)-2]].(type) {
+		case nil:
+			return ""
+		case string:
+			return value
+		case float64:
+			return strconv.FormatFloat(value, 'f', -1, 64)
+		case bool:
+			return strconv.FormatBool(value)
+		default:
+			data, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Sprint(value)
+			}
+			return string(data)
+		}
+	})
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
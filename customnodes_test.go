@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSaveCustomNodeVersions(t *testing.T) {
+	s := newBundleStorage(t)
+	for i := 0; i < 3; i++ {
+		saved, err := s.SaveCustomNode(strings.Replace(testCustomNode, `"Ping"`, `"Ping `+string(rune('A'+i))+`"`, 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if saved["version"] != float64(i+1) {
+			t.Errorf("save %d stored version %v", i+1, saved["version"])
+		}
+	}
+
+	versions, err := s.ListCustomNodeVersions("custom_ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 3 {
+		t.Fatalf("got %d versions, want 3", len(versions))
+	}
+	for i, want := range []string{"Ping C", "Ping B", "Ping A"} {
+		if versions[i]["name"] != want || versions[i]["createdAt"] != versions[2]["createdAt"] {
+			t.Errorf("version %d = %v", i, versions[i])
+		}
+	}
+
+	if err := s.DeleteCustomNode("custom_ping"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.LoadCustomNode("custom_ping"); err == nil {
+		t.Error("deleted custom node can still be loaded")
+	}
+	if _, err := os.Stat(s.getCustomNodeVersionsDir("custom_ping")); !os.IsNotExist(err) {
+		t.Error("version history wasn't deleted with the node")
+	}
+}
+
+func TestSaveCustomNodeInvalid(t *testing.T) {
+	s := newBundleStorage(t)
+	for _, node := range []string{
+		`not json`,
+		`{"name":"No type","actionType":"shell"}`,
+		`{"type":"../escape","actionType":"shell"}`,
+		`{"type":"custom_a:b","actionType":"shell"}`,
+		`{"type":"custom_ok","actionType":"teleport"}`,
+		`{"type":"custom_ok"}`,
+	} {
+		if _, err := s.SaveCustomNode(node); err == nil {
+			t.Errorf("SaveCustomNode(%s) should fail", node)
+		}
+	}
+	if nodes, _ := s.ListCustomNodes(); len(nodes) != 0 {
+		t.Errorf("%d invalid custom nodes were stored", len(nodes))
+	}
+}
+
+func TestInterpolateCustomNode(t *testing.T) {
+	vars := map[string]interface{}{
+		"name": "Ann", "count": 3.5, "ok": true, "items": []interface{}{1.0, "x"},
+		"_previousOutput": map[string]interface{}{"id": 7.0},
+	}
+	tests := []struct{ template, want string }{
+		{"Hello {{name}}", "Hello Ann"},
+		{"{{count}} {{ok}}", "3.5 true"},
+		{"{{items}}", `[1,"x"]`},
+		{"{{_previousOutput}}", `{"id":7}`},
+		{"[{{missing}}]", "[]"},
+		{"{{ name }} {name}", "{{ name }} {name}"},
+	}
+	for _, tt := range tests {
+		if got := interpolateCustomNode(tt.template, vars); got != tt.want {
+			t.Errorf("interpolateCustomNode(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestExecuteCustomNode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"method":"` + r.Method + `","q":"` + r.URL.Query().Get("q") + `","token":"` + r.Header.Get("X-Token") + `"}`))
+	}))
+	defer server.Close()
+
+	e := NewEngine(newBundleStorage(t))
+	tests := []struct {
+		name  string
+		def   CustomNodeDefinition
+		check string // expected output, as JSON
+	}{
+		{"script", CustomNodeDefinition{ActionType: "script", ActionConfig: CustomNodeAction{Script: `return input.greeting + ", " + input._previousOutput.name`}},
+			`"Hi, Ann"`},
+		{"empty script", CustomNodeDefinition{ActionType: "script"},
+			`{"greeting":"Hi","query":"a b","_previousOutput":{"name":"Ann"}}`},
+		{"http", CustomNodeDefinition{ActionType: "http", ActionConfig: CustomNodeAction{Method: "POST", URL: server.URL + "?q={{greeting}}", Headers: `{"X-Token":"{{greeting}}"}`}},
+			`{"method":"POST","q":"Hi","token":"Hi"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.def.Name = tt.name
+			got, err := e.executeCustomNode(context.Background(), &tt.def, map[string]interface{}{"greeting": "Hi", "query": "a b"}, map[string]interface{}{"name": "Ann"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msg := compareValues(got, decodeJSON(t, tt.check)); msg != "" {
+				t.Error(msg)
+			}
+		})
+	}
+
+	if _, err := e.executeCustomNode(context.Background(), &CustomNodeDefinition{ActionType: "http"}, nil, nil); err == nil || err.Error() != "no URL specified" {
+		t.Errorf("http without URL: %v", err)
+	}
+}
@@ -3,7 +3,10 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -44,87 +47,273 @@ type FlowEdge struct {
 }
 
 type Flow struct {
-	ID          string     `json:"id"`
-	Name        string     `json:"name"`
-	Description string     `json:"description,omitempty"`
-	Nodes       []FlowNode `json:"nodes"`
-	Edges       []FlowEdge `json:"edges"`
-	Enabled     bool       `json:"enabled"`
-	CreatedAt   string     `json:"createdAt"`
-	UpdatedAt   string     `json:"updatedAt"`
+	SchemaVersion int        `json:"schemaVersion"`
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	Description   string     `json:"description,omitempty"`
+	Nodes         []FlowNode `json:"nodes"`
+	Edges         []FlowEdge `json:"edges"`
+	Enabled       bool       `json:"enabled"`
+	CreatedAt     string     `json:"createdAt"`
+	UpdatedAt     string     `json:"updatedAt"`
 }
 
 type ExecutionResult struct {
-	NodeID    string      `json:"nodeId"`
-	Status    NodeStatus  `json:"status"`
-	Output    interface{} `json:"output,omitempty"`
-	Error     string      `json:"error,omitempty"`
-	Duration  int64       `json:"duration"`
-	Timestamp string      `json:"timestamp"`
+	NodeID    string                 `json:"nodeId"`
+	Status    NodeStatus             `json:"status"`
+	Output    interface{}            `json:"output,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	Branch    string                 `json:"branch,omitempty"`
+	Retries   int                    `json:"retries,omitempty"`
+	Config    map[string]interface{} `json:"config,omitempty"` // node config as run, with type defaults; secret references stay unresolved
+	Duration  int64                  `json:"duration"`
+	Timestamp string                 `json:"timestamp"`
 }
 
+// executionTimeFormat is RFC 3339 with milliseconds, so the durations of
+// short runs can be compared
+const executionTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
 type FlowExecution struct {
 	ID        string            `json:"id"`
 	FlowID    string            `json:"flowId"`
+	FlowName  string            `json:"flowName,omitempty"`
+	Trigger   string            `json:"trigger,omitempty"`
 	Status    NodeStatus        `json:"status"`
 	Results   []ExecutionResult `json:"results"`
 	StartedAt string            `json:"startedAt"`
 	EndedAt   string            `json:"endedAt,omitempty"`
 }
 
+// runOptions carries per-run overrides, used by flow tests to inject a
+// trigger payload and replace node outputs with fixtures.
+type runOptions struct {
+	triggerInput interface{}
+	mocks        map[string]NodeMock
+	traceparent  string // W3C trace context of the caller, e.g. a webhook request
+	trigger      string // trigger type for background runs, e.g. "schedule"
+}
+
+// booleanBranchTypes route to their "true"/"false" handle based on output
+var booleanBranchTypes = map[string]bool{
+	"condition_if":              true,
+	"condition_type_check":      true,
+	"condition_is_empty":        true,
+	"condition_date_compare":    true,
+	"condition_array_contains":  true,
+	"condition_manual_approval": true,
+}
+
 type Engine struct {
 	ctx        context.Context
 	mu         sync.RWMutex
 	executions map[string]*FlowExecution
 	cancel     map[string]context.CancelFunc
 	storage    *Storage
+	tracer     *Tracer
+
+	pluginsOnce sync.Once
+	plugins     *PluginManager
 }
 
 func NewEngine(storage *Storage) *Engine {
-	return &Engine{
+	e := &Engine{
 		executions: make(map[string]*FlowExecution),
 		cancel:     make(map[string]context.CancelFunc),
 		storage:    storage,
 	}
+
+	metrics.gaugeFunc("forgeflow_execution_queue_depth", "Flow executions queued or running.", func() map[string]float64 {
+		e.mu.RLock()
+		defer e.mu.RUnlock()
+		return map[string]float64{"": float64(len(e.cancel))}
+	})
+
+	return e
 }
 
 func (e *Engine) RunFlow(flowJSON string) (*FlowExecution, error) {
+	return e.runFlow(flowJSON, runOptions{})
+}
+
+func (e *Engine) runFlow(flowJSON string, opts runOptions) (*FlowExecution, error) {
 	var flow Flow
 	if err := json.Unmarshal([]byte(flowJSON), &flow); err != nil {
 		return nil, fmt.Errorf("invalid flow: %w", err)
 	}
+	e.applyNodeDefaults(&flow)
+	if problems := e.validateFlow(&flow, nil); len(problems) > 0 {
+		return nil, fmt.Errorf("invalid flow: %s", strings.Join(problems, "; "))
+	}
 
-	execID := fmt.Sprintf("exec-%d", time.Now().UnixNano())
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-
-	execution := &FlowExecution{
-		ID:        execID,
-		FlowID:    flow.ID,
-		Status:    StatusRunning,
-		Results:   []ExecutionResult{},
-		StartedAt: time.Now().Format(time.RFC3339),
+	red := newRedactor()
+	ctx = contextWithRedactor(ctx, red)
+	execution := newExecution(flow.ID)
+	execution.FlowName = flow.Name
+	execution.Trigger = opts.trigger
+	if execution.Trigger == "" {
+		execution.Trigger = "manual"
 	}
 
 	e.mu.Lock()
-	e.executions[execID] = execution
-	e.cancel[execID] = cancel
+	e.executions[execution.ID] = execution
+	e.cancel[execution.ID] = cancel
+	tracer := e.tracer
 	e.mu.Unlock()
 
-	go e.executeFlow(ctx, &flow, execution)
+	// Every run is persisted with its log stream. The editor executor runs
+	// flows in the frontend and records them itself (AppendExecutionLogs).
+	var lg *Logger
+	execLog, err := e.storage.openExecutionLog(execution.ID)
+	if err != nil {
+		fmt.Printf("Execution %s will not be logged: %v\n", execution.ID, err)
+	} else {
+		lg = execLog.Logger().withRedactor(red)
+		ctx = contextWithLogger(ctx, lg)
+	}
+
+	var root *Span
+	if tracer != nil {
+		kind := SpanKindInternal
+		if opts.traceparent != "" {
+			kind = SpanKindServer
+		}
+		root = tracer.StartTrace("flow "+flow.Name, opts.traceparent, kind)
+		root.SetAttribute("forgeflow.flow.id", flow.ID)
+		root.SetAttribute("forgeflow.flow.name", flow.Name)
+		root.SetAttribute("forgeflow.execution.id", execution.ID)
+		ctx = contextWithSpan(ctx, root)
+	}
+
+	metricExecutionsStarted.Inc(flow.ID)
+	lg.Info("Starting flow: "+flow.Name, "flowId", flow.ID, "trigger", execution.Trigger, "nodes", len(flow.Nodes))
+
+	go func() {
+		start := time.Now()
+		e.executeFlow(ctx, &flow, execution, opts)
+		e.mu.RLock()
+		status := execution.Status
+		e.mu.RUnlock()
+		metricExecutionsFinished.Inc(flow.ID, string(status))
+
+		level := LogSuccess
+		if status != StatusSuccess {
+			level = LogError
+		}
+		lg.Log(level, "Execution finished", "status", string(status), "duration", time.Since(start).Milliseconds())
+
+		if root != nil {
+			root.SetAttribute("forgeflow.execution.status", string(status))
+			if status == StatusError {
+				root.SetError("execution failed")
+			}
+			root.End()
+			if err := tracer.Flush(root); err != nil {
+				lg.Warn("Failed to export trace", "error", err.Error())
+			}
+		}
+
+		e.mu.RLock()
+		data, err := json.Marshal(execution)
+		e.mu.RUnlock()
+		if err == nil {
+			err = e.storage.SaveExecution(string(data))
+		}
+		if err != nil {
+			lg.Error("Failed to save execution", "error", err.Error())
+		}
+
+		if execLog != nil {
+			execLog.Close()
+		}
+	}()
 
 	return execution, nil
 }
 
-func (e *Engine) executeFlow(ctx context.Context, flow *Flow, execution *FlowExecution) {
+// ValidateFlow checks that a flow can run and returns the problems found
+func (e *Engine) ValidateFlow(flowJSON string) ([]string, error) {
+	var flow Flow
+	if err := json.Unmarshal([]byte(flowJSON), &flow); err != nil {
+		return nil, fmt.Errorf("invalid flow: %w", err)
+	}
+	e.applyNodeDefaults(&flow)
+	problems := e.validateFlow(&flow, nil)
+	if problems == nil {
+		problems = []string{}
+	}
+	return problems, nil
+}
+
+// validateFlow reports nodes that can't be run: unknown node types, custom
+// node types with no stored definition and configs that don't fit their
+// type's schema. Mocked nodes are not run, so they aren't checked.
+func (e *Engine) validateFlow(flow *Flow, mocks map[string]NodeMock) []string {
+	return validateFlowNodes(flow, mocks, e.nodeTypeSpec)
+}
+
+func validateFlowNodes(flow *Flow, mocks map[string]NodeMock, nodeTypeSpec func(string) (*NodeTypeSpec, bool)) []string {
+	var problems []string
+	for _, node := range flow.Nodes {
+		nodeType := node.Data.NodeType
+		if _, mocked := mocks[node.ID]; mocked || nodeType == "" {
+			continue
+		}
+		spec, ok := nodeTypeSpec(nodeType)
+		switch {
+		case !ok && isCustomNodeType(nodeType):
+			problems = append(problems, fmt.Sprintf("node %q uses unknown custom node type %s", node.Data.Label, nodeType))
+		case !ok:
+			problems = append(problems, fmt.Sprintf("node %q has unknown node type %s", node.Data.Label, nodeType))
+		default:
+			for _, problem := range checkNodeConfig(spec, node.Data.Config) {
+				problems = append(problems, fmt.Sprintf("node %q: %s", node.Data.Label, problem))
+			}
+		}
+	}
+	return problems
+}
+
+// initTracing configures trace export from settings. Called once storage is
+// ready; changing the exporter requires a restart.
+func (e *Engine) initTracing() {
+	tracer, err := newTracerFromSettings(e.storage)
+	if err != nil {
+		fmt.Printf("Tracing disabled: %v\n", err)
+		return
+	}
+	e.mu.Lock()
+	e.tracer = tracer
+	e.mu.Unlock()
+}
+
+// activeTracer returns the tracer, or nil when tracing is disabled
+func (e *Engine) activeTracer() *Tracer {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.tracer
+}
+
+func newExecution(flowID string) *FlowExecution {
+	return &FlowExecution{
+		ID:        fmt.Sprintf("exec-%d", time.Now().UnixNano()),
+		FlowID:    flowID,
+		Status:    StatusRunning,
+		Results:   []ExecutionResult{},
+		StartedAt: time.Now().Format(executionTimeFormat),
+	}
+}
+
+func (e *Engine) executeFlow(ctx context.Context, flow *Flow, execution *FlowExecution, opts runOptions) {
 	defer func() {
 		e.mu.Lock()
 		delete(e.cancel, execution.ID)
-		execution.EndedAt = time.Now().Format(time.RFC3339)
+		execution.EndedAt = time.Now().Format(executionTimeFormat)
 		if execution.Status == StatusRunning {
 			execution.Status = StatusSuccess
 		}
 		e.mu.Unlock()
-		// NOTE: Execution history is saved by the frontend which has more complete data
 	}()
 
 	nodeMap := make(map[string]*FlowNode)
@@ -132,21 +321,21 @@ func (e *Engine) executeFlow(ctx context.Context, flow *Flow, execution *FlowExe
 		nodeMap[flow.Nodes[i].ID] = &flow.Nodes[i]
 	}
 
-	adjacency := make(map[string][]string)
+	adjacency := make(map[string][]FlowEdge)
 	inDegree := make(map[string]int)
 	for _, node := range flow.Nodes {
-		adjacency[node.ID] = []string{}
+		adjacency[node.ID] = []FlowEdge{}
 		inDegree[node.ID] = 0
 	}
 	for _, edge := range flow.Edges {
-		adjacency[edge.Source] = append(adjacency[edge.Source], edge.Target)
+		adjacency[edge.Source] = append(adjacency[edge.Source], edge)
 		inDegree[edge.Target]++
 	}
 
 	var startNodes []string
-	for nodeID, degree := range inDegree {
-		if degree == 0 {
-			startNodes = append(startNodes, nodeID)
+	for _, node := range flow.Nodes {
+		if inDegree[node.ID] == 0 {
+			startNodes = append(startNodes, node.ID)
 		}
 	}
 
@@ -154,41 +343,247 @@ func (e *Engine) executeFlow(ctx context.Context, flow *Flow, execution *FlowExe
 		if ctx.Err() != nil {
 			return
 		}
-		e.executeNode(ctx, nodeMap[nodeID], execution, nodeMap, adjacency)
+		if !e.executeNode(ctx, nodeMap[nodeID], nil, execution, nodeMap, adjacency, opts) {
+			return
+		}
 	}
 }
 
-func (e *Engine) executeNode(ctx context.Context, node *FlowNode, execution *FlowExecution, nodeMap map[string]*FlowNode, adjacency map[string][]string) {
+// executeNode runs a node and its downstream branch. input is the output of
+// the upstream node. It returns false when the node failed, which aborts the
+// rest of the flow.
+func (e *Engine) executeNode(ctx context.Context, node *FlowNode, input interface{}, execution *FlowExecution, nodeMap map[string]*FlowNode, adjacency map[string][]FlowEdge, opts runOptions) bool {
 	if ctx.Err() != nil {
-		return
+		return false
+	}
+
+	result, edges := e.processNode(ctx, node, input, adjacency[node.ID], opts)
+
+	e.mu.Lock()
+	execution.Results = append(execution.Results, result)
+	if result.Status == StatusError {
+		execution.Status = StatusError
+	}
+	e.mu.Unlock()
+
+	if result.Status == StatusError {
+		return false
+	}
+
+	for _, edge := range edges {
+		if nextNode, ok := nodeMap[edge.Target]; ok {
+			if !e.executeNode(ctx, nextNode, result.Output, execution, nodeMap, adjacency, opts) {
+				return false
+			}
+		}
 	}
+	return true
+}
 
+// processNode runs a single node (with retries, tracing and logging) and
+// returns its result along with the outgoing edges to follow.
+func (e *Engine) processNode(ctx context.Context, node *FlowNode, input interface{}, edges []FlowEdge, opts runOptions) (ExecutionResult, []FlowEdge) {
 	start := time.Now()
 	result := ExecutionResult{
 		NodeID:    node.ID,
 		Status:    StatusRunning,
-		Timestamp: start.Format(time.RFC3339),
+		Config:    node.Data.Config,
+		Timestamp: start.Format(executionTimeFormat),
 	}
 
-	time.Sleep(100 * time.Millisecond)
+	lg := loggerFromContext(ctx).Node(node.ID)
+	ctx = contextWithLogger(ctx, lg)
+	red := redactorFromContext(ctx)
+
+	var span *Span
+	if parent := spanFromContext(ctx); parent != nil {
+		span = parent.StartChild("node "+node.Data.NodeType, SpanKindInternal)
+		span.SetAttribute("forgeflow.node.id", node.ID)
+		span.SetAttribute("forgeflow.node.type", node.Data.NodeType)
+		span.SetAttribute("forgeflow.node.label", node.Data.Label)
+		ctx = contextWithSpan(ctx, span)
+		defer span.End()
+	}
 
+	lg.Info("Executing: "+node.Data.Label, "nodeType", node.Data.NodeType)
+
+	// Failed nodes are retried up to config.retries times
+	retries := configInt(node.Data.Config, "retries")
+	retryDelay := time.Duration(configInt(node.Data.Config, "retryDelay")) * time.Millisecond
+
+	_, mocked := opts.mocks[node.ID]
+	output, err := e.runNode(ctx, node, input, opts)
+	for attempt := 1; err != nil && attempt <= retries && ctx.Err() == nil; attempt++ {
+		lg.Warn("Retrying after error", "attempt", attempt, "error", err.Error())
+		if span != nil {
+			span.AddEvent("retry", map[string]interface{}{"attempt": attempt, "error": red.String(err.Error())})
+		}
+		time.Sleep(retryDelay)
+		result.Retries = attempt
+		output, err = e.runNode(ctx, node, input, opts)
+	}
+
+	// Outputs and errors are passed on and stored, so secrets are masked
+	result.Output = red.Value(output)
 	result.Status = StatusSuccess
+	if err != nil {
+		result.Status = StatusError
+		result.Error = red.String(err.Error())
+	}
 	result.Duration = time.Since(start).Milliseconds()
-	result.Output = map[string]interface{}{
+	if !mocked {
+		metricNodeDuration.ObserveSince(start, node.Data.NodeType)
+	}
+
+	if result.Status == StatusSuccess {
+		result.Branch, edges = selectBranch(node, result.Output, edges)
+		lg.Log(LogSuccess, "Completed: "+node.Data.Label, "duration", result.Duration)
+		if result.Branch != "" {
+			lg.Info("Taking branch: "+result.Branch, "branch", result.Branch)
+		}
+	} else {
+		lg.Error("Failed: "+node.Data.Label, "error", result.Error, "retries", result.Retries)
+	}
+
+	if span != nil {
+		span.SetAttribute("forgeflow.node.retries", result.Retries)
+		if result.Branch != "" {
+			span.SetAttribute("forgeflow.node.branch", result.Branch)
+		}
+		if result.Error != "" {
+			span.SetError(result.Error)
+		}
+	}
+
+	return result, edges
+}
+
+// runNode produces a node's output: a test mock, the injected trigger input,
+// a plugin's result, or the simulated execution result. Plugins and custom
+// nodes get their config with secret references resolved.
+func (e *Engine) runNode(ctx context.Context, node *FlowNode, input interface{}, opts runOptions) (interface{}, error) {
+	if mock, ok := opts.mocks[node.ID]; ok {
+		if mock.Error != "" {
+			return mock.Output, errors.New(mock.Error)
+		}
+		return mock.Output, nil
+	}
+	if opts.triggerInput != nil && node.Data.Category == "trigger" {
+		return opts.triggerInput, nil
+	}
+	if pm := e.pluginManager(); pm.Has(node.Data.NodeType) {
+		config, err := e.resolveSecretRefs(ctx, node.Data.Config)
+		if err != nil {
+			return nil, err
+		}
+		result, err := e.executePlugin(ctx, node.Data.NodeType, config, input)
+		if err != nil {
+			return nil, err
+		}
+		return result.Output, nil
+	}
+	if isCustomNodeType(node.Data.NodeType) {
+		def, err := e.storage.loadCustomNodeDefinition(node.Data.NodeType)
+		if err != nil {
+			return nil, err
+		}
+		config, err := e.resolveSecretRefs(ctx, node.Data.Config)
+		if err != nil {
+			return nil, err
+		}
+		return e.executeCustomNode(ctx, def, config, input)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	return map[string]interface{}{
 		"nodeId":   node.ID,
 		"category": node.Data.Category,
 		"executed": true,
+	}, nil
+}
+
+// configInt reads a numeric config value that may be stored as a number or
+// a string
+func configInt(config map[string]interface{}, key string) int {
+	switch v := config[key].(type) {
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(strings.TrimSpace(v))
+		return n
 	}
+	return 0
+}
 
-	e.mu.Lock()
-	execution.Results = append(execution.Results, result)
-	e.mu.Unlock()
+// selectBranch picks the outgoing edges to follow for branching node types,
+// mirroring the handle names used by the frontend executor.
+func selectBranch(node *FlowNode, output interface{}, edges []FlowEdge) (string, []FlowEdge) {
+	nodeType := node.Data.NodeType
+	var branch string
+	switch {
+	case booleanBranchTypes[nodeType]:
+		branch = "false"
+		if b, ok := output.(bool); ok && b {
+			branch = "true"
+		}
+	case nodeType == "condition_switch":
+		branch = fmt.Sprintf("%v", output)
+		if len(edgesWithHandle(edges, branch)) == 0 {
+			branch = "default"
+		}
+	default:
+		return "", edges
+	}
+	return branch, edgesWithHandle(edges, branch)
+}
 
-	for _, nextID := range adjacency[node.ID] {
-		if nextNode, ok := nodeMap[nextID]; ok {
-			e.executeNode(ctx, nextNode, execution, nodeMap, adjacency)
+func edgesWithHandle(edges []FlowEdge, handle string) []FlowEdge {
+	var matched []FlowEdge
+	for _, edge := range edges {
+		if edge.SourceHandle == handle {
+			matched = append(matched, edge)
+		}
+	}
+	return matched
+}
+
+// lookupPath resolves a dotted path such as "user.name" or "items[0].id"
+// against a decoded JSON value.
+func lookupPath(value interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return value, true
+	}
+	for _, part := range strings.Split(path, ".") {
+		name := part
+		var indices []int
+		if i := strings.Index(part, "["); i >= 0 {
+			name = part[:i]
+			for _, idx := range strings.Split(strings.TrimSuffix(part[i+1:], "]"), "][") {
+				n, err := strconv.Atoi(idx)
+				if err != nil {
+					return nil, false
+				}
+				indices = append(indices, n)
+			}
+		}
+		if name != "" {
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if value, ok = m[name]; !ok {
+				return nil, false
+			}
+		}
+		for _, idx := range indices {
+			arr, ok := value.([]interface{})
+			if !ok || idx < 0 || idx >= len(arr) {
+				return nil, false
+			}
+			value = arr[idx]
 		}
 	}
+	return value, true
 }
 
 func (e *Engine) StopExecution(execID string) error {
@@ -199,7 +594,7 @@ func (e *Engine) StopExecution(execID string) error {
 		cancel()
 		if exec, ok := e.executions[execID]; ok {
 			exec.Status = StatusError
-			exec.EndedAt = time.Now().Format(time.RFC3339)
+			exec.EndedAt = time.Now().Format(executionTimeFormat)
 		}
 		return nil
 	}
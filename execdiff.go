@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ExecutionDiff compares two executions node by node
+type ExecutionDiff struct {
+	ExecutionA    string     `json:"executionA"`
+	ExecutionB    string     `json:"executionB"`
+	StatusA       NodeStatus `json:"statusA"`
+	StatusB       NodeStatus `json:"statusB"`
+	StatusChanged bool       `json:"statusChanged"`
+	DurationA     int64      `json:"durationA"`
+	DurationB     int64      `json:"durationB"`
+	DurationDelta int64      `json:"durationDelta"`
+	ChangedNodes  int        `json:"changedNodes"`
+	Nodes         []NodeDiff `json:"nodes"`
+}
+
+// NodeDiff describes how one node's result differs between two executions.
+// Presence is "both", "onlyA" or "onlyB".
+type NodeDiff struct {
+	NodeID        string       `json:"nodeId"`
+	Presence      string       `json:"presence"`
+	Changed       bool         `json:"changed"`
+	StatusA       NodeStatus   `json:"statusA,omitempty"`
+	StatusB       NodeStatus   `json:"statusB,omitempty"`
+	StatusChanged bool         `json:"statusChanged"`
+	DurationA     int64        `json:"durationA"`
+	DurationB     int64        `json:"durationB"`
+	DurationDelta int64        `json:"durationDelta"`
+	BranchA       string       `json:"branchA,omitempty"`
+	BranchB       string       `json:"branchB,omitempty"`
+	BranchChanged bool         `json:"branchChanged"`
+	ErrorA        string       `json:"errorA,omitempty"`
+	ErrorB        string       `json:"errorB,omitempty"`
+	OutputChanges []JSONChange `json:"outputChanges,omitempty"`
+	ConfigChanges []JSONChange `json:"configChanges,omitempty"`
+}
+
+// JSONChange is one difference found by diffJSON. Kind is "added",
+// "removed" or "changed"; Path uses the same syntax as variable references
+// (e.g. "items[0].id"), empty for the root value.
+type JSONChange struct {
+	Path   string      `json:"path"`
+	Kind   string      `json:"kind"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// DiffExecutions compares execution a (typically the last good run) with b,
+// aligning node results by node ID. Executions are looked up in memory
+// first, then in the saved history.
+func (e *Engine) DiffExecutions(a, b string) (*ExecutionDiff, error) {
+	execA, err := e.findExecution(a)
+	if err != nil {
+		return nil, err
+	}
+	execB, err := e.findExecution(b)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return diffExecutions(execA, execB), nil
+}
+
+func (e *Engine) findExecution(execID string) (*FlowExecution, error) {
+	e.mu.RLock()
+	exec, ok := e.executions[execID]
+	e.mu.RUnlock()
+	if ok {
+		return exec, nil
+	}
+	return e.storage.loadExecution(execID)
+}
+
+func diffExecutions(a, b *FlowExecution) *ExecutionDiff {
+	diff := &ExecutionDiff{
+		ExecutionA:    a.ID,
+		ExecutionB:    b.ID,
+		StatusA:       a.Status,
+		StatusB:       b.Status,
+		StatusChanged: a.Status != b.Status,
+		DurationA:     executionDuration(a),
+		DurationB:     executionDuration(b),
+		Nodes:         []NodeDiff{},
+	}
+	diff.DurationDelta = diff.DurationB - diff.DurationA
+
+	// Nodes inside loops report several results; the last one wins
+	resultsA := make(map[string]ExecutionResult)
+	resultsB := make(map[string]ExecutionResult)
+	var order []string
+	for _, r := range a.Results {
+		if _, seen := resultsA[r.NodeID]; !seen {
+			order = append(order, r.NodeID)
+		}
+		resultsA[r.NodeID] = r
+	}
+	for _, r := range b.Results {
+		if _, seen := resultsB[r.NodeID]; !seen {
+			if _, inA := resultsA[r.NodeID]; !inA {
+				order = append(order, r.NodeID)
+			}
+		}
+		resultsB[r.NodeID] = r
+	}
+
+	for _, nodeID := range order {
+		ra, inA := resultsA[nodeID]
+		rb, inB := resultsB[nodeID]
+
+		nd := NodeDiff{NodeID: nodeID}
+		switch {
+		case inA && inB:
+			nd.Presence = "both"
+		case inA:
+			nd.Presence = "onlyA"
+		default:
+			nd.Presence = "onlyB"
+		}
+
+		nd.StatusA, nd.StatusB = ra.Status, rb.Status
+		nd.DurationA, nd.DurationB = ra.Duration, rb.Duration
+		nd.BranchA, nd.BranchB = ra.Branch, rb.Branch
+		nd.ErrorA, nd.ErrorB = ra.Error, rb.Error
+
+		if inA && inB {
+			nd.StatusChanged = ra.Status != rb.Status
+			nd.DurationDelta = rb.Duration - ra.Duration
+			nd.BranchChanged = ra.Branch != rb.Branch
+			nd.OutputChanges = diffJSON("", normalizeJSON(ra.Output), normalizeJSON(rb.Output))
+			nd.ConfigChanges = diffJSON("", normalizeJSON(ra.Config), normalizeJSON(rb.Config))
+		}
+
+		nd.Changed = nd.Presence != "both" || nd.StatusChanged || nd.BranchChanged ||
+			nd.ErrorA != nd.ErrorB || len(nd.OutputChanges) > 0 || len(nd.ConfigChanges) > 0
+		if nd.Changed {
+			diff.ChangedNodes++
+		}
+		diff.Nodes = append(diff.Nodes, nd)
+	}
+
+	return diff
+}
+
+// executionDuration is the run time in milliseconds. Executions recorded
+// before timestamps had milliseconds are only accurate to the second.
+func executionDuration(exec *FlowExecution) int64 {
+	return runDuration(exec.StartedAt, exec.EndedAt)
+}
+
+// diffJSON structurally compares two decoded JSON values
+func diffJSON(path string, a, b interface{}) []JSONChange {
+	switch va := a.(type) {
+	case map[string]interface{}:
+		vb, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(va)+len(vb))
+		for k := range va {
+			keys = append(keys, k)
+		}
+		for k := range vb {
+			if _, ok := va[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		var changes []JSONChange
+		for _, k := range keys {
+			childPath := k
+			if path != "" {
+				childPath = path + "." + k
+			}
+			before, inA := va[k]
+			after, inB := vb[k]
+			switch {
+			case !inB:
+				changes = append(changes, JSONChange{Path: childPath, Kind: "removed", Before: before})
+			case !inA:
+				changes = append(changes, JSONChange{Path: childPath, Kind: "added", After: after})
+			default:
+				changes = append(changes, diffJSON(childPath, before, after)...)
+			}
+		}
+		return changes
+
+	case []interface{}:
+		vb, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		var changes []JSONChange
+		for i := 0; i < len(va) || i < len(vb); i++ {
+			childPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(vb):
+				changes = append(changes, JSONChange{Path: childPath, Kind: "removed", Before: va[i]})
+			case i >= len(va):
+				changes = append(changes, JSONChange{Path: childPath, Kind: "added", After: vb[i]})
+			default:
+				changes = append(changes, diffJSON(childPath, va[i], vb[i])...)
+			}
+		}
+		return changes
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return nil
+	}
+	switch {
+	case a == nil:
+		return []JSONChange{{Path: path, Kind: "added", After: b}}
+	case b == nil:
+		return []JSONChange{{Path: path, Kind: "removed", Before: a}}
+	}
+	return []JSONChange{{Path: path, Kind: "changed", Before: a, After: b}}
+}
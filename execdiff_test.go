@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func decodeJSON(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestDiffJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []JSONChange
+	}{
+		{"equal", `{"a":[1,{"b":2}],"c":"x"}`, `{"c":"x","a":[1,{"b":2}]}`, nil},
+		{"changed root", `1`, `"1"`, []JSONChange{{Path: "", Kind: "changed", Before: 1.0, After: "1"}}},
+		{"added key", `{"a":1}`, `{"a":1,"b":true}`, []JSONChange{{Path: "b", Kind: "added", After: true}}},
+		{"removed key", `{"a":1,"b":2}`, `{"b":2}`, []JSONChange{{Path: "a", Kind: "removed", Before: 1.0}}},
+		{"nested", `{"user":{"name":"Ann","tags":["x"]}}`, `{"user":{"name":"Bob","tags":["x","y"]}}`, []JSONChange{
+			{Path: "user.name", Kind: "changed", Before: "Ann", After: "Bob"},
+			{Path: "user.tags[1]", Kind: "added", After: "y"},
+		}},
+		{"shorter array", `{"items":[{"id":1},{"id":2}]}`, `{"items":[{"id":3}]}`, []JSONChange{
+			{Path: "items[0].id", Kind: "changed", Before: 1.0, After: 3.0},
+			{Path: "items[1]", Kind: "removed", Before: map[string]interface{}{"id": 2.0}},
+		}},
+		{"object to array", `{"a":{"b":1}}`, `{"a":[1]}`, []JSONChange{{Path: "a", Kind: "changed", Before: map[string]interface{}{"b": 1.0}, After: []interface{}{1.0}}}},
+		{"null to value", `{"a":null}`, `{"a":2}`, []JSONChange{{Path: "a", Kind: "added", After: 2.0}}},
+		{"value to null", `{"a":2}`, `{"a":null}`, []JSONChange{{Path: "a", Kind: "removed", Before: 2.0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffJSON("", decodeJSON(t, tt.a), decodeJSON(t, tt.b))
+			if msg := compareValues(got, tt.want); msg != "" {
+				t.Error(msg)
+			}
+		})
+	}
+}
+
+func TestDiffExecutions(t *testing.T) {
+	s := NewStorageAt(t.TempDir())
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.close)
+	for _, execution := range []string{
+		`{"id":"exec-good","flowId":"flow-1","status":"success","startedAt":"2026-01-01T10:00:00.000Z","endedAt":"2026-01-01T10:00:01.500Z","results":[
+			{"nodeId":"fetch","status":"success","output":{"count":2},"config":{"url":"https://a.example"},"duration":300},
+			{"nodeId":"check","status":"success","output":true,"branch":"true","duration":5},
+			{"nodeId":"loop","status":"success","output":1,"duration":10},
+			{"nodeId":"loop","status":"success","output":2,"duration":10},
+			{"nodeId":"save","status":"success","duration":100}]}`,
+		`{"id":"exec-bad","flowId":"flow-1","status":"error","startedAt":"2026-01-01T11:00:00.000Z","endedAt":"2026-01-01T11:00:01.000Z","results":[
+			{"nodeId":"fetch","status":"success","output":{"count":0},"config":{"url":"https://b.example"},"duration":250},
+			{"nodeId":"check","status":"success","output":false,"branch":"false","duration":5},
+			{"nodeId":"loop","status":"success","output":2,"duration":12},
+			{"nodeId":"alert","status":"error","error":"no recipients","duration":20}]}`,
+	} {
+		if err := s.SaveExecution(execution); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	diff, err := NewEngine(s).DiffExecutions("exec-good", "exec-bad")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.StatusChanged || diff.DurationA != 1500 || diff.DurationB != 1000 || diff.DurationDelta != -500 {
+		t.Errorf("diff = %+v", diff)
+	}
+
+	nodes := make(map[string]NodeDiff)
+	var order []string
+	for _, nd := range diff.Nodes {
+		nodes[nd.NodeID] = nd
+		order = append(order, nd.NodeID)
+	}
+	if msg := compareValues(order, []string{"fetch", "check", "loop", "save", "alert"}); msg != "" {
+		t.Errorf("node order: %s", msg)
+	}
+	tests := []struct {
+		nodeID   string
+		presence string
+		changed  bool
+		check    func(nd NodeDiff) bool
+	}{
+		{"fetch", "both", true, func(nd NodeDiff) bool {
+			return len(nd.OutputChanges) == 1 && nd.OutputChanges[0].Path == "count" && len(nd.ConfigChanges) == 1 && nd.DurationDelta == -50
+		}},
+		{"check", "both", true, func(nd NodeDiff) bool { return nd.BranchChanged && nd.BranchA == "true" && nd.BranchB == "false" }},
+		// The last result of a node that ran several times is compared
+		{"loop", "both", false, func(nd NodeDiff) bool { return nd.DurationA == 10 && nd.DurationB == 12 }},
+		{"save", "onlyA", true, func(nd NodeDiff) bool { return nd.StatusA == StatusSuccess && nd.StatusB == "" }},
+		{"alert", "onlyB", true, func(nd NodeDiff) bool { return nd.StatusB == StatusError && nd.ErrorB == "no recipients" }},
+	}
+	changed := 0
+	for _, tt := range tests {
+		nd := nodes[tt.nodeID]
+		if nd.Presence != tt.presence || nd.Changed != tt.changed || !tt.check(nd) {
+			t.Errorf("node %s: %+v", tt.nodeID, nd)
+		}
+		if tt.changed {
+			changed++
+		}
+	}
+	if diff.ChangedNodes != changed {
+		t.Errorf("changed nodes = %d, want %d", diff.ChangedNodes, changed)
+	}
+
+	if _, err := NewEngine(s).DiffExecutions("exec-good", "exec-missing"); err == nil {
+		t.Error("diff with a missing execution should fail")
+	}
+}
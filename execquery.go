@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultExecutionPageSize = 50
+	maxExecutionPageSize     = 500
+)
+
+// ExecutionQuery filters the execution history. Empty fields match
+// everything. Times are RFC3339; the duration filters only match finished
+// executions.
+type ExecutionQuery struct {
+	FlowID        string `json:"flowId,omitempty"`
+	Status        string `json:"status,omitempty"`
+	Trigger       string `json:"trigger,omitempty"`
+	StartedAfter  string `json:"startedAfter,omitempty"`  // inclusive
+	StartedBefore string `json:"startedBefore,omitempty"` // exclusive
+	MinDurationMs int64  `json:"minDurationMs,omitempty"`
+	MaxDurationMs int64  `json:"maxDurationMs,omitempty"`
+	Cursor        string `json:"cursor,omitempty"` // NextCursor of the previous page
+	Limit         int    `json:"limit,omitempty"`  // defaults to 50, at most 500
+}
+
+// ExecutionPage is one page of QueryExecutions, newest first. Total counts
+// every execution matching the query; StatusCounts counts them per status
+// with the status filter left out, so all tabs of a status filter can show
+// their count at once.
+type ExecutionPage struct {
+	Executions   []ExecutionSummary `json:"executions"`
+	NextCursor   string             `json:"nextCursor,omitempty"` // empty on the last page
+	Total        int                `json:"total"`
+	StatusCounts map[string]int     `json:"statusCounts"`
+}
+
+// executionFilter is a validated ExecutionQuery, as handed to the backends
+type executionFilter struct {
+	flowID, status, trigger string
+	after, before           int64 // unix milliseconds, 0 for no bound
+	minDuration             int64
+	maxDuration             int64
+	cursor                  *executionCursor
+	limit                   int
+}
+
+// executionCursor is the position after the last execution of a page, in
+// the (startedAt, id) descending order of the history
+type executionCursor struct {
+	startedMs int64
+	id        string
+}
+
+func parseExecutionQuery(q ExecutionQuery) (executionFilter, error) {
+	f := executionFilter{
+		flowID:      q.FlowID,
+		status:      q.Status,
+		trigger:     q.Trigger,
+		minDuration: q.MinDurationMs,
+		maxDuration: q.MaxDurationMs,
+		limit:       q.Limit,
+	}
+	if f.limit <= 0 {
+		f.limit = defaultExecutionPageSize
+	}
+	if f.limit > maxExecutionPageSize {
+		f.limit = maxExecutionPageSize
+	}
+	if f.minDuration < 0 || f.maxDuration < 0 {
+		return f, errors.New("durations can't be negative")
+	}
+
+	for _, bound := range []struct {
+		value string
+		dest  *int64
+		name  string
+	}{{q.StartedAfter, &f.after, "startedAfter"}, {q.StartedBefore, &f.before, "startedBefore"}} {
+		if bound.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, bound.value)
+		if err != nil {
+			return f, fmt.Errorf("invalid %s: %w", bound.name, err)
+		}
+		*bound.dest = t.UnixMilli()
+	}
+
+	if q.Cursor != "" {
+		cursor, err := decodeExecutionCursor(q.Cursor)
+		if err != nil {
+			return f, err
+		}
+		f.cursor = cursor
+	}
+	return f, nil
+}
+
+func (c executionCursor) encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(c.startedMs, 10) + ":" + c.id))
+}
+
+func decodeExecutionCursor(s string) (*executionCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	ms, id, ok := strings.Cut(string(data), ":")
+	if !ok {
+		return nil, errors.New("invalid cursor")
+	}
+	startedMs, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	return &executionCursor{startedMs: startedMs, id: id}, nil
+}
+
+// executionTime converts a stored RFC3339 timestamp to unix milliseconds,
+// which unlike the strings sort correctly across time zones. Unparsable
+// times count as 0.
+func executionTime(s string) int64 {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+// runDuration is the run time in milliseconds, or 0 while running
+func runDuration(startedAt, endedAt string) int64 {
+	if endedAt == "" {
+		return 0
+	}
+	start, end := executionTime(startedAt), executionTime(endedAt)
+	if start == 0 || end < start {
+		return 0
+	}
+	return end - start
+}
+
+// matches reports whether an execution passes every filter but the status
+// and the cursor
+func (f executionFilter) matches(e ExecutionSummary) bool {
+	if f.flowID != "" && e.FlowID != f.flowID {
+		return false
+	}
+	if f.trigger != "" && e.Trigger != f.trigger {
+		return false
+	}
+	started := executionTime(e.StartedAt)
+	if f.after != 0 && started < f.after {
+		return false
+	}
+	if f.before != 0 && started >= f.before {
+		return false
+	}
+	if f.minDuration > 0 || f.maxDuration > 0 {
+		if e.EndedAt == "" {
+			return false
+		}
+		if e.DurationMs < f.minDuration || (f.maxDuration > 0 && e.DurationMs > f.maxDuration) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortExecutions orders summaries newest first, by start time and then ID
+// so that the order (and the cursor) is stable
+func sortExecutions(executions []ExecutionSummary) {
+	sort.Slice(executions, func(i, j int) bool {
+		ti, tj := executionTime(executions[i].StartedAt), executionTime(executions[j].StartedAt)
+		if ti != tj {
+			return ti > tj
+		}
+		return executions[i].ID > executions[j].ID
+	})
+}
+
+// queryExecutionSummaries runs a query over summaries held in memory, for
+// backends without a query engine of their own
+func queryExecutionSummaries(executions []ExecutionSummary, f executionFilter) *ExecutionPage {
+	sortExecutions(executions)
+
+	page := &ExecutionPage{Executions: []ExecutionSummary{}, StatusCounts: map[string]int{}}
+	for _, e := range executions {
+		if !f.matches(e) {
+			continue
+		}
+		page.StatusCounts[e.Status]++
+		if f.status != "" && e.Status != f.status {
+			continue
+		}
+		page.Total++
+
+		if f.cursor != nil {
+			started := executionTime(e.StartedAt)
+			if started > f.cursor.startedMs || (started == f.cursor.startedMs && e.ID >= f.cursor.id) {
+				continue
+			}
+		}
+		if len(page.Executions) < f.limit {
+			page.Executions = append(page.Executions, e)
+		} else if page.NextCursor == "" {
+			page.NextCursor = lastExecutionCursor(page.Executions)
+		}
+	}
+	return page
+}
+
+func lastExecutionCursor(executions []ExecutionSummary) string {
+	last := executions[len(executions)-1]
+	return executionCursor{startedMs: executionTime(last.StartedAt), id: last.ID}.encode()
+}
+
+// QueryExecutions returns a page of execution summaries matching the query,
+// newest first, with counts per status. Pass the page's NextCursor in the
+// next query to continue. Node results are never loaded.
+func (s *Storage) QueryExecutions(query ExecutionQuery) (*ExecutionPage, error) {
+	filter, err := parseExecutionQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	store, unlock, err := s.reader()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return store.QueryExecutions(filter)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// newQueryStorage opens a storage on backend holding five executions of two
+// flows, started a minute apart
+func newQueryStorage(t *testing.T, backend string) *Storage {
+	t.Helper()
+	s := NewStorageAt(t.TempDir())
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.close)
+	if backend != BackendJSON {
+		if _, err := s.MigrateStorage(backend); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, e := range []map[string]interface{}{
+		{"id": "exec-1", "flowId": "flow-a", "status": "success", "trigger": "manual", "startedAt": "2026-01-01T10:00:00Z", "endedAt": "2026-01-01T10:00:01Z"},
+		{"id": "exec-2", "flowId": "flow-a", "status": "error", "trigger": "schedule", "startedAt": "2026-01-01T10:01:00Z", "endedAt": "2026-01-01T10:01:10Z"},
+		{"id": "exec-3", "flowId": "flow-b", "status": "success", "trigger": "schedule", "startedAt": "2026-01-01T10:02:00Z", "endedAt": "2026-01-01T10:02:30Z"},
+		{"id": "exec-4", "flowId": "flow-b", "status": "running", "trigger": "webhook", "startedAt": "2026-01-01T10:03:00Z"},
+		{"id": "exec-5", "flowId": "flow-a", "status": "success", "trigger": "schedule", "startedAt": "2026-01-01T10:04:00+01:00", "endedAt": "2026-01-01T10:04:05+01:00"},
+	} {
+		data, _ := json.Marshal(e)
+		if err := s.SaveExecution(string(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func executionIDs(executions []ExecutionSummary) []string {
+	ids := []string{}
+	for _, e := range executions {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestQueryExecutions(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  ExecutionQuery
+		want   []string
+		counts map[string]int
+	}{
+		{"all", ExecutionQuery{}, []string{"exec-4", "exec-3", "exec-2", "exec-1", "exec-5"}, map[string]int{"success": 3, "error": 1, "running": 1}},
+		{"flow", ExecutionQuery{FlowID: "flow-b"}, []string{"exec-4", "exec-3"}, map[string]int{"success": 1, "running": 1}},
+		{"status", ExecutionQuery{Status: "success"}, []string{"exec-3", "exec-1", "exec-5"}, map[string]int{"success": 3, "error": 1, "running": 1}},
+		{"trigger", ExecutionQuery{Trigger: "schedule"}, []string{"exec-3", "exec-2", "exec-5"}, map[string]int{"success": 2, "error": 1}},
+		{"time range", ExecutionQuery{StartedAfter: "2026-01-01T10:01:00Z", StartedBefore: "2026-01-01T10:03:00Z"}, []string{"exec-3", "exec-2"}, map[string]int{"success": 1, "error": 1}},
+		{"time zones", ExecutionQuery{StartedBefore: "2026-01-01T10:00:00Z"}, []string{"exec-5"}, map[string]int{"success": 1}},
+		{"min duration", ExecutionQuery{MinDurationMs: 10000}, []string{"exec-3", "exec-2"}, map[string]int{"success": 1, "error": 1}},
+		{"max duration", ExecutionQuery{MaxDurationMs: 5000}, []string{"exec-1", "exec-5"}, map[string]int{"success": 2}},
+		{"no match", ExecutionQuery{FlowID: "flow-c"}, []string{}, map[string]int{}},
+	}
+	for _, backend := range []string{BackendJSON, BackendSQLite} {
+		s := newQueryStorage(t, backend)
+		for _, tt := range tests {
+			t.Run(backend+"/"+tt.name, func(t *testing.T) {
+				page, err := s.QueryExecutions(tt.query)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got := executionIDs(page.Executions)
+				if len(got) != len(tt.want) {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+				for i := range got {
+					if got[i] != tt.want[i] {
+						t.Fatalf("got %v, want %v", got, tt.want)
+					}
+				}
+				if page.Total != len(tt.want) || page.NextCursor != "" {
+					t.Errorf("total = %d, next cursor = %q", page.Total, page.NextCursor)
+				}
+				if len(page.StatusCounts) != len(tt.counts) {
+					t.Errorf("status counts = %v, want %v", page.StatusCounts, tt.counts)
+				}
+				for status, n := range tt.counts {
+					if page.StatusCounts[status] != n {
+						t.Errorf("status counts = %v, want %v", page.StatusCounts, tt.counts)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestQueryExecutionsCursor(t *testing.T) {
+	for _, backend := range []string{BackendJSON, BackendSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			s := newQueryStorage(t, backend)
+			var got []string
+			query := ExecutionQuery{Limit: 2}
+			total := 5
+			for pages := 1; ; pages++ {
+				page, err := s.QueryExecutions(query)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if page.Total != total {
+					t.Errorf("page %d: total = %d, want %d", pages, page.Total, total)
+				}
+				got = append(got, executionIDs(page.Executions)...)
+				if page.NextCursor == "" {
+					if pages != 3 {
+						t.Errorf("got %d pages, want 3", pages)
+					}
+					break
+				}
+				query.Cursor = page.NextCursor
+
+				// A newer run recorded between pages doesn't shift the next one
+				if pages == 1 {
+					if err := s.SaveExecution(`{"id":"exec-6","flowId":"flow-a","status":"success","startedAt":"2026-01-01T11:00:00Z"}`); err != nil {
+						t.Fatal(err)
+					}
+					total++
+				}
+			}
+			want := []string{"exec-4", "exec-3", "exec-2", "exec-1", "exec-5"}
+			if len(got) != len(want) {
+				t.Fatalf("got %v, want %v", got, want)
+			}
+			for i := range got {
+				if got[i] != want[i] {
+					t.Fatalf("got %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryExecutionsInvalid(t *testing.T) {
+	s := newQueryStorage(t, BackendJSON)
+	for _, query := range []ExecutionQuery{
+		{Cursor: "not a cursor"},
+		{StartedAfter: "yesterday"},
+		{MinDurationMs: -1},
+	} {
+		if _, err := s.QueryExecutions(query); err == nil {
+			t.Errorf("QueryExecutions(%+v) should fail", query)
+		}
+	}
+}
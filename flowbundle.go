@@ -0,0 +1,436 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A flow bundle carries a flow with everything it needs on another machine:
+// the flows it calls, the custom nodes they use and the names of the
+// secrets they reference. Secret values are never included; the importer
+// reports the ones to fill in.
+const (
+	bundleFormat  = "forgeflow-bundle"
+	bundleVersion = 1
+)
+
+// FlowBundleManifest lists the contents of a bundle
+type FlowBundleManifest struct {
+	RootFlow    string           `json:"rootFlow"`
+	Flows       []BundleFlowInfo `json:"flows"`
+	CustomNodes []string         `json:"customNodes"`
+	Secrets     []string         `json:"secrets"` // as referenced, e.g. "api_key" or "vault:apps/github#token"
+	Triggers    []BundleTrigger  `json:"triggers"`
+
+	// MissingCustomNodes are custom node types the flows use but that
+	// weren't found when exporting, so the bundle doesn't carry them
+	MissingCustomNodes []string `json:"missingCustomNodes"`
+}
+
+type BundleFlowInfo struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type BundleTrigger struct {
+	FlowID   string `json:"flowId"`
+	NodeType string `json:"nodeType"`
+}
+
+type flowBundle struct {
+	Format      string             `json:"format"`
+	Version     int                `json:"version"`
+	CreatedAt   string             `json:"createdAt"`
+	Manifest    FlowBundleManifest `json:"manifest"`
+	Flows       []json.RawMessage  `json:"flows"`
+	CustomNodes []json.RawMessage  `json:"customNodes"`
+}
+
+// BundleImportReport describes an ImportFlowBundle run
+type BundleImportReport struct {
+	FlowID              string            `json:"flowId"`              // new ID of the bundle's main flow
+	FlowIDs             map[string]string `json:"flowIds"`             // bundled flow ID to its new ID
+	CustomNodes         []string          `json:"customNodes"`         // added
+	ExistingCustomNodes []string          `json:"existingCustomNodes"` // already present and kept as they are
+	MissingSecrets      []string          `json:"missingSecrets"`      // to set before running the flows
+	Triggers            int               `json:"triggers"`            // trigger nodes registered
+}
+
+// ExportFlowBundle returns a flow as a bundle along with its dependencies.
+// A flow calls another when a node config value is that flow's ID; called
+// flows are followed transitively.
+func (s *Storage) ExportFlowBundle(flowID string) (string, error) {
+	summaries, err := s.ListFlows()
+	if err != nil {
+		return "", err
+	}
+	known := make(map[string]bool, len(summaries))
+	for _, f := range summaries {
+		id, _ := f["id"].(string)
+		known[id] = true
+	}
+	if !known[flowID] {
+		return "", fmt.Errorf("flow not found: %s", flowID)
+	}
+
+	bundle := flowBundle{
+		Format:    bundleFormat,
+		Version:   bundleVersion,
+		CreatedAt: time.Now().Format(time.RFC3339),
+		Manifest: FlowBundleManifest{
+			RootFlow:    flowID,
+			Flows:       []BundleFlowInfo{},
+			CustomNodes: []string{},
+			Secrets:     []string{},
+			Triggers:    []BundleTrigger{},
+
+			MissingCustomNodes: []string{},
+		},
+		CustomNodes: []json.RawMessage{},
+	}
+	nodeTypes := make(map[string]bool)
+	secrets := make(map[string]bool)
+
+	queue := []string{flowID}
+	visited := map[string]bool{flowID: true}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		flowJSON, err := s.LoadFlow(id)
+		if err != nil {
+			return "", err
+		}
+		var flow Flow
+		if err := json.Unmarshal([]byte(flowJSON), &flow); err != nil {
+			return "", fmt.Errorf("invalid flow %s: %w", id, err)
+		}
+		bundle.Flows = append(bundle.Flows, json.RawMessage(flowJSON))
+		bundle.Manifest.Flows = append(bundle.Manifest.Flows, BundleFlowInfo{ID: flow.ID, Name: flow.Name})
+
+		for _, nodeType := range customNodeTypes(&flow) {
+			nodeTypes[nodeType] = true
+		}
+		for _, node := range flow.Nodes {
+			walkConfigStrings(node.Data.Config, func(value string) {
+				if known[value] && !visited[value] {
+					visited[value] = true
+					queue = append(queue, value)
+				}
+			})
+			addSecretRefs(secrets, node.Data.Config)
+		}
+		for _, nodeType := range flowTriggerTypes(&flow) {
+			bundle.Manifest.Triggers = append(bundle.Manifest.Triggers, BundleTrigger{FlowID: flow.ID, NodeType: nodeType})
+		}
+	}
+
+	types := make([]string, 0, len(nodeTypes))
+	for nodeType := range nodeTypes {
+		types = append(types, nodeType)
+	}
+	sort.Strings(types)
+	for _, nodeType := range types {
+		data, err := s.LoadCustomNode(nodeType)
+		if err != nil {
+			bundle.Manifest.MissingCustomNodes = append(bundle.Manifest.MissingCustomNodes, nodeType)
+			continue
+		}
+		bundle.CustomNodes = append(bundle.CustomNodes, json.RawMessage(data))
+		bundle.Manifest.CustomNodes = append(bundle.Manifest.CustomNodes, nodeType)
+		// Definitions reference secrets too, e.g. in HTTP headers
+		addSecretRefs(secrets, json.RawMessage(data))
+	}
+	for name := range secrets {
+		bundle.Manifest.Secrets = append(bundle.Manifest.Secrets, name)
+	}
+	sort.Strings(bundle.Manifest.Secrets)
+
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ImportFlowBundle adds the flows of a bundle under new IDs, rewriting the
+// references between them, along with custom nodes that don't exist yet.
+// The triggers of the new flows are registered right away.
+func (s *Storage) ImportFlowBundle(bundleJSON string) (*BundleImportReport, error) {
+	var bundle flowBundle
+	if err := json.Unmarshal([]byte(bundleJSON), &bundle); err != nil {
+		return nil, fmt.Errorf("invalid bundle JSON: %w", err)
+	}
+	if bundle.Format != bundleFormat {
+		return nil, errors.New("not a ForgeFlow bundle")
+	}
+	if bundle.Version > bundleVersion {
+		return nil, fmt.Errorf("bundle uses format version %d, but this version of ForgeFlow only supports up to %d", bundle.Version, bundleVersion)
+	}
+	if len(bundle.Flows) == 0 {
+		return nil, errors.New("the bundle has no flows")
+	}
+
+	// Migrate every flow and pick the new IDs before anything is stored
+	type importedFlow struct {
+		flowData map[string]interface{}
+		original []byte
+		from     int
+		changed  bool
+	}
+	flows := make([]importedFlow, 0, len(bundle.Flows))
+	taken := make(map[string]bool)
+	report := &BundleImportReport{
+		FlowIDs:             make(map[string]string),
+		CustomNodes:         []string{},
+		ExistingCustomNodes: []string{},
+		MissingSecrets:      []string{},
+	}
+	for i, raw := range bundle.Flows {
+		migrated, from, changed, err := upgradeFlowJSON(raw)
+		if err != nil {
+			return nil, fmt.Errorf("flow %d of the bundle: %w", i+1, err)
+		}
+		var flowData map[string]interface{}
+		if err := json.Unmarshal(migrated, &flowData); err != nil {
+			return nil, fmt.Errorf("invalid flow JSON: %w", err)
+		}
+		oldID, _ := flowData["id"].(string)
+		newID := fmt.Sprintf("flow-%d", time.Now().UnixNano())
+		// The clock can be coarse enough to repeat in this loop
+		for i := 1; taken[newID]; i++ {
+			newID = fmt.Sprintf("flow-%d-%d", time.Now().UnixNano(), i)
+		}
+		taken[newID] = true
+		if oldID != "" {
+			report.FlowIDs[oldID] = newID
+		}
+		flowData["id"] = newID
+		flows = append(flows, importedFlow{flowData: flowData, original: raw, from: from, changed: changed})
+	}
+	report.FlowID = report.FlowIDs[bundle.Manifest.RootFlow]
+	if report.FlowID == "" {
+		report.FlowID, _ = flows[0].flowData["id"].(string)
+	}
+
+	// Check the custom nodes and every flow too, so a bad one doesn't leave
+	// the ones before it imported
+	secrets := make(map[string]bool)
+	var newNodes []string
+	bundled := make(map[string]*CustomNodeDefinition)
+	bundledJSON := make(map[string]json.RawMessage)
+	for _, raw := range bundle.CustomNodes {
+		_, nodeType, err := parseCustomNode(raw)
+		if err != nil {
+			return nil, err
+		}
+		if existing, err := s.LoadCustomNode(nodeType); err == nil {
+			report.ExistingCustomNodes = append(report.ExistingCustomNodes, nodeType)
+			addSecretRefs(secrets, json.RawMessage(existing))
+			continue
+		}
+		var def CustomNodeDefinition
+		if err := json.Unmarshal(raw, &def); err != nil {
+			return nil, fmt.Errorf("invalid custom node %s: %w", nodeType, err)
+		}
+		if _, dup := bundled[nodeType]; !dup {
+			newNodes = append(newNodes, nodeType)
+		}
+		bundled[nodeType] = &def
+		bundledJSON[nodeType] = raw
+		addSecretRefs(secrets, raw)
+	}
+
+	nodeTypeSpec := s.importNodeTypeSpec(bundled)
+	pending := make([]Flow, 0, len(flows))
+	for i, imported := range flows {
+		remapFlowReferences(imported.flowData, report.FlowIDs)
+		data, err := json.Marshal(imported.flowData)
+		if err != nil {
+			return nil, err
+		}
+		var flow, checked Flow
+		if err := json.Unmarshal(data, &flow); err != nil {
+			return nil, fmt.Errorf("invalid flow JSON: %w", err)
+		}
+		json.Unmarshal(data, &checked)
+		applyNodeDefaults(&checked, nodeTypeSpec)
+		if problems := validateFlowNodes(&checked, nil, nodeTypeSpec); len(problems) > 0 {
+			return nil, fmt.Errorf("flow %d of the bundle: %s", i+1, strings.Join(problems, "; "))
+		}
+		pending = append(pending, flow)
+	}
+
+	for _, nodeType := range newNodes {
+		if _, err := s.SaveCustomNode(string(bundledJSON[nodeType])); err != nil {
+			return report, fmt.Errorf("failed to import custom node %s: %w", nodeType, err)
+		}
+		report.CustomNodes = append(report.CustomNodes, nodeType)
+	}
+	var newIDs []string
+	for i := range pending {
+		flow := &pending[i]
+		imported := flows[i]
+		if err := s.putImportedFlow(flow, imported.original, imported.from, imported.changed); err != nil {
+			return report, err
+		}
+		newIDs = append(newIDs, flow.ID)
+		report.Triggers += len(flowTriggerTypes(flow))
+		for _, node := range flow.Nodes {
+			addSecretRefs(secrets, node.Data.Config)
+		}
+	}
+
+	missing, err := s.missingSecrets(secrets)
+	if err != nil {
+		return report, err
+	}
+	report.MissingSecrets = missing
+
+	if s.onFlowsChanged != nil {
+		s.onFlowsChanged(newIDs)
+	}
+	return report, nil
+}
+
+// importNodeTypeSpec looks up the node types of an imported bundle: the
+// engine's, or built-in and stored custom node types without one, and then
+// the custom nodes the bundle adds
+func (s *Storage) importNodeTypeSpec(bundled map[string]*CustomNodeDefinition) func(string) (*NodeTypeSpec, bool) {
+	return func(nodeType string) (*NodeTypeSpec, bool) {
+		if s.nodeTypeSpec != nil {
+			if spec, ok := s.nodeTypeSpec(nodeType); ok {
+				return spec, true
+			}
+		} else if spec, ok := builtinNodeTypeIndex[nodeType]; ok {
+			return spec, true
+		} else if isCustomNodeType(nodeType) {
+			if def, err := s.loadCustomNodeDefinition(nodeType); err == nil {
+				spec := def.nodeTypeSpec()
+				return &spec, true
+			}
+		}
+		if def, ok := bundled[nodeType]; ok {
+			spec := def.nodeTypeSpec()
+			return &spec, true
+		}
+		return nil, false
+	}
+}
+
+// missingSecrets returns the referenced secrets that can't be resolved:
+// local secrets that aren't set and references to unknown providers.
+// Provider values are not looked up.
+func (s *Storage) missingSecrets(refs map[string]bool) ([]string, error) {
+	missing := []string{}
+	if len(refs) == 0 {
+		return missing, nil
+	}
+	stored, err := s.ListSecrets()
+	if err != nil {
+		return nil, err
+	}
+	local := make(map[string]bool, len(stored))
+	for _, secret := range stored {
+		local[secret.Name] = true
+	}
+	providers, err := s.secretProviderConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	for ref := range refs {
+		if provider, _, ok := strings.Cut(ref, ":"); ok {
+			if _, known := providers[provider]; !known {
+				missing = append(missing, ref)
+			}
+		} else if !local[ref] {
+			missing = append(missing, ref)
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// addSecretRefs adds the secrets referenced in a node config, or in the
+// JSON of a custom node definition, to refs
+func addSecretRefs(refs map[string]bool, value interface{}) {
+	if data, ok := value.(json.RawMessage); ok {
+		value = nil
+		json.Unmarshal(data, &value)
+	}
+	walkConfigStrings(value, func(s string) {
+		for _, match := range secretRef.FindAllStringSubmatch(s, -1) {
+			refs[match[1]] = true
+		}
+	})
+}
+
+// flowTriggerTypes returns the node types of a flow's enabled triggers
+func flowTriggerTypes(flow *Flow) []string {
+	var types []string
+	for _, node := range flow.Nodes {
+		if node.Data.Category != "trigger" {
+			continue
+		}
+		if enabled, ok := node.Data.Config["enabled"].(bool); ok && !enabled {
+			continue
+		}
+		types = append(types, node.Data.NodeType)
+	}
+	return types
+}
+
+// remapFlowReferences rewrites node config values naming a bundled flow to
+// the flow's new ID
+func remapFlowReferences(flowData map[string]interface{}, ids map[string]string) {
+	nodes, _ := flowData["nodes"].([]interface{})
+	for _, n := range nodes {
+		node, _ := n.(map[string]interface{})
+		data, _ := node["data"].(map[string]interface{})
+		if config, ok := data["config"].(map[string]interface{}); ok {
+			data["config"] = rewriteConfigStrings(config, func(value string) string {
+				if newID, ok := ids[value]; ok {
+					return newID
+				}
+				return value
+			})
+		}
+	}
+}
+
+// walkConfigStrings calls fn with every string in a decoded config
+func walkConfigStrings(value interface{}, fn func(string)) {
+	switch v := value.(type) {
+	case string:
+		fn(v)
+	case map[string]interface{}:
+		for _, item := range v {
+			walkConfigStrings(item, fn)
+		}
+	case []interface{}:
+		for _, item := range v {
+			walkConfigStrings(item, fn)
+		}
+	}
+}
+
+func rewriteConfigStrings(value interface{}, fn func(string) string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return fn(v)
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = rewriteConfigStrings(item, fn)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = rewriteConfigStrings(item, fn)
+		}
+	}
+	return value
+}
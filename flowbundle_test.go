@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// testBundleFlow is a flow for a bundle with one node of each given type,
+// configured with config
+func testBundleFlow(id string, config map[string]interface{}, nodeTypes ...string) json.RawMessage {
+	nodes := []interface{}{}
+	for i, nodeType := range nodeTypes {
+		nodes = append(nodes, map[string]interface{}{
+			"id":   nodeType + "-" + string(rune('a'+i)),
+			"type": "custom",
+			"data": map[string]interface{}{"label": nodeType, "nodeType": nodeType, "config": config},
+		})
+	}
+	data, _ := json.Marshal(map[string]interface{}{"id": id, "name": id, "nodes": nodes, "edges": []interface{}{}, "schemaVersion": FlowSchemaVersion})
+	return data
+}
+
+func testBundle(flows []json.RawMessage, customNodes ...string) string {
+	nodes := []json.RawMessage{}
+	for _, node := range customNodes {
+		nodes = append(nodes, json.RawMessage(node))
+	}
+	data, _ := json.Marshal(flowBundle{
+		Format:      bundleFormat,
+		Version:     bundleVersion,
+		Manifest:    FlowBundleManifest{RootFlow: "flow-main"},
+		Flows:       flows,
+		CustomNodes: nodes,
+	})
+	return string(data)
+}
+
+func TestImportFlowBundle(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string // custom node stored before the import
+		bundle   string
+		check    func(t *testing.T, s *Storage, report *BundleImportReport)
+	}{
+		{
+			name: "references to bundled flows",
+			bundle: testBundle([]json.RawMessage{
+				testBundleFlow("flow-main", map[string]interface{}{"flowId": "flow-sub", "other": "flow-elsewhere"}, "trigger_manual"),
+				testBundleFlow("flow-sub", nil, "trigger_manual"),
+			}),
+			check: func(t *testing.T, s *Storage, report *BundleImportReport) {
+				if len(report.FlowIDs) != 2 || report.FlowID != report.FlowIDs["flow-main"] {
+					t.Fatalf("report = %+v", report)
+				}
+				var flow Flow
+				data, err := s.LoadFlow(report.FlowID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				json.Unmarshal([]byte(data), &flow)
+				config := flow.Nodes[0].Data.Config
+				if config["flowId"] != report.FlowIDs["flow-sub"] || config["other"] != "flow-elsewhere" {
+					t.Errorf("config = %v", config)
+				}
+			},
+		},
+		{
+			name:   "new custom node",
+			bundle: testBundle([]json.RawMessage{testBundleFlow("flow-main", nil, "custom_ping")}, testCustomNode),
+			check: func(t *testing.T, s *Storage, report *BundleImportReport) {
+				if len(report.CustomNodes) != 1 || len(report.ExistingCustomNodes) != 0 {
+					t.Errorf("report = %+v", report)
+				}
+				if _, err := s.LoadCustomNode("custom_ping"); err != nil {
+					t.Error(err)
+				}
+			},
+		},
+		{
+			name:     "existing custom node",
+			existing: `{"type":"custom_ping","name":"Mine","actionType":"shell","actionConfig":{"command":"echo"}}`,
+			bundle:   testBundle([]json.RawMessage{testBundleFlow("flow-main", nil, "custom_ping")}, testCustomNode),
+			check: func(t *testing.T, s *Storage, report *BundleImportReport) {
+				if len(report.CustomNodes) != 0 || len(report.ExistingCustomNodes) != 1 {
+					t.Errorf("report = %+v", report)
+				}
+				node, _ := s.LoadCustomNode("custom_ping")
+				if !strings.Contains(node, `"Mine"`) {
+					t.Errorf("existing custom node was replaced: %s", node)
+				}
+			},
+		},
+		{
+			name:   "missing secrets",
+			bundle: testBundle([]json.RawMessage{testBundleFlow("flow-main", map[string]interface{}{"url": "https://example.com?key={{secret.api_key}}"}, "action_http")}),
+			check: func(t *testing.T, s *Storage, report *BundleImportReport) {
+				if len(report.MissingSecrets) != 1 || report.MissingSecrets[0] != "api_key" {
+					t.Errorf("missing secrets = %v", report.MissingSecrets)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newBundleStorage(t)
+			if tt.existing != "" {
+				if _, err := s.SaveCustomNode(tt.existing); err != nil {
+					t.Fatal(err)
+				}
+			}
+			report, err := s.ImportFlowBundle(tt.bundle)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, s, report)
+		})
+	}
+}
+
+func TestImportFlowBundleRejected(t *testing.T) {
+	good := testBundleFlow("flow-main", nil, "trigger_manual", "custom_ping")
+	tests := []struct {
+		name   string
+		bundle string
+		want   string
+	}{
+		{"not a bundle", `{"format":"other"}`, "not a ForgeFlow bundle"},
+		{"no flows", testBundle(nil), "no flows"},
+		{"newer schema", testBundle([]json.RawMessage{good, json.RawMessage(`{"id":"flow-new","schemaVersion":999}`)}, testCustomNode), "schema version"},
+		{"unknown node type", testBundle([]json.RawMessage{good, testBundleFlow("flow-sub", nil, "action_teleport")}, testCustomNode), "unknown node type"},
+		{"invalid config", testBundle([]json.RawMessage{good, testBundleFlow("flow-sub", nil, "action_http")}, testCustomNode), "URL is required"},
+		{"custom node not bundled", testBundle([]json.RawMessage{good}), "unknown custom node type"},
+		{"invalid custom node", testBundle([]json.RawMessage{good}, `{"type":"custom_ping","actionType":"teleport"}`), "unknown action type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newBundleStorage(t)
+			_, err := s.ImportFlowBundle(tt.bundle)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want %q", err, tt.want)
+			}
+			// Nothing is stored when any part of the bundle is rejected
+			if flows, _ := s.ListFlows(); len(flows) != 0 {
+				t.Errorf("%d flows were stored", len(flows))
+			}
+			if nodes, _ := s.ListCustomNodes(); len(nodes) != 0 {
+				t.Errorf("%d custom nodes were stored", len(nodes))
+			}
+		})
+	}
+}
+
+func TestFlowBundleRoundTrip(t *testing.T) {
+	s := newBundleStorage(t)
+	if _, err := s.SaveCustomNode(testCustomNode); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.SaveFlow(string(testBundleFlow("flow-sub", map[string]interface{}{"message": "{{secret.token}}"}, "trigger_manual"))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.SaveFlow(string(testBundleFlow("flow-main", map[string]interface{}{"flowId": "flow-sub"}, "custom_ping"))); err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := s.ExportFlowBundle("flow-main")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := newBundleStorage(t)
+	report, err := other.ImportFlowBundle(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.FlowIDs) != 2 || len(report.CustomNodes) != 1 || len(report.MissingSecrets) != 1 || report.MissingSecrets[0] != "token" {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+func newBundleStorage(t *testing.T) *Storage {
+	t.Helper()
+	s := NewStorageAt(t.TempDir())
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.close)
+	return s
+}
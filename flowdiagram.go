@@ -0,0 +1,360 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Diagram formats of ExportFlowDiagram
+const (
+	DiagramMermaid = "mermaid"
+	DiagramDOT     = "dot"
+	DiagramSVG     = "svg"
+)
+
+// categoryColors are the node colors of the canvas (CATEGORY_COLORS in FlowNode.tsx)
+var categoryColors = map[string]string{
+	"trigger":   "#10b981",
+	"condition": "#f59e0b",
+	"action":    "#3b82f6",
+	"loop":      "#8b5cf6",
+	"utility":   "#64748b",
+	"ai":        "#a855f7",
+	"apps":      "#14b8a6",
+}
+
+// Node geometry of the canvas, so the SVG looks like the editor
+const (
+	diagramRowHeight  = 56 // FLOW_ROW_HEIGHT
+	diagramNodeHeight = 48 // NODE_HEIGHT
+	diagramIconSize   = 28 // ICON_SIZE
+	diagramMinWidth   = 120
+	diagramMaxWidth   = 220
+	diagramPadding    = 40
+	diagramTitle      = 32
+)
+
+// ExportFlowDiagram renders a flow's nodes and edges as a Mermaid flowchart,
+// a Graphviz DOT graph or a standalone SVG image laid out from the node
+// positions of the canvas
+func (s *Storage) ExportFlowDiagram(flowID, format string) (string, error) {
+	flowJSON, err := s.LoadFlow(flowID)
+	if err != nil {
+		return "", err
+	}
+	var flow Flow
+	if err := json.Unmarshal([]byte(flowJSON), &flow); err != nil {
+		return "", fmt.Errorf("invalid flow %s: %w", flowID, err)
+	}
+
+	switch strings.ToLower(format) {
+	case DiagramMermaid:
+		return mermaidDiagram(&flow), nil
+	case DiagramDOT:
+		return dotDiagram(&flow), nil
+	case DiagramSVG:
+		return svgDiagram(&flow), nil
+	}
+	return "", fmt.Errorf("unknown diagram format: %s (expected mermaid, dot or svg)", format)
+}
+
+// diagramEdge is an edge between two nodes of the flow, by index
+type diagramEdge struct {
+	from, to int
+	label    string
+}
+
+// diagramEdges returns the flow's edges, leaving out the ones whose nodes
+// don't exist. Edges are labelled with their branch handle (true, false,
+// loop, ...); the default "out" handle is left unlabelled.
+func diagramEdges(flow *Flow) []diagramEdge {
+	index := make(map[string]int, len(flow.Nodes))
+	for i, node := range flow.Nodes {
+		index[node.ID] = i
+	}
+	edges := make([]diagramEdge, 0, len(flow.Edges))
+	for _, edge := range flow.Edges {
+		from, ok := index[edge.Source]
+		if !ok {
+			continue
+		}
+		to, ok := index[edge.Target]
+		if !ok {
+			continue
+		}
+		label := edge.SourceHandle
+		if label == "out" {
+			label = ""
+		}
+		edges = append(edges, diagramEdge{from: from, to: to, label: label})
+	}
+	return edges
+}
+
+func diagramLabel(node *FlowNode) string {
+	if node.Data.Label != "" {
+		return node.Data.Label
+	}
+	return node.Data.NodeType
+}
+
+func diagramIconLabel(node *FlowNode) string {
+	if node.Data.Icon == "" {
+		return diagramLabel(node)
+	}
+	return node.Data.Icon + " " + diagramLabel(node)
+}
+
+func categoryColor(category string) string {
+	if color, ok := categoryColors[category]; ok {
+		return color
+	}
+	return categoryColors["utility"]
+}
+
+// diagramClass turns a node category into a class name for Mermaid
+func diagramClass(category string) string {
+	var b strings.Builder
+	b.WriteString("cat_")
+	for _, r := range category {
+		if r < utf8.RuneSelf && (r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	if category == "" {
+		b.WriteString("none")
+	}
+	return b.String()
+}
+
+func mermaidDiagram(flow *Flow) string {
+	var b strings.Builder
+	if flow.Name != "" {
+		// A quoted Go string is also a valid YAML scalar
+		fmt.Fprintf(&b, "---\ntitle: %s\n---\n", strconv.Quote(flow.Name))
+	}
+	b.WriteString("flowchart LR\n")
+
+	categories := make(map[string]bool)
+	for i := range flow.Nodes {
+		node := &flow.Nodes[i]
+		label := mermaidText(diagramIconLabel(node))
+		if node.Data.Category != "" {
+			label += "<br/><small>" + mermaidText(node.Data.Category) + "</small>"
+		}
+		open, close := `["`, `"]`
+		switch node.Data.Category {
+		case "trigger":
+			open, close = `(["`, `"])`
+		case "condition":
+			open, close = `{"`, `"}`
+		case "loop":
+			open, close = `[["`, `"]]`
+		}
+		categories[node.Data.Category] = true
+		fmt.Fprintf(&b, "    n%d%s%s%s:::%s\n", i, open, label, close, diagramClass(node.Data.Category))
+	}
+	for _, edge := range diagramEdges(flow) {
+		if edge.label != "" {
+			fmt.Fprintf(&b, "    n%d -->|\"%s\"| n%d\n", edge.from, mermaidText(edge.label), edge.to)
+		} else {
+			fmt.Fprintf(&b, "    n%d --> n%d\n", edge.from, edge.to)
+		}
+	}
+
+	for _, category := range sortedKeys(categories) {
+		color := categoryColor(category)
+		fmt.Fprintf(&b, "    classDef %s fill:%s1a,stroke:%s,color:#111827\n", diagramClass(category), color, color)
+	}
+	return b.String()
+}
+
+// mermaidText escapes text for a quoted Mermaid label
+func mermaidText(text string) string {
+	return strings.NewReplacer(
+		`"`, "#quot;",
+		"<", "#lt;",
+		">", "#gt;",
+		"\n", " ",
+	).Replace(text)
+}
+
+// dotDiagram pins the nodes at their canvas positions; `neato -n` keeps
+// them, while `dot` lays the graph out again from left to right
+func dotDiagram(flow *Flow) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotString(flow.Name))
+	fmt.Fprintf(&b, "  graph [rankdir=LR, label=%s, labelloc=t, fontname=\"Helvetica\"];\n", dotString(flow.Name))
+	b.WriteString("  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\", fontsize=11];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10, color=\"#94a3b8\"];\n")
+
+	for i := range flow.Nodes {
+		node := &flow.Nodes[i]
+		label := diagramIconLabel(node)
+		if node.Data.Category != "" {
+			label += "\n" + node.Data.Category
+		}
+		color := categoryColor(node.Data.Category)
+		shape := ""
+		if node.Data.Category == "condition" {
+			shape = ", shape=diamond, style=filled"
+		}
+		width := diagramNodeWidth(diagramLabel(node))
+		// Graphviz places node centers, in points, with y going up
+		x := node.Position.X + width/2
+		y := -(node.Position.Y + diagramRowHeight/2)
+		fmt.Fprintf(&b, "  n%d [label=%s, color=%q, fillcolor=%q%s, pos=\"%s,%s!\"];\n",
+			i, dotString(label), color, color+"1a", shape, diagramNumber(x), diagramNumber(y))
+	}
+	for _, edge := range diagramEdges(flow) {
+		if edge.label != "" {
+			fmt.Fprintf(&b, "  n%d -> n%d [label=%s];\n", edge.from, edge.to, dotString(edge.label))
+		} else {
+			fmt.Fprintf(&b, "  n%d -> n%d;\n", edge.from, edge.to)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotString quotes text as a DOT string, keeping line breaks
+func dotString(text string) string {
+	return `"` + strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\r", "",
+		"\n", `\n`,
+	).Replace(text) + `"`
+}
+
+// diagramNodeWidth is the width of a node on the canvas, which grows with
+// its label
+func diagramNodeWidth(label string) float64 {
+	width := float64(utf8.RuneCountInString(label)*9 + 80)
+	return math.Max(diagramMinWidth, math.Min(diagramMaxWidth, width))
+}
+
+func svgDiagram(flow *Flow) string {
+	widths := make([]float64, len(flow.Nodes))
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for i := range flow.Nodes {
+		node := &flow.Nodes[i]
+		widths[i] = diagramNodeWidth(diagramLabel(node))
+		minX = math.Min(minX, node.Position.X)
+		minY = math.Min(minY, node.Position.Y)
+		maxX = math.Max(maxX, node.Position.X+widths[i])
+		maxY = math.Max(maxY, node.Position.Y+diagramRowHeight)
+	}
+	if len(flow.Nodes) == 0 {
+		minX, minY, maxX, maxY = 0, 0, 0, 0
+	}
+	offsetX := diagramPadding - minX
+	offsetY := diagramPadding + diagramTitle - minY
+	width := math.Max(maxX-minX+2*diagramPadding, 240)
+	height := maxY - minY + 2*diagramPadding + diagramTitle
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%s\" height=\"%s\" viewBox=\"0 0 %s %s\" font-family=\"Inter, Helvetica, Arial, sans-serif\">\n",
+		diagramNumber(width), diagramNumber(height), diagramNumber(width), diagramNumber(height))
+	fmt.Fprintf(&b, "  <title>%s</title>\n", html.EscapeString(flow.Name))
+	b.WriteString("  <defs>\n")
+	b.WriteString("    <marker id=\"arrow\" viewBox=\"0 0 10 10\" refX=\"10\" refY=\"5\" markerWidth=\"8\" markerHeight=\"8\" orient=\"auto-start-reverse\">\n")
+	b.WriteString("      <path d=\"M 0 0 L 10 5 L 0 10 z\" fill=\"#94a3b8\"/>\n")
+	b.WriteString("    </marker>\n")
+	b.WriteString("  </defs>\n")
+	fmt.Fprintf(&b, "  <rect width=\"100%%\" height=\"100%%\" fill=\"#ffffff\"/>\n")
+	fmt.Fprintf(&b, "  <text x=\"%d\" y=\"%d\" font-size=\"16\" font-weight=\"600\" fill=\"#111827\">%s</text>\n",
+		diagramPadding, diagramPadding, html.EscapeString(flow.Name))
+
+	// Edges go from the right side of a node to the left side of the next,
+	// under the nodes; their labels are drawn last so nothing hides them
+	edges := diagramEdges(flow)
+	var labels strings.Builder
+	for _, edge := range edges {
+		from, to := &flow.Nodes[edge.from], &flow.Nodes[edge.to]
+		sx := from.Position.X + offsetX + widths[edge.from]
+		sy := from.Position.Y + offsetY + diagramRowHeight/2
+		tx := to.Position.X + offsetX
+		ty := to.Position.Y + offsetY + diagramRowHeight/2
+		bend := math.Max(40, math.Abs(tx-sx)/2)
+		fmt.Fprintf(&b, "  <path d=\"M %s %s C %s %s, %s %s, %s %s\" fill=\"none\" stroke=\"#94a3b8\" stroke-width=\"1.5\" marker-end=\"url(#arrow)\"/>\n",
+			diagramNumber(sx), diagramNumber(sy), diagramNumber(sx+bend), diagramNumber(sy),
+			diagramNumber(tx-bend), diagramNumber(ty), diagramNumber(tx), diagramNumber(ty))
+		if edge.label == "" {
+			continue
+		}
+		// The curve is symmetric, so its middle is halfway between its ends
+		mx, my := (sx+tx)/2, (sy+ty)/2
+		labelWidth := float64(utf8.RuneCountInString(edge.label)*6 + 12)
+		fmt.Fprintf(&labels, "  <rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"16\" rx=\"8\" fill=\"#ffffff\" stroke=\"#cbd5e1\"/>\n",
+			diagramNumber(mx-labelWidth/2), diagramNumber(my-8), diagramNumber(labelWidth))
+		fmt.Fprintf(&labels, "  <text x=\"%s\" y=\"%s\" font-size=\"10\" text-anchor=\"middle\" fill=\"#475569\">%s</text>\n",
+			diagramNumber(mx), diagramNumber(my+3.5), html.EscapeString(edge.label))
+	}
+
+	for i := range flow.Nodes {
+		node := &flow.Nodes[i]
+		color := categoryColor(node.Data.Category)
+		x := node.Position.X + offsetX
+		y := node.Position.Y + offsetY + (diagramRowHeight-diagramNodeHeight)/2
+		iconX := x + 10
+		iconY := y + (diagramNodeHeight-diagramIconSize)/2
+		textX := iconX + diagramIconSize + 10
+		// About 7px per character at 12px, as the canvas truncates labels
+		label := truncateRunes(diagramLabel(node), int((widths[i]-(textX-x)-8)/7))
+
+		b.WriteString("  <g>\n")
+		fmt.Fprintf(&b, "    <rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%d\" rx=\"12\" fill=\"#ffffff\" stroke=\"%s\" stroke-width=\"1.5\"/>\n",
+			diagramNumber(x), diagramNumber(y), diagramNumber(widths[i]), diagramNodeHeight, color)
+		fmt.Fprintf(&b, "    <rect x=\"%s\" y=\"%s\" width=\"%d\" height=\"%d\" rx=\"6\" fill=\"%s\"/>\n",
+			diagramNumber(iconX), diagramNumber(iconY), diagramIconSize, diagramIconSize, color)
+		if node.Data.Icon != "" {
+			fmt.Fprintf(&b, "    <text x=\"%s\" y=\"%s\" font-size=\"14\" text-anchor=\"middle\">%s</text>\n",
+				diagramNumber(iconX+diagramIconSize/2), diagramNumber(iconY+19), html.EscapeString(node.Data.Icon))
+		}
+		fmt.Fprintf(&b, "    <text x=\"%s\" y=\"%s\" font-size=\"12\" font-weight=\"600\" fill=\"#111827\">%s</text>\n",
+			diagramNumber(textX), diagramNumber(y+21), html.EscapeString(label))
+		if node.Data.Category != "" {
+			fmt.Fprintf(&b, "    <text x=\"%s\" y=\"%s\" font-size=\"10\" fill=\"%s\">%s</text>\n",
+				diagramNumber(textX), diagramNumber(y+36), color, html.EscapeString(node.Data.Category))
+		}
+		b.WriteString("  </g>\n")
+	}
+	b.WriteString(labels.String())
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// diagramNumber formats a coordinate with at most one decimal
+func diagramNumber(value float64) string {
+	return strconv.FormatFloat(math.Round(value*10)/10, 'f', -1, 64)
+}
+
+func truncateRunes(text string, max int) string {
+	if max < 1 {
+		max = 1
+	}
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:max-1]) + "…"
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDiagramEscaping(t *testing.T) {
+	tests := []struct {
+		text    string
+		mermaid string
+		dot     string
+	}{
+		{`plain`, `plain`, `"plain"`},
+		{`say "hi"`, `say #quot;hi#quot;`, `"say \"hi\""`},
+		{`a < b && c > d`, `a #lt; b && c #gt; d`, `"a < b && c > d"`},
+		{`<script>`, `#lt;script#gt;`, `"<script>"`},
+		{"two\nlines", `two lines`, `"two\nlines"`},
+		{"crlf\r\nend", "crlf\r end", `"crlf\nend"`},
+		{`C:\temp\`, `C:\temp\`, `"C:\\temp\\"`},
+	}
+	for _, tt := range tests {
+		if got := mermaidText(tt.text); got != tt.mermaid {
+			t.Errorf("mermaidText(%q) = %q, want %q", tt.text, got, tt.mermaid)
+		}
+		if got := dotString(tt.text); got != tt.dot {
+			t.Errorf("dotString(%q) = %q, want %q", tt.text, got, tt.dot)
+		}
+	}
+}
+
+func TestDiagramClass(t *testing.T) {
+	tests := map[string]string{
+		"trigger":  "cat_trigger",
+		"":         "cat_none",
+		"my-apps":  "cat_my_apps",
+		"données":  "cat_donn_es",
+		"a b\"c<d": "cat_a_b_c_d",
+	}
+	for category, want := range tests {
+		if got := diagramClass(category); got != want {
+			t.Errorf("diagramClass(%q) = %q, want %q", category, got, want)
+		}
+	}
+}
+
+// diagramTestFlow has labels, a name and a branch handle that need escaping
+// in every format, and an edge to a node that doesn't exist
+const diagramTestFlow = `{"id":"diagram","name":"Say \"hi\" <now> & later","nodes":[
+	{"id":"a","position":{"x":0,"y":0},"data":{"label":"Start \"here\"","nodeType":"trigger_manual","category":"trigger","icon":"▶"}},
+	{"id":"b","position":{"x":240,"y":0},"data":{"label":"a < b & c","nodeType":"condition_if","category":"condition"}},
+	{"id":"c","position":{"x":480,"y":80},"data":{"label":"</text><x>","nodeType":"action_log","category":"action"}}
+],"edges":[
+	{"id":"e1","source":"a","target":"b","sourceHandle":"out"},
+	{"id":"e2","source":"b","target":"c","sourceHandle":"<true>"},
+	{"id":"e3","source":"b","target":"gone","sourceHandle":"false"}
+]}`
+
+func newDiagramStorage(t *testing.T) *Storage {
+	t.Helper()
+	s := NewStorageAt(t.TempDir())
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.close)
+	if _, err := s.SaveFlow(diagramTestFlow); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestExportFlowDiagram(t *testing.T) {
+	s := newDiagramStorage(t)
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{DiagramMermaid, []string{
+			`title: "Say \"hi\" <now> & later"`,
+			`n0(["▶ Start #quot;here#quot;<br/><small>trigger</small>"]):::cat_trigger`,
+			`n1{"a #lt; b & c<br/><small>condition</small>"}:::cat_condition`,
+			`n2["#lt;/text#gt;#lt;x#gt;<br/><small>action</small>"]:::cat_action`,
+			"n0 --> n1\n",
+			`n1 -->|"#lt;true#gt;"| n2`,
+		}},
+		{DiagramDOT, []string{
+			`digraph "Say \"hi\" <now> & later" {`,
+			`n0 [label="▶ Start \"here\"\ntrigger"`,
+			`n1 [label="a < b & c\ncondition"`,
+			"n0 -> n1;\n",
+			`n1 -> n2 [label="<true>"];`,
+		}},
+		{"SVG", []string{
+			`<title>Say &#34;hi&#34; &lt;now&gt; &amp; later</title>`,
+			`>Start &#34;here&#34;</text>`,
+			`>a &lt; b &amp; c</text>`,
+			`>&lt;/text&gt;&lt;x&gt;</text>`,
+			`>&lt;true&gt;</text>`,
+		}},
+	}
+	for _, tt := range tests {
+		diagram, err := s.ExportFlowDiagram("diagram", tt.format)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.format, err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(diagram, want) {
+				t.Errorf("%s diagram lacks %q:\n%s", tt.format, want, diagram)
+			}
+		}
+		if strings.Contains(diagram, "gone") || strings.Contains(diagram, "false") {
+			t.Errorf("%s diagram has the edge to a missing node:\n%s", tt.format, diagram)
+		}
+	}
+
+	if _, err := s.ExportFlowDiagram("diagram", "png"); err == nil {
+		t.Error("an unknown format should fail")
+	}
+}
+
+// TestSVGDiagramWellFormed reads the SVG back, so labels can't break out of
+// their elements
+func TestSVGDiagramWellFormed(t *testing.T) {
+	s := newDiagramStorage(t)
+	diagram, err := s.ExportFlowDiagram("diagram", DiagramSVG)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var texts []string
+	decoder := xml.NewDecoder(strings.NewReader(diagram))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid SVG: %v\n%s", err, diagram)
+		}
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == "x" {
+			t.Fatalf("a label became an element:\n%s", diagram)
+		}
+		if data, ok := token.(xml.CharData); ok && strings.TrimSpace(string(data)) != "" {
+			texts = append(texts, string(data))
+		}
+	}
+	for _, want := range []string{`Say "hi" <now> & later`, `Start "here"`, `a < b & c`, `</text><x>`, `<true>`} {
+		found := false
+		for _, text := range texts {
+			found = found || text == want
+		}
+		if !found {
+			t.Errorf("SVG text %q not found in %q", want, texts)
+		}
+	}
+}
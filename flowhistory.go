@@ -0,0 +1,468 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// FlowRevision describes one saved version of a flow. Revisions are kept
+// per flow under flows/history/<flowID>: the documents as <revision>.json
+// and their metadata, one line per revision, in index.jsonl.
+type FlowRevision struct {
+	Revision  int    `json:"revision"`
+	FlowID    string `json:"flowId"`
+	CreatedAt string `json:"createdAt"`
+	Message   string `json:"message,omitempty"`
+	Name      string `json:"name"`
+	NodeCount int    `json:"nodeCount"`
+	EdgeCount int    `json:"edgeCount"`
+	Hash      string `json:"hash"` // of the content, to skip saves that change nothing
+}
+
+// FlowDiff is the structural difference between two revisions of a flow
+type FlowDiff struct {
+	FlowID       string            `json:"flowId"`
+	From         int               `json:"from"`
+	To           int               `json:"to"`
+	Changes      []FlowValueChange `json:"changes"` // name and description
+	NodesAdded   []FlowDiffNode    `json:"nodesAdded"`
+	NodesRemoved []FlowDiffNode    `json:"nodesRemoved"`
+	NodesChanged []FlowDiffNode    `json:"nodesChanged"`
+	EdgesAdded   []FlowEdge        `json:"edgesAdded"`
+	EdgesRemoved []FlowEdge        `json:"edgesRemoved"`
+	EdgesRewired []FlowEdgeRewire  `json:"edgesRewired"`
+}
+
+type FlowDiffNode struct {
+	ID       string            `json:"id"`
+	NodeType string            `json:"nodeType"`
+	Label    string            `json:"label"`
+	Changes  []FlowValueChange `json:"changes,omitempty"`
+}
+
+// FlowValueChange is a changed field, e.g. "label" or "config.url"
+type FlowValueChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// FlowEdgeRewire is a connection moved to another node or handle
+type FlowEdgeRewire struct {
+	From FlowEdge `json:"from"`
+	To   FlowEdge `json:"to"`
+}
+
+func (s *Storage) getFlowHistoryDir(flowID string) string {
+	return filepath.Join(s.getFlowsDir(), "history", flowID)
+}
+
+// ListFlowRevisions returns the saved revisions of a flow, newest first
+func (s *Storage) ListFlowRevisions(flowID string) ([]FlowRevision, error) {
+	if err := checkFlowID(flowID); err != nil {
+		return nil, err
+	}
+	_, unlock, err := s.reader()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	revisions, err := s.readFlowRevisions(flowID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision > revisions[j].Revision })
+	return revisions, nil
+}
+
+// GetFlowRevision returns a revision of a flow, migrated to the current
+// schema version
+func (s *Storage) GetFlowRevision(flowID string, revision int) (string, error) {
+	if err := checkFlowID(flowID); err != nil {
+		return "", err
+	}
+	_, unlock, err := s.reader()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	data, err := s.readFlowRevision(flowID, revision)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// RestoreFlowRevision makes a revision the current version of a flow. The
+// restore is saved as a new revision, so nothing in the history is lost.
+// It also recreates a deleted flow from its history.
+func (s *Storage) RestoreFlowRevision(flowID string, revision int) (string, error) {
+	data, err := s.GetFlowRevision(flowID, revision)
+	if err != nil {
+		return "", err
+	}
+	var flowData map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &flowData); err != nil {
+		return "", fmt.Errorf("invalid flow revision %d: %w", revision, err)
+	}
+	flowData["id"] = flowID
+
+	restored, err := json.Marshal(flowData)
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.SaveFlowWithMessage(string(restored), fmt.Sprintf("Restored revision %d", revision)); err != nil {
+		return "", err
+	}
+	return s.LoadFlow(flowID)
+}
+
+// DiffFlowRevisions compares two revisions of a flow. Revision 0 stands for
+// the flow as currently saved.
+func (s *Storage) DiffFlowRevisions(flowID string, from, to int) (*FlowDiff, error) {
+	if err := checkFlowID(flowID); err != nil {
+		return nil, err
+	}
+	a, err := s.flowAtRevision(flowID, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.flowAtRevision(flowID, to)
+	if err != nil {
+		return nil, err
+	}
+	diff := diffFlows(a, b)
+	diff.FlowID = flowID
+	diff.From = from
+	diff.To = to
+	return diff, nil
+}
+
+func (s *Storage) flowAtRevision(flowID string, revision int) (*Flow, error) {
+	var data string
+	var err error
+	if revision == 0 {
+		data, err = s.LoadFlow(flowID)
+	} else {
+		data, err = s.GetFlowRevision(flowID, revision)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var flow Flow
+	if err := json.Unmarshal([]byte(data), &flow); err != nil {
+		return nil, fmt.Errorf("invalid flow JSON: %w", err)
+	}
+	return &flow, nil
+}
+
+// checkFlowID rejects IDs that can't be used as a file name
+func checkFlowID(flowID string) error {
+	if flowID == "" || flowID == "." || flowID == ".." || filepath.Base(flowID) != flowID {
+		return fmt.Errorf("invalid flow ID: %q", flowID)
+	}
+	return nil
+}
+
+func (s *Storage) readFlowRevisions(flowID string) ([]FlowRevision, error) {
+	revisions := []FlowRevision{}
+	f, err := os.Open(filepath.Join(s.getFlowHistoryDir(flowID), "index.jsonl"))
+	if os.IsNotExist(err) {
+		return revisions, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var rev FlowRevision
+		// A line cut short by a crash is skipped
+		if err := json.Unmarshal(scanner.Bytes(), &rev); err != nil || rev.Revision < 1 {
+			continue
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, scanner.Err()
+}
+
+func (s *Storage) readFlowRevision(flowID string, revision int) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(s.getFlowHistoryDir(flowID), fmt.Sprintf("%d.json", revision)))
+	if err != nil {
+		return nil, fmt.Errorf("revision %d of flow %s not found", revision, flowID)
+	}
+	migrated, _, _, err := upgradeFlowJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("revision %d of flow %s: %w", revision, flowID, err)
+	}
+	return migrated, nil
+}
+
+// recordFlowRevision appends a saved flow to its history, unless it's the
+// same as the latest revision and there is no message. Callers hold the
+// storage write lock.
+func (s *Storage) recordFlowRevision(flowData map[string]interface{}, data []byte, message string) error {
+	flowID, _ := flowData["id"].(string)
+	if err := checkFlowID(flowID); err != nil {
+		return err
+	}
+	revisions, err := s.readFlowRevisions(flowID)
+	if err != nil {
+		return err
+	}
+
+	hash := flowContentHash(flowData)
+	next := 1
+	if n := len(revisions); n > 0 {
+		latest := revisions[n-1]
+		if latest.Hash == hash && message == "" {
+			return nil
+		}
+		next = latest.Revision + 1
+	}
+
+	summary := flowSummary(flowData)
+	rev := FlowRevision{
+		Revision:  next,
+		FlowID:    flowID,
+		CreatedAt: time.Now().Format(time.RFC3339),
+		Message:   message,
+		Name:      summary.Name,
+		NodeCount: summary.NodeCount,
+		Hash:      hash,
+	}
+	if edges, ok := flowData["edges"].([]interface{}); ok {
+		rev.EdgeCount = len(edges)
+	}
+
+	dir := s.getFlowHistoryDir(flowID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	// The document goes first, so an index line always has its file
+	if err := writeFileAtomic(filepath.Join(dir, fmt.Sprintf("%d.json", next)), data, 0644); err != nil {
+		return err
+	}
+	line, err := json.Marshal(rev)
+	if err != nil {
+		return err
+	}
+	return appendLine(filepath.Join(dir, "index.jsonl"), line)
+}
+
+// appendLine appends a line to a file and syncs it. A previous append cut
+// short by a crash is terminated first, so it can't swallow this line.
+func appendLine(path string, line []byte) error {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	end, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if end > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, end-1); err != nil {
+			return err
+		}
+		if last[0] != '\n' {
+			line = append([]byte{'\n'}, line...)
+		}
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// flowContentHash fingerprints what a user edits in a flow, leaving out
+// timestamps and the node run status the editor saves along
+func flowContentHash(flowData map[string]interface{}) string {
+	content := make(map[string]interface{}, len(flowData))
+	for key, value := range flowData {
+		switch key {
+		case "createdAt", "updatedAt", "schemaVersion":
+			continue
+		}
+		content[key] = value
+	}
+	if nodes, ok := flowData["nodes"].([]interface{}); ok {
+		stripped := make([]interface{}, len(nodes))
+		for i, n := range nodes {
+			node, ok := n.(map[string]interface{})
+			data, hasData := node["data"].(map[string]interface{})
+			if !ok || !hasData {
+				stripped[i] = n
+				continue
+			}
+			copied := make(map[string]interface{}, len(node))
+			for k, v := range node {
+				copied[k] = v
+			}
+			copiedData := make(map[string]interface{}, len(data))
+			for k, v := range data {
+				if k != "status" {
+					copiedData[k] = v
+				}
+			}
+			copied["data"] = copiedData
+			stripped[i] = copied
+		}
+		content["nodes"] = stripped
+	}
+
+	// Maps are encoded with sorted keys, so equal content hashes the same
+	encoded, _ := json.Marshal(content)
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])
+}
+
+// diffFlows compares nodes by ID and edges by ID. Node positions are left
+// out; an edge that keeps its source handle but moves to another target (or
+// vice versa) counts as rewired rather than removed and added.
+func diffFlows(a, b *Flow) *FlowDiff {
+	diff := &FlowDiff{
+		Changes:      []FlowValueChange{},
+		NodesAdded:   []FlowDiffNode{},
+		NodesRemoved: []FlowDiffNode{},
+		NodesChanged: []FlowDiffNode{},
+		EdgesAdded:   []FlowEdge{},
+		EdgesRemoved: []FlowEdge{},
+		EdgesRewired: []FlowEdgeRewire{},
+	}
+	if a.Name != b.Name {
+		diff.Changes = append(diff.Changes, FlowValueChange{Field: "name", From: a.Name, To: b.Name})
+	}
+	if a.Description != b.Description {
+		diff.Changes = append(diff.Changes, FlowValueChange{Field: "description", From: a.Description, To: b.Description})
+	}
+
+	oldNodes := make(map[string]FlowNode, len(a.Nodes))
+	for _, node := range a.Nodes {
+		oldNodes[node.ID] = node
+	}
+	newNodes := make(map[string]bool, len(b.Nodes))
+	for _, node := range b.Nodes {
+		newNodes[node.ID] = true
+		old, ok := oldNodes[node.ID]
+		if !ok {
+			diff.NodesAdded = append(diff.NodesAdded, diffNode(node))
+			continue
+		}
+		if changes := diffNodeFields(old, node); len(changes) > 0 {
+			changed := diffNode(node)
+			changed.Changes = changes
+			diff.NodesChanged = append(diff.NodesChanged, changed)
+		}
+	}
+	for _, node := range a.Nodes {
+		if !newNodes[node.ID] {
+			diff.NodesRemoved = append(diff.NodesRemoved, diffNode(node))
+		}
+	}
+
+	oldEdges := make(map[string]FlowEdge, len(a.Edges))
+	for _, edge := range a.Edges {
+		oldEdges[edge.ID] = edge
+	}
+	newEdges := make(map[string]bool, len(b.Edges))
+	var added []FlowEdge
+	for _, edge := range b.Edges {
+		newEdges[edge.ID] = true
+		old, ok := oldEdges[edge.ID]
+		switch {
+		case !ok:
+			added = append(added, edge)
+		case old != edge:
+			diff.EdgesRewired = append(diff.EdgesRewired, FlowEdgeRewire{From: old, To: edge})
+		}
+	}
+	var removed []FlowEdge
+	for _, edge := range a.Edges {
+		if !newEdges[edge.ID] {
+			removed = append(removed, edge)
+		}
+	}
+
+	// Editors give a reconnected edge a new ID, so pair up removed and added
+	// edges that share one end
+	paired := make(map[int]bool)
+	for _, old := range removed {
+		match := -1
+		for i, edge := range added {
+			if paired[i] {
+				continue
+			}
+			sameSource := edge.Source == old.Source && edge.SourceHandle == old.SourceHandle
+			sameTarget := edge.Target == old.Target && edge.TargetHandle == old.TargetHandle
+			if sameSource != sameTarget {
+				match = i
+				break
+			}
+		}
+		if match < 0 {
+			diff.EdgesRemoved = append(diff.EdgesRemoved, old)
+			continue
+		}
+		paired[match] = true
+		diff.EdgesRewired = append(diff.EdgesRewired, FlowEdgeRewire{From: old, To: added[match]})
+	}
+	for i, edge := range added {
+		if !paired[i] {
+			diff.EdgesAdded = append(diff.EdgesAdded, edge)
+		}
+	}
+
+	return diff
+}
+
+func diffNode(node FlowNode) FlowDiffNode {
+	return FlowDiffNode{ID: node.ID, NodeType: node.Data.NodeType, Label: node.Data.Label}
+}
+
+func diffNodeFields(a, b FlowNode) []FlowValueChange {
+	var changes []FlowValueChange
+	if a.Data.NodeType != b.Data.NodeType {
+		changes = append(changes, FlowValueChange{Field: "nodeType", From: a.Data.NodeType, To: b.Data.NodeType})
+	}
+	if a.Data.Label != b.Data.Label {
+		changes = append(changes, FlowValueChange{Field: "label", From: a.Data.Label, To: b.Data.Label})
+	}
+
+	keys := make(map[string]bool)
+	for key := range a.Data.Config {
+		keys[key] = true
+	}
+	for key := range b.Data.Config {
+		keys[key] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	for _, key := range sorted {
+		from, to := a.Data.Config[key], b.Data.Config[key]
+		if !reflect.DeepEqual(from, to) {
+			changes = append(changes, FlowValueChange{Field: "config." + key, From: from, To: to})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveFlowRejectsInvalidID(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStorageAt(filepath.Join(dir, "data"))
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.close)
+
+	for _, id := range []string{"../x", "a/b", ".."} {
+		if _, err := s.SaveFlow(`{"id":"` + id + `","name":"Bad","nodes":[],"edges":[]}`); err == nil {
+			t.Errorf("SaveFlow(%q) should fail", id)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "data", "x.json")); !os.IsNotExist(err) {
+		t.Errorf("flow was written outside the flows directory: %v", err)
+	}
+}
+
+func TestSaveFlowRecordsRevisions(t *testing.T) {
+	s := NewStorageAt(t.TempDir())
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.close)
+
+	saveTestFlow(t, s, "flow-1", "First")
+	saveTestFlow(t, s, "flow-1", "First")
+	if _, err := s.SaveFlowWithMessage(`{"id":"flow-1","name":"Second","nodes":[],"edges":[]}`, "Renamed"); err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, err := s.ListFlowRevisions("flow-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("got %d revisions, want 2 (an unchanged save isn't recorded)", len(revisions))
+	}
+	if revisions[0].Name != "Second" || revisions[0].Message != "Renamed" {
+		t.Errorf("latest revision = %+v", revisions[0])
+	}
+}
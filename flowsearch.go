@@ -0,0 +1,437 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// FlowSearchResult is a flow matching a SearchFlows query, with the fields
+// that matched. NodeIDs lists the matching nodes, to highlight on the canvas.
+type FlowSearchResult struct {
+	FlowID      string            `json:"flowId"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Score       float64           `json:"score"`
+	NodeIDs     []string          `json:"nodeIds"`
+	Matches     []FlowSearchMatch `json:"matches"`
+}
+
+type FlowSearchMatch struct {
+	NodeID   string        `json:"nodeId,omitempty"` // empty for the flow's own name and description
+	Label    string        `json:"label,omitempty"`
+	NodeType string        `json:"nodeType,omitempty"`
+	Field    string        `json:"field"` // "name", "description", "label", "nodeType" or "config.<key>"
+	Snippet  []SnippetPart `json:"snippet"`
+}
+
+// SnippetPart is a piece of a matched value; Match marks the query hits
+type SnippetPart struct {
+	Text  string `json:"text"`
+	Match bool   `json:"match,omitempty"`
+}
+
+// searchFieldWeights rank where a term matched
+var searchFieldWeights = map[string]float64{
+	"name":        10,
+	"description": 3,
+	"label":       5,
+	"nodeType":    2,
+}
+
+// secretConfigKey matches config keys whose values are never indexed, on
+// top of fields declared as passwords
+var secretConfigKey = regexp.MustCompile(`(?i)(password|passwd|secret|token|api_?key|auth|credential|private_?key)`)
+
+const searchSnippetContext = 40 // characters shown around the first match
+
+// flowSearchIndex is an in-memory inverted index of flows, built on the
+// first search and kept up to date as flows are saved and deleted
+type flowSearchIndex struct {
+	mu    sync.RWMutex
+	built bool
+	docs  map[string]*flowSearchDoc
+	terms map[string]map[string]bool // term -> flow IDs
+}
+
+type flowSearchDoc struct {
+	flowID      string
+	name        string
+	description string
+	fields      []flowSearchField
+	terms       []string
+}
+
+type flowSearchField struct {
+	nodeID   string
+	label    string
+	nodeType string
+	field    string
+	text     string
+}
+
+// searchTerms splits text into lowercase words, so "api.example.com/v1"
+// gives api, example, com and v1
+func searchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+}
+
+// SearchFlows finds flows whose name, description, node labels, node types
+// or string config values contain every word of the query. Words match as
+// prefixes, so "slac" finds "slack". Secret config values aren't indexed.
+func (s *Storage) SearchFlows(query string) ([]FlowSearchResult, error) {
+	words := searchTerms(query)
+	if len(words) == 0 {
+		return []FlowSearchResult{}, nil
+	}
+	if err := s.buildSearchIndex(); err != nil {
+		return nil, err
+	}
+	return s.search.query(words), nil
+}
+
+// buildSearchIndex indexes every stored flow the first time it's needed
+func (s *Storage) buildSearchIndex() error {
+	s.search.mu.RLock()
+	built := s.search.built
+	s.search.mu.RUnlock()
+	if built {
+		return nil
+	}
+
+	store, unlock, err := s.reader()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	summaries, err := store.ListFlows()
+	if err != nil {
+		return err
+	}
+	docs := make(map[string]*flowSearchDoc, len(summaries))
+	for _, summary := range summaries {
+		data, err := store.GetFlow(summary.ID)
+		if err != nil {
+			continue
+		}
+		var flowData map[string]interface{}
+		if err := json.Unmarshal(data, &flowData); err != nil {
+			continue
+		}
+		docs[summary.ID] = s.searchDocument(flowData)
+	}
+
+	s.search.mu.Lock()
+	defer s.search.mu.Unlock()
+	if !s.search.built {
+		s.search.docs = make(map[string]*flowSearchDoc)
+		s.search.terms = make(map[string]map[string]bool)
+		for _, doc := range docs {
+			s.search.put(doc)
+		}
+		s.search.built = true
+	}
+	return nil
+}
+
+// indexFlow updates the search index after a flow is stored. Before the
+// first search there is no index to update.
+func (s *Storage) indexFlow(flowData map[string]interface{}) {
+	s.search.mu.RLock()
+	built := s.search.built
+	s.search.mu.RUnlock()
+	if !built {
+		return
+	}
+	doc := s.searchDocument(flowData)
+
+	s.search.mu.Lock()
+	defer s.search.mu.Unlock()
+	if s.search.built {
+		s.search.remove(doc.flowID)
+		s.search.put(doc)
+	}
+}
+
+func (s *Storage) unindexFlow(flowID string) {
+	s.search.mu.Lock()
+	defer s.search.mu.Unlock()
+	if s.search.built {
+		s.search.remove(flowID)
+	}
+}
+
+// resetSearchIndex drops the index, e.g. after flows changed in bulk; the
+// next search rebuilds it
+func (s *Storage) resetSearchIndex() {
+	s.search.mu.Lock()
+	defer s.search.mu.Unlock()
+	s.search.built = false
+	s.search.docs = nil
+	s.search.terms = nil
+}
+
+// searchDocument collects the searchable text of a flow
+func (s *Storage) searchDocument(flowData map[string]interface{}) *flowSearchDoc {
+	doc := &flowSearchDoc{}
+	doc.flowID, _ = flowData["id"].(string)
+	doc.name, _ = flowData["name"].(string)
+	doc.description, _ = flowData["description"].(string)
+	doc.fields = append(doc.fields,
+		flowSearchField{field: "name", text: doc.name},
+		flowSearchField{field: "description", text: doc.description},
+	)
+
+	nodes, _ := flowData["nodes"].([]interface{})
+	for _, n := range nodes {
+		node, ok := n.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		data, _ := node["data"].(map[string]interface{})
+		nodeID, _ := node["id"].(string)
+		label, _ := data["label"].(string)
+		nodeType, _ := data["nodeType"].(string)
+		field := flowSearchField{nodeID: nodeID, label: label, nodeType: nodeType}
+
+		add := func(name, text string) {
+			if strings.TrimSpace(text) == "" {
+				return
+			}
+			f := field
+			f.field = name
+			f.text = text
+			doc.fields = append(doc.fields, f)
+		}
+		add("label", label)
+		add("nodeType", nodeType)
+
+		config, _ := data["config"].(map[string]interface{})
+		secrets := s.secretConfigKeys(nodeType)
+		keys := make([]string, 0, len(config))
+		for key := range config {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if secrets[key] || secretConfigKey.MatchString(key) {
+				continue
+			}
+			collectConfigStrings("config."+key, config[key], add)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, f := range doc.fields {
+		for _, term := range searchTerms(f.text) {
+			if !seen[term] {
+				seen[term] = true
+				doc.terms = append(doc.terms, term)
+			}
+		}
+	}
+	return doc
+}
+
+// collectConfigStrings walks a config value, passing on every string in it
+// along with its path, e.g. config.headers.Accept
+func collectConfigStrings(path string, value interface{}, add func(field, text string)) {
+	switch v := value.(type) {
+	case string:
+		add(path, v)
+	case map[string]interface{}:
+		for key, item := range v {
+			if secretConfigKey.MatchString(key) {
+				continue
+			}
+			collectConfigStrings(path+"."+key, item, add)
+		}
+	case []interface{}:
+		for _, item := range v {
+			collectConfigStrings(path, item, add)
+		}
+	}
+}
+
+// secretConfigKeys returns the password fields of a node type. Custom node
+// definitions are read straight from disk, since this runs under the
+// storage write lock.
+func (s *Storage) secretConfigKeys(nodeType string) map[string]bool {
+	var fields []NodeFieldSpec
+	if spec, ok := builtinNodeTypeIndex[nodeType]; ok {
+		fields = spec.Fields
+	} else if isCustomNodeType(nodeType) && checkCustomNodeType(nodeType) == nil {
+		var def CustomNodeDefinition
+		if data, err := os.ReadFile(filepath.Join(s.getCustomNodesDir(), nodeType+".json")); err == nil && json.Unmarshal(data, &def) == nil {
+			fields = def.Fields
+		}
+	}
+
+	keys := make(map[string]bool)
+	for _, f := range fields {
+		if f.Type == "password" {
+			keys[f.Key] = true
+		}
+	}
+	return keys
+}
+
+func (idx *flowSearchIndex) put(doc *flowSearchDoc) {
+	idx.docs[doc.flowID] = doc
+	for _, term := range doc.terms {
+		if idx.terms[term] == nil {
+			idx.terms[term] = make(map[string]bool)
+		}
+		idx.terms[term][doc.flowID] = true
+	}
+}
+
+func (idx *flowSearchIndex) remove(flowID string) {
+	doc, ok := idx.docs[flowID]
+	if !ok {
+		return
+	}
+	for _, term := range doc.terms {
+		delete(idx.terms[term], flowID)
+		if len(idx.terms[term]) == 0 {
+			delete(idx.terms, term)
+		}
+	}
+	delete(idx.docs, flowID)
+}
+
+func (idx *flowSearchIndex) query(words []string) []FlowSearchResult {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	// Flows containing every word, as a prefix of one of their terms
+	var candidates map[string]bool
+	for _, word := range words {
+		matches := make(map[string]bool)
+		for term, flows := range idx.terms {
+			if strings.HasPrefix(term, word) {
+				for flowID := range flows {
+					if candidates == nil || candidates[flowID] {
+						matches[flowID] = true
+					}
+				}
+			}
+		}
+		candidates = matches
+		if len(candidates) == 0 {
+			return []FlowSearchResult{}
+		}
+	}
+
+	results := []FlowSearchResult{}
+	for flowID := range candidates {
+		doc := idx.docs[flowID]
+		result := FlowSearchResult{
+			FlowID:      doc.flowID,
+			Name:        doc.name,
+			Description: doc.description,
+			NodeIDs:     []string{},
+			Matches:     []FlowSearchMatch{},
+		}
+		nodes := make(map[string]bool)
+		for _, f := range doc.fields {
+			snippet, hits := searchSnippet(f.text, words)
+			if hits == 0 {
+				continue
+			}
+			weight, ok := searchFieldWeights[f.field]
+			if !ok {
+				weight = 1
+			}
+			result.Score += weight * float64(hits)
+			result.Matches = append(result.Matches, FlowSearchMatch{
+				NodeID:   f.nodeID,
+				Label:    f.label,
+				NodeType: f.nodeType,
+				Field:    f.field,
+				Snippet:  snippet,
+			})
+			if f.nodeID != "" && !nodes[f.nodeID] {
+				nodes[f.nodeID] = true
+				result.NodeIDs = append(result.NodeIDs, f.nodeID)
+			}
+		}
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return strings.ToLower(results[i].Name) < strings.ToLower(results[j].Name)
+	})
+	return results
+}
+
+// searchSnippet cuts the text around its first match, marking every
+// occurrence of a query word in it. It returns the number of occurrences.
+func searchSnippet(text string, words []string) ([]SnippetPart, int) {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		runes = lower // case mapping changed the length; show the lowercase text
+	}
+
+	// Mark every rune inside a match
+	marked := make([]bool, len(lower))
+	hits := 0
+	first := -1
+	for _, word := range words {
+		w := []rune(word)
+		for i := 0; i+len(w) <= len(lower); i++ {
+			if string(lower[i:i+len(w)]) != word {
+				continue
+			}
+			hits++
+			if first < 0 || i < first {
+				first = i
+			}
+			for j := i; j < i+len(w); j++ {
+				marked[j] = true
+			}
+		}
+	}
+	if hits == 0 {
+		return nil, 0
+	}
+
+	start := first - searchSnippetContext
+	if start < 0 {
+		start = 0
+	}
+	end := first + 2*searchSnippetContext
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	var parts []SnippetPart
+	if start > 0 {
+		parts = append(parts, SnippetPart{Text: "…"})
+	}
+	for i := start; i < end; {
+		j := i
+		for j < end && marked[j] == marked[i] {
+			j++
+		}
+		text := strings.ReplaceAll(string(runes[i:j]), "\n", " ")
+		parts = append(parts, SnippetPart{Text: text, Match: marked[i]})
+		i = j
+	}
+	if end < len(runes) {
+		parts = append(parts, SnippetPart{Text: "…"})
+	}
+	return parts, hits
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSearchTerms(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"api.example.com/v1", []string{"api", "example", "com", "v1"}},
+		{"Send Slack_Message!", []string{"send", "slack_message"}},
+		{"  ", []string{}},
+		{"Größe café", []string{"größe", "café"}},
+	}
+	for _, tt := range tests {
+		if got := searchTerms(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("searchTerms(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSearchSnippet(t *testing.T) {
+	parts, hits := searchSnippet("Post to Slack, then slack again", []string{"slack"})
+	if hits != 2 {
+		t.Fatalf("hits = %d, want 2", hits)
+	}
+	want := []SnippetPart{
+		{Text: "Post to "},
+		{Text: "Slack", Match: true},
+		{Text: ", then "},
+		{Text: "slack", Match: true},
+		{Text: " again"},
+	}
+	if !reflect.DeepEqual(parts, want) {
+		t.Errorf("parts = %+v", parts)
+	}
+
+	long := strings.Repeat("a ", 50) + "needle" + strings.Repeat(" b", 100)
+	parts, _ = searchSnippet(long, []string{"needle"})
+	if parts[0].Text != "…" || parts[len(parts)-1].Text != "…" {
+		t.Errorf("long text should be cut on both sides: %+v", parts)
+	}
+
+	if parts, hits := searchSnippet("nothing here", []string{"slack"}); hits != 0 || parts != nil {
+		t.Errorf("no match gave %+v, %d", parts, hits)
+	}
+}
+
+const searchTestFlows = `[
+{"id":"notify","name":"Slack notifier","description":"Posts build results","nodes":[
+  {"id":"n1","data":{"label":"Post message","nodeType":"action_slack","config":{"webhookUrl":"https://hooks.slack.com/services/hidden","text":"Build finished"}}},
+  {"id":"n2","data":{"label":"Fetch","nodeType":"action_http","config":{"url":"https://api.example.com/builds","headers":{"Authorization":"Bearer sekrit"},"apiKey":"topsecretvalue"}}}
+],"edges":[]},
+{"id":"report","name":"Weekly report","description":"","nodes":[
+  {"id":"r1","data":{"label":"Send to slack","nodeType":"action_log","config":{"message":"report ready"}}}
+],"edges":[]}
+]`
+
+func newSearchStorage(t *testing.T) *Storage {
+	t.Helper()
+	s := NewStorageAt(t.TempDir())
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.close)
+	for _, flow := range splitJSONArray(t, searchTestFlows) {
+		if _, err := s.SaveFlow(flow); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func splitJSONArray(t *testing.T, text string) []string {
+	t.Helper()
+	var items []json.RawMessage
+	if err := json.Unmarshal([]byte(text), &items); err != nil {
+		t.Fatal(err)
+	}
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = string(item)
+	}
+	return out
+}
+
+func searchFlowIDs(t *testing.T, s *Storage, query string) []string {
+	t.Helper()
+	results, err := s.SearchFlows(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := []string{}
+	for _, r := range results {
+		ids = append(ids, r.FlowID)
+	}
+	return ids
+}
+
+func TestSearchFlows(t *testing.T) {
+	s := newSearchStorage(t)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"slack", []string{"notify", "report"}}, // the name outranks a node label
+		{"SLA", []string{"notify", "report"}},
+		{"weekly slack", []string{"report"}},
+		{"builds", []string{"notify"}},     // config URL
+		{"finished", []string{"notify"}},   // nested in a node's config
+		{"action_log", []string{"report"}}, // node type
+		{"hidden", []string{}},             // password field
+		{"topsecretvalue", []string{}},     // secret-looking key
+		{"sekrit", []string{}},             // secret-looking nested key
+		{"slack missing", []string{}},
+		{"   ", []string{}},
+	}
+	for _, tt := range tests {
+		if got := searchFlowIDs(t, s, tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SearchFlows(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchFlowsMatches(t *testing.T) {
+	s := newSearchStorage(t)
+
+	results, err := s.SearchFlows("build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("results = %+v", results)
+	}
+	r := results[0]
+	if !reflect.DeepEqual(r.NodeIDs, []string{"n1", "n2"}) {
+		t.Errorf("NodeIDs = %q", r.NodeIDs)
+	}
+	fields := []string{}
+	for _, m := range r.Matches {
+		fields = append(fields, m.NodeID+":"+m.Field)
+	}
+	want := []string{":description", "n1:config.text", "n2:config.url"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("matched fields = %q, want %q", fields, want)
+	}
+	if m := r.Matches[1]; m.Label != "Post message" || m.NodeType != "action_slack" {
+		t.Errorf("match = %+v", m)
+	}
+}
+
+func TestSearchIndexUpdates(t *testing.T) {
+	s := newSearchStorage(t)
+	if got := searchFlowIDs(t, s, "weekly"); !reflect.DeepEqual(got, []string{"report"}) {
+		t.Fatalf("weekly = %q", got)
+	}
+
+	if _, err := s.SaveFlow(`{"id":"report","name":"Monthly report","nodes":[],"edges":[]}`); err != nil {
+		t.Fatal(err)
+	}
+	if got := searchFlowIDs(t, s, "weekly"); len(got) != 0 {
+		t.Errorf("renamed flow still found: %q", got)
+	}
+	if got := searchFlowIDs(t, s, "monthly"); !reflect.DeepEqual(got, []string{"report"}) {
+		t.Errorf("monthly = %q", got)
+	}
+
+	if err := s.DeleteFlow("report"); err != nil {
+		t.Fatal(err)
+	}
+	if got := searchFlowIDs(t, s, "report"); len(got) != 0 {
+		t.Errorf("deleted flow still found: %q", got)
+	}
+}
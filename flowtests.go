@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+)
+
+// FlowTestSuite is the test-case document stored next to a flow as
+// flows/<flowID>.tests.json
+type FlowTestSuite struct {
+	FlowID string         `json:"flowId"`
+	Cases  []FlowTestCase `json:"cases"`
+}
+
+type FlowTestCase struct {
+	Name         string              `json:"name"`
+	Description  string              `json:"description,omitempty"`
+	TriggerInput interface{}         `json:"triggerInput,omitempty"`
+	Mocks        map[string]NodeMock `json:"mocks,omitempty"`
+	Assertions   []FlowTestAssertion `json:"assertions"`
+}
+
+// NodeMock replaces a node's output (or makes it fail) during a test run
+type NodeMock struct {
+	Output interface{} `json:"output,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// FlowTestAssertion checks one aspect of a test run. Supported types:
+//   - "status":      final execution status equals Equals
+//   - "nodeStatus":  status of NodeID equals Equals
+//   - "output":      value at Path in NodeID's output equals Equals
+//   - "branch":      branch handle taken by NodeID equals Equals
+//   - "notExecuted": NodeID did not run
+type FlowTestAssertion struct {
+	Type   string      `json:"type"`
+	NodeID string      `json:"nodeId,omitempty"`
+	Path   string      `json:"path,omitempty"`
+	Equals interface{} `json:"equals,omitempty"`
+}
+
+type FlowTestResult struct {
+	Name        string     `json:"name"`
+	Passed      bool       `json:"passed"`
+	Failures    []string   `json:"failures,omitempty"`
+	Status      NodeStatus `json:"status"`
+	ExecutionID string     `json:"executionId"`
+	Duration    int64      `json:"duration"`
+}
+
+type FlowTestReport struct {
+	FlowID   string           `json:"flowId"`
+	FlowName string           `json:"flowName"`
+	Total    int              `json:"total"`
+	Passed   int              `json:"passed"`
+	Failed   int              `json:"failed"`
+	Duration int64            `json:"duration"`
+	Results  []FlowTestResult `json:"results"`
+}
+
+// RunFlowTests runs every test case stored for a flow and reports the results
+func (e *Engine) RunFlowTests(flowID string) (*FlowTestReport, error) {
+	flowJSON, err := e.storage.LoadFlow(flowID)
+	if err != nil {
+		return nil, err
+	}
+
+	var flow Flow
+	if err := json.Unmarshal([]byte(flowJSON), &flow); err != nil {
+		return nil, fmt.Errorf("invalid flow: %w", err)
+	}
+	e.applyNodeDefaults(&flow)
+
+	suite, err := e.storage.loadFlowTestSuite(flowID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	report := &FlowTestReport{
+		FlowID:   flow.ID,
+		FlowName: flow.Name,
+		Results:  []FlowTestResult{},
+	}
+
+	for _, tc := range suite.Cases {
+		result := e.runFlowTestCase(&flow, tc)
+		report.Total++
+		if result.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	report.Duration = time.Since(start).Milliseconds()
+	return report, nil
+}
+
+func (e *Engine) runFlowTestCase(flow *Flow, tc FlowTestCase) FlowTestResult {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	ctx = contextWithRedactor(ctx, newRedactor())
+
+	start := time.Now()
+	execution := newExecution(flow.ID)
+	problems := e.validateFlow(flow, tc.Mocks)
+	problems = append(problems, e.unmockedBranchNodes(flow, tc)...)
+	if len(problems) > 0 {
+		return FlowTestResult{
+			Name:        tc.Name,
+			Status:      StatusError,
+			ExecutionID: execution.ID,
+			Failures:    problems,
+		}
+	}
+	e.executeFlow(ctx, flow, execution, runOptions{
+		triggerInput: tc.TriggerInput,
+		mocks:        tc.Mocks,
+	})
+
+	result := FlowTestResult{
+		Name:        tc.Name,
+		Status:      execution.Status,
+		ExecutionID: execution.ID,
+		Duration:    time.Since(start).Milliseconds(),
+	}
+
+	results := make(map[string]ExecutionResult)
+	for _, r := range execution.Results {
+		results[r.NodeID] = r
+	}
+
+	for i, a := range tc.Assertions {
+		if msg := checkAssertion(a, execution, results); msg != "" {
+			result.Failures = append(result.Failures, fmt.Sprintf("assertion %d (%s): %s", i+1, a.Type, msg))
+		}
+	}
+	result.Passed = len(result.Failures) == 0
+	return result
+}
+
+// unmockedBranchNodes reports what a test case expects from built-in nodes
+// without a mock. The engine doesn't run those: it stands in an output of
+// {"nodeId", "category", "executed"}, so a condition's branch would always
+// be "false" (or "default" for a switch) whatever the input, and an output
+// assertion would only check the stand-in. Plugin and custom nodes run for
+// real, as do triggers given the case's trigger input.
+func (e *Engine) unmockedBranchNodes(flow *Flow, tc FlowTestCase) []string {
+	simulated := make(map[string]bool)
+	for _, node := range flow.Nodes {
+		nodeType := node.Data.NodeType
+		if _, mocked := tc.Mocks[node.ID]; mocked {
+			continue
+		}
+		if tc.TriggerInput != nil && node.Data.Category == "trigger" {
+			continue
+		}
+		if isCustomNodeType(nodeType) || e.pluginManager().Has(nodeType) {
+			continue
+		}
+		simulated[node.ID] = true
+	}
+
+	var problems []string
+	for _, node := range flow.Nodes {
+		nodeType := node.Data.NodeType
+		if simulated[node.ID] && (booleanBranchTypes[nodeType] || nodeType == "condition_switch") {
+			problems = append(problems, fmt.Sprintf("condition node %q (%s) must be mocked with the branch to take", node.Data.Label, node.ID))
+		}
+	}
+	for i, a := range tc.Assertions {
+		if a.Type == "output" && simulated[a.NodeID] {
+			problems = append(problems, fmt.Sprintf("assertion %d (output): node %s must be mocked, as built-in nodes aren't run in tests", i+1, a.NodeID))
+		}
+	}
+	return problems
+}
+
+// checkAssertion returns an empty string when the assertion holds, otherwise
+// a description of the mismatch
+func checkAssertion(a FlowTestAssertion, execution *FlowExecution, results map[string]ExecutionResult) string {
+	if a.Type == "status" {
+		return compareValues(string(execution.Status), a.Equals)
+	}
+
+	r, executed := results[a.NodeID]
+	if a.Type == "notExecuted" {
+		if executed {
+			return fmt.Sprintf("node %s was executed", a.NodeID)
+		}
+		return ""
+	}
+	if !executed {
+		return fmt.Sprintf("node %s was not executed", a.NodeID)
+	}
+
+	switch a.Type {
+	case "nodeStatus":
+		return compareValues(string(r.Status), a.Equals)
+	case "branch":
+		return compareValues(r.Branch, a.Equals)
+	case "output":
+		value, ok := lookupPath(normalizeJSON(r.Output), a.Path)
+		if !ok {
+			return fmt.Sprintf("path %q not found in output of %s", a.Path, a.NodeID)
+		}
+		return compareValues(value, a.Equals)
+	default:
+		return fmt.Sprintf("unknown assertion type %q", a.Type)
+	}
+}
+
+func compareValues(actual, expected interface{}) string {
+	actual, expected = normalizeJSON(actual), normalizeJSON(expected)
+	if reflect.DeepEqual(actual, expected) {
+		return ""
+	}
+	got, _ := json.Marshal(actual)
+	want, _ := json.Marshal(expected)
+	return fmt.Sprintf("expected %s, got %s", want, got)
+}
+
+// normalizeJSON round-trips a value through JSON so Go values and decoded
+// fixtures compare equal
+func normalizeJSON(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnitReport renders test reports as JUnit XML for CI systems
+func writeJUnitReport(w io.Writer, reports []*FlowTestReport) error {
+	seconds := func(ms int64) string { return fmt.Sprintf("%.3f", float64(ms)/1000) }
+
+	doc := junitTestSuites{}
+	var total int64
+	for _, report := range reports {
+		suite := junitTestSuite{
+			Name:     report.FlowName,
+			Tests:    report.Total,
+			Failures: report.Failed,
+			Time:     seconds(report.Duration),
+		}
+		if suite.Name == "" {
+			suite.Name = report.FlowID
+		}
+		for _, r := range report.Results {
+			tc := junitTestCase{
+				Name:      r.Name,
+				ClassName: report.FlowID,
+				Time:      seconds(r.Duration),
+			}
+			if !r.Passed {
+				text := ""
+				for _, f := range r.Failures {
+					text += f + "\n"
+				}
+				tc.Failure = &junitFailure{
+					Message: fmt.Sprintf("%d assertion(s) failed", len(r.Failures)),
+					Text:    text,
+				}
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		doc.Tests += report.Total
+		doc.Failures += report.Failed
+		total += report.Duration
+		doc.Suites = append(doc.Suites, suite)
+	}
+	doc.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
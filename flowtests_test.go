@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+// testFlowJSON is a manual trigger followed by an If/Else node that logs on
+// its true branch and notifies on its false branch
+const testFlowJSON = `{
+	"id": "flow-tested", "name": "Tested",
+	"nodes": [
+		{"id": "start", "type": "custom", "data": {"label": "Start", "category": "trigger", "nodeType": "trigger_manual", "config": {}}},
+		{"id": "check", "type": "custom", "data": {"label": "Check", "category": "condition", "nodeType": "condition_if", "config": {"condition": "{{output.ok}}"}}},
+		{"id": "log", "type": "custom", "data": {"label": "Log", "category": "action", "nodeType": "action_log", "config": {"message": "yes"}}},
+		{"id": "notify", "type": "custom", "data": {"label": "Notify", "category": "action", "nodeType": "action_notification", "config": {"title": "no"}}}
+	],
+	"edges": [
+		{"id": "e1", "source": "start", "target": "check"},
+		{"id": "e2", "source": "check", "target": "log", "sourceHandle": "true"},
+		{"id": "e3", "source": "check", "target": "notify", "sourceHandle": "false"}
+	]
+}`
+
+func runTestCases(t *testing.T, cases ...FlowTestCase) *FlowTestReport {
+	t.Helper()
+	s := NewStorageAt(t.TempDir())
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.close)
+	if _, err := s.SaveFlow(testFlowJSON); err != nil {
+		t.Fatal(err)
+	}
+	suite, _ := json.Marshal(FlowTestSuite{Cases: cases})
+	if err := s.SaveFlowTests("flow-tested", string(suite)); err != nil {
+		t.Fatal(err)
+	}
+	report, err := NewEngine(s).RunFlowTests("flow-tested")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func TestRunFlowTests(t *testing.T) {
+	branchTrue := map[string]NodeMock{"check": {Output: true}}
+	tests := []struct {
+		name     string
+		tc       FlowTestCase
+		failures []string // substrings, one per expected failure
+	}{
+		{
+			name: "branch and status",
+			tc: FlowTestCase{Mocks: branchTrue, Assertions: []FlowTestAssertion{
+				{Type: "status", Equals: "success"},
+				{Type: "branch", NodeID: "check", Equals: "true"},
+				{Type: "nodeStatus", NodeID: "log", Equals: "success"},
+				{Type: "notExecuted", NodeID: "notify"},
+			}},
+		},
+		{
+			name: "mocked output",
+			tc: FlowTestCase{
+				TriggerInput: map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": 7}}},
+				Mocks:        map[string]NodeMock{"check": {Output: false}, "notify": {Output: map[string]interface{}{"sent": true}}},
+				Assertions: []FlowTestAssertion{
+					{Type: "output", NodeID: "start", Path: "items[0].id", Equals: 7},
+					{Type: "output", NodeID: "notify", Path: "sent", Equals: true},
+				},
+			},
+		},
+		{
+			name: "failed assertions",
+			tc: FlowTestCase{Mocks: branchTrue, Assertions: []FlowTestAssertion{
+				{Type: "branch", NodeID: "check", Equals: "false"},
+				{Type: "notExecuted", NodeID: "log"},
+				{Type: "nodeStatus", NodeID: "notify", Equals: "success"},
+			}},
+			failures: []string{`expected "false", got "true"`, "node log was executed", "node notify was not executed"},
+		},
+		{
+			name: "mocked error",
+			tc: FlowTestCase{Mocks: map[string]NodeMock{"check": {Output: true}, "log": {Error: "disk full"}}, Assertions: []FlowTestAssertion{
+				{Type: "status", Equals: "error"},
+				{Type: "nodeStatus", NodeID: "log", Equals: "error"},
+			}},
+		},
+		{
+			name:     "unmocked condition",
+			tc:       FlowTestCase{Assertions: []FlowTestAssertion{{Type: "status", Equals: "success"}}},
+			failures: []string{`condition node "Check" (check) must be mocked`},
+		},
+		{
+			name: "output of an unmocked node",
+			tc: FlowTestCase{Mocks: branchTrue, Assertions: []FlowTestAssertion{
+				{Type: "output", NodeID: "log", Path: "executed", Equals: true},
+				{Type: "output", NodeID: "start", Path: "executed", Equals: true},
+			}},
+			failures: []string{"assertion 1 (output): node log must be mocked", "assertion 2 (output): node start must be mocked"},
+		},
+	}
+
+	cases := make([]FlowTestCase, len(tests))
+	for i, tt := range tests {
+		cases[i] = tt.tc
+		cases[i].Name = tt.name
+	}
+	report := runTestCases(t, cases...)
+	if report.Total != len(tests) || report.FlowName != "Tested" {
+		t.Fatalf("report = %+v", report)
+	}
+	for i, tt := range tests {
+		result := report.Results[i]
+		if result.Name != tt.name {
+			t.Fatalf("result %d is %q, want %q", i, result.Name, tt.name)
+		}
+		if result.Passed != (len(tt.failures) == 0) || len(result.Failures) != len(tt.failures) {
+			t.Errorf("%s: passed = %v, failures = %q", tt.name, result.Passed, result.Failures)
+			continue
+		}
+		for j, want := range tt.failures {
+			if !strings.Contains(result.Failures[j], want) {
+				t.Errorf("%s: failure %d = %q, want %q", tt.name, j+1, result.Failures[j], want)
+			}
+		}
+	}
+}
+
+func TestWriteJUnitReport(t *testing.T) {
+	reports := []*FlowTestReport{
+		{FlowID: "flow-1", FlowName: "First", Total: 2, Passed: 1, Failed: 1, Duration: 1500, Results: []FlowTestResult{
+			{Name: "passes", Passed: true, Duration: 250},
+			{Name: "fails", Failures: []string{"assertion 1 (status): expected \"success\", got \"error\"", "assertion 2 (notExecuted): node <b> & co"}, Duration: 1250},
+		}},
+		{FlowID: "flow-2", Total: 1, Passed: 1, Duration: 20, Results: []FlowTestResult{{Name: "also passes", Passed: true, Duration: 20}}},
+	}
+	var buf bytes.Buffer
+	if err := writeJUnitReport(&buf, reports); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), xml.Header) {
+		t.Errorf("missing XML header: %q", buf.String())
+	}
+
+	var doc junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, buf.String())
+	}
+	if doc.Tests != 3 || doc.Failures != 1 || doc.Time != "1.520" || len(doc.Suites) != 2 {
+		t.Fatalf("testsuites = %+v", doc)
+	}
+	first := doc.Suites[0]
+	if first.Name != "First" || first.Time != "1.500" || len(first.Cases) != 2 {
+		t.Errorf("first suite = %+v", first)
+	}
+	if c := first.Cases[0]; c.Name != "passes" || c.ClassName != "flow-1" || c.Time != "0.250" || c.Failure != nil {
+		t.Errorf("passing case = %+v", c)
+	}
+	failure := first.Cases[1].Failure
+	if failure == nil || failure.Message != "2 assertion(s) failed" || !strings.Contains(failure.Text, "node <b> & co\n") {
+		t.Errorf("failure = %+v", failure)
+	}
+	// Suites of unnamed flows are named after the flow ID
+	if doc.Suites[1].Name != "flow-2" {
+		t.Errorf("second suite is named %q", doc.Suites[1].Name)
+	}
+}
@@ -15,6 +15,8 @@ import { DialogProvider } from "@/components/ui";
 import { useFlowStore } from "@/stores/flowStore";
 import { useWorkflowStore } from "@/stores/workflowStore";
 import { useSettingsStore } from "@/stores/settingsStore";
+import { usePluginStore } from "@/stores/pluginStore";
+import { useCustomNodeStore } from "@/stores/customNodeStore";
 import { toast } from "@/stores/dialogStore";
 import { useEffect, useState } from "react";
 
@@ -71,6 +73,8 @@ export default function App() {
   } = useFlowStore();
   const { executionHistoryOpen, setExecutionHistoryOpen, importExportOpen, setImportExportOpen } = useWorkflowStore();
   const { loadSettings, applyTheme } = useSettingsStore();
+  const { loadPlugins } = usePluginStore();
+  const { loadCustomNodes } = useCustomNodeStore();
   const [isLoading, setIsLoading] = useState(true);
 
   const activeFlow = flows.find((f) => f.id === activeFlowId);
@@ -85,6 +89,8 @@ export default function App() {
         await loadSettings();
         applyTheme();
         await loadFlows();
+        await loadPlugins();
+        await loadCustomNodes();
       } catch (error) {
         console.error("Failed to initialize app:", error);
         toast.error("Initialization failed", "Some features may not work correctly");
@@ -94,7 +100,7 @@ export default function App() {
     };
 
     initApp();
-  }, [loadSettings, applyTheme, loadFlows]);
+  }, [loadSettings, applyTheme, loadFlows, loadPlugins, loadCustomNodes]);
 
   if (isLoading) {
     return <SplashScreen />;
@@ -154,13 +154,13 @@ export default function CustomNodeBuilder() {
     setFields(fields.filter((_, i) => i !== index));
   };
 
-  const handleSave = () => {
+  const handleSave = async () => {
     if (!name.trim()) {
       toast.error('Name required', 'Please enter a node name');
       return;
     }
 
-    const nodeType = editingNode?.type || `custom_${name.toLowerCase().replace(/\s+/g, '_')}_${Date.now()}`;
+    const nodeType = editingNode?.type || `custom_${name.toLowerCase().replace(/[^a-z0-9]+/g, '_')}_${Date.now()}`;
     
     const nodeData: Omit<CustomNodeDefinition, 'createdAt' | 'updatedAt' | 'isCustom'> = {
       type: nodeType,
@@ -186,12 +186,17 @@ export default function CustomNodeBuilder() {
       },
     };
 
-    if (editingNode) {
-      updateCustomNode(editingNode.type, nodeData);
-      toast.success('Node updated', `"${name}" has been updated`);
-    } else {
-      addCustomNode(nodeData);
-      toast.success('Node created', `"${name}" is now available in the sidebar`);
+    try {
+      if (editingNode) {
+        await updateCustomNode(editingNode.type, nodeData);
+        toast.success('Node updated', `"${name}" has been updated`);
+      } else {
+        await addCustomNode(nodeData);
+        toast.success('Node created', `"${name}" is now available in the sidebar`);
+      }
+    } catch (error) {
+      toast.error('Save failed', String(error));
+      return;
     }
 
     handleClose();
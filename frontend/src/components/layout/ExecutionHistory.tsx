@@ -9,7 +9,11 @@ interface ExecutionHistoryProps {
 }
 
 export default function ExecutionHistory({ onClose }: ExecutionHistoryProps) {
-  const { executions, selectedExecution, isLoading, loadExecutions, deleteExecution, clearExecutions, setSelectedExecution } = useExecutionStore();
+  const {
+    executions, selectedExecution, isLoading, statusFilter, statusCounts, total, nextCursor,
+    loadExecutions, loadMoreExecutions, setStatusFilter, deleteExecution, clearExecutions, setSelectedExecution,
+  } = useExecutionStore();
+  const allCount = Object.values(statusCounts).reduce((sum, n) => sum + n, 0);
   const { confirm } = useConfirm();
 
   useEffect(() => {
@@ -70,7 +74,7 @@ export default function ExecutionHistory({ onClose }: ExecutionHistoryProps) {
         <div className="flex items-center justify-between px-6 py-4 border-b border-[#3e3e42]">
           <div>
             <h2 className="text-lg font-semibold text-[#d4d4d4]">Execution History</h2>
-            <p className="text-sm text-[#858585]">{executions.length} executions</p>
+            <p className="text-sm text-[#858585]">{total} executions</p>
           </div>
           <div className="flex items-center gap-2">
             {executions.length > 0 && (
@@ -105,6 +109,19 @@ export default function ExecutionHistory({ onClose }: ExecutionHistoryProps) {
         <div className="flex-1 flex overflow-hidden">
           {/* Execution List */}
           <div className="w-1/2 border-r border-[#3e3e42] overflow-y-auto">
+            <div className="flex gap-1 px-4 py-2 border-b border-[#3e3e42]">
+              {[["", "All", allCount], ["success", "Success", statusCounts.success || 0], ["error", "Error", statusCounts.error || 0], ["running", "Running", statusCounts.running || 0]].map(([status, label, count]) => (
+                <button
+                  key={status as string}
+                  onClick={() => setStatusFilter(status as string)}
+                  className={`px-2 py-1 text-xs rounded transition-colors ${
+                    statusFilter === status ? "bg-[#2d2d30] text-[#d4d4d4]" : "text-[#858585] hover:bg-[#2a2a2d]"
+                  }`}
+                >
+                  {label} ({count})
+                </button>
+              ))}
+            </div>
             {isLoading ? (
               <div className="flex items-center justify-center h-full text-[#858585]">
                 Loading...
@@ -151,6 +168,14 @@ export default function ExecutionHistory({ onClose }: ExecutionHistoryProps) {
                     </div>
                   </div>
                 ))}
+                {nextCursor && (
+                  <button
+                    onClick={loadMoreExecutions}
+                    className="w-full p-3 text-sm text-[#858585] hover:bg-[#2a2a2d] transition-colors"
+                  >
+                    Load more
+                  </button>
+                )}
               </div>
             )}
           </div>
@@ -1,32 +1,74 @@
 import { useState } from "react";
 import { X, Upload, Download, FileJson, AlertCircle } from "lucide-react";
 import { useFlowStore } from "@/stores/flowStore";
-import { ExportFlow, ImportFlow } from "../../../wailsjs/go/main/Storage";
+import { useCustomNodeStore } from "@/stores/customNodeStore";
+import { ExportFlow, ExportFlowBundle, ExportFlowDiagram, ImportFlowBundle, ImportFlowWithReport } from "../../../wailsjs/go/main/Storage";
 
 interface ImportExportProps {
   onClose: () => void;
 }
 
+type ExportFormat = "flow" | "bundle" | "mermaid" | "dot" | "svg";
+
+const DIAGRAM_FORMATS: Partial<Record<ExportFormat, { label: string; extension: string; type: string }>> = {
+  mermaid: { label: "a Mermaid diagram", extension: "mmd", type: "text/plain" },
+  dot: { label: "a Graphviz diagram", extension: "dot", type: "text/vnd.graphviz" },
+  svg: { label: "an SVG image", extension: "svg", type: "image/svg+xml" },
+};
+
 export default function ImportExport({ onClose }: ImportExportProps) {
   const { flows, activeFlowId, nodes, edges, loadFlow } = useFlowStore();
   const [importing, setImporting] = useState(false);
   const [error, setError] = useState<string | null>(null);
   const [success, setSuccess] = useState<string | null>(null);
+  const [exportAs, setExportAs] = useState<ExportFormat>("flow");
+  const asBundle = exportAs === "bundle";
+  const diagram = DIAGRAM_FORMATS[exportAs];
+  const [notes, setNotes] = useState<string[]>([]);
+
+  const download = (content: string, type: string, fileName: string) => {
+    const blob = new Blob([content], { type });
+    const url = URL.createObjectURL(blob);
+    const a = document.createElement("a");
+    a.href = url;
+    a.download = fileName;
+    a.click();
+    URL.revokeObjectURL(url);
+  };
+
+  const handleExportDiagram = async (flowId: string, format: { label: string; extension: string; type: string }) => {
+    try {
+      const content = await ExportFlowDiagram(flowId, exportAs);
+      const name = flows.find((f) => f.id === flowId)?.name ?? "flow";
+      download(content, format.type, `${name.replace(/[^a-z0-9]/gi, '-').toLowerCase()}.${format.extension}`);
+      setSuccess(`Exported "${name}" as ${format.label}`);
+      setTimeout(() => setSuccess(null), 3000);
+    } catch (err) {
+      setError(`Failed to export diagram: ${err}`);
+      setTimeout(() => setError(null), 5000);
+    }
+  };
 
   const handleExport = async (flowId: string) => {
+    if (diagram) {
+      return handleExportDiagram(flowId, diagram);
+    }
     try {
-      const flowData = await ExportFlow(flowId);
-      const flow = JSON.parse(flowData);
+      const flowData = asBundle ? await ExportFlowBundle(flowId) : await ExportFlow(flowId);
+      const exported = JSON.parse(flowData);
+      const name: string = asBundle ? exported.manifest.flows[0]?.name ?? "flow" : exported.name;
       
-      const blob = new Blob([JSON.stringify(flow, null, 2)], { type: "application/json" });
-      const url = URL.createObjectURL(blob);
-      const a = document.createElement("a");
-      a.href = url;
-      a.download = `${flow.name.replace(/[^a-z0-9]/gi, '-').toLowerCase()}-${new Date().toISOString().split('T')[0]}.json`;
-      a.click();
-      URL.revokeObjectURL(url);
+      download(
+        JSON.stringify(exported, null, 2),
+        "application/json",
+        `${name.replace(/[^a-z0-9]/gi, '-').toLowerCase()}${asBundle ? "-bundle" : ""}-${new Date().toISOString().split('T')[0]}.json`,
+      );
       
-      setSuccess(`Exported "${flow.name}" successfully`);
+      const missing: string[] = asBundle ? exported.manifest.missingCustomNodes ?? [] : [];
+      setSuccess(asBundle
+        ? `Exported "${name}" with ${exported.manifest.flows.length - 1} called flows and ${exported.manifest.customNodes.length} custom nodes` +
+          (missing.length > 0 ? `. Not installed, so not bundled: ${missing.join(", ")}` : '')
+        : `Exported "${name}" successfully`);
       setTimeout(() => setSuccess(null), 3000);
     } catch (err) {
       setError(`Failed to export flow: ${err}`);
@@ -35,8 +77,13 @@ export default function ImportExport({ onClose }: ImportExportProps) {
   };
 
   const handleExportCurrent = () => {
-    if (!activeFlowId) {
-      // Export current canvas as new flow
+    if (!activeFlowId && diagram) {
+      setError("Save the flow before exporting it as a diagram");
+      setTimeout(() => setError(null), 5000);
+    } else if (!activeFlowId) {
+      // Export current canvas as new flow, with the custom nodes it uses
+      const usedTypes = new Set(nodes.map((n) => n.data.nodeType));
+      const customNodes = useCustomNodeStore.getState().customNodes.filter((n) => usedTypes.has(n.type));
       const flow = {
         id: crypto.randomUUID(),
         name: "Untitled Flow",
@@ -46,15 +93,10 @@ export default function ImportExport({ onClose }: ImportExportProps) {
         enabled: false,
         createdAt: new Date().toISOString(),
         updatedAt: new Date().toISOString(),
+        ...(customNodes.length > 0 && { customNodes }),
       };
       
-      const blob = new Blob([JSON.stringify(flow, null, 2)], { type: "application/json" });
-      const url = URL.createObjectURL(blob);
-      const a = document.createElement("a");
-      a.href = url;
-      a.download = `flow-${new Date().toISOString().split('T')[0]}.json`;
-      a.click();
-      URL.revokeObjectURL(url);
+      download(JSON.stringify(flow, null, 2), "application/json", `flow-${new Date().toISOString().split('T')[0]}.json`);
       
       setSuccess("Exported current canvas successfully");
       setTimeout(() => setSuccess(null), 3000);
@@ -70,26 +112,66 @@ export default function ImportExport({ onClose }: ImportExportProps) {
     setImporting(true);
     setError(null);
     setSuccess(null);
+    setNotes([]);
 
     try {
       const text = await file.text();
       const flow = JSON.parse(text);
+
+      if (flow.format === "forgeflow-bundle") {
+        const report = await ImportFlowBundle(text);
+        if (report.customNodes.length > 0) {
+          await useCustomNodeStore.getState().loadCustomNodes();
+        }
+        await useFlowStore.getState().loadFlows();
+        if (report.missingSecrets.length > 0) {
+          // Stays open so the list can be read
+          setSuccess(`Imported ${Object.keys(report.flowIds).length} flows. Set these secrets before running them: ${report.missingSecrets.join(", ")}`);
+          return;
+        }
+        setSuccess(`Imported ${Object.keys(report.flowIds).length} flows and ${report.customNodes.length} custom nodes, ${report.triggers} triggers registered`);
+        setTimeout(() => {
+          setSuccess(null);
+          loadFlow(report.flowId);
+          onClose();
+        }, 2000);
+        return;
+      }
       
-      // Validate flow structure
-      if (!flow.nodes || !Array.isArray(flow.nodes)) {
+      // Validate flow structure; n8n workflows have connections instead of
+      // edges, and Node-RED exports are a list of nodes
+      const nodeRed = Array.isArray(flow) || Array.isArray(flow.flows);
+      if (!nodeRed && (!flow.nodes || !Array.isArray(flow.nodes))) {
         throw new Error("Invalid flow format: missing nodes array");
       }
-      if (!flow.edges || !Array.isArray(flow.edges)) {
+      if (!nodeRed && !Array.isArray(flow.edges) && (!flow.connections || typeof flow.connections !== "object")) {
         throw new Error("Invalid flow format: missing edges array");
       }
       
       // Import flow
-      const newFlowId = await ImportFlow(text);
-      
-      setSuccess(`Imported "${flow.name || 'Untitled Flow'}" successfully`);
+      const report = await ImportFlowWithReport(text);
+      if (nodeRed || (Array.isArray(flow.customNodes) && flow.customNodes.length > 0)) {
+        await useCustomNodeStore.getState().loadCustomNodes();
+      }
+      await useFlowStore.getState().loadFlows();
+
+      const reportNotes = [...report.placeholders, ...report.warnings].map((note) =>
+        [note.flow, note.node].filter(Boolean).join(" › ") + (note.flow || note.node ? ": " : "") + note.message);
+      if (report.format !== "forgeflow") {
+        const converted = report.flowIds.length > 1 ? `${report.flowIds.length} flows` : `"${flow.name || 'Untitled Flow'}"`;
+        setSuccess(`Converted ${converted} from ${report.format}: ${report.nodes} nodes converted, ${report.placeholders.length} imported as placeholders`);
+        if (reportNotes.length > 0) {
+          // Stays open so the notes can be read
+          setNotes(reportNotes);
+          loadFlow(report.flowId);
+          return;
+        }
+      } else {
+        setSuccess(`Imported "${flow.name || 'Untitled Flow'}" successfully`);
+      }
       setTimeout(() => {
         setSuccess(null);
-        loadFlow(newFlowId);
+        loadFlow(report.flowId);
         onClose();
       }, 2000);
     } catch (err) {
@@ -135,6 +217,17 @@ export default function ImportExport({ onClose }: ImportExportProps) {
             </div>
           )}
 
+          {notes.length > 0 && (
+            <div className="p-4 bg-yellow-500/10 border border-yellow-500/20 rounded-lg">
+              <p className="text-sm text-yellow-400 mb-2">Review these before running the flow:</p>
+              <ul className="text-xs text-[#d4d4d4] space-y-1 max-h-40 overflow-y-auto list-disc pl-4">
+                {notes.map((note, i) => (
+                  <li key={i}>{note}</li>
+                ))}
+              </ul>
+            </div>
+          )}
+
           {/* Import Section */}
           <div>
             <h3 className="text-sm font-semibold text-[#d4d4d4] mb-3 flex items-center gap-2">
@@ -159,7 +252,7 @@ export default function ImportExport({ onClose }: ImportExportProps) {
                   {importing ? "Importing..." : "Click to select a flow file"}
                 </p>
                 <p className="text-xs text-[#858585]">
-                  Supports .json files exported from ForgeFlow
+                  Supports .json flows and bundles exported from ForgeFlow, n8n workflows and Node-RED flows
                 </p>
               </label>
             </div>
@@ -172,6 +265,21 @@ export default function ImportExport({ onClose }: ImportExportProps) {
               Export Flows
             </h3>
             
+            <label className="flex items-center gap-2 mb-3 text-xs text-[#858585]">
+              Export as
+              <select
+                value={exportAs}
+                onChange={(e) => setExportAs(e.target.value as ExportFormat)}
+                className="flex-1 px-2 py-1 text-xs bg-[#1e1e1e] text-[#d4d4d4] border border-[#3e3e42] rounded focus:outline-none"
+              >
+                <option value="flow">Flow (.json)</option>
+                <option value="bundle">Bundle with called flows, custom nodes and the names of the secrets used (.json)</option>
+                <option value="mermaid">Mermaid diagram (.mmd)</option>
+                <option value="dot">Graphviz diagram (.dot)</option>
+                <option value="svg">SVG image (.svg)</option>
+              </select>
+            </label>
+
             {/* Current Canvas */}
             {(nodes.length > 0 || edges.length > 0) && (
               <div className="mb-3">
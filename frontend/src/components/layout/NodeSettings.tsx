@@ -6,6 +6,7 @@ import { useFlowStore } from "@/stores/flowStore";
 import { useAIStore } from "@/stores/aiStore";
 import { cn } from "@/lib/utils";
 import { getNodeDefinition } from "@/nodes";
+import { usePluginStore } from "@/stores/pluginStore";
 
 interface NodeSettingsProps {
   selectedNodeId: string | null;
@@ -15,12 +16,13 @@ interface NodeSettingsProps {
 export default function NodeSettings({ selectedNodeId, onClose }: NodeSettingsProps) {
   const { nodes, updateNodeData } = useFlowStore();
   const { models, fetchModels, isLoading: isModelsLoading } = useAIStore();
+  const { getPluginNode } = usePluginStore();
   const selectedNode = nodes.find((n) => n.id === selectedNodeId);
   if (!selectedNode) return null;
 
   const { data } = selectedNode;
   const config = (data.config || {}) as Record<string, any>;
-  const definition = getNodeDefinition(data.nodeType || "");
+  const definition = getNodeDefinition(data.nodeType || "") ?? getPluginNode(data.nodeType || "");
   const hasFields = definition?.fields && definition.fields.length > 0;
 
   // Fix isConfigured status on mount
@@ -2,9 +2,13 @@ import { useState, useEffect } from 'react';
 import { X, Moon, Sun, Palette, Zap, Database, Bell, Shield, Code, RotateCcw, Save, Sparkles, Brain, Globe, Key, Plus, Trash, Eye, EyeOff, List, Info } from 'lucide-react';
 import { useSettingsStore } from '@/stores/settingsStore';
 import { useAIStore } from '@/stores/aiStore';
-import { useDialogStore } from '@/stores/dialogStore';
+import { useDialogStore, toast } from '@/stores/dialogStore';
+import { useFlowStore } from '@/stores/flowStore';
+import { CreateBackup, DeleteSecret, GetSecretsStatus, GetStorageBackend, GitPull, GitPush, ListSecrets, LockSecrets, MigrateStorage, PreviewBackup, RekeySecrets, RestoreBackup, UnlockSecrets } from '../../../wailsjs/go/main/Storage';
+import { SelectDirectory, SelectFile } from '../../../wailsjs/go/main/App';
+import type { main } from '../../../wailsjs/go/models';
 import { themes, accentColors } from '@/types/settings';
-import type { AppSettings } from '@/types/settings';
+import type { AppSettings, SecretProvider } from '@/types/settings';
 import { cn } from '@/lib/utils';
 
 type SettingsTab = 'appearance' | 'performance' | 'ai' | 'variables' | 'storage' | 'notifications' | 'security' | 'advanced';
@@ -135,7 +139,7 @@ export default function Settings({ onClose }: SettingsProps) {
                 <PerformanceSettings settings={settings} updateSettings={updateSettings} />
               )}
               {activeTab === 'storage' && (
-                <StorageSettings />
+                <StorageSettings settings={settings} updateSettings={updateSettings} />
               )}
               {activeTab === 'notifications' && (
                 <NotificationSettings settings={settings} updateSettings={updateSettings} />
@@ -428,7 +432,7 @@ function PerformanceSettings({ settings, updateSettings }: SettingsPageProps) {
   );
 }
 
-function StorageSettings() {
+function StorageSettings({ settings, updateSettings }: SettingsPageProps) {
   return (
     <>
       <div className="flex items-center gap-3 mb-6">
@@ -461,11 +465,233 @@ function StorageSettings() {
             Settings are persisted locally and synced on startup.
           </p>
         </div>
+
+        <BackupSetting />
+
+        <ScheduledBackupSetting settings={settings} updateSettings={updateSettings} />
       </div>
     </>
   );
 }
 
+function BackupSetting() {
+  const { confirm } = useDialogStore();
+  const loadFlows = useFlowStore((state) => state.loadFlows);
+  const loadSettings = useSettingsStore((state) => state.loadSettings);
+  const [includeExecutions, setIncludeExecutions] = useState(false);
+  const [passphrase, setPassphrase] = useState('');
+  const [restorePath, setRestorePath] = useState('');
+  const [preview, setPreview] = useState<main.BackupPreview | null>(null);
+  const [options, setOptions] = useState({ mode: 'merge', onConflict: 'skip', settings: true, executions: true, secrets: true });
+  const [busy, setBusy] = useState(false);
+
+  const backUp = async () => {
+    const dir = await SelectDirectory('Save Backup To');
+    if (!dir) return;
+    const stamp = new Date().toISOString().slice(0, 19).replace(/[-:]/g, '').replace('T', '-');
+    setBusy(true);
+    try {
+      const manifest = await CreateBackup(`${dir}/forgeflow-backup-${stamp}.zip`, {
+        executions: includeExecutions,
+        secrets: passphrase !== '',
+        passphrase,
+      });
+      toast.success('Backup created', `${manifest.flows} flows, ${manifest.customNodes} custom nodes and ${manifest.secrets} secrets`);
+    } catch (error) {
+      toast.error('Backup failed', error instanceof Error ? error.message : String(error));
+    } finally {
+      setBusy(false);
+    }
+  };
+
+  const chooseBackup = async () => {
+    const path = await SelectFile('Restore Backup', [{ DisplayName: 'ForgeFlow Backup', Pattern: '*.zip' }]);
+    if (!path) return;
+    try {
+      setPreview(await PreviewBackup(path));
+      setRestorePath(path);
+    } catch (error) {
+      toast.error('Invalid backup', error instanceof Error ? error.message : String(error));
+    }
+  };
+
+  const restore = () => {
+    if (!preview) return;
+    confirm({
+      title: 'Restore Backup',
+      message: options.mode === 'replace'
+        ? 'All flows and custom nodes, and the restored kinds of data, will be replaced by the backup. The current data is saved to a backup in the data directory first.'
+        : 'The backup will be added to your current data.',
+      confirmText: 'Restore',
+      cancelText: 'Cancel',
+      variant: options.mode === 'replace' ? 'danger' : 'default',
+      onConfirm: async () => {
+        setBusy(true);
+        try {
+          const report = await RestoreBackup(restorePath, {
+            ...options,
+            secrets: options.secrets && preview.secrets.length > 0,
+            passphrase,
+          });
+          await loadFlows();
+          if (report.settings) await loadSettings();
+          setPreview(null);
+          setPassphrase('');
+          toast.success('Backup restored', `${report.flows} flows, ${report.customNodes} custom nodes, ${report.executions} executions and ${report.secrets} secrets restored, ${report.skipped} skipped`);
+        } catch (error) {
+          toast.error('Restore failed', error instanceof Error ? error.message : String(error));
+        } finally {
+          setBusy(false);
+        }
+      },
+    });
+  };
+
+  const conflicts = preview
+    ? preview.flows.filter((f) => f.exists).length + preview.customNodes.filter((n) => n.exists).length + preview.secrets.filter((s) => s.exists).length + preview.executionConflicts
+    : 0;
+
+  const inputClass = 'px-3 py-2 rounded-lg bg-background border border-border focus:ring-1 focus:ring-primary outline-none text-sm transition-all';
+  const buttonClass = 'px-3 py-2 rounded-lg border border-border bg-muted/30 hover:bg-muted text-[10px] font-bold uppercase transition-all disabled:opacity-50';
+
+  return (
+    <div className="p-4 rounded-lg bg-muted/30">
+      <label className="text-sm font-medium mb-1 block">Backup & Restore</label>
+      <p className="text-xs text-muted-foreground mb-3">
+        Save flows, settings and custom nodes to a single archive, to move your setup to another machine. Secrets are included when you enter a passphrase to encrypt them with.
+      </p>
+      <div className="space-y-2">
+        <input
+          type="password"
+          value={passphrase}
+          onChange={(e) => setPassphrase(e.target.value)}
+          placeholder="Backup passphrase (for secrets)"
+          className={cn(inputClass, 'w-full')}
+        />
+        <div className="flex items-center gap-2">
+          <button onClick={backUp} disabled={busy} className={buttonClass}>Back Up Now</button>
+          <button onClick={chooseBackup} disabled={busy} className={buttonClass}>Restore...</button>
+          <label className="flex items-center gap-2 text-xs text-muted-foreground ml-2">
+            <input type="checkbox" checked={includeExecutions} onChange={(e) => setIncludeExecutions(e.target.checked)} />
+            Include execution history
+          </label>
+        </div>
+
+        {preview && (
+          <div className="p-3 rounded-lg border border-border space-y-3 text-xs">
+            <div className="flex items-center justify-between">
+              <span className="font-medium">Backup of {new Date(preview.manifest.createdAt).toLocaleString()}</span>
+              <button onClick={() => setPreview(null)} className="text-muted-foreground hover:text-foreground">
+                <X className="w-3.5 h-3.5" />
+              </button>
+            </div>
+            <p className="text-muted-foreground">
+              {preview.flows.length} flows, {preview.customNodes.length} custom nodes, {preview.executions} executions, {preview.secrets.length} secrets
+              {preview.manifest.settings && ' and settings'}. {conflicts > 0 ? `${conflicts} already exist.` : 'None exist yet.'}
+            </p>
+            {preview.flows.some((f) => f.exists) && (
+              <div className="max-h-24 overflow-y-auto rounded border border-border divide-y divide-border">
+                {preview.flows.filter((f) => f.exists).map((f) => (
+                  <div key={f.id} className="px-2 py-1 font-mono">{f.name || f.id}</div>
+                ))}
+              </div>
+            )}
+            <div className="flex gap-2">
+              <select value={options.mode} onChange={(e) => setOptions({ ...options, mode: e.target.value })} className={cn(inputClass, 'flex-1')}>
+                <option value="merge">Merge with current data</option>
+                <option value="replace">Replace current data</option>
+              </select>
+              <select value={options.onConflict} onChange={(e) => setOptions({ ...options, onConflict: e.target.value })} className={cn(inputClass, 'flex-1')}>
+                <option value="skip">Keep existing items</option>
+                <option value="overwrite">Overwrite existing items</option>
+                <option value="copy">Restore flows as copies</option>
+              </select>
+            </div>
+            <div className="flex gap-4 text-muted-foreground">
+              {([['settings', 'Settings'], ['executions', 'Execution history'], ['secrets', 'Secrets']] as const).map(([key, label]) => (
+                <label key={key} className="flex items-center gap-2">
+                  <input type="checkbox" checked={options[key]} onChange={(e) => setOptions({ ...options, [key]: e.target.checked })} />
+                  {label}
+                </label>
+              ))}
+            </div>
+            <button
+              onClick={restore}
+              disabled={busy || (options.secrets && preview.secrets.length > 0 && !passphrase)}
+              className={buttonClass}
+            >
+              {busy ? '...' : 'Restore'}
+            </button>
+          </div>
+        )}
+      </div>
+    </div>
+  );
+}
+
+function ScheduledBackupSetting({ settings, updateSettings }: SettingsPageProps) {
+  const inputClass = 'px-3 py-2 rounded-lg bg-background border border-border focus:ring-1 focus:ring-primary outline-none text-sm transition-all';
+
+  return (
+    <div className="p-4 rounded-lg bg-muted/30">
+      <div className="flex items-center justify-between mb-1">
+        <label className="text-sm font-medium block">Scheduled Backups</label>
+        <button
+          onClick={() => updateSettings('backupEnabled', !settings.backupEnabled)}
+          className={cn(
+            'px-3 py-1 rounded-full text-[10px] font-bold uppercase transition-all',
+            settings.backupEnabled
+              ? 'bg-primary/20 text-primary border border-primary/30'
+              : 'bg-muted text-muted-foreground border border-border'
+          )}
+        >
+          {settings.backupEnabled ? 'Enabled' : 'Disabled'}
+        </button>
+      </div>
+      <p className="text-xs text-muted-foreground mb-3">
+        Back up automatically and keep only the newest backups. Secrets are included when FORGEFLOW_BACKUP_PASSPHRASE is set.
+      </p>
+      {settings.backupEnabled && (
+        <div className="space-y-2">
+          <div className="flex gap-2 items-center text-xs text-muted-foreground">
+            Every
+            <input
+              type="number"
+              min={1}
+              value={settings.backupIntervalHours ?? 24}
+              onChange={(e) => updateSettings('backupIntervalHours', parseInt(e.target.value) || 24)}
+              className={cn(inputClass, 'w-20')}
+            />
+            hours, keep
+            <input
+              type="number"
+              min={1}
+              value={settings.backupKeep ?? 7}
+              onChange={(e) => updateSettings('backupKeep', parseInt(e.target.value) || 7)}
+              className={cn(inputClass, 'w-20')}
+            />
+            <label className="flex items-center gap-2 ml-2">
+              <input
+                type="checkbox"
+                checked={settings.backupExecutions ?? false}
+                onChange={(e) => updateSettings('backupExecutions', e.target.checked)}
+              />
+              With execution history
+            </label>
+          </div>
+          <input
+            type="text"
+            value={settings.backupDir ?? ''}
+            onChange={(e) => updateSettings('backupDir', e.target.value)}
+            placeholder="Backup folder (default: backups in the data directory)"
+            className={cn(inputClass, 'w-full')}
+          />
+        </div>
+      )}
+    </div>
+  );
+}
+
 function NotificationSettings({ settings, updateSettings }: SettingsPageProps) {
   return (
     <>
@@ -798,11 +1024,494 @@ function AdvancedSettings({ settings, updateSettings }: SettingsPageProps) {
             ))}
           </div>
         </div>
+
+        <div className="p-4 rounded-lg bg-muted/30">
+          <label className="text-sm font-medium mb-1 block">Metrics Address</label>
+          <p className="text-xs text-muted-foreground mb-3">
+            Serve Prometheus metrics on /metrics at this address, e.g. 127.0.0.1:9464 or :9464 for every interface ("off" to disable). Applies on restart.
+          </p>
+          <input
+            type="text"
+            value={settings.metricsAddress ?? ''}
+            placeholder="127.0.0.1:9464"
+            onChange={(e) => updateSettings('metricsAddress', e.target.value)}
+            className="w-48 px-3 py-2 rounded-lg bg-background border border-border focus:ring-1 focus:ring-primary outline-none text-sm transition-all"
+          />
+        </div>
+
+        <div className="p-4 rounded-lg bg-muted/30">
+          <label className="text-sm font-medium mb-1 block">Script Limits</label>
+          <p className="text-xs text-muted-foreground mb-3">
+            Time (ms) and memory (MB) allowed for each JavaScript node run
+          </p>
+          <div className="flex gap-2">
+            <input
+              type="number"
+              min={100}
+              value={settings.scriptTimeout ?? 5000}
+              onChange={(e) => updateSettings('scriptTimeout', parseInt(e.target.value) || 5000)}
+              className="w-32 px-3 py-2 rounded-lg bg-background border border-border focus:ring-1 focus:ring-primary outline-none text-sm transition-all"
+            />
+            <input
+              type="number"
+              min={1}
+              value={settings.scriptMemoryLimit ?? 64}
+              onChange={(e) => updateSettings('scriptMemoryLimit', parseInt(e.target.value) || 64)}
+              className="w-32 px-3 py-2 rounded-lg bg-background border border-border focus:ring-1 focus:ring-primary outline-none text-sm transition-all"
+            />
+          </div>
+        </div>
+
+        <div className="p-4 rounded-lg bg-muted/30">
+          <label className="text-sm font-medium mb-1 block">Trace Export</label>
+          <p className="text-xs text-muted-foreground mb-3">
+            Export background executions as traces. Applies on restart.
+          </p>
+          <div className="grid grid-cols-3 gap-2 mb-3">
+            {([['', 'Off'], ['otlp', 'OTLP/HTTP'], ['file', 'JSON Lines']] as const).map(([value, label]) => (
+              <button
+                key={value}
+                onClick={() => updateSettings('tracingExporter', value)}
+                className={cn(
+                  'px-3 py-2 rounded-lg border text-sm transition-all',
+                  (settings.tracingExporter ?? '') === value
+                    ? 'border-primary bg-primary/10 font-medium'
+                    : 'border-border hover:border-primary/50'
+                )}
+              >
+                {label}
+              </button>
+            ))}
+          </div>
+          {settings.tracingExporter === 'otlp' && (
+            <input
+              type="text"
+              value={settings.tracingEndpoint ?? ''}
+              onChange={(e) => updateSettings('tracingEndpoint', e.target.value)}
+              placeholder="http://localhost:4318"
+              className="w-full px-3 py-2 rounded-lg bg-background border border-border focus:ring-1 focus:ring-primary outline-none text-sm transition-all"
+            />
+          )}
+          {settings.tracingExporter === 'file' && (
+            <input
+              type="text"
+              value={settings.tracingFile ?? ''}
+              onChange={(e) => updateSettings('tracingFile', e.target.value)}
+              placeholder="traces.jsonl in the data directory"
+              className="w-full px-3 py-2 rounded-lg bg-background border border-border focus:ring-1 focus:ring-primary outline-none text-sm transition-all"
+            />
+          )}
+        </div>
+
+        <StorageBackendSetting />
+
+        <GitSyncSetting settings={settings} updateSettings={updateSettings} />
+
+        <SecretsVaultSetting />
+
+        <SecretProvidersSetting settings={settings} updateSettings={updateSettings} />
       </div>
     </>
   );
 }
 
+function StorageBackendSetting() {
+  const { confirm } = useDialogStore();
+  const [backend, setBackend] = useState('');
+  const [isMigrating, setIsMigrating] = useState(false);
+
+  useEffect(() => {
+    GetStorageBackend().then(setBackend).catch(() => setBackend(''));
+  }, []);
+
+  const migrate = (target: string) => {
+    confirm({
+      title: 'Change Storage Backend',
+      message: `Copy all flows, executions, settings and secrets to ${target === 'sqlite' ? 'SQLite' : 'JSON files'} and switch to it? The current data is kept as it is.`,
+      confirmText: 'Migrate',
+      cancelText: 'Cancel',
+      onConfirm: async () => {
+        setIsMigrating(true);
+        try {
+          const report = await MigrateStorage(target);
+          setBackend(report.to);
+          toast.success('Storage migrated', `${report.flows} flows, ${report.executions} executions and ${report.secrets} secrets copied`);
+        } catch (error) {
+          toast.error('Migration failed', error instanceof Error ? error.message : String(error));
+        } finally {
+          setIsMigrating(false);
+        }
+      },
+    });
+  };
+
+  return (
+    <div className="p-4 rounded-lg bg-muted/30">
+      <label className="text-sm font-medium mb-1 block">Storage Backend</label>
+      <p className="text-xs text-muted-foreground mb-3">
+        Where flows, executions, settings and secrets are kept. Switching copies everything to the new backend.
+      </p>
+      <div className="grid grid-cols-2 gap-2">
+        {([['json', 'JSON Files'], ['sqlite', 'SQLite']] as const).map(([value, label]) => (
+          <button
+            key={value}
+            disabled={isMigrating || !backend || backend === value}
+            onClick={() => migrate(value)}
+            className={cn(
+              'px-3 py-2 rounded-lg border text-sm transition-all disabled:cursor-default',
+              backend === value
+                ? 'border-primary bg-primary/10 font-medium'
+                : 'border-border hover:border-primary/50 disabled:opacity-50'
+            )}
+          >
+            {label}
+          </button>
+        ))}
+      </div>
+    </div>
+  );
+}
+
+function GitSyncSetting({ settings, updateSettings }: SettingsPageProps) {
+  const loadFlows = useFlowStore((state) => state.loadFlows);
+  const [busy, setBusy] = useState<'' | 'pull' | 'push'>('');
+
+  const pull = async () => {
+    setBusy('pull');
+    try {
+      const result = await GitPull();
+      if (result.updated) {
+        await loadFlows();
+        toast.success('Flows pulled', `${result.changedFlows.length} flows changed`);
+      } else {
+        toast.success('Flows pulled', 'Already up to date');
+      }
+    } catch (error) {
+      toast.error('Pull failed', error instanceof Error ? error.message : String(error));
+    } finally {
+      setBusy('');
+    }
+  };
+
+  const push = async () => {
+    setBusy('push');
+    try {
+      await GitPush();
+      toast.success('Flows pushed');
+    } catch (error) {
+      toast.error('Push failed', error instanceof Error ? error.message : String(error));
+    } finally {
+      setBusy('');
+    }
+  };
+
+  return (
+    <div className="p-4 rounded-lg bg-muted/30">
+      <div className="flex items-center justify-between mb-1">
+        <label className="text-sm font-medium block">Git Sync</label>
+        <button
+          onClick={() => updateSettings('gitEnabled', !settings.gitEnabled)}
+          className={cn(
+            'px-3 py-1 rounded-full text-[10px] font-bold uppercase transition-all',
+            settings.gitEnabled
+              ? 'bg-primary/20 text-primary border border-primary/30'
+              : 'bg-muted text-muted-foreground border border-border'
+          )}
+        >
+          {settings.gitEnabled ? 'Enabled' : 'Disabled'}
+        </button>
+      </div>
+      <p className="text-xs text-muted-foreground mb-3">
+        Keep the flows folder in a git repository and commit every save and delete. Needs JSON file storage and git on the PATH.
+      </p>
+      {settings.gitEnabled && (
+        <div className="space-y-2">
+          <div className="flex gap-2">
+            <input
+              type="text"
+              value={settings.gitRemote ?? ''}
+              onChange={(e) => updateSettings('gitRemote', e.target.value)}
+              placeholder="git@github.com:team/flows.git"
+              className="flex-1 px-3 py-2 rounded-lg bg-background border border-border focus:ring-1 focus:ring-primary outline-none text-sm transition-all"
+            />
+            <input
+              type="text"
+              value={settings.gitBranch ?? 'main'}
+              onChange={(e) => updateSettings('gitBranch', e.target.value)}
+              placeholder="main"
+              className="w-32 px-3 py-2 rounded-lg bg-background border border-border focus:ring-1 focus:ring-primary outline-none text-sm transition-all"
+            />
+          </div>
+          <div className="flex gap-2">
+            <button
+              onClick={pull}
+              disabled={busy !== '' || !settings.gitRemote}
+              className="px-3 py-2 rounded-lg border border-border bg-muted/30 hover:bg-muted text-[10px] font-bold uppercase transition-all disabled:opacity-50"
+            >
+              {busy === 'pull' ? '...' : 'Pull'}
+            </button>
+            <button
+              onClick={push}
+              disabled={busy !== '' || !settings.gitRemote}
+              className="px-3 py-2 rounded-lg border border-border bg-muted/30 hover:bg-muted text-[10px] font-bold uppercase transition-all disabled:opacity-50"
+            >
+              {busy === 'push' ? '...' : 'Push'}
+            </button>
+          </div>
+        </div>
+      )}
+    </div>
+  );
+}
+
+function SecretsVaultSetting() {
+  const { confirm } = useDialogStore();
+  const [status, setStatus] = useState<main.SecretsStatus | null>(null);
+  const [secrets, setSecrets] = useState<main.SecretInfo[]>([]);
+  const [passphrase, setPassphrase] = useState('');
+  const [busy, setBusy] = useState(false);
+
+  const refresh = async () => {
+    try {
+      setStatus(await GetSecretsStatus());
+      setSecrets((await ListSecrets()) || []);
+    } catch (error) {
+      console.error('Failed to load secrets:', error);
+    }
+  };
+
+  useEffect(() => {
+    refresh();
+  }, []);
+
+  const run = async (action: () => Promise<void>, success: string) => {
+    setBusy(true);
+    try {
+      await action();
+      setPassphrase('');
+      toast.success(success);
+    } catch (error) {
+      toast.error('Secrets', error instanceof Error ? error.message : String(error));
+    } finally {
+      setBusy(false);
+      refresh();
+    }
+  };
+
+  const rekey = () => {
+    confirm({
+      title: passphrase ? 'Set Master Passphrase' : 'Use a Key File',
+      message: passphrase
+        ? 'All secrets will be re-encrypted under the new passphrase. It is needed to unlock them in every new session and cannot be recovered.'
+        : 'All secrets will be re-encrypted under a new random key stored in the data directory.',
+      confirmText: 'Re-encrypt',
+      cancelText: 'Cancel',
+      onConfirm: () => run(() => RekeySecrets(passphrase), 'Secrets re-encrypted'),
+    });
+  };
+
+  const locked = status?.locked ?? false;
+
+  return (
+    <div className="p-4 rounded-lg bg-muted/30">
+      <div className="flex items-center justify-between mb-1">
+        <label className="text-sm font-medium block">Secrets Vault</label>
+        {status?.mode && (
+          <span className="px-3 py-1 rounded-full text-[10px] font-bold uppercase bg-muted text-muted-foreground border border-border">
+            {locked ? 'Locked' : status.mode === 'passphrase' ? 'Passphrase' : 'Key file'}
+          </span>
+        )}
+      </div>
+      <p className="text-xs text-muted-foreground mb-3">
+        Secrets are encrypted with AES-GCM. The master key lives in a key file in the data directory, or is derived from a passphrase you enter once per session.
+      </p>
+      <div className="space-y-2">
+        <div className="flex gap-2">
+          <input
+            type="password"
+            value={passphrase}
+            onChange={(e) => setPassphrase(e.target.value)}
+            placeholder={locked ? 'Master passphrase' : 'New passphrase (empty for a key file)'}
+            className="flex-1 px-3 py-2 rounded-lg bg-background border border-border focus:ring-1 focus:ring-primary outline-none text-sm transition-all"
+          />
+          {locked ? (
+            <button
+              onClick={() => run(() => UnlockSecrets(passphrase), 'Secrets unlocked')}
+              disabled={busy || !passphrase}
+              className="px-3 py-2 rounded-lg border border-border bg-muted/30 hover:bg-muted text-[10px] font-bold uppercase transition-all disabled:opacity-50"
+            >
+              Unlock
+            </button>
+          ) : (
+            <>
+              <button
+                onClick={rekey}
+                disabled={busy}
+                className="px-3 py-2 rounded-lg border border-border bg-muted/30 hover:bg-muted text-[10px] font-bold uppercase transition-all disabled:opacity-50"
+              >
+                Re-encrypt
+              </button>
+              {status?.mode === 'passphrase' && (
+                <button
+                  onClick={() => run(() => LockSecrets(), 'Secrets locked')}
+                  disabled={busy}
+                  className="px-3 py-2 rounded-lg border border-border bg-muted/30 hover:bg-muted text-[10px] font-bold uppercase transition-all disabled:opacity-50"
+                >
+                  Lock
+                </button>
+              )}
+            </>
+          )}
+        </div>
+        {secrets.length > 0 && (
+          <div className="divide-y divide-border rounded-lg border border-border">
+            {secrets.map((secret) => (
+              <div key={secret.name} className="flex items-center justify-between px-3 py-2 text-xs">
+                <div>
+                  <span className="font-mono">{secret.name}</span>
+                  {!secret.encrypted && <span className="ml-2 text-yellow-500">not encrypted</span>}
+                  {secret.updatedAt && (
+                    <span className="ml-2 text-muted-foreground">updated {new Date(secret.updatedAt).toLocaleDateString()}</span>
+                  )}
+                </div>
+                <button
+                  onClick={() => confirm({
+                    title: 'Delete Secret',
+                    message: `Delete the secret "${secret.name}"? Flows using it will fail.`,
+                    confirmText: 'Delete',
+                    cancelText: 'Cancel',
+                    variant: 'danger',
+                    onConfirm: () => run(() => DeleteSecret(secret.name), 'Secret deleted'),
+                  })}
+                  className="p-1 rounded hover:bg-destructive/10 text-muted-foreground hover:text-destructive transition-all"
+                >
+                  <Trash className="w-3.5 h-3.5" />
+                </button>
+              </div>
+            ))}
+          </div>
+        )}
+      </div>
+    </div>
+  );
+}
+
+function SecretProvidersSetting({ settings, updateSettings }: SettingsPageProps) {
+  const providers = settings.secretProviders || [];
+
+  const fields: Record<SecretProvider['type'], [keyof SecretProvider, string][]> = {
+    env: [['prefix', 'Variable prefix (required), e.g. FORGEFLOW_SECRET_']],
+    pass: [['dir', 'Store directory (default ~/.password-store)']],
+    command: [],
+    vault: [
+      ['address', 'http://127.0.0.1:8200'],
+      ['mount', 'KV mount (default secret)'],
+      ['namespace', 'Namespace (optional)'],
+      ['tokenSecret', 'Secret holding the token (default $VAULT_TOKEN)'],
+    ],
+  };
+
+  const addProvider = () => {
+    updateSettings('secretProviders', [...providers, { name: '', type: 'vault' }]);
+  };
+
+  const updateProvider = (index: number, changes: Partial<SecretProvider>) => {
+    updateSettings('secretProviders', providers.map((p, i) => (i === index ? { ...p, ...changes } : p)));
+  };
+
+  const removeProvider = (index: number) => {
+    updateSettings('secretProviders', providers.filter((_, i) => i !== index));
+  };
+
+  const inputClass = 'px-3 py-2 rounded-lg bg-background border border-border focus:ring-1 focus:ring-primary outline-none text-sm transition-all';
+
+  return (
+    <div className="p-4 rounded-lg bg-muted/30">
+      <div className="flex items-center justify-between mb-1">
+        <label className="text-sm font-medium block">Secret Providers</label>
+        <button
+          onClick={addProvider}
+          className="flex items-center gap-1 px-3 py-1 rounded-lg border border-border bg-muted/30 hover:bg-muted text-[10px] font-bold uppercase transition-all"
+        >
+          <Plus className="w-3 h-3" />
+          Add
+        </button>
+      </div>
+      <p className="text-xs text-muted-foreground mb-3">
+        Read secrets from external stores with {'{{secret.PROVIDER:NAME}}'}. Values are cached for the TTL.
+      </p>
+      <div className="space-y-3">
+        {providers.map((provider, index) => (
+          <div key={index} className="p-3 rounded-lg border border-border space-y-2">
+            <div className="flex gap-2">
+              <input
+                type="text"
+                value={provider.name}
+                onChange={(e) => updateProvider(index, { name: e.target.value.toLowerCase() })}
+                placeholder="name"
+                className={cn(inputClass, 'w-32 font-mono')}
+              />
+              <select
+                value={provider.type}
+                onChange={(e) => updateProvider(index, { type: e.target.value as SecretProvider['type'] })}
+                className={cn(inputClass, 'flex-1')}
+              >
+                <option value="vault">HashiCorp Vault KV</option>
+                <option value="pass">pass / gopass</option>
+                <option value="command">Command output</option>
+                <option value="env">Environment</option>
+              </select>
+              <input
+                type="number"
+                value={provider.ttl ?? 0}
+                onChange={(e) => updateProvider(index, { ttl: parseInt(e.target.value) || 0 })}
+                title="Seconds to cache values; 0 for 5 minutes, -1 to never cache"
+                className={cn(inputClass, 'w-20')}
+              />
+              <button
+                onClick={() => removeProvider(index)}
+                className="p-2 rounded hover:bg-destructive/10 text-muted-foreground hover:text-destructive transition-all"
+              >
+                <Trash className="w-3.5 h-3.5" />
+              </button>
+            </div>
+            {fields[provider.type].map(([field, placeholder]) => (
+              <input
+                key={field}
+                type="text"
+                value={(provider[field] as string) ?? ''}
+                onChange={(e) => updateProvider(index, { [field]: e.target.value })}
+                placeholder={placeholder}
+                className={cn(inputClass, 'w-full')}
+              />
+            ))}
+            {provider.type === 'command' && (
+              <textarea
+                value={(provider.args || []).join('\n')}
+                onChange={(e) => updateProvider(index, { args: e.target.value.split('\n') })}
+                onBlur={() => updateProvider(index, { args: (provider.args || []).filter((arg) => arg !== '') })}
+                placeholder={'One argument per line, e.g.\nop\nread\nop://team/{{name}}'}
+                rows={3}
+                className={cn(inputClass, 'w-full font-mono resize-y')}
+              />
+            )}
+            {provider.type === 'vault' && (
+              <select
+                value={provider.kvVersion ?? 2}
+                onChange={(e) => updateProvider(index, { kvVersion: parseInt(e.target.value) })}
+                className={cn(inputClass, 'w-full')}
+              >
+                <option value={2}>KV version 2</option>
+                <option value={1}>KV version 1</option>
+              </select>
+            )}
+          </div>
+        ))}
+      </div>
+    </div>
+  );
+}
+
 function VariablesSettings({ settings, updateSettings }: SettingsPageProps) {
   const [showValues, setShowValues] = useState<Record<string, boolean>>({});
 
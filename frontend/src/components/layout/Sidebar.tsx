@@ -2,11 +2,13 @@ import { useState, useMemo } from "react";
 import {
   FileText, Clock, Webhook, Play, Monitor, FileIcon, Globe, Terminal, Bell,
   Download, Brain, Sparkles, Tag, Search as SearchIcon, PenTool, Wand2,
-  MessageSquare, GitBranch, ChevronRight, Plus, Settings2, Trash2
+  MessageSquare, GitBranch, ChevronRight, Plus, Settings2, Trash2, RefreshCw
 } from "lucide-react";
 import { cn } from "@/lib/utils";
 import { useFlowStore } from "@/stores/flowStore";
 import { useCustomNodeStore, type CustomNodeDefinition } from "@/stores/customNodeStore";
+import { usePluginStore, type PluginNodeDefinition } from "@/stores/pluginStore";
+import { toast } from "@/stores/dialogStore";
 import type { NodeData, NodeCategory } from "@/types/flow";
 import { nodeDefinitions, getNodesByCategory } from "@/nodes";
 
@@ -144,7 +146,10 @@ function CustomNodeItem({ node }: { node: CustomNodeDefinition }) {
           <Settings2 className="w-3 h-3 text-muted-foreground" />
         </button>
         <button
-          onClick={(e) => { e.stopPropagation(); deleteCustomNode(node.type); }}
+          onClick={(e) => {
+            e.stopPropagation();
+            deleteCustomNode(node.type).catch((err) => toast.error("Delete failed", String(err)));
+          }}
           className="p-1 hover:bg-destructive/20 rounded"
           title="Delete"
         >
@@ -155,12 +160,58 @@ function CustomNodeItem({ node }: { node: CustomNodeDefinition }) {
   );
 }
 
+function PluginNodeItem({ node }: { node: PluginNodeDefinition }) {
+  const { addNodeAtCenter } = useFlowStore();
+
+  const data: NodeData = {
+    label: node.name,
+    category: node.category,
+    icon: node.icon,
+    description: node.description,
+    status: "idle",
+    nodeType: node.type,
+    config: node.defaultData,
+  };
+
+  const onDragStart = (e: React.DragEvent) => {
+    e.dataTransfer.setData("application/forgeflow-node", JSON.stringify(data));
+    e.dataTransfer.effectAllowed = "move";
+  };
+
+  return (
+    <div
+      draggable
+      onDragStart={onDragStart}
+      onClick={() => addNodeAtCenter(data)}
+      title={`${node.description} (${node.plugin})`}
+      className="group flex items-center gap-2 px-2 py-1.5 rounded-lg border border-transparent cursor-grab active:cursor-grabbing transition-all duration-150 hover:scale-[1.02] hover:border-border/50 bg-teal-500/10 hover:bg-teal-500/20"
+    >
+      <div className="w-6 h-6 flex items-center justify-center rounded-md text-teal-500">
+        <span className="text-sm">{node.icon}</span>
+      </div>
+      <div className="flex-1 overflow-hidden">
+        <span className="block text-[12px] font-medium truncate">{node.name}</span>
+        <span className="block text-[9px] text-muted-foreground truncate">{node.description}</span>
+      </div>
+    </div>
+  );
+}
+
 export default function Sidebar() {
   const { sidebarCollapsed } = useFlowStore();
   const { customNodes, setBuilderOpen } = useCustomNodeStore();
+  const { pluginNodes, reloadPlugins } = usePluginStore();
   const [expandedCategories, setExpandedCategories] = useState<Set<string>>(new Set(['Triggers']));
   const [searchQuery, setSearchQuery] = useState('');
 
+  const handleReloadPlugins = async () => {
+    try {
+      await reloadPlugins();
+    } catch (error) {
+      toast.error("Failed to reload plugins", error instanceof Error ? error.message : String(error));
+    }
+  };
+
   const toggleCategory = (name: string) => {
     setExpandedCategories(prev => {
       const next = new Set(prev);
@@ -246,6 +297,29 @@ export default function Sidebar() {
         )}
       </div>
 
+      {/* Plugin Nodes Section */}
+        <div className="border-t border-border/50 pt-1">
+          <div className="flex items-center justify-between px-1.5 py-1">
+            <span className="text-[11px] font-semibold text-teal-500">Plugins</span>
+            <button
+              onClick={handleReloadPlugins}
+              className="p-1 hover:bg-muted rounded-md transition-colors"
+              title="Reload plugins"
+            >
+              <RefreshCw className="w-3 h-3 text-teal-500" />
+            </button>
+          </div>
+          {pluginNodes.length === 0 ? (
+            <div className="px-2 pb-1.5 text-[10px] text-muted-foreground">No plugins installed</div>
+          ) : (
+            <div className="px-1.5 pb-1.5 space-y-1 max-h-48 overflow-y-auto">
+              {pluginNodes.map((node) => (
+                <PluginNodeItem key={node.type} node={node} />
+              ))}
+            </div>
+          )}
+        </div>
+
       {/* Custom Nodes Section */}
         <div className="border-t border-border/50 pt-1">
           <div className="flex items-center justify-between px-1.5 py-1">
@@ -1,4 +1,4 @@
-import { useState } from 'react';
+import { useEffect, useState } from 'react';
 import {
   X,
   Plus,
@@ -17,6 +17,8 @@ import { useWorkflowStore } from '@/stores/workflowStore';
 import { useDialogStore } from '@/stores/dialogStore';
 import { useContextMenu, useDialog } from '@/hooks';
 import type { Flow } from '@/types/flow';
+import { SearchFlows } from '../../../wailsjs/go/main/Storage';
+import type { main } from '../../../wailsjs/go/models';
 
 export default function WorkflowsPanel() {
   const { workflowPanelOpen, setWorkflowPanelOpen, setTemplateModalOpen } = useWorkflowStore();
@@ -26,13 +28,43 @@ export default function WorkflowsPanel() {
   const { showDialog } = useDialog();
   const [searchQuery, setSearchQuery] = useState('');
   const [hoveredFlowId, setHoveredFlowId] = useState<string | null>(null);
+  const [searchResults, setSearchResults] = useState<Record<string, main.FlowSearchResult> | null>(null);
+
+  // Search node labels, types and config in the backend, not just names
+  useEffect(() => {
+    const query = searchQuery.trim();
+    if (!query) {
+      setSearchResults(null);
+      return;
+    }
+    let cancelled = false;
+    const timer = setTimeout(() => {
+      SearchFlows(query)
+        .then((results) => {
+          if (!cancelled) {
+            setSearchResults(Object.fromEntries((results || []).map((r) => [r.flowId, r])));
+          }
+        })
+        .catch(() => {
+          if (!cancelled) setSearchResults(null);
+        });
+    }, 200);
+    return () => {
+      cancelled = true;
+      clearTimeout(timer);
+    };
+  }, [searchQuery]);
 
   if (!workflowPanelOpen) return null;
 
-  const filteredFlows = flows.filter(flow => 
-    flow.name.toLowerCase().includes(searchQuery.toLowerCase()) ||
-    (flow.description?.toLowerCase().includes(searchQuery.toLowerCase()))
-  );
+  const filteredFlows = searchResults
+    ? flows
+        .filter(flow => searchResults[flow.id])
+        .sort((a, b) => searchResults[b.id].score - searchResults[a.id].score)
+    : flows.filter(flow =>
+        flow.name.toLowerCase().includes(searchQuery.toLowerCase()) ||
+        (flow.description?.toLowerCase().includes(searchQuery.toLowerCase()))
+      );
 
   const handleNewWorkflow = () => {
     clearCanvas();
@@ -269,6 +301,7 @@ export default function WorkflowsPanel() {
                       <span>•</span>
                       <span>{new Date(flow.updatedAt).toLocaleDateString()}</span>
                     </div>
+                    {searchResults?.[flow.id] && <SearchMatchPreview result={searchResults[flow.id]} />}
                   </div>
 
                   {(hoveredFlowId === flow.id || activeFlowId === flow.id) && (
@@ -298,3 +331,24 @@ export default function WorkflowsPanel() {
     </div>
   );
 }
+
+function SearchMatchPreview({ result }: { result: main.FlowSearchResult }) {
+  const match = result.matches.find((m) => m.nodeId) || result.matches[0];
+  if (!match) return null;
+  const more = result.nodeIds.length > 1 ? ` +${result.nodeIds.length - 1} nodes` : '';
+
+  return (
+    <div className="mt-1 text-[10px] text-muted-foreground truncate">
+      {match.nodeId && <span className="font-medium">{match.label || match.nodeType} · </span>}
+      <span className="opacity-70">{match.field}: </span>
+      {match.snippet.map((part, i) =>
+        part.match ? (
+          <mark key={i} className="bg-primary/20 text-foreground rounded-sm">{part.text}</mark>
+        ) : (
+          <span key={i}>{part.text}</span>
+        )
+      )}
+      {more}
+    </div>
+  );
+}
@@ -1,10 +1,12 @@
 // Workflow execution engine with variable interpolation
 import type { FlowNode, FlowEdge } from '@/types/flow';
 import { getHandler } from '@/handlers';
-import type { LogCallback, HandlerContext } from '@/handlers/types';
+import type { LogCallback, LogLevel, HandlerContext } from '@/handlers/types';
 import { useSettingsStore } from '@/stores/settingsStore';
 import { useDialogStore } from '@/stores/dialogStore';
 import { useCustomNodeStore } from '@/stores/customNodeStore';
+import { usePluginStore } from '@/stores/pluginStore';
+import { RunPluginNode, RunEditorNode } from '../../wailsjs/go/main/Engine';
 import { executeCustomNode } from '@/handlers/custom';
 
 export interface NodeResult {
@@ -16,6 +18,22 @@ export interface NodeResult {
   error?: string;
 }
 
+// newTraceparent starts a W3C trace context for a run, so the HTTP requests
+// it makes can be traced together
+function newTraceparent(): string {
+  const hex = (bytes: number) =>
+    Array.from(crypto.getRandomValues(new Uint8Array(bytes)), (b) => b.toString(16).padStart(2, '0')).join('');
+  return `00-${hex(16)}-${hex(8)}-01`;
+}
+
+// hasSecretRefs reports whether a node config references a secret with
+// {{secret.NAME}}, which only the engine resolves
+function hasSecretRefs(value: any): boolean {
+  if (typeof value === 'string') return /\{\{\s*secret\./.test(value);
+  if (value && typeof value === 'object') return Object.values(value).some(hasSecretRefs);
+  return false;
+}
+
 export class WorkflowExecutor {
   private nodes: FlowNode[];
   private edges: FlowEdge[];
@@ -24,13 +42,17 @@ export class WorkflowExecutor {
   private onProgress: (results: NodeResult[]) => void;
   private onLog: LogCallback;
   private isAborted: boolean = false;
+  private traceparent: string = newTraceparent();
+  private executionId: string;
 
   constructor(
     nodes: FlowNode[],
     edges: FlowEdge[],
     onProgress: (results: NodeResult[]) => void,
-    onLog: LogCallback = () => {}
+    onLog: LogCallback = () => {},
+    executionId: string = crypto.randomUUID()
   ) {
+    this.executionId = executionId;
     this.nodes = nodes;
     this.edges = edges;
     this.onProgress = onProgress;
@@ -414,20 +436,33 @@ export class WorkflowExecutor {
       onLog: this.onLog,
       nodeId: node.id,
       settings: useSettingsStore.getState().settings,
+      traceparent: this.traceparent,
       api: {
         http: {
           get: async (url: string, headers: Record<string, string> = {}) => {
             const { HTTPRequest } = await import('../../wailsjs/go/main/ActionService');
-            return HTTPRequest('GET', url, headers, '');
+            return HTTPRequest('GET', url, { traceparent: this.traceparent, ...headers }, '');
           },
           post: async (url: string, body: any, headers: Record<string, string> = {}) => {
             const { HTTPRequest } = await import('../../wailsjs/go/main/ActionService');
-            return HTTPRequest('POST', url, headers, typeof body === 'string' ? body : JSON.stringify(body));
+            return HTTPRequest('POST', url, { traceparent: this.traceparent, ...headers }, typeof body === 'string' ? body : JSON.stringify(body));
           },
         }
       }
     };
 
+    // Nodes referencing secrets run in the engine, the only place their
+    // values are resolved; the output comes back with them masked
+    if (hasSecretRefs(data) && !usePluginStore.getState().getPluginNode(nodeType)) {
+      this.onLog('info', `🔒 Running in the engine: it uses secrets`, node.id);
+      const result = await RunEditorNode(this.executionId, this.traceparent, nodeType, data, this.variables['output']);
+      for (const line of result.logs || []) {
+        const level = line.level === 'debug' ? 'info' : line.level;
+        this.onLog(level as LogLevel, `   ${line.message}`, node.id);
+      }
+      return result.output;
+    }
+
     // Check for custom node first
     const customNodes = useCustomNodeStore.getState().customNodes;
     const customNode = customNodes.find(n => n.type === nodeType);
@@ -436,6 +471,18 @@ export class WorkflowExecutor {
       return executeCustomNode(customNode, ctx);
     }
 
+    // Plugin nodes run in the engine
+    const pluginNode = usePluginStore.getState().getPluginNode(nodeType);
+    if (pluginNode) {
+      this.onLog('info', `🧩 Plugin: ${pluginNode.plugin}`, node.id);
+      const result = await RunPluginNode(nodeType, data, this.variables['output']);
+      for (const line of result.logs || []) {
+        const level = line.level === 'debug' ? 'info' : line.level;
+        this.onLog(level as LogLevel, `   ${line.message}`, node.id);
+      }
+      return result.output;
+    }
+
     // Get built-in handler
     const handler = getHandler(nodeType);
     
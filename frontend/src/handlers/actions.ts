@@ -2,7 +2,7 @@ import type { HandlerContext } from './types';
 import * as ActionService from '../../wailsjs/go/main/ActionService';
 
 export const actionHandlers: Record<string, (ctx: HandlerContext) => Promise<any>> = {
-  action_http: async ({ data, onLog }) => {
+  action_http: async ({ data, onLog, traceparent }) => {
     const { method, url, headers, body } = data;
     
     onLog('info', `🌐 HTTP ${method} → ${url}`);
@@ -19,7 +19,7 @@ export const actionHandlers: Record<string, (ctx: HandlerContext) => Promise<any
       }
 
       // Use backend HTTP service
-      const response = await ActionService.HTTPRequest(method, url, parsedHeaders, body || '');
+      const response = await ActionService.HTTPRequest(method, url, { traceparent, ...parsedHeaders }, body || '');
       
       onLog('success', `✓ Status: ${response.status} ${response.statusText || ''}`);
       
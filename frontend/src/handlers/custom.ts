@@ -1,5 +1,6 @@
-import type { HandlerContext } from './types';
+import type { HandlerContext, LogLevel } from './types';
 import * as ActionService from '../../wailsjs/go/main/ActionService';
+import { RunScript } from '../../wailsjs/go/main/Engine';
 import type { CustomNodeDefinition } from '@/stores/customNodeStore';
 
 // Replace {{variable}} placeholders with actual values
@@ -12,41 +13,11 @@ function interpolate(template: string, variables: Record<string, any>): string {
   });
 }
 
-// Safe script execution with limited globals
-function executeScript(script: string, input: Record<string, any>): any {
-  const safeGlobals = {
-    JSON,
-    Math,
-    Date,
-    String,
-    Array,
-    Object,
-    Number,
-    Boolean,
-    parseInt,
-    parseFloat,
-    isNaN,
-    isFinite,
-    encodeURIComponent,
-    decodeURIComponent,
-    encodeURI,
-    decodeURI,
-  };
-
-  const fn = new Function(
-    ...Object.keys(safeGlobals),
-    'input',
-    `"use strict"; ${script}`
-  );
-
-  return fn(...Object.values(safeGlobals), input);
-}
-
 export async function executeCustomNode(
   nodeDef: CustomNodeDefinition,
   ctx: HandlerContext
 ): Promise<any> {
-  const { data, onLog, variables } = ctx;
+  const { data, onLog, variables, traceparent } = ctx;
   
   // Merge config data with previous output
   const input: Record<string, any> = {
@@ -107,7 +78,7 @@ export async function executeCustomNode(
 
         onLog('info', `   🌐 ${method} ${url}`);
 
-        const response = await ActionService.HTTPRequest(method, url, headers, body);
+        const response = await ActionService.HTTPRequest(method, url, { traceparent, ...headers }, body);
 
         onLog('success', `✓ Status: ${response.status}`);
 
@@ -119,11 +90,16 @@ export async function executeCustomNode(
 
         onLog('info', `   📜 Executing script...`);
 
-        const result = executeScript(script, input);
+        // Runs in the engine's sandboxed JavaScript runtime
+        const result = await RunScript(script, input);
+        for (const line of result.console || []) {
+          const level = line.level === 'debug' ? 'info' : line.level;
+          onLog(level as LogLevel, `   ${line.message}`);
+        }
 
         onLog('success', `✓ Script completed`);
 
-        return result;
+        return result.output;
       }
 
       default:
@@ -11,6 +11,7 @@ export interface HandlerContext {
   nodeId: string;                      // Current node ID
   api: any;                            // External API access
   settings: AppSettings;               // Global application settings
+  traceparent: string;                 // W3C trace context of the run, sent with HTTP requests
 }
 
 export type NodeHandler = (ctx: HandlerContext) => Promise<any>;
@@ -1,6 +1,6 @@
 import { create } from 'zustand';
-import { persist } from 'zustand/middleware';
 import type { NodeDefinition } from '@/nodes/types';
+import { DeleteCustomNode, ImportCustomNodes, ListCustomNodes, SaveCustomNode } from '../../wailsjs/go/main/Storage';
 
 export type CustomNodeActionType = 'shell' | 'http' | 'script';
 
@@ -20,6 +20,7 @@ export interface CustomNodeDefinition extends NodeDefinition {
     // For scripts (JavaScript)
     script?: string;
   };
+  version?: number;
   createdAt: string;
   updatedAt: string;
 }
@@ -31,68 +32,84 @@ interface CustomNodeState {
   
   setBuilderOpen: (open: boolean) => void;
   setEditingNode: (node: CustomNodeDefinition | null) => void;
-  addCustomNode: (node: Omit<CustomNodeDefinition, 'createdAt' | 'updatedAt' | 'isCustom'>) => void;
-  updateCustomNode: (type: string, updates: Partial<CustomNodeDefinition>) => void;
-  deleteCustomNode: (type: string) => void;
-  duplicateCustomNode: (type: string) => void;
+  loadCustomNodes: () => Promise<void>;
+  addCustomNode: (node: Omit<CustomNodeDefinition, 'createdAt' | 'updatedAt' | 'isCustom'>) => Promise<void>;
+  updateCustomNode: (type: string, updates: Partial<CustomNodeDefinition>) => Promise<void>;
+  deleteCustomNode: (type: string) => Promise<void>;
+  duplicateCustomNode: (type: string) => Promise<void>;
 }
 
-export const useCustomNodeStore = create<CustomNodeState>()(
-  persist(
-    (set, get) => ({
-      customNodes: [],
-      builderOpen: false,
-      editingNode: null,
+// Custom nodes used to be kept in localStorage only
+const LEGACY_STORAGE_KEY = 'forgeflow-custom-nodes';
 
-      setBuilderOpen: (open) => set({ builderOpen: open, editingNode: open ? get().editingNode : null }),
-      
-      setEditingNode: (node) => set({ editingNode: node, builderOpen: !!node }),
+async function migrateLegacyCustomNodes(): Promise<void> {
+  const legacy = localStorage.getItem(LEGACY_STORAGE_KEY);
+  if (!legacy) return;
+  const nodes = JSON.parse(legacy)?.state?.customNodes;
+  if (Array.isArray(nodes) && nodes.length > 0) {
+    await ImportCustomNodes(JSON.stringify(nodes));
+  }
+  localStorage.removeItem(LEGACY_STORAGE_KEY);
+}
+
+// Definitions are stored by the backend, which adds version and timestamps
+async function saveDefinition(node: Omit<CustomNodeDefinition, 'createdAt' | 'updatedAt' | 'isCustom'>): Promise<CustomNodeDefinition> {
+  const saved = await SaveCustomNode(JSON.stringify({ ...node, isCustom: true }));
+  return { ...saved, isCustom: true } as CustomNodeDefinition;
+}
+
+export const useCustomNodeStore = create<CustomNodeState>()((set, get) => ({
+  customNodes: [],
+  builderOpen: false,
+  editingNode: null,
+
+  setBuilderOpen: (open) => set({ builderOpen: open, editingNode: open ? get().editingNode : null }),
+  
+  setEditingNode: (node) => set({ editingNode: node, builderOpen: !!node }),
+
+  loadCustomNodes: async () => {
+    try {
+      await migrateLegacyCustomNodes();
+    } catch (error) {
+      console.error('Failed to migrate custom nodes:', error);
+    }
+    try {
+      const nodes = (await ListCustomNodes()) || [];
+      set({ customNodes: nodes.map((n) => ({ ...n, isCustom: true }) as CustomNodeDefinition) });
+    } catch (error) {
+      console.error('Failed to load custom nodes:', error);
+    }
+  },
 
-      addCustomNode: (node) => {
-        const now = new Date().toISOString();
-        const customNode: CustomNodeDefinition = {
-          ...node,
-          isCustom: true,
-          createdAt: now,
-          updatedAt: now,
-        };
-        set({ customNodes: [...get().customNodes, customNode] });
-      },
+  addCustomNode: async (node) => {
+    const saved = await saveDefinition(node);
+    set({ customNodes: [...get().customNodes, saved] });
+  },
 
-      updateCustomNode: (type, updates) => {
-        set({
-          customNodes: get().customNodes.map((n) =>
-            n.type === type
-              ? { ...n, ...updates, updatedAt: new Date().toISOString() }
-              : n
-          ),
-        });
-      },
+  updateCustomNode: async (type, updates) => {
+    const node = get().customNodes.find((n) => n.type === type);
+    if (!node) return;
+    const saved = await saveDefinition({ ...node, ...updates, type });
+    set({ customNodes: get().customNodes.map((n) => (n.type === type ? saved : n)) });
+  },
 
-      deleteCustomNode: (type) => {
-        set({ customNodes: get().customNodes.filter((n) => n.type !== type) });
-      },
+  deleteCustomNode: async (type) => {
+    await DeleteCustomNode(type);
+    set({ customNodes: get().customNodes.filter((n) => n.type !== type) });
+  },
 
-      duplicateCustomNode: (type) => {
-        const node = get().customNodes.find((n) => n.type === type);
-        if (node) {
-          const now = new Date().toISOString();
-          const newNode: CustomNodeDefinition = {
-            ...node,
-            type: `${node.type}_copy_${Date.now()}`,
-            name: `${node.name} (Copy)`,
-            createdAt: now,
-            updatedAt: now,
-          };
-          set({ customNodes: [...get().customNodes, newNode] });
-        }
-      },
-    }),
-    {
-      name: 'forgeflow-custom-nodes',
+  duplicateCustomNode: async (type) => {
+    const node = get().customNodes.find((n) => n.type === type);
+    if (node) {
+      const saved = await saveDefinition({
+        ...node,
+        type: `${node.type}_copy_${Date.now()}`,
+        name: `${node.name} (Copy)`,
+      });
+      set({ customNodes: [...get().customNodes, saved] });
     }
-  )
-);
+  },
+}));
 
 // Helper to get all nodes including custom ones
 export function getAllNodeDefinitions(
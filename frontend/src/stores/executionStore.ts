@@ -1,14 +1,20 @@
 import { create } from "zustand";
 import type { FlowExecution } from "@/types/flow";
-import { ListExecutions, DeleteExecution, SaveExecution } from "../../wailsjs/go/main/Storage";
+import { QueryExecutions, DeleteExecution, SaveExecution } from "../../wailsjs/go/main/Storage";
 import { toast } from "@/stores/dialogStore";
 
 interface ExecutionState {
   executions: FlowExecution[];
   selectedExecution: FlowExecution | null;
   isLoading: boolean;
+  statusFilter: string;
+  statusCounts: Record<string, number>;
+  total: number;
+  nextCursor: string;
   
   loadExecutions: () => Promise<void>;
+  loadMoreExecutions: () => Promise<void>;
+  setStatusFilter: (status: string) => void;
   addExecution: (execution: FlowExecution) => Promise<void>;
   deleteExecution: (execId: string) => Promise<void>;
   clearExecutions: () => Promise<void>;
@@ -19,33 +25,50 @@ export const useExecutionStore = create<ExecutionState>()((set, get) => ({
   executions: [],
   selectedExecution: null,
   isLoading: false,
+  statusFilter: "",
+  statusCounts: {},
+  total: 0,
+  nextCursor: "",
 
   loadExecutions: async () => {
     set({ isLoading: true });
     try {
-      const executions = await ListExecutions(100);
-      
-      // Handle null or undefined response
-      if (!executions || !Array.isArray(executions)) {
-        set({ executions: [], isLoading: false });
-        return;
-      }
-      
-      // Backend now provides nodeCount, successCount, errorCount
-      const processedExecutions = executions.map((exec: any) => ({
-        ...exec,
-        nodeCount: exec.nodeCount || 0,
-        successCount: exec.successCount || 0,
-        errorCount: exec.errorCount || 0,
-      } as FlowExecution));
-      
-      set({ executions: processedExecutions, isLoading: false });
+      const page = await QueryExecutions({ status: get().statusFilter, limit: 100 } as any);
+      set({
+        executions: toExecutions(page?.executions),
+        statusCounts: page?.statusCounts || {},
+        total: page?.total || 0,
+        nextCursor: page?.nextCursor || "",
+        isLoading: false,
+      });
     } catch (error) {
       console.error("Failed to load executions:", error);
-      set({ executions: [], isLoading: false });
+      set({ executions: [], statusCounts: {}, total: 0, nextCursor: "", isLoading: false });
     }
   },
 
+  loadMoreExecutions: async () => {
+    const { nextCursor, statusFilter, executions } = get();
+    if (!nextCursor) return;
+    try {
+      const page = await QueryExecutions({ status: statusFilter, cursor: nextCursor, limit: 100 } as any);
+      set({
+        executions: [...executions, ...toExecutions(page?.executions)],
+        statusCounts: page?.statusCounts || {},
+        total: page?.total || 0,
+        nextCursor: page?.nextCursor || "",
+      });
+    } catch (error) {
+      console.error("Failed to load executions:", error);
+      toast.error("Failed to load executions");
+    }
+  },
+
+  setStatusFilter: (status) => {
+    set({ statusFilter: status, selectedExecution: null });
+    get().loadExecutions();
+  },
+
   addExecution: async (execution: FlowExecution) => {
     try {
       await SaveExecution(JSON.stringify(execution));
@@ -59,7 +82,8 @@ export const useExecutionStore = create<ExecutionState>()((set, get) => ({
   deleteExecution: async (execId: string) => {
     try {
       await DeleteExecution(execId);
-      set({ executions: get().executions.filter(e => e.id !== execId) });
+      const { executions, total } = get();
+      set({ executions: executions.filter(e => e.id !== execId), total: Math.max(0, total - 1) });
       toast.success("Execution deleted");
     } catch (error) {
       console.error("Failed to delete execution:", error);
@@ -81,3 +105,13 @@ export const useExecutionStore = create<ExecutionState>()((set, get) => ({
 
   setSelectedExecution: (execution) => set({ selectedExecution: execution }),
 }));
+
+// Summaries come without node results
+function toExecutions(summaries: any[] | undefined): FlowExecution[] {
+  return (summaries || []).map((exec: any) => ({
+    ...exec,
+    nodeCount: exec.nodeCount || 0,
+    successCount: exec.successCount || 0,
+    errorCount: exec.errorCount || 0,
+  } as FlowExecution));
+}
@@ -2,8 +2,9 @@ import { create } from "zustand";
 import type { OnNodesChange, OnEdgesChange, OnConnect } from "@xyflow/react";
 import { applyNodeChanges, applyEdgeChanges, addEdge } from "@xyflow/react";
 import type { NodeData, FlowNode, FlowEdge, Flow } from "@/types/flow";
-import { RunFlow, StopExecution } from "../../wailsjs/go/main/Engine";
-import { SaveFlow, LoadFlow, ListFlows, DeleteFlow, SaveExecution } from "../../wailsjs/go/main/Storage";
+import { StopExecution, ValidateFlow } from "../../wailsjs/go/main/Engine";
+import { SaveFlow, LoadFlow, ListFlows, DeleteFlow, SaveExecution, AppendExecutionLogs } from "../../wailsjs/go/main/Storage";
+import type { main } from "../../wailsjs/go/models";
 import { WorkflowExecutor } from "@/executor/WorkflowExecutor";
 import type { NodeResult } from "@/executor/WorkflowExecutor";
 import type { LogLevel } from "@/handlers/types";
@@ -435,6 +436,22 @@ export const useFlowStore = create<FlowState>()((set, get) => ({
 
       runFlow: async () => {
         const { nodes, edges, updateNodeData, addLog, activeFlowId, flows } = get();
+
+        // Refuse to start when a node can't run: an unknown type (e.g. a
+        // deleted custom node) or a config missing required fields
+        const problems = await ValidateFlow(JSON.stringify({
+          nodes: nodes.map((n) => ({
+            id: n.id,
+            data: { label: n.data.label, nodeType: n.data.nodeType, config: n.data.config },
+          })),
+          edges: [],
+        }));
+        if (problems.length > 0) {
+          problems.forEach((problem) => addLog(`❌ ${problem}`));
+          toast.error('Flow cannot run', problems[0]);
+          return;
+        }
+
         const executionId = crypto.randomUUID();
         set({ isRunning: true, executionId });
 
@@ -497,8 +514,10 @@ export const useFlowStore = create<FlowState>()((set, get) => ({
           });
         };
 
-        // Create log callback
-        const onLog = (level: LogLevel, message: string) => {
+        // Create log callback; the entries are saved with the execution
+        const logEntries: main.LogEntry[] = [];
+        const onLog = (level: LogLevel, message: string, nodeId?: string) => {
+          logEntries.push({ timestamp: new Date().toISOString(), level, nodeId, message } as main.LogEntry);
           const emoji = {
             info: '',
             success: '',
@@ -508,7 +527,7 @@ export const useFlowStore = create<FlowState>()((set, get) => ({
           addLog(`${emoji[level] || ''} ${message}`);
         };
 
-        const executor = new WorkflowExecutor(nodes, edges, onProgress, onLog);
+        const executor = new WorkflowExecutor(nodes, edges, onProgress, onLog, executionId);
         set({ isRunning: true, executionId, executor });
         const startedAt = new Date().toISOString();
         let finalStatus: "success" | "error" = "success";
@@ -519,24 +538,6 @@ export const useFlowStore = create<FlowState>()((set, get) => ({
           if (get().isRunning) {
             addLog(`🎉 Flow execution completed successfully`);
           }
-
-          // Call backend for persistence/tracking
-          const flowData = {
-            id: executionId,
-            nodes: nodes.map((n) => ({
-              id: n.id,
-              type: n.type,
-              category: n.data.category,
-              label: n.data.label,
-              position: n.position,
-            })),
-            edges: edges.map((e) => ({
-              id: e.id,
-              source: e.source,
-              target: e.target,
-            })),
-          };
-          await RunFlow(JSON.stringify(flowData));
         } catch (error) {
           finalStatus = "error";
           addLog(`💥 Flow execution failed: ${error}`);
@@ -559,6 +560,12 @@ export const useFlowStore = create<FlowState>()((set, get) => ({
           } catch (error) {
             console.error("Failed to save execution history:", error);
           }
+          // Saving the logs also ends the run in the engine
+          try {
+            await AppendExecutionLogs(executionId, logEntries);
+          } catch (error) {
+            console.error("Failed to save execution logs:", error);
+          }
           
           set({ isRunning: false, executionId: null, executor: null });
         }
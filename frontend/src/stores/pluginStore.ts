@@ -0,0 +1,61 @@
+import { create } from 'zustand';
+import type { NodeCategory, NodeDefinition, NodeField } from '@/nodes/types';
+import { GetPlugins, ReloadPlugins } from '../../wailsjs/go/main/Engine';
+import type { main } from '../../wailsjs/go/models';
+
+export interface PluginNodeDefinition extends NodeDefinition {
+  plugin: string;
+}
+
+interface PluginState {
+  plugins: main.PluginInfo[];
+  pluginNodes: PluginNodeDefinition[];
+
+  loadPlugins: () => Promise<void>;
+  reloadPlugins: () => Promise<void>;
+  getPluginNode: (type: string) => PluginNodeDefinition | undefined;
+}
+
+// Plugin manifests describe nodes loosely; fill in what the UI needs
+function toNodeDefinition(node: main.PluginNode): PluginNodeDefinition {
+  return {
+    type: node.type,
+    category: (node.category || 'utility') as NodeCategory,
+    name: node.name || node.type,
+    icon: node.icon || '🧩',
+    color: '#14b8a6',
+    description: node.description || '',
+    inputs: [{ id: 'in', type: 'input' }],
+    outputs: [{ id: 'out', type: 'output', label: 'Output' }],
+    defaultData: node.defaultData || {},
+    fields: (node.fields || []) as NodeField[],
+    plugin: node.plugin,
+  };
+}
+
+function collectNodes(plugins: main.PluginInfo[]): PluginNodeDefinition[] {
+  return plugins
+    .filter((p) => !p.error)
+    .flatMap((p) => (p.nodes || []).map(toNodeDefinition));
+}
+
+export const usePluginStore = create<PluginState>()((set, get) => ({
+  plugins: [],
+  pluginNodes: [],
+
+  loadPlugins: async () => {
+    try {
+      const plugins = (await GetPlugins()) || [];
+      set({ plugins, pluginNodes: collectNodes(plugins) });
+    } catch (error) {
+      console.error('Failed to load plugins:', error);
+    }
+  },
+
+  reloadPlugins: async () => {
+    const plugins = (await ReloadPlugins()) || [];
+    set({ plugins, pluginNodes: collectNodes(plugins) });
+  },
+
+  getPluginNode: (type) => get().pluginNodes.find((n) => n.type === type),
+}));
@@ -43,6 +43,7 @@ export interface FlowExecution {
   id: string;
   flowId: string;
   flowName?: string;
+  trigger?: string; // set for runs started by background triggers
   status: "idle" | "running" | "success" | "error";
   results: ExecutionResult[];
   startedAt: string;
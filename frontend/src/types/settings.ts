@@ -11,6 +11,20 @@ export interface AIServiceConfig {
   enabled: boolean;
 }
 
+export interface SecretProvider {
+  name: string; // referenced as {{secret.NAME:path}}
+  type: 'env' | 'pass' | 'command' | 'vault';
+  ttl?: number; // seconds to cache values; 0 for 5 minutes, -1 to never cache
+  prefix?: string; // env, required
+  dir?: string; // pass
+  args?: string[]; // command and its arguments, {{name}} is replaced by the secret name
+  address?: string; // vault
+  mount?: string; // vault, defaults to secret
+  kvVersion?: number; // vault, 1 or 2
+  namespace?: string; // vault
+  tokenSecret?: string; // vault, local secret holding the token
+}
+
 export interface AppSettings {
   // Appearance
   theme: 'vscode' | 'raycast' | 'github' | 'nord';
@@ -40,6 +54,21 @@ export interface AppSettings {
   // Advanced
   debugMode: boolean;
   logLevel: 'error' | 'warn' | 'info' | 'debug';
+  metricsAddress: string; // listen address of /metrics, "off" to disable; defaults to 127.0.0.1:9464
+  tracingExporter: '' | 'otlp' | 'file';
+  tracingEndpoint: string; // OTLP/HTTP collector, e.g. http://localhost:4318
+  tracingFile: string; // JSON-lines file, defaults to <data dir>/traces.jsonl
+  scriptTimeout: number; // in milliseconds
+  scriptMemoryLimit: number; // in MB
+  gitEnabled: boolean; // keep the flows directory in a git repository
+  gitRemote: string; // URL or path of the remote to pull from and push to
+  gitBranch: string; // defaults to main
+  secretProviders: SecretProvider[]; // external stores for {{secret.PROVIDER:NAME}}
+  backupEnabled: boolean; // write scheduled backups
+  backupIntervalHours: number;
+  backupKeep: number; // scheduled backups to keep
+  backupDir: string; // defaults to <data dir>/backups
+  backupExecutions: boolean; // include the execution history
 
   // Variables
   environmentVariables: EnvironmentVariable[];
@@ -65,6 +94,21 @@ export const defaultSettings: AppSettings = {
   },
   debugMode: false,
   logLevel: 'info',
+  metricsAddress: '',
+  tracingExporter: '',
+  tracingEndpoint: '',
+  tracingFile: '',
+  scriptTimeout: 5000,
+  scriptMemoryLimit: 64,
+  gitEnabled: false,
+  gitRemote: '',
+  gitBranch: 'main',
+  secretProviders: [],
+  backupEnabled: false,
+  backupIntervalHours: 24,
+  backupKeep: 7,
+  backupDir: '',
+  backupExecutions: false,
   environmentVariables: [],
 };
 
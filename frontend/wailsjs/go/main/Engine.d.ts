@@ -2,10 +2,28 @@
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
 
+export function DiffExecutions(arg1:string,arg2:string):Promise<main.ExecutionDiff>;
+
 export function GetExecution(arg1:string):Promise<main.FlowExecution>;
 
 export function GetExecutions():Promise<Array<main.FlowExecution>>;
 
+export function GetNodeTypes():Promise<Array<main.NodeTypeSpec>>;
+
+export function GetPlugins():Promise<Array<main.PluginInfo>>;
+
+export function ReloadPlugins():Promise<Array<main.PluginInfo>>;
+
+export function RunEditorNode(arg1:string,arg2:string,arg3:string,arg4:Record<string, any>,arg5:any):Promise<main.PluginResult>;
+
 export function RunFlow(arg1:string):Promise<main.FlowExecution>;
 
+export function RunFlowTests(arg1:string):Promise<main.FlowTestReport>;
+
+export function RunPluginNode(arg1:string,arg2:Record<string, any>,arg3:any):Promise<main.PluginResult>;
+
+export function RunScript(arg1:string,arg2:Record<string, any>):Promise<main.ScriptResult>;
+
 export function StopExecution(arg1:string):Promise<void>;
+
+export function ValidateFlow(arg1:string):Promise<Array<string>>;
@@ -2,6 +2,10 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function DiffExecutions(arg1, arg2) {
+  return window['go']['main']['Engine']['DiffExecutions'](arg1, arg2);
+}
+
 export function GetExecution(arg1) {
   return window['go']['main']['Engine']['GetExecution'](arg1);
 }
@@ -10,10 +14,42 @@ export function GetExecutions() {
   return window['go']['main']['Engine']['GetExecutions']();
 }
 
+export function GetNodeTypes() {
+  return window['go']['main']['Engine']['GetNodeTypes']();
+}
+
+export function GetPlugins() {
+  return window['go']['main']['Engine']['GetPlugins']();
+}
+
+export function ReloadPlugins() {
+  return window['go']['main']['Engine']['ReloadPlugins']();
+}
+
+export function RunEditorNode(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['Engine']['RunEditorNode'](arg1, arg2, arg3, arg4, arg5);
+}
+
 export function RunFlow(arg1) {
   return window['go']['main']['Engine']['RunFlow'](arg1);
 }
 
+export function RunFlowTests(arg1) {
+  return window['go']['main']['Engine']['RunFlowTests'](arg1);
+}
+
+export function RunPluginNode(arg1, arg2, arg3) {
+  return window['go']['main']['Engine']['RunPluginNode'](arg1, arg2, arg3);
+}
+
+export function RunScript(arg1, arg2) {
+  return window['go']['main']['Engine']['RunScript'](arg1, arg2);
+}
+
 export function StopExecution(arg1) {
   return window['go']['main']['Engine']['StopExecution'](arg1);
 }
+
+export function ValidateFlow(arg1) {
+  return window['go']['main']['Engine']['ValidateFlow'](arg1);
+}
@@ -1,32 +1,103 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {main} from '../models';
+
+export function AppendExecutionLogs(arg1:string,arg2:Array<main.LogEntry>):Promise<void>;
+
+export function CreateBackup(arg1:string,arg2:main.BackupOptions):Promise<main.BackupManifest>;
+
+export function DeleteCustomNode(arg1:string):Promise<void>;
 
 export function DeleteExecution(arg1:string):Promise<void>;
 
 export function DeleteFlow(arg1:string):Promise<void>;
 
+export function DeleteSecret(arg1:string):Promise<void>;
+
+export function DiffFlowRevisions(arg1:string,arg2:number,arg3:number):Promise<main.FlowDiff>;
+
 export function ExportFlow(arg1:string):Promise<string>;
 
+export function ExportFlowBundle(arg1:string):Promise<string>;
+
+export function ExportFlowDiagram(arg1:string,arg2:string):Promise<string>;
+
 export function GetFlow(arg1:string):Promise<string>;
 
+export function GetFlowGitLog(arg1:string,arg2:number):Promise<Array<main.GitCommit>>;
+
+export function GetFlowRevision(arg1:string,arg2:number):Promise<string>;
+
 export function GetSecret(arg1:string):Promise<string>;
 
+export function GetSecretsStatus():Promise<main.SecretsStatus>;
+
+export function GetStorageBackend():Promise<string>;
+
+export function GitPull():Promise<main.GitPullResult>;
+
+export function GitPush():Promise<void>;
+
+export function ImportCustomNodes(arg1:string):Promise<number>;
+
 export function ImportFlow(arg1:string):Promise<string>;
 
+export function ImportFlowBundle(arg1:string):Promise<main.BundleImportReport>;
+
+export function ImportFlowWithReport(arg1:string):Promise<main.FlowImportReport>;
+
 export function Init():Promise<void>;
 
+export function ListCustomNodeVersions(arg1:string):Promise<Array<Record<string, any>>>;
+
+export function ListCustomNodes():Promise<Array<Record<string, any>>>;
+
 export function ListExecutions(arg1:number):Promise<Array<Record<string, any>>>;
 
+export function ListFlowRevisions(arg1:string):Promise<Array<main.FlowRevision>>;
+
 export function ListFlows():Promise<Array<Record<string, any>>>;
 
+export function ListSecrets():Promise<Array<main.SecretInfo>>;
+
+export function LoadCustomNode(arg1:string):Promise<string>;
+
 export function LoadFlow(arg1:string):Promise<string>;
 
+export function LoadFlowTests(arg1:string):Promise<string>;
+
 export function LoadSettings():Promise<string>;
 
+export function LockSecrets():Promise<void>;
+
+export function MigrateStorage(arg1:string):Promise<main.StorageMigrationReport>;
+
+export function PreviewBackup(arg1:string):Promise<main.BackupPreview>;
+
+export function QueryExecutionLogs(arg1:string,arg2:string,arg3:string,arg4:string,arg5:number,arg6:number):Promise<main.LogPage>;
+
+export function QueryExecutions(arg1:main.ExecutionQuery):Promise<main.ExecutionPage>;
+
+export function RekeySecrets(arg1:string):Promise<void>;
+
+export function RestoreBackup(arg1:string,arg2:main.RestoreOptions):Promise<main.RestoreReport>;
+
+export function RestoreFlowRevision(arg1:string,arg2:number):Promise<string>;
+
+export function SaveCustomNode(arg1:string):Promise<Record<string, any>>;
+
 export function SaveExecution(arg1:string):Promise<void>;
 
 export function SaveFlow(arg1:string):Promise<string>;
 
+export function SaveFlowTests(arg1:string,arg2:string):Promise<void>;
+
+export function SaveFlowWithMessage(arg1:string,arg2:string):Promise<string>;
+
 export function SaveSecret(arg1:string,arg2:string):Promise<void>;
 
 export function SaveSettings(arg1:string):Promise<void>;
+
+export function SearchFlows(arg1:string):Promise<Array<main.FlowSearchResult>>;
+
+export function UnlockSecrets(arg1:string):Promise<void>;
@@ -2,6 +2,18 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AppendExecutionLogs(arg1, arg2) {
+  return window['go']['main']['Storage']['AppendExecutionLogs'](arg1, arg2);
+}
+
+export function CreateBackup(arg1, arg2) {
+  return window['go']['main']['Storage']['CreateBackup'](arg1, arg2);
+}
+
+export function DeleteCustomNode(arg1) {
+  return window['go']['main']['Storage']['DeleteCustomNode'](arg1);
+}
+
 export function DeleteExecution(arg1) {
   return window['go']['main']['Storage']['DeleteExecution'](arg1);
 }
@@ -10,42 +22,154 @@ export function DeleteFlow(arg1) {
   return window['go']['main']['Storage']['DeleteFlow'](arg1);
 }
 
+export function DeleteSecret(arg1) {
+  return window['go']['main']['Storage']['DeleteSecret'](arg1);
+}
+
+export function DiffFlowRevisions(arg1, arg2, arg3) {
+  return window['go']['main']['Storage']['DiffFlowRevisions'](arg1, arg2, arg3);
+}
+
 export function ExportFlow(arg1) {
   return window['go']['main']['Storage']['ExportFlow'](arg1);
 }
 
+export function ExportFlowBundle(arg1) {
+  return window['go']['main']['Storage']['ExportFlowBundle'](arg1);
+}
+
+export function ExportFlowDiagram(arg1, arg2) {
+  return window['go']['main']['Storage']['ExportFlowDiagram'](arg1, arg2);
+}
+
 export function GetFlow(arg1) {
   return window['go']['main']['Storage']['GetFlow'](arg1);
 }
 
+export function GetFlowGitLog(arg1, arg2) {
+  return window['go']['main']['Storage']['GetFlowGitLog'](arg1, arg2);
+}
+
+export function GetFlowRevision(arg1, arg2) {
+  return window['go']['main']['Storage']['GetFlowRevision'](arg1, arg2);
+}
+
 export function GetSecret(arg1) {
   return window['go']['main']['Storage']['GetSecret'](arg1);
 }
 
+export function GetSecretsStatus() {
+  return window['go']['main']['Storage']['GetSecretsStatus']();
+}
+
+export function GetStorageBackend() {
+  return window['go']['main']['Storage']['GetStorageBackend']();
+}
+
+export function GitPull() {
+  return window['go']['main']['Storage']['GitPull']();
+}
+
+export function GitPush() {
+  return window['go']['main']['Storage']['GitPush']();
+}
+
+export function ImportCustomNodes(arg1) {
+  return window['go']['main']['Storage']['ImportCustomNodes'](arg1);
+}
+
 export function ImportFlow(arg1) {
   return window['go']['main']['Storage']['ImportFlow'](arg1);
 }
 
+export function ImportFlowBundle(arg1) {
+  return window['go']['main']['Storage']['ImportFlowBundle'](arg1);
+}
+
+export function ImportFlowWithReport(arg1) {
+  return window['go']['main']['Storage']['ImportFlowWithReport'](arg1);
+}
+
 export function Init() {
   return window['go']['main']['Storage']['Init']();
 }
 
+export function ListCustomNodeVersions(arg1) {
+  return window['go']['main']['Storage']['ListCustomNodeVersions'](arg1);
+}
+
+export function ListCustomNodes() {
+  return window['go']['main']['Storage']['ListCustomNodes']();
+}
+
 export function ListExecutions(arg1) {
   return window['go']['main']['Storage']['ListExecutions'](arg1);
 }
 
+export function ListFlowRevisions(arg1) {
+  return window['go']['main']['Storage']['ListFlowRevisions'](arg1);
+}
+
 export function ListFlows() {
   return window['go']['main']['Storage']['ListFlows']();
 }
 
+export function ListSecrets() {
+  return window['go']['main']['Storage']['ListSecrets']();
+}
+
+export function LoadCustomNode(arg1) {
+  return window['go']['main']['Storage']['LoadCustomNode'](arg1);
+}
+
 export function LoadFlow(arg1) {
   return window['go']['main']['Storage']['LoadFlow'](arg1);
 }
 
+export function LoadFlowTests(arg1) {
+  return window['go']['main']['Storage']['LoadFlowTests'](arg1);
+}
+
 export function LoadSettings() {
   return window['go']['main']['Storage']['LoadSettings']();
 }
 
+export function LockSecrets() {
+  return window['go']['main']['Storage']['LockSecrets']();
+}
+
+export function MigrateStorage(arg1) {
+  return window['go']['main']['Storage']['MigrateStorage'](arg1);
+}
+
+export function PreviewBackup(arg1) {
+  return window['go']['main']['Storage']['PreviewBackup'](arg1);
+}
+
+export function QueryExecutionLogs(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['Storage']['QueryExecutionLogs'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
+export function QueryExecutions(arg1) {
+  return window['go']['main']['Storage']['QueryExecutions'](arg1);
+}
+
+export function RekeySecrets(arg1) {
+  return window['go']['main']['Storage']['RekeySecrets'](arg1);
+}
+
+export function RestoreBackup(arg1, arg2) {
+  return window['go']['main']['Storage']['RestoreBackup'](arg1, arg2);
+}
+
+export function RestoreFlowRevision(arg1, arg2) {
+  return window['go']['main']['Storage']['RestoreFlowRevision'](arg1, arg2);
+}
+
+export function SaveCustomNode(arg1) {
+  return window['go']['main']['Storage']['SaveCustomNode'](arg1);
+}
+
 export function SaveExecution(arg1) {
   return window['go']['main']['Storage']['SaveExecution'](arg1);
 }
@@ -54,6 +178,14 @@ export function SaveFlow(arg1) {
   return window['go']['main']['Storage']['SaveFlow'](arg1);
 }
 
+export function SaveFlowTests(arg1, arg2) {
+  return window['go']['main']['Storage']['SaveFlowTests'](arg1, arg2);
+}
+
+export function SaveFlowWithMessage(arg1, arg2) {
+  return window['go']['main']['Storage']['SaveFlowWithMessage'](arg1, arg2);
+}
+
 export function SaveSecret(arg1, arg2) {
   return window['go']['main']['Storage']['SaveSecret'](arg1, arg2);
 }
@@ -61,3 +193,11 @@ export function SaveSecret(arg1, arg2) {
 export function SaveSettings(arg1) {
   return window['go']['main']['Storage']['SaveSettings'](arg1);
 }
+
+export function SearchFlows(arg1) {
+  return window['go']['main']['Storage']['SearchFlows'](arg1);
+}
+
+export function UnlockSecrets(arg1) {
+  return window['go']['main']['Storage']['UnlockSecrets'](arg1);
+}
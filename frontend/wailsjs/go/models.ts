@@ -19,11 +19,358 @@ export namespace frontend {
 
 export namespace main {
 	
+	export class BackupItem {
+	    id: string;
+	    name?: string;
+	    exists: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.exists = source["exists"];
+	    }
+	}
+	export class BackupManifest {
+	    format: string;
+	    version: number;
+	    createdAt: string;
+	    backend: string;
+	    flows: number;
+	    customNodes: number;
+	    executions: number;
+	    secrets: number;
+	    settings: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupManifest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.format = source["format"];
+	        this.version = source["version"];
+	        this.createdAt = source["createdAt"];
+	        this.backend = source["backend"];
+	        this.flows = source["flows"];
+	        this.customNodes = source["customNodes"];
+	        this.executions = source["executions"];
+	        this.secrets = source["secrets"];
+	        this.settings = source["settings"];
+	    }
+	}
+	export class BackupOptions {
+	    executions: boolean;
+	    secrets: boolean;
+	    passphrase?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.executions = source["executions"];
+	        this.secrets = source["secrets"];
+	        this.passphrase = source["passphrase"];
+	    }
+	}
+	export class BackupPreview {
+	    manifest: BackupManifest;
+	    flows: BackupItem[];
+	    customNodes: BackupItem[];
+	    secrets: BackupItem[];
+	    executions: number;
+	    executionConflicts: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.manifest = this.convertValues(source["manifest"], BackupManifest);
+	        this.flows = this.convertValues(source["flows"], BackupItem);
+	        this.customNodes = this.convertValues(source["customNodes"], BackupItem);
+	        this.secrets = this.convertValues(source["secrets"], BackupItem);
+	        this.executions = source["executions"];
+	        this.executionConflicts = source["executionConflicts"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BundleImportReport {
+	    flowId: string;
+	    flowIds: Record<string, string>;
+	    customNodes: string[];
+	    existingCustomNodes: string[];
+	    missingSecrets: string[];
+	    triggers: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BundleImportReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.flowId = source["flowId"];
+	        this.flowIds = source["flowIds"];
+	        this.customNodes = source["customNodes"];
+	        this.existingCustomNodes = source["existingCustomNodes"];
+	        this.missingSecrets = source["missingSecrets"];
+	        this.triggers = source["triggers"];
+	    }
+	}
+	export class JSONChange {
+	    path: string;
+	    kind: string;
+	    before?: any;
+	    after?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new JSONChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.kind = source["kind"];
+	        this.before = source["before"];
+	        this.after = source["after"];
+	    }
+	}
+	export class NodeDiff {
+	    nodeId: string;
+	    presence: string;
+	    changed: boolean;
+	    statusA?: string;
+	    statusB?: string;
+	    statusChanged: boolean;
+	    durationA: number;
+	    durationB: number;
+	    durationDelta: number;
+	    branchA?: string;
+	    branchB?: string;
+	    branchChanged: boolean;
+	    errorA?: string;
+	    errorB?: string;
+	    outputChanges?: JSONChange[];
+	    configChanges?: JSONChange[];
+	
+	    static createFrom(source: any = {}) {
+	        return new NodeDiff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.nodeId = source["nodeId"];
+	        this.presence = source["presence"];
+	        this.changed = source["changed"];
+	        this.statusA = source["statusA"];
+	        this.statusB = source["statusB"];
+	        this.statusChanged = source["statusChanged"];
+	        this.durationA = source["durationA"];
+	        this.durationB = source["durationB"];
+	        this.durationDelta = source["durationDelta"];
+	        this.branchA = source["branchA"];
+	        this.branchB = source["branchB"];
+	        this.branchChanged = source["branchChanged"];
+	        this.errorA = source["errorA"];
+	        this.errorB = source["errorB"];
+	        this.outputChanges = this.convertValues(source["outputChanges"], JSONChange);
+	        this.configChanges = this.convertValues(source["configChanges"], JSONChange);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ExecutionDiff {
+	    executionA: string;
+	    executionB: string;
+	    statusA: string;
+	    statusB: string;
+	    statusChanged: boolean;
+	    durationA: number;
+	    durationB: number;
+	    durationDelta: number;
+	    changedNodes: number;
+	    nodes: NodeDiff[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ExecutionDiff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.executionA = source["executionA"];
+	        this.executionB = source["executionB"];
+	        this.statusA = source["statusA"];
+	        this.statusB = source["statusB"];
+	        this.statusChanged = source["statusChanged"];
+	        this.durationA = source["durationA"];
+	        this.durationB = source["durationB"];
+	        this.durationDelta = source["durationDelta"];
+	        this.changedNodes = source["changedNodes"];
+	        this.nodes = this.convertValues(source["nodes"], NodeDiff);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ExecutionSummary {
+	    id: string;
+	    flowId: string;
+	    flowName?: string;
+	    trigger?: string;
+	    status: string;
+	    startedAt: string;
+	    endedAt?: string;
+	    durationMs: number;
+	    nodeCount: number;
+	    successCount: number;
+	    errorCount: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ExecutionSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.flowId = source["flowId"];
+	        this.flowName = source["flowName"];
+	        this.trigger = source["trigger"];
+	        this.status = source["status"];
+	        this.startedAt = source["startedAt"];
+	        this.endedAt = source["endedAt"];
+	        this.durationMs = source["durationMs"];
+	        this.nodeCount = source["nodeCount"];
+	        this.successCount = source["successCount"];
+	        this.errorCount = source["errorCount"];
+	    }
+	}
+	export class ExecutionPage {
+	    executions: ExecutionSummary[];
+	    nextCursor?: string;
+	    total: number;
+	    statusCounts: Record<string, number>;
+	
+	    static createFrom(source: any = {}) {
+	        return new ExecutionPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.executions = this.convertValues(source["executions"], ExecutionSummary);
+	        this.nextCursor = source["nextCursor"];
+	        this.total = source["total"];
+	        this.statusCounts = source["statusCounts"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ExecutionQuery {
+	    flowId?: string;
+	    status?: string;
+	    trigger?: string;
+	    startedAfter?: string;
+	    startedBefore?: string;
+	    minDurationMs?: number;
+	    maxDurationMs?: number;
+	    cursor?: string;
+	    limit?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ExecutionQuery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.flowId = source["flowId"];
+	        this.status = source["status"];
+	        this.trigger = source["trigger"];
+	        this.startedAfter = source["startedAfter"];
+	        this.startedBefore = source["startedBefore"];
+	        this.minDurationMs = source["minDurationMs"];
+	        this.maxDurationMs = source["maxDurationMs"];
+	        this.cursor = source["cursor"];
+	        this.limit = source["limit"];
+	    }
+	}
 	export class ExecutionResult {
 	    nodeId: string;
 	    status: string;
 	    output?: any;
 	    error?: string;
+	    branch?: string;
+	    retries?: number;
+	    config?: Record<string, any>;
 	    duration: number;
 	    timestamp: string;
 	
@@ -37,30 +384,82 @@ export namespace main {
 	        this.status = source["status"];
 	        this.output = source["output"];
 	        this.error = source["error"];
+	        this.branch = source["branch"];
+	        this.retries = source["retries"];
+	        this.config = source["config"];
 	        this.duration = source["duration"];
 	        this.timestamp = source["timestamp"];
 	    }
 	}
-	export class FlowExecution {
+	
+	export class FlowEdgeRewire {
+	    from: FlowEdge;
+	    to: FlowEdge;
+	
+	    static createFrom(source: any = {}) {
+	        return new FlowEdgeRewire(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = this.convertValues(source["from"], FlowEdge);
+	        this.to = this.convertValues(source["to"], FlowEdge);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FlowEdge {
 	    id: string;
-	    flowId: string;
-	    status: string;
-	    results: ExecutionResult[];
-	    startedAt: string;
-	    endedAt?: string;
+	    source: string;
+	    target: string;
+	    sourceHandle?: string;
+	    targetHandle?: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new FlowExecution(source);
+	        return new FlowEdge(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
-	        this.flowId = source["flowId"];
-	        this.status = source["status"];
-	        this.results = this.convertValues(source["results"], ExecutionResult);
-	        this.startedAt = source["startedAt"];
-	        this.endedAt = source["endedAt"];
+	        this.source = source["source"];
+	        this.target = source["target"];
+	        this.sourceHandle = source["sourceHandle"];
+	        this.targetHandle = source["targetHandle"];
+	    }
+	}
+	export class FlowDiffNode {
+	    id: string;
+	    nodeType: string;
+	    label: string;
+	    changes?: FlowValueChange[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FlowDiffNode(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.nodeType = source["nodeType"];
+	        this.label = source["label"];
+	        this.changes = this.convertValues(source["changes"], FlowValueChange);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -81,6 +480,814 @@ export namespace main {
 		    return a;
 		}
 	}
+	export class FlowValueChange {
+	    field: string;
+	    from: any;
+	    to: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new FlowValueChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	    }
+	}
+	export class FlowDiff {
+	    flowId: string;
+	    from: number;
+	    to: number;
+	    changes: FlowValueChange[];
+	    nodesAdded: FlowDiffNode[];
+	    nodesRemoved: FlowDiffNode[];
+	    nodesChanged: FlowDiffNode[];
+	    edgesAdded: FlowEdge[];
+	    edgesRemoved: FlowEdge[];
+	    edgesRewired: FlowEdgeRewire[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FlowDiff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.flowId = source["flowId"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.changes = this.convertValues(source["changes"], FlowValueChange);
+	        this.nodesAdded = this.convertValues(source["nodesAdded"], FlowDiffNode);
+	        this.nodesRemoved = this.convertValues(source["nodesRemoved"], FlowDiffNode);
+	        this.nodesChanged = this.convertValues(source["nodesChanged"], FlowDiffNode);
+	        this.edgesAdded = this.convertValues(source["edgesAdded"], FlowEdge);
+	        this.edgesRemoved = this.convertValues(source["edgesRemoved"], FlowEdge);
+	        this.edgesRewired = this.convertValues(source["edgesRewired"], FlowEdgeRewire);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	
+	export class FlowExecution {
+	    id: string;
+	    flowId: string;
+	    flowName?: string;
+	    trigger?: string;
+	    status: string;
+	    results: ExecutionResult[];
+	    startedAt: string;
+	    endedAt?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FlowExecution(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.flowId = source["flowId"];
+	        this.flowName = source["flowName"];
+	        this.trigger = source["trigger"];
+	        this.status = source["status"];
+	        this.results = this.convertValues(source["results"], ExecutionResult);
+	        this.startedAt = source["startedAt"];
+	        this.endedAt = source["endedAt"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ImportNote {
+	    flow?: string;
+	    node?: string;
+	    type?: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportNote(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.flow = source["flow"];
+	        this.node = source["node"];
+	        this.type = source["type"];
+	        this.message = source["message"];
+	    }
+	}
+	export class FlowImportReport {
+	    flowId: string;
+	    flowIds: string[];
+	    format: string;
+	    nodes: number;
+	    placeholders: ImportNote[];
+	    warnings: ImportNote[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FlowImportReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.flowId = source["flowId"];
+	        this.flowIds = source["flowIds"];
+	        this.format = source["format"];
+	        this.nodes = source["nodes"];
+	        this.placeholders = this.convertValues(source["placeholders"], ImportNote);
+	        this.warnings = this.convertValues(source["warnings"], ImportNote);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FlowRevision {
+	    revision: number;
+	    flowId: string;
+	    createdAt: string;
+	    message?: string;
+	    name: string;
+	    nodeCount: number;
+	    edgeCount: number;
+	    hash: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FlowRevision(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.revision = source["revision"];
+	        this.flowId = source["flowId"];
+	        this.createdAt = source["createdAt"];
+	        this.message = source["message"];
+	        this.name = source["name"];
+	        this.nodeCount = source["nodeCount"];
+	        this.edgeCount = source["edgeCount"];
+	        this.hash = source["hash"];
+	    }
+	}
+	export class SnippetPart {
+	    text: string;
+	    match?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SnippetPart(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.text = source["text"];
+	        this.match = source["match"];
+	    }
+	}
+	export class FlowSearchMatch {
+	    nodeId?: string;
+	    label?: string;
+	    nodeType?: string;
+	    field: string;
+	    snippet: SnippetPart[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FlowSearchMatch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.nodeId = source["nodeId"];
+	        this.label = source["label"];
+	        this.nodeType = source["nodeType"];
+	        this.field = source["field"];
+	        this.snippet = this.convertValues(source["snippet"], SnippetPart);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FlowSearchResult {
+	    flowId: string;
+	    name: string;
+	    description: string;
+	    score: number;
+	    nodeIds: string[];
+	    matches: FlowSearchMatch[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FlowSearchResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.flowId = source["flowId"];
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.score = source["score"];
+	        this.nodeIds = source["nodeIds"];
+	        this.matches = this.convertValues(source["matches"], FlowSearchMatch);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FlowTestResult {
+	    name: string;
+	    passed: boolean;
+	    failures?: string[];
+	    status: string;
+	    executionId: string;
+	    duration: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FlowTestResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.passed = source["passed"];
+	        this.failures = source["failures"];
+	        this.status = source["status"];
+	        this.executionId = source["executionId"];
+	        this.duration = source["duration"];
+	    }
+	}
+	export class FlowTestReport {
+	    flowId: string;
+	    flowName: string;
+	    total: number;
+	    passed: number;
+	    failed: number;
+	    duration: number;
+	    results: FlowTestResult[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FlowTestReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.flowId = source["flowId"];
+	        this.flowName = source["flowName"];
+	        this.total = source["total"];
+	        this.passed = source["passed"];
+	        this.failed = source["failed"];
+	        this.duration = source["duration"];
+	        this.results = this.convertValues(source["results"], FlowTestResult);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class GitCommit {
+	    hash: string;
+	    author: string;
+	    date: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GitCommit(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hash = source["hash"];
+	        this.author = source["author"];
+	        this.date = source["date"];
+	        this.message = source["message"];
+	    }
+	}
+	export class GitPullResult {
+	    updated: boolean;
+	    changedFlows: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new GitPullResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.updated = source["updated"];
+	        this.changedFlows = source["changedFlows"];
+	    }
+	}
+	
+	
+	export class LogEntry {
+	    timestamp: string;
+	    level: string;
+	    nodeId?: string;
+	    message: string;
+	    fields?: Record<string, any>;
+	
+	    static createFrom(source: any = {}) {
+	        return new LogEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.timestamp = source["timestamp"];
+	        this.level = source["level"];
+	        this.nodeId = source["nodeId"];
+	        this.message = source["message"];
+	        this.fields = source["fields"];
+	    }
+	}
+	export class LogPage {
+	    entries: LogEntry[];
+	    total: number;
+	    offset: number;
+	    limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new LogPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.entries = this.convertValues(source["entries"], LogEntry);
+	        this.total = source["total"];
+	        this.offset = source["offset"];
+	        this.limit = source["limit"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class NodeFieldOption {
+	    value: string;
+	    label: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new NodeFieldOption(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.value = source["value"];
+	        this.label = source["label"];
+	    }
+	}
+	export class NodeFieldSpec {
+	    key: string;
+	    label: string;
+	    type: string;
+	    placeholder?: string;
+	    required?: boolean;
+	    defaultValue?: any;
+	    options?: NodeFieldOption[];
+	    fileTypes?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new NodeFieldSpec(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.label = source["label"];
+	        this.type = source["type"];
+	        this.placeholder = source["placeholder"];
+	        this.required = source["required"];
+	        this.defaultValue = source["defaultValue"];
+	        this.options = this.convertValues(source["options"], NodeFieldOption);
+	        this.fileTypes = source["fileTypes"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class NodeHandle {
+	    id: string;
+	    label?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new NodeHandle(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.label = source["label"];
+	    }
+	}
+	export class NodeTypeSpec {
+	    type: string;
+	    category: string;
+	    name: string;
+	    icon?: string;
+	    description?: string;
+	    inputs: NodeHandle[];
+	    outputs: NodeHandle[];
+	    fields: NodeFieldSpec[];
+	    defaults: Record<string, any>;
+	    sideEffect: string;
+	    source: string;
+	    plugin?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new NodeTypeSpec(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.category = source["category"];
+	        this.name = source["name"];
+	        this.icon = source["icon"];
+	        this.description = source["description"];
+	        this.inputs = this.convertValues(source["inputs"], NodeHandle);
+	        this.outputs = this.convertValues(source["outputs"], NodeHandle);
+	        this.fields = this.convertValues(source["fields"], NodeFieldSpec);
+	        this.defaults = source["defaults"];
+	        this.sideEffect = source["sideEffect"];
+	        this.source = source["source"];
+	        this.plugin = source["plugin"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PluginNode {
+	    type: string;
+	    name: string;
+	    category: string;
+	    icon?: string;
+	    description?: string;
+	    fields?: any[];
+	    defaultData?: Record<string, any>;
+	    plugin: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PluginNode(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.name = source["name"];
+	        this.category = source["category"];
+	        this.icon = source["icon"];
+	        this.description = source["description"];
+	        this.fields = source["fields"];
+	        this.defaultData = source["defaultData"];
+	        this.plugin = source["plugin"];
+	    }
+	}
+	export class PluginInfo {
+	    name: string;
+	    version: string;
+	    description?: string;
+	    runtime: string;
+	    dir: string;
+	    nodes: PluginNode[];
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PluginInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.version = source["version"];
+	        this.description = source["description"];
+	        this.runtime = source["runtime"];
+	        this.dir = source["dir"];
+	        this.nodes = this.convertValues(source["nodes"], PluginNode);
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class PluginResult {
+	    output: any;
+	    logs: LogEntry[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PluginResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.output = source["output"];
+	        this.logs = this.convertValues(source["logs"], LogEntry);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RestoreOptions {
+	    mode: string;
+	    onConflict: string;
+	    settings: boolean;
+	    executions: boolean;
+	    secrets: boolean;
+	    passphrase?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RestoreOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mode = source["mode"];
+	        this.onConflict = source["onConflict"];
+	        this.settings = source["settings"];
+	        this.executions = source["executions"];
+	        this.secrets = source["secrets"];
+	        this.passphrase = source["passphrase"];
+	    }
+	}
+	export class RestoreReport {
+	    flows: number;
+	    customNodes: number;
+	    executions: number;
+	    secrets: number;
+	    settings: boolean;
+	    skipped: number;
+	    renamed?: Record<string, string>;
+	    safetyBackup?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RestoreReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.flows = source["flows"];
+	        this.customNodes = source["customNodes"];
+	        this.executions = source["executions"];
+	        this.secrets = source["secrets"];
+	        this.settings = source["settings"];
+	        this.skipped = source["skipped"];
+	        this.renamed = source["renamed"];
+	        this.safetyBackup = source["safetyBackup"];
+	    }
+	}
+	export class ScriptResult {
+	    output: any;
+	    console: LogEntry[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ScriptResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.output = source["output"];
+	        this.console = this.convertValues(source["console"], LogEntry);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SecretInfo {
+	    name: string;
+	    createdAt?: string;
+	    updatedAt?: string;
+	    encrypted: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SecretInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.createdAt = source["createdAt"];
+	        this.updatedAt = source["updatedAt"];
+	        this.encrypted = source["encrypted"];
+	    }
+	}
+	export class SecretsStatus {
+	    initialized: boolean;
+	    mode?: string;
+	    locked: boolean;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SecretsStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.initialized = source["initialized"];
+	        this.mode = source["mode"];
+	        this.locked = source["locked"];
+	        this.count = source["count"];
+	    }
+	}
+	
+	export class StorageMigrationReport {
+	    from: string;
+	    to: string;
+	    flows: number;
+	    executions: number;
+	    secrets: number;
+	    settings: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new StorageMigrationReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.flows = source["flows"];
+	        this.executions = source["executions"];
+	        this.secrets = source["secrets"];
+	        this.settings = source["settings"];
+	    }
+	}
 
 }
 
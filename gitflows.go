@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// gitIgnore keeps ForgeFlow's own bookkeeping out of the flows repository
+const gitIgnore = `history/
+backups/
+quarantine/
+.*.tmp-*
+`
+
+// GitSettings configure the git-backed flows directory. With gitEnabled,
+// the flows directory is a git repository and every save or delete of a
+// flow is committed.
+type GitSettings struct {
+	Enabled bool   `json:"gitEnabled"`
+	Remote  string `json:"gitRemote"` // URL or path of the remote repository
+	Branch  string `json:"gitBranch"` // defaults to main
+}
+
+type GitCommit struct {
+	Hash    string `json:"hash"`
+	Author  string `json:"author"`
+	Date    string `json:"date"`
+	Message string `json:"message"`
+}
+
+// GitPullResult lists the flows a pull added, changed or removed
+type GitPullResult struct {
+	Updated      bool     `json:"updated"`
+	ChangedFlows []string `json:"changedFlows"`
+}
+
+// flowRepo runs git in the flows directory
+type flowRepo struct {
+	dir      string
+	settings GitSettings
+}
+
+// gitFlowRepo returns the flows repository, creating it on first use, or
+// nil when git is disabled. Callers hold the storage write lock; settings
+// are read from the backend directly since LoadSettings would take the
+// lock again.
+func (s *Storage) gitFlowRepo(store StorageBackend) (*flowRepo, error) {
+	var settings GitSettings
+	if data, err := store.GetSettings(); err == nil {
+		json.Unmarshal(data, &settings)
+	}
+	if !settings.Enabled {
+		return nil, nil
+	}
+	if store.Name() != BackendJSON {
+		return nil, fmt.Errorf("git-backed flows need the %s storage backend", BackendJSON)
+	}
+	if settings.Branch == "" {
+		settings.Branch = "main"
+	}
+	// git would read a name starting with a dash as an option
+	if strings.HasPrefix(settings.Branch, "-") {
+		return nil, fmt.Errorf("invalid git branch: %q", settings.Branch)
+	}
+
+	repo := &flowRepo{dir: s.getFlowsDir(), settings: settings}
+	if err := repo.ensure(); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+func (r *flowRepo) git(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	hidePluginWindow(cmd)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		if msg == "" {
+			msg = err.Error()
+		}
+		return stdout.String(), fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return stdout.String(), nil
+}
+
+// ensure turns the flows directory into a repository with every existing
+// flow committed
+func (r *flowRepo) ensure() error {
+	if _, err := os.Stat(filepath.Join(r.dir, ".git")); err == nil {
+		return nil
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return errors.New("git-backed flows need git installed and on the PATH")
+	}
+
+	if _, err := r.git("init", "-q", "-b", r.settings.Branch); err != nil {
+		return err
+	}
+	// Commits need an identity; keep the user's global one if they have it
+	if _, err := r.git("config", "user.email"); err != nil {
+		r.git("config", "user.name", "ForgeFlow")
+		r.git("config", "user.email", "forgeflow@localhost")
+	}
+	if err := writeFileAtomic(filepath.Join(r.dir, ".gitignore"), []byte(gitIgnore), 0644); err != nil {
+		return err
+	}
+	return r.commit("Start tracking flows", ".")
+}
+
+// commit stages the given paths (including deletions) and commits them if
+// anything changed
+func (r *flowRepo) commit(message string, paths ...string) error {
+	if _, err := r.git(append([]string{"add", "-A", "--"}, paths...)...); err != nil {
+		return err
+	}
+	if _, err := r.git("diff", "--cached", "--quiet"); err == nil {
+		return nil // nothing staged
+	}
+	_, err := r.git("commit", "-q", "-m", message)
+	return err
+}
+
+func (r *flowRepo) head() string {
+	out, err := r.git("rev-parse", "-q", "--verify", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// commitFlow records a saved or deleted flow, along with its test cases
+func (r *flowRepo) commitFlow(flowID, message string) error {
+	// git add fails on paths that neither exist nor are tracked
+	var paths []string
+	for _, name := range []string{flowID + ".json", flowID + flowTestsSuffix} {
+		if _, err := os.Stat(filepath.Join(r.dir, name)); err == nil {
+			paths = append(paths, name)
+		} else if _, err := r.git("ls-files", "--error-unmatch", "--", name); err == nil {
+			paths = append(paths, name)
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	return r.commit(message, paths...)
+}
+
+func (r *flowRepo) remote() (string, error) {
+	if r.settings.Remote == "" {
+		return "", errors.New("no git remote configured")
+	}
+	if strings.HasPrefix(r.settings.Remote, "-") {
+		return "", fmt.Errorf("invalid git remote: %q", r.settings.Remote)
+	}
+	return r.settings.Remote, nil
+}
+
+// gitFlowMessage describes a flow save for the commit log
+func gitFlowMessage(action string, flowData map[string]interface{}) string {
+	id, _ := flowData["id"].(string)
+	if name, _ := flowData["name"].(string); name != "" {
+		return fmt.Sprintf("%s flow %q (%s)", action, name, id)
+	}
+	return fmt.Sprintf("%s flow %s", action, id)
+}
+
+// commitFlowChange commits a flow after SaveFlow or DeleteFlow when git is
+// enabled. The flow is already stored, so failures are only logged.
+func (s *Storage) commitFlowChange(store StorageBackend, flowID, message string) {
+	repo, err := s.gitFlowRepo(store)
+	if err == nil && repo != nil {
+		err = repo.commitFlow(flowID, message)
+	}
+	if err != nil {
+		fmt.Printf("Failed to commit flow %s: %v\n", flowID, err)
+	}
+}
+
+// GetFlowGitLog returns the commits that touched a flow, newest first, or
+// the whole repository's log when flowID is empty
+func (s *Storage) GetFlowGitLog(flowID string, limit int) ([]GitCommit, error) {
+	if flowID != "" {
+		if err := checkFlowID(flowID); err != nil {
+			return nil, err
+		}
+	}
+	store, unlock, err := s.writer()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	repo, err := s.gitFlowRepo(store)
+	if err != nil {
+		return nil, err
+	}
+	if repo == nil {
+		return nil, errors.New("git-backed flows are not enabled")
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	args := []string{"log", "--format=%H%x1f%an%x1f%aI%x1f%s", "-n", strconv.Itoa(limit)}
+	if flowID != "" {
+		args = append(args, "--", flowID+".json")
+	}
+	out, err := repo.git(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := []GitCommit{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		parts := strings.Split(line, "\x1f")
+		if len(parts) != 4 {
+			continue
+		}
+		commits = append(commits, GitCommit{Hash: parts[0], Author: parts[1], Date: parts[2], Message: parts[3]})
+	}
+	return commits, nil
+}
+
+// GitPull merges the configured remote branch into the flows repository.
+// Pending local changes are committed first. Triggers of the flows the pull
+// changed are re-registered.
+func (s *Storage) GitPull() (*GitPullResult, error) {
+	result, err := s.gitPull()
+	if err != nil {
+		return nil, err
+	}
+	if len(result.ChangedFlows) > 0 && s.onFlowsChanged != nil {
+		s.onFlowsChanged(result.ChangedFlows)
+	}
+	return result, nil
+}
+
+func (s *Storage) gitPull() (*GitPullResult, error) {
+	store, unlock, err := s.writer()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	repo, err := s.gitFlowRepo(store)
+	if err != nil {
+		return nil, err
+	}
+	if repo == nil {
+		return nil, errors.New("git-backed flows are not enabled")
+	}
+	remote, err := repo.remote()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repo.commit("Commit local flow changes", "."); err != nil {
+		return nil, err
+	}
+	before := repo.head()
+	if _, err := repo.git("pull", "-q", "--no-rebase", "--no-edit", "--allow-unrelated-histories", "--", remote, repo.settings.Branch); err != nil {
+		repo.git("merge", "--abort")
+		return nil, err
+	}
+	after := repo.head()
+
+	result := &GitPullResult{Updated: before != after, ChangedFlows: []string{}}
+	if !result.Updated {
+		return result, nil
+	}
+	s.resetSearchIndex()
+	out, err := repo.git("diff", "--name-only", before, after)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range strings.Split(strings.TrimSpace(out), "\n") {
+		if strings.Contains(name, "/") || filepath.Ext(name) != ".json" || strings.HasSuffix(name, flowTestsSuffix) {
+			continue
+		}
+		result.ChangedFlows = append(result.ChangedFlows, strings.TrimSuffix(name, ".json"))
+	}
+	return result, nil
+}
+
+// GitPush pushes the flows repository to the configured remote branch
+func (s *Storage) GitPush() error {
+	store, unlock, err := s.writer()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	repo, err := s.gitFlowRepo(store)
+	if err != nil {
+		return err
+	}
+	if repo == nil {
+		return errors.New("git-backed flows are not enabled")
+	}
+	remote, err := repo.remote()
+	if err != nil {
+		return err
+	}
+	if err := repo.commit("Commit local flow changes", "."); err != nil {
+		return err
+	}
+	_, err = repo.git("push", "-q", "--", remote, "HEAD:"+repo.settings.Branch)
+	return err
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// newGitStorage opens a storage in a temp dir with git-backed flows using
+// remote
+func newGitStorage(t *testing.T, remote string) *Storage {
+	t.Helper()
+	s := NewStorageAt(t.TempDir())
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.close)
+	settings, _ := json.Marshal(map[string]interface{}{"gitEnabled": true, "gitRemote": remote, "gitBranch": "main"})
+	if err := s.SaveSettings(string(settings)); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func saveTestFlow(t *testing.T, s *Storage, id, name string) {
+	t.Helper()
+	flow, _ := json.Marshal(map[string]interface{}{"id": id, "name": name, "nodes": []interface{}{}, "edges": []interface{}{}})
+	if _, err := s.SaveFlow(string(flow)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGitPushPull(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@localhost")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@localhost")
+
+	remote := filepath.Join(t.TempDir(), "flows.git")
+	if out, err := exec.Command("git", "init", "-q", "--bare", "-b", "main", remote).CombinedOutput(); err != nil {
+		t.Fatalf("git init --bare: %v: %s", err, out)
+	}
+
+	a := newGitStorage(t, remote)
+	saveTestFlow(t, a, "flow-1", "First")
+	if err := a.GitPush(); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+
+	b := newGitStorage(t, remote)
+	result, err := b.GitPull()
+	if err != nil {
+		t.Fatalf("pull: %v", err)
+	}
+	if !result.Updated || len(result.ChangedFlows) != 1 || result.ChangedFlows[0] != "flow-1" {
+		t.Fatalf("pull result = %+v, want flow-1 changed", result)
+	}
+	if _, err := b.LoadFlow("flow-1"); err != nil {
+		t.Fatalf("pulled flow: %v", err)
+	}
+
+	// Changes made on the other side come back with the next pull
+	saveTestFlow(t, b, "flow-1", "Renamed")
+	if err := b.GitPush(); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	if result, err = a.GitPull(); err != nil {
+		t.Fatalf("pull: %v", err)
+	}
+	if !result.Updated || len(result.ChangedFlows) != 1 {
+		t.Fatalf("pull result = %+v, want flow-1 changed", result)
+	}
+	data, _ := a.LoadFlow("flow-1")
+	var flow Flow
+	json.Unmarshal([]byte(data), &flow)
+	if flow.Name != "Renamed" {
+		t.Errorf("flow name = %q, want Renamed", flow.Name)
+	}
+
+	if commits, err := a.GetFlowGitLog("flow-1", 10); err != nil || len(commits) < 2 {
+		t.Errorf("git log = %v, %v; want both saves", commits, err)
+	}
+}
+
+func TestGitRemoteOption(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	marker := filepath.Join(t.TempDir(), "ran")
+	s := newGitStorage(t, "--upload-pack=touch "+marker)
+	saveTestFlow(t, s, "flow-1", "First")
+
+	if _, err := s.GitPull(); err == nil {
+		t.Error("pull accepted a remote starting with a dash")
+	}
+	if err := s.GitPush(); err == nil {
+		t.Error("push accepted a remote starting with a dash")
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("the remote was run as a git option")
+	}
+}
@@ -0,0 +1,403 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// jsonBackend stores each flow and execution as a JSON file:
+//
+//	flows/<id>.json, executions/<id>.json, settings.json, secrets/<key>
+//
+// Listing flows reads and parses every file. Executions are listed from an
+// index of their summaries, see executionIndexFile. Files that don't parse
+// are moved to a quarantine directory next to them when the storage is
+// opened, see reconcile.
+type jsonBackend struct {
+	dataDir string
+
+	// The execution index as last read, see readExecutionIndex
+	indexMu    sync.Mutex
+	index      map[string]executionIndexEntry // by file name
+	indexInfo  os.FileInfo
+	indexRead  int64 // bytes of the file read into index
+	indexLines int   // lines read, including replaced and deleted entries
+}
+
+func newJSONBackend(dataDir string) *jsonBackend {
+	return &jsonBackend{dataDir: dataDir}
+}
+
+func (b *jsonBackend) Name() string { return BackendJSON }
+
+func (b *jsonBackend) dir(name string, perm os.FileMode) string {
+	dir := filepath.Join(b.dataDir, name)
+	os.MkdirAll(dir, perm)
+	return dir
+}
+
+func (b *jsonBackend) PutFlow(summary FlowSummary, data []byte) error {
+	return writeFileAtomic(filepath.Join(b.dir("flows", 0755), summary.ID+".json"), data, 0644)
+}
+
+func (b *jsonBackend) GetFlow(id string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(b.dir("flows", 0755), id+".json"))
+}
+
+func (b *jsonBackend) ListFlows() ([]FlowSummary, error) {
+	flowsDir := b.dir("flows", 0755)
+	entries, err := os.ReadDir(flowsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	flows := []FlowSummary{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" || strings.HasSuffix(entry.Name(), flowTestsSuffix) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(flowsDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var flowData map[string]interface{}
+		// Unreadable files are quarantined by reconcile
+		if err := json.Unmarshal(data, &flowData); err != nil {
+			continue
+		}
+		flows = append(flows, flowSummary(flowData))
+	}
+
+	sort.Slice(flows, func(i, j int) bool { return flows[i].UpdatedAt > flows[j].UpdatedAt })
+	return flows, nil
+}
+
+func (b *jsonBackend) DeleteFlow(id string) error {
+	return os.Remove(filepath.Join(b.dir("flows", 0755), id+".json"))
+}
+
+func (b *jsonBackend) PutExecution(summary ExecutionSummary, data []byte) error {
+	path := filepath.Join(b.dir("executions", 0755), summary.ID+".json")
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return b.appendExecutionIndex(executionIndexEntry{
+		ExecutionSummary: summary,
+		File:             filepath.Base(path),
+		Size:             info.Size(),
+		ModTime:          info.ModTime().UnixNano(),
+	})
+}
+
+func (b *jsonBackend) GetExecution(id string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(b.dir("executions", 0755), id+".json"))
+}
+
+func (b *jsonBackend) ListExecutions(limit int) ([]ExecutionSummary, error) {
+	executions, err := b.indexedExecutions()
+	if err != nil {
+		return nil, err
+	}
+	sortExecutions(executions)
+	if limit > 0 && len(executions) > limit {
+		executions = executions[:limit]
+	}
+	return executions, nil
+}
+
+func (b *jsonBackend) QueryExecutions(filter executionFilter) (*ExecutionPage, error) {
+	executions, err := b.indexedExecutions()
+	if err != nil {
+		return nil, err
+	}
+	return queryExecutionSummaries(executions, filter), nil
+}
+
+func (b *jsonBackend) DeleteExecution(id string) error {
+	if err := os.Remove(filepath.Join(b.dir("executions", 0755), id+".json")); err != nil {
+		return err
+	}
+	return b.appendExecutionIndex(executionIndexEntry{File: id + ".json", Deleted: true})
+}
+
+// executionIndexFile keeps the summary of every execution file along with
+// the file's size and modification time, so listing and querying the
+// history doesn't read the files. It has one JSON line per save or delete,
+// later lines replacing earlier ones for the same file. The index is
+// reconciled with the directory and compacted when the storage is opened.
+const executionIndexFile = ".index.jsonl"
+
+type executionIndexEntry struct {
+	ExecutionSummary
+	File    string `json:"file"`
+	Size    int64  `json:"size,omitempty"`
+	ModTime int64  `json:"modTime,omitempty"` // unix nanoseconds
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+func (b *jsonBackend) executionIndexPath() string {
+	return filepath.Join(b.dir("executions", 0755), executionIndexFile)
+}
+
+// indexedExecutions returns the summaries of all executions. Without an
+// index, the directory is scanned; the index is only written on the write
+// paths.
+func (b *jsonBackend) indexedExecutions() ([]ExecutionSummary, error) {
+	b.indexMu.Lock()
+	defer b.indexMu.Unlock()
+
+	var entries []executionIndexEntry
+	if err := b.readExecutionIndex(); err == nil {
+		entries = make([]executionIndexEntry, 0, len(b.index))
+		for _, e := range b.index {
+			entries = append(entries, e)
+		}
+	} else if os.IsNotExist(err) {
+		entries, _ = b.scanExecutions(nil, false)
+	} else {
+		return nil, err
+	}
+
+	executions := make([]ExecutionSummary, len(entries))
+	for i, e := range entries {
+		executions[i] = e.ExecutionSummary
+	}
+	return executions, nil
+}
+
+// readExecutionIndex brings the cached index up to date with the index
+// file, reading only the lines appended since the last call unless the file
+// was replaced. A line still being appended by another process is left for
+// the next call. The caller holds indexMu.
+func (b *jsonBackend) readExecutionIndex() error {
+	f, err := os.Open(b.executionIndexPath())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if b.indexInfo == nil || !os.SameFile(b.indexInfo, info) || info.Size() < b.indexRead {
+		b.index = map[string]executionIndexEntry{}
+		b.indexRead = 0
+		b.indexLines = 0
+	}
+	b.indexInfo = info
+	if info.Size() == b.indexRead {
+		return nil
+	}
+
+	data := make([]byte, info.Size()-b.indexRead)
+	n, err := f.ReadAt(data, b.indexRead)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	data = data[:n]
+	end := bytes.LastIndexByte(data, '\n') + 1
+	for _, line := range bytes.Split(data[:end], []byte("\n")) {
+		var e executionIndexEntry
+		// A line that doesn't parse only loses its entry until the index
+		// is reconciled
+		if len(line) == 0 || json.Unmarshal(line, &e) != nil {
+			continue
+		}
+		b.indexLines++
+		if e.Deleted {
+			delete(b.index, e.File)
+		} else {
+			b.index[e.File] = e
+		}
+	}
+	b.indexRead += int64(end)
+	return nil
+}
+
+// appendExecutionIndex records a saved or deleted execution. Without an
+// index, the whole directory is indexed instead. The caller holds the
+// storage write lock.
+func (b *jsonBackend) appendExecutionIndex(entry executionIndexEntry) error {
+	path := b.executionIndexPath()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		b.reindexExecutions()
+		return nil
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// scanExecutions lists the execution files, reusing the entries of indexed
+// files whose size and modification time haven't changed. Files that don't
+// parse are skipped, or moved to quarantine when quarantine is set. changed
+// reports whether the result differs from indexed.
+func (b *jsonBackend) scanExecutions(indexed map[string]executionIndexEntry, quarantine bool) (current []executionIndexEntry, changed bool) {
+	execDir := b.dir("executions", 0755)
+	entries, err := os.ReadDir(execDir)
+	if err != nil {
+		return nil, true
+	}
+	current = make([]executionIndexEntry, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		// Dot files are the index and leftovers of interrupted writes
+		if entry.IsDir() || filepath.Ext(name) != ".json" || strings.HasPrefix(name, ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if e, ok := indexed[name]; ok && e.Size == info.Size() && e.ModTime == info.ModTime().UnixNano() {
+			current = append(current, e)
+			continue
+		}
+
+		changed = true
+		data, err := os.ReadFile(filepath.Join(execDir, name))
+		if err != nil {
+			continue
+		}
+		var execution map[string]interface{}
+		if err := json.Unmarshal(data, &execution); err != nil {
+			if quarantine {
+				quarantineJSON(filepath.Join(execDir, name), err)
+			}
+			continue
+		}
+		current = append(current, executionIndexEntry{
+			ExecutionSummary: executionSummary(execution),
+			File:             name,
+			Size:             info.Size(),
+			ModTime:          info.ModTime().UnixNano(),
+		})
+	}
+	if len(current) != len(indexed) {
+		changed = true
+	}
+	return current, changed
+}
+
+// reconcile picks up files that changed while ForgeFlow wasn't writing
+// them (edited by hand or by an older version): unreadable flows are
+// quarantined and the executions are reindexed. Reads never move files, as
+// they don't hold the cross-process lock. The caller holds the storage
+// write lock.
+func (b *jsonBackend) reconcile() {
+	flowsDir := b.dir("flows", 0755)
+	if entries, err := os.ReadDir(flowsDir); err == nil {
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || filepath.Ext(name) != ".json" || strings.HasSuffix(name, flowTestsSuffix) {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(flowsDir, name))
+			if err != nil {
+				continue
+			}
+			var flowData map[string]interface{}
+			if err := json.Unmarshal(data, &flowData); err != nil {
+				quarantineJSON(filepath.Join(flowsDir, name), err)
+			}
+		}
+	}
+
+	b.reindexExecutions()
+}
+
+// reindexExecutions re-reads the execution files that are new or changed
+// since they were indexed, quarantines the unreadable ones and compacts the
+// index. The caller holds the storage write lock.
+func (b *jsonBackend) reindexExecutions() {
+	b.indexMu.Lock()
+	defer b.indexMu.Unlock()
+	if err := b.readExecutionIndex(); err != nil {
+		b.index, b.indexInfo, b.indexRead, b.indexLines = nil, nil, 0, 0
+	}
+	current, changed := b.scanExecutions(b.index, true)
+	if !changed && b.indexLines == len(current) {
+		return
+	}
+	var buf bytes.Buffer
+	for _, e := range current {
+		line, err := json.Marshal(e)
+		if err != nil {
+			continue
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	// A stale or missing index only costs a re-scan, so a failed write
+	// isn't an error
+	writeFileAtomic(b.executionIndexPath(), buf.Bytes(), 0644)
+	b.indexInfo = nil
+}
+
+func (b *jsonBackend) GetSettings() ([]byte, error) {
+	return os.ReadFile(filepath.Join(b.dataDir, "settings.json"))
+}
+
+func (b *jsonBackend) PutSettings(data []byte) error {
+	return writeFileAtomic(filepath.Join(b.dataDir, "settings.json"), data, 0644)
+}
+
+func (b *jsonBackend) GetSecret(key string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(b.dir("secrets", 0700), key))
+}
+
+func (b *jsonBackend) PutSecret(key string, value []byte) error {
+	return writeFileAtomic(filepath.Join(b.dir("secrets", 0700), key), value, 0600)
+}
+
+func (b *jsonBackend) ListSecrets() ([]string, error) {
+	entries, err := os.ReadDir(b.dir("secrets", 0700))
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	for _, entry := range entries {
+		// Dot files are leftovers of interrupted writes
+		if !entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			keys = append(keys, entry.Name())
+		}
+	}
+	return keys, nil
+}
+
+func (b *jsonBackend) DeleteSecret(key string) error {
+	return os.Remove(filepath.Join(b.dir("secrets", 0700), key))
+}
+
+func (b *jsonBackend) Close() error { return nil }
+
+// quarantineJSON moves a file that failed to parse out of the listing
+func quarantineJSON(path string, parseErr error) {
+	dest, err := quarantineFile(path)
+	if err != nil {
+		fmt.Printf("Skipping unreadable file %s: %v\n", path, parseErr)
+		return
+	}
+	fmt.Printf("Quarantined unreadable file %s to %s: %v\n", path, dest, parseErr)
+}
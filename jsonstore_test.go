@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func openTestStorage(t *testing.T, dir string) *Storage {
+	t.Helper()
+	s := NewStorageAt(dir)
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.close)
+	return s
+}
+
+func listedExecutions(t *testing.T, s *Storage) []string {
+	t.Helper()
+	page, err := s.QueryExecutions(ExecutionQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return executionIDs(page.Executions)
+}
+
+func TestJSONExecutionIndex(t *testing.T) {
+	dir := t.TempDir()
+	a := openTestStorage(t, dir)
+	b := openTestStorage(t, dir)
+	for _, id := range []string{"exec-1", "exec-2", "exec-3"} {
+		if err := a.SaveExecution(`{"id":"` + id + `","flowId":"flow-1","status":"success","startedAt":"2026-01-01T00:00:00Z"}`); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := a.SaveExecution(`{"id":"exec-1","flowId":"flow-1","status":"error","startedAt":"2026-01-01T00:00:00Z"}`); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.DeleteExecution("exec-2"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another process sharing the data directory reads the appended lines
+	if got := listedExecutions(t, b); len(got) != 2 || got[0] != "exec-3" || got[1] != "exec-1" {
+		t.Fatalf("listed %v, want [exec-3 exec-1]", got)
+	}
+	page, _ := b.QueryExecutions(ExecutionQuery{Status: "error"})
+	if page.Total != 1 {
+		t.Errorf("the resaved execution isn't updated in the index: %+v", page)
+	}
+
+	// Files written behind ForgeFlow's back are picked up when the storage
+	// is opened, and the index is compacted
+	execDir := filepath.Join(dir, "executions")
+	os.WriteFile(filepath.Join(execDir, "exec-4.json"), []byte(`{"id":"exec-4","flowId":"flow-1","status":"success","startedAt":"2026-01-02T00:00:00Z"}`), 0644)
+	os.WriteFile(filepath.Join(execDir, "exec-5.json"), []byte(`{"id":`), 0644)
+	if got := listedExecutions(t, a); len(got) != 2 {
+		t.Errorf("listed %v before reopening, want the indexed executions only", got)
+	}
+	c := openTestStorage(t, dir)
+	if got := listedExecutions(t, c); len(got) != 3 || got[0] != "exec-4" {
+		t.Errorf("listed %v after reopening, want exec-4 added", got)
+	}
+	index, err := os.ReadFile(filepath.Join(execDir, executionIndexFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := bytes.Count(index, []byte("\n")); lines != 3 {
+		t.Errorf("index has %d lines after compaction, want 3", lines)
+	}
+	if _, err := os.Stat(filepath.Join(execDir, "exec-5.json")); !os.IsNotExist(err) {
+		t.Error("unreadable execution wasn't quarantined")
+	}
+}
+
+func TestJSONExecutionIndexMissing(t *testing.T) {
+	dir := t.TempDir()
+	s := openTestStorage(t, dir)
+	for _, id := range []string{"exec-1", "exec-2"} {
+		if err := s.SaveExecution(`{"id":"` + id + `","flowId":"flow-1","status":"success","startedAt":"2026-01-01T00:00:00Z"}`); err != nil {
+			t.Fatal(err)
+		}
+	}
+	indexPath := filepath.Join(dir, "executions", executionIndexFile)
+	if err := os.Remove(indexPath); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reads scan the directory without writing the index
+	if got := listedExecutions(t, s); len(got) != 2 {
+		t.Errorf("listed %v without an index, want both executions", got)
+	}
+	if _, err := os.Stat(indexPath); !os.IsNotExist(err) {
+		t.Error("a query wrote the index")
+	}
+
+	// The next save indexes the whole directory
+	if err := s.SaveExecution(`{"id":"exec-3","flowId":"flow-1","status":"success","startedAt":"2026-01-01T00:00:00Z"}`); err != nil {
+		t.Fatal(err)
+	}
+	index, err := os.ReadFile(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := bytes.Count(index, []byte("\n")); lines != 3 {
+		t.Errorf("index has %d lines, want 3", lines)
+	}
+}
+
+func TestJSONQuarantineOnOpen(t *testing.T) {
+	dir := t.TempDir()
+	s := openTestStorage(t, dir)
+	saveTestFlow(t, s, "flow-1", "Good")
+	bad := filepath.Join(dir, "flows", "flow-2.json")
+	if err := os.WriteFile(bad, []byte(`{"id":"flow-2",`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Listing only holds the read lock, so it skips the file without moving it
+	flows, err := s.ListFlows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flows) != 1 {
+		t.Errorf("listed %d flows, want 1", len(flows))
+	}
+	if _, err := os.Stat(bad); err != nil {
+		t.Errorf("listing moved the unreadable flow: %v", err)
+	}
+
+	openTestStorage(t, dir)
+	if _, err := os.Stat(bad); !os.IsNotExist(err) {
+		t.Error("unreadable flow wasn't quarantined when the storage was opened")
+	}
+	quarantined, _ := filepath.Glob(filepath.Join(dir, "flows", "quarantine", "flow-2.json.*"))
+	if len(quarantined) != 1 {
+		t.Errorf("quarantine holds %v", quarantined)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Log levels, ordered by severity. "success" ranks with "info", matching the
+// levels used by the frontend executor.
+const (
+	LogDebug   = "debug"
+	LogInfo    = "info"
+	LogSuccess = "success"
+	LogWarn    = "warn"
+	LogError   = "error"
+)
+
+var logSeverity = map[string]int{
+	LogDebug:   0,
+	LogInfo:    1,
+	LogSuccess: 1,
+	LogWarn:    2,
+	LogError:   3,
+}
+
+type LogEntry struct {
+	Timestamp string                 `json:"timestamp"`
+	Level     string                 `json:"level"`
+	NodeID    string                 `json:"nodeId,omitempty"`
+	Message   string                 `json:"message"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+}
+
+type LogPage struct {
+	Entries []LogEntry `json:"entries"`
+	Total   int        `json:"total"`
+	Offset  int        `json:"offset"`
+	Limit   int        `json:"limit"`
+}
+
+// ExecutionLog is the structured log stream of one execution. Entries are
+// appended to executions/<execID>.logs.jsonl as they are written, so logs
+// of a running execution can already be queried.
+type ExecutionLog struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+
+	// onWrite also receives every entry, e.g. to return them to the editor
+	onWrite func(LogEntry)
+}
+
+// Logger writes to an execution log, optionally scoped to a node. A nil
+// Logger discards everything, so callers never need to check.
+type Logger struct {
+	log    *ExecutionLog
+	nodeID string
+	redact *redactor // masks secrets resolved during the execution
+}
+
+func newExecutionLog(file *os.File) *ExecutionLog {
+	return &ExecutionLog{file: file, enc: json.NewEncoder(file)}
+}
+
+func (l *ExecutionLog) write(entry LogEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.onWrite != nil {
+		l.onWrite(entry)
+	}
+	if l.file == nil {
+		return
+	}
+	if err := l.enc.Encode(entry); err != nil {
+		fmt.Printf("Failed to write execution log: %v\n", err)
+	}
+}
+
+func (l *ExecutionLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// Logger returns a logger for engine-level entries
+func (l *ExecutionLog) Logger() *Logger {
+	return &Logger{log: l}
+}
+
+// Node returns a logger whose entries are attributed to nodeID
+func (lg *Logger) Node(nodeID string) *Logger {
+	if lg == nil {
+		return nil
+	}
+	return &Logger{log: lg.log, nodeID: nodeID, redact: lg.redact}
+}
+
+// withRedactor returns a logger that masks the secret values known to r in
+// messages and fields
+func (lg *Logger) withRedactor(r *redactor) *Logger {
+	if lg == nil {
+		return nil
+	}
+	return &Logger{log: lg.log, nodeID: lg.nodeID, redact: r}
+}
+
+// Log records an entry. keyvals are alternating field names and values.
+func (lg *Logger) Log(level, message string, keyvals ...interface{}) {
+	if lg == nil || lg.log == nil {
+		return
+	}
+	entry := LogEntry{
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Level:     level,
+		NodeID:    lg.nodeID,
+		Message:   lg.redact.String(message),
+	}
+	if len(keyvals) > 0 {
+		entry.Fields = make(map[string]interface{}, len(keyvals)/2)
+		for i := 0; i+1 < len(keyvals); i += 2 {
+			entry.Fields[fmt.Sprint(keyvals[i])] = lg.redact.Value(keyvals[i+1])
+		}
+	}
+	lg.log.write(entry)
+}
+
+func (lg *Logger) Debug(message string, keyvals ...interface{}) {
+	lg.Log(LogDebug, message, keyvals...)
+}
+
+func (lg *Logger) Info(message string, keyvals ...interface{}) {
+	lg.Log(LogInfo, message, keyvals...)
+}
+
+func (lg *Logger) Warn(message string, keyvals ...interface{}) {
+	lg.Log(LogWarn, message, keyvals...)
+}
+
+func (lg *Logger) Error(message string, keyvals ...interface{}) {
+	lg.Log(LogError, message, keyvals...)
+}
+
+type loggerContextKey struct{}
+
+func contextWithLogger(ctx context.Context, lg *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, lg)
+}
+
+// loggerFromContext returns the logger of the executing node, or nil
+func loggerFromContext(ctx context.Context) *Logger {
+	lg, _ := ctx.Value(loggerContextKey{}).(*Logger)
+	return lg
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"embed"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/wailsapp/wails/v2"
@@ -18,12 +19,19 @@ var assets embed.FS
 func main() {
 	start := time.Now()
 
+	// Headless subcommands (e.g. `ForgeFlow test`) run without a window
+	if handled, code := runCLI(os.Args[1:]); handled {
+		os.Exit(code)
+	}
+
 	// 1. Create service instances (Fast: just memory allocation)
 	app := NewApp()
 	storage := NewStorage()
 	engine := NewEngine(storage)
 	triggerManager := NewTriggerManager(engine, storage)
-	actionService := NewActionService(app, storage)
+	storage.onFlowsChanged = triggerManager.reconcileFlowTriggers
+	storage.nodeTypeSpec = engine.nodeTypeSpec
+	actionService := NewActionService(app, storage, engine)
 	excelService := NewExcelService()
 
 	// 2. Launch Wails window immediately
@@ -50,13 +58,17 @@ func main() {
 			// This allows the splash screen to show UP instantly.
 			go func() {
 				storage.Init()
+				engine.initTracing()
+				engine.initPlugins()
 				triggerManager.StartAllTriggers()
 				fmt.Printf("✅ ForgeFlow Engine ready in %v\n", time.Since(start))
 			}()
 		},
 		OnShutdown: func(ctx context.Context) {
 			triggerManager.Shutdown()
+			engine.closePlugins()
 			app.shutdown(ctx)
+			storage.close()
 		},
 		Bind: []interface{}{
 			app,
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics is a minimal Prometheus-compatible registry rendering the text
+// exposition format. Counters and histograms are updated by the engine and
+// trigger manager; gauges are computed at scrape time.
+type Metrics struct {
+	mu       sync.RWMutex
+	families []*metricFamily
+	byName   map[string]*metricFamily
+}
+
+type metricFamily struct {
+	name       string
+	help       string
+	kind       string // counter, histogram or gauge
+	labelNames []string
+	buckets    []float64
+
+	mu      sync.Mutex
+	series  map[string]*metricSeries
+	collect func() map[string]float64 // gauges: label values joined by labelSep
+}
+
+type metricSeries struct {
+	labels []string
+	value  float64
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+const labelSep = "\xff"
+
+var defaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// metrics is the process-wide registry served on /metrics
+var metrics = NewMetrics()
+
+var (
+	metricExecutionsStarted = metrics.counter("forgeflow_executions_started_total",
+		"Flow executions started.", "flow")
+	metricExecutionsFinished = metrics.counter("forgeflow_executions_finished_total",
+		"Flow executions finished, by final status.", "flow", "status")
+	metricNodeDuration = metrics.histogram("forgeflow_node_duration_seconds",
+		"Node execution duration.", defaultDurationBuckets, "node_type")
+	metricWebhookRequests = metrics.counter("forgeflow_webhook_requests_total",
+		"Webhook HTTP requests, by method, path and response code.", "method", "path", "code")
+	metricWebhookLatency = metrics.histogram("forgeflow_webhook_request_duration_seconds",
+		"Webhook HTTP request latency.", defaultDurationBuckets, "method", "path")
+	metricFileWatcherEvents = metrics.counter("forgeflow_file_watcher_events_total",
+		"File system events received by file watcher triggers.", "flow", "op")
+)
+
+func NewMetrics() *Metrics {
+	return &Metrics{byName: make(map[string]*metricFamily)}
+}
+
+func (m *Metrics) register(f *metricFamily) *metricFamily {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existing, ok := m.byName[f.name]; ok {
+		// Re-registering a gauge replaces its collector (e.g. a new Engine)
+		existing.mu.Lock()
+		existing.collect = f.collect
+		existing.mu.Unlock()
+		return existing
+	}
+	f.series = make(map[string]*metricSeries)
+	m.families = append(m.families, f)
+	m.byName[f.name] = f
+	return f
+}
+
+func (m *Metrics) counter(name, help string, labels ...string) *metricFamily {
+	return m.register(&metricFamily{name: name, help: help, kind: "counter", labelNames: labels})
+}
+
+func (m *Metrics) histogram(name, help string, buckets []float64, labels ...string) *metricFamily {
+	return m.register(&metricFamily{name: name, help: help, kind: "histogram", labelNames: labels, buckets: buckets})
+}
+
+// gaugeFunc registers a gauge whose values are read from collect on each
+// scrape. Keys of the returned map are label values joined with labelSep.
+func (m *Metrics) gaugeFunc(name, help string, collect func() map[string]float64, labels ...string) *metricFamily {
+	return m.register(&metricFamily{name: name, help: help, kind: "gauge", labelNames: labels, collect: collect})
+}
+
+func (f *metricFamily) get(labels []string) *metricSeries {
+	key := strings.Join(labels, labelSep)
+	s, ok := f.series[key]
+	if !ok {
+		s = &metricSeries{labels: append([]string(nil), labels...)}
+		if f.kind == "histogram" {
+			s.counts = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// Inc adds one to a counter
+func (f *metricFamily) Inc(labels ...string) {
+	f.mu.Lock()
+	f.get(labels).value++
+	f.mu.Unlock()
+}
+
+// Observe records a value in a histogram
+func (f *metricFamily) Observe(v float64, labels ...string) {
+	f.mu.Lock()
+	s := f.get(labels)
+	for i, b := range f.buckets {
+		if v <= b {
+			s.counts[i]++
+		}
+	}
+	s.sum += v
+	s.count++
+	f.mu.Unlock()
+}
+
+// ObserveSince records the seconds elapsed since start
+func (f *metricFamily) ObserveSince(start time.Time, labels ...string) {
+	f.Observe(time.Since(start).Seconds(), labels...)
+}
+
+// WriteTo renders all metrics in the Prometheus text exposition format
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.RLock()
+	families := append([]*metricFamily(nil), m.families...)
+	m.mu.RUnlock()
+
+	var b strings.Builder
+	for _, f := range families {
+		f.write(&b)
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func (f *metricFamily) write(b *strings.Builder) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fmt.Fprintf(b, "# HELP %s %s\n", f.name, f.help)
+	fmt.Fprintf(b, "# TYPE %s %s\n", f.name, f.kind)
+
+	if f.collect != nil {
+		values := f.collect()
+		keys := make([]string, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			var labels []string
+			if len(f.labelNames) > 0 {
+				labels = strings.Split(k, labelSep)
+			}
+			fmt.Fprintf(b, "%s%s %s\n", f.name, formatLabels(f.labelNames, labels, "", ""), formatFloat(values[k]))
+		}
+		return
+	}
+
+	keys := make([]string, 0, len(f.series))
+	for k := range f.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		s := f.series[k]
+		if f.kind != "histogram" {
+			fmt.Fprintf(b, "%s%s %s\n", f.name, formatLabels(f.labelNames, s.labels, "", ""), formatFloat(s.value))
+			continue
+		}
+		for i, bound := range f.buckets {
+			fmt.Fprintf(b, "%s_bucket%s %d\n", f.name, formatLabels(f.labelNames, s.labels, "le", formatFloat(bound)), s.counts[i])
+		}
+		fmt.Fprintf(b, "%s_bucket%s %d\n", f.name, formatLabels(f.labelNames, s.labels, "le", "+Inf"), s.count)
+		fmt.Fprintf(b, "%s_sum%s %s\n", f.name, formatLabels(f.labelNames, s.labels, "", ""), formatFloat(s.sum))
+		fmt.Fprintf(b, "%s_count%s %d\n", f.name, formatLabels(f.labelNames, s.labels, "", ""), s.count)
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	var parts []string
+	for i, name := range names {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		parts = append(parts, fmt.Sprintf("%s=\"%s\"", name, labelEscaper.Replace(value)))
+	}
+	if extraName != "" {
+		parts = append(parts, fmt.Sprintf("%s=\"%s\"", extraName, labelEscaper.Replace(extraValue)))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Handler serves the registry for Prometheus scrapes
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.WriteTo(w)
+	})
+}
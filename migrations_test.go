@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// baselineFlow is a flow as saved by the first release: no schemaVersion,
+// React Flow nodes of type "custom" with the node type in data.nodeType,
+// and edges with null handles
+const baselineFlow = `{
+  "id": "flow-1700000000000",
+  "name": "Morning report",
+  "description": "",
+  "nodes": [
+    {"id": "trigger-1700000000001", "type": "custom", "position": {"x": 100, "y": 100},
+     "data": {"label": "Schedule", "category": "trigger", "icon": "⏰", "description": "Run on a schedule", "nodeType": "trigger_schedule", "config": {"cron": "0 8 * * *"}, "status": "idle"}},
+    {"id": "action-1700000000002", "type": "custom", "position": {"x": 100, "y": 250},
+     "data": {"label": "Fetch", "category": "action", "icon": "🌐", "description": "Make an HTTP request", "nodeType": "action_http", "config": {"url": "https://example.com/report", "method": "GET"}, "status": "idle"}}
+  ],
+  "edges": [
+    {"id": "e1", "source": "trigger-1700000000001", "target": "action-1700000000002", "sourceHandle": null, "targetHandle": null, "type": "smoothstep", "animated": true}
+  ],
+  "enabled": true,
+  "createdAt": "2025-06-01T08:00:00.000Z",
+  "updatedAt": "2025-06-01T08:00:00.000Z"
+}`
+
+func TestLoadBaselineFlow(t *testing.T) {
+	dir := t.TempDir()
+	s := openTestStorage(t, dir)
+	flowsDir := filepath.Join(dir, "flows")
+	if err := os.WriteFile(filepath.Join(flowsDir, "flow-1700000000000.json"), []byte(baselineFlow), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := s.LoadFlow("flow-1700000000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded != baselineFlow {
+		t.Errorf("baseline flow was rewritten on load:\n%s", loaded)
+	}
+	if _, err := os.Stat(filepath.Join(flowsDir, "backups")); !os.IsNotExist(err) {
+		t.Error("a baseline flow was backed up as if it had been migrated")
+	}
+
+	var flow Flow
+	if err := json.Unmarshal([]byte(loaded), &flow); err != nil {
+		t.Fatal(err)
+	}
+	if len(flow.Nodes) != 2 || flow.Nodes[1].Data.NodeType != "action_http" || flow.Nodes[1].Data.Config["url"] != "https://example.com/report" {
+		t.Errorf("nodes = %+v", flow.Nodes)
+	}
+	problems, err := NewEngine(s).ValidateFlow(loaded)
+	if err != nil || len(problems) != 0 {
+		t.Errorf("ValidateFlow = %v, %v", problems, err)
+	}
+
+	// Saving it again stamps the current version
+	if _, err := s.SaveFlow(loaded); err != nil {
+		t.Fatal(err)
+	}
+	saved, _ := s.LoadFlow("flow-1700000000000")
+	var data map[string]interface{}
+	json.Unmarshal([]byte(saved), &data)
+	if data["schemaVersion"] != float64(FlowSchemaVersion) {
+		t.Errorf("schemaVersion = %v", data["schemaVersion"])
+	}
+}
+
+func TestNewerFlowRefused(t *testing.T) {
+	dir := t.TempDir()
+	s := openTestStorage(t, dir)
+	newer := `{"id":"flow-new","name":"From the future","schemaVersion":99,"nodes":[],"edges":[]}`
+	if err := os.WriteFile(filepath.Join(dir, "flows", "flow-new.json"), []byte(newer), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.LoadFlow("flow-new"); err == nil || !strings.Contains(err.Error(), "update ForgeFlow") {
+		t.Errorf("LoadFlow error = %v", err)
+	}
+	if _, err := s.SaveFlow(newer); err == nil {
+		t.Error("SaveFlow accepted a newer schema version")
+	}
+	if _, err := s.ImportFlowWithReport(newer); err == nil {
+		t.Error("import accepted a newer schema version")
+	}
+}
@@ -0,0 +1,1012 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// n8n workflows are converted to ForgeFlow flows on import. Nodes with an
+// equivalent built-in type are mapped with their parameters; the others
+// become comment placeholders that keep the original parameters, so the flow
+// still runs through them and nothing is lost.
+
+type n8nWorkflow struct {
+	Name        string                                  `json:"name"`
+	Nodes       []n8nNode                               `json:"nodes"`
+	Connections map[string]map[string][][]n8nConnection `json:"connections"`
+}
+
+type n8nNode struct {
+	Name        string                 `json:"name"`
+	Type        string                 `json:"type"`
+	TypeVersion float64                `json:"typeVersion"`
+	Position    []float64              `json:"position"`
+	Parameters  map[string]interface{} `json:"parameters"`
+	Credentials map[string]interface{} `json:"credentials"`
+	Disabled    bool                   `json:"disabled"`
+}
+
+type n8nConnection struct {
+	Node  string `json:"node"`
+	Type  string `json:"type"`
+	Index int    `json:"index"`
+}
+
+// importMapping is a converted node. outputs overrides the catalog's output
+// handles, by output index.
+type importMapping struct {
+	nodeType string
+	config   map[string]interface{}
+	outputs  []string
+}
+
+type n8nMapper func(c *n8nConverter, node *n8nNode, p importParams) *importMapping
+
+// n8nMappers converts n8n node types, without the package prefix. A mapper
+// returns nil for settings it can't convert, making the node a placeholder.
+var n8nMappers = map[string]n8nMapper{
+	"manualTrigger":   n8nManualTrigger,
+	"start":           n8nManualTrigger,
+	"scheduleTrigger": n8nScheduleTrigger,
+	"cron":            n8nCronTrigger,
+	"webhook":         n8nWebhook,
+	"httpRequest":     n8nHTTPRequest,
+	"if":              n8nIf,
+	"switch":          n8nSwitch,
+	"set":             n8nSet,
+	"wait":            n8nWait,
+	"executeCommand":  n8nExecuteCommand,
+	"splitInBatches":  n8nSplitInBatches,
+	"splitOut":        n8nSplitOut,
+	"merge":           n8nMerge,
+	"noOp":            n8nNoOp,
+	"stickyNote":      n8nStickyNote,
+	"emailSend":       n8nEmailSend,
+	"slack":           n8nSlack,
+	"telegram":        n8nTelegram,
+	"discord":         n8nDiscord,
+	"rssFeedRead":     n8nRSSFeedRead,
+}
+
+// isN8nWorkflow tells n8n workflow JSON, including nodes copied from the
+// n8n editor, from a ForgeFlow flow
+func isN8nWorkflow(data []byte) bool {
+	var doc struct {
+		Nodes []struct {
+			Parameters json.RawMessage `json:"parameters"`
+		} `json:"nodes"`
+		Connections json.RawMessage `json:"connections"`
+		Edges       json.RawMessage `json:"edges"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return false
+	}
+	if doc.Edges != nil || doc.Connections == nil || len(doc.Nodes) == 0 {
+		return false
+	}
+	for _, node := range doc.Nodes {
+		if node.Parameters == nil {
+			return false
+		}
+	}
+	return true
+}
+
+type n8nConverter struct {
+	ids    map[string]string // n8n node name to ForgeFlow node ID
+	report *FlowImportReport
+	node   *n8nNode // being converted, for notes
+}
+
+// convertN8nWorkflow returns n8n workflow JSON as a ForgeFlow flow, adding
+// what couldn't be converted to report
+func convertN8nWorkflow(data []byte, report *FlowImportReport) ([]byte, error) {
+	var workflow n8nWorkflow
+	if err := json.Unmarshal(data, &workflow); err != nil {
+		return nil, fmt.Errorf("invalid n8n workflow JSON: %w", err)
+	}
+	report.Format = "n8n"
+
+	c := &n8nConverter{ids: make(map[string]string, len(workflow.Nodes)), report: report}
+	nodes := make([]FlowNode, 0, len(workflow.Nodes))
+	mappings := make(map[string]*importMapping, len(workflow.Nodes))
+	// IDs first, as expressions can refer to any node
+	for i, node := range workflow.Nodes {
+		if _, dup := c.ids[node.Name]; dup {
+			return nil, fmt.Errorf("invalid n8n workflow: two nodes are named %q", node.Name)
+		}
+		c.ids[node.Name] = fmt.Sprintf("node-%d", i+1)
+	}
+
+	for i := range workflow.Nodes {
+		node := &workflow.Nodes[i]
+		c.node = node
+
+		var mapping *importMapping
+		if name, ok := strings.CutPrefix(node.Type, "n8n-nodes-base."); ok && !node.Disabled {
+			if mapper, ok := n8nMappers[name]; ok {
+				mapping = mapper(c, node, importParams(node.Parameters))
+			}
+		}
+		if node.Disabled {
+			// n8n passes items through a disabled node, as the placeholder does
+			mapping = c.placeholder(node, "is disabled in n8n")
+		} else if mapping == nil {
+			mapping = c.placeholder(node, "has no ForgeFlow equivalent")
+		} else {
+			report.Nodes++
+			if len(node.Credentials) > 0 {
+				names := make([]string, 0, len(node.Credentials))
+				for name := range node.Credentials {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				c.warn("n8n credentials (%s) aren't imported; fill in the node's connection settings", strings.Join(names, ", "))
+			}
+		}
+		mappings[node.Name] = mapping
+
+		var x, y float64
+		if len(node.Position) == 2 {
+			x, y = node.Position[0], node.Position[1]
+		}
+		nodes = append(nodes, importedFlowNode(c.ids[node.Name], node.Name, x, y, mapping.nodeType, mapping.config))
+	}
+	c.node = nil
+
+	edges := c.edges(&workflow, mappings)
+	edges = c.dropLoopBacks(&workflow, edges)
+
+	name := workflow.Name
+	if name == "" {
+		name = "Imported n8n workflow"
+	}
+	flow := Flow{
+		SchemaVersion: FlowSchemaVersion,
+		Name:          name,
+		Description:   "Imported from n8n",
+		Nodes:         nodes,
+		Edges:         edges,
+	}
+	return json.Marshal(flow)
+}
+
+// shellSyntax matches what only a shell understands in a command line:
+// pipes, redirects, command lists, quotes, expansions and globs
+var shellSyntax = regexp.MustCompile("[|&;<>()$`\\\\\"'*?\\[\\]~#\n]")
+
+// importedCommand converts a command line that n8n or Node-RED runs
+// through a shell to a Run Script config. Run Script starts the program
+// itself with space-separated arguments, so a line using shell syntax can't
+// be converted.
+func importedCommand(line string) (map[string]interface{}, bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || shellSyntax.MatchString(line) {
+		return nil, false
+	}
+	return map[string]interface{}{
+		"command": fields[0],
+		"args":    strings.Join(fields[1:], " "),
+	}, true
+}
+
+// importedFlowNode returns a node of a built-in type, with config over the
+// type's defaults
+func importedFlowNode(id, label string, x, y float64, nodeType string, config map[string]interface{}) FlowNode {
+	spec := builtinNodeTypeIndex[nodeType]
+	merged := make(map[string]interface{}, len(spec.Defaults)+len(config))
+	for key, value := range spec.Defaults {
+		merged[key] = value
+	}
+	for key, value := range config {
+		merged[key] = value
+	}
+
+	var node FlowNode
+	node.ID = id
+	node.Type = "custom"
+	node.Position.X = x
+	node.Position.Y = y
+	node.Data.Label = label
+	node.Data.Category = spec.Category
+	node.Data.Icon = spec.Icon
+	node.Data.Description = spec.Description
+	node.Data.NodeType = spec.Type
+	node.Data.Config = merged
+	return node
+}
+
+// edges converts the connections between nodes. n8n numbers outputs and
+// inputs; ForgeFlow names them, so each index becomes the handle of the
+// node type at that position.
+func (c *n8nConverter) edges(workflow *n8nWorkflow, mappings map[string]*importMapping) []FlowEdge {
+	edges := []FlowEdge{}
+	for i := range workflow.Nodes {
+		source := &workflow.Nodes[i]
+		c.node = source
+		byType := workflow.Connections[source.Name]
+		kinds := make([]string, 0, len(byType))
+		for kind := range byType {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+
+		for _, kind := range kinds {
+			if kind != "main" {
+				c.warn("%s connections aren't supported and were left out", kind)
+				continue
+			}
+			for output, targets := range byType[kind] {
+				for _, conn := range targets {
+					target, ok := mappings[conn.Node]
+					if !ok {
+						c.warn("the connection to unknown node %q was left out", conn.Node)
+						continue
+					}
+					inputs := builtinNodeTypeIndex[target.nodeType].Inputs
+					if len(inputs) == 0 {
+						c.warn("the connection to %q was left out: it takes no input", conn.Node)
+						continue
+					}
+					targetHandle := inputs[len(inputs)-1].ID
+					if conn.Index < len(inputs) {
+						targetHandle = inputs[conn.Index].ID
+					} else {
+						c.warn("input %d of %q doesn't exist in ForgeFlow; connected to %q", conn.Index+1, conn.Node, targetHandle)
+					}
+					edges = append(edges, FlowEdge{
+						ID:           fmt.Sprintf("edge-%d", len(edges)+1),
+						Source:       c.ids[source.Name],
+						Target:       c.ids[conn.Node],
+						SourceHandle: c.outputHandle(mappings[source.Name], output),
+						TargetHandle: targetHandle,
+					})
+				}
+			}
+		}
+	}
+	c.node = nil
+	return edges
+}
+
+func (c *n8nConverter) outputHandle(mapping *importMapping, output int) string {
+	handle, ok := mapping.outputHandle(output)
+	if !ok {
+		c.warn("output %d doesn't exist in ForgeFlow; its connections start from %q", output+1, handle)
+	}
+	return handle
+}
+
+// outputHandle names output i of a converted node. Past its last output,
+// ok is false and the last output is returned.
+func (m *importMapping) outputHandle(i int) (handle string, ok bool) {
+	handles := m.outputs
+	if handles == nil {
+		for _, h := range builtinNodeTypeIndex[m.nodeType].Outputs {
+			handles = append(handles, h.ID)
+		}
+	}
+	if len(handles) == 0 {
+		return "out", i == 0
+	}
+	if i < len(handles) {
+		return handles[i], true
+	}
+	return handles[len(handles)-1], false
+}
+
+// dropLoopBacks removes the connections that lead back into a converted
+// Loop Over Items node. n8n loops by feeding each batch back in, while For
+// Each runs its loop branch once per item by itself.
+func (c *n8nConverter) dropLoopBacks(workflow *n8nWorkflow, edges []FlowEdge) []FlowEdge {
+	next := make(map[string][]string)
+	for _, edge := range edges {
+		next[edge.Source] = append(next[edge.Source], edge.Target)
+	}
+	names := make(map[string]*n8nNode, len(workflow.Nodes))
+	for i := range workflow.Nodes {
+		names[c.ids[workflow.Nodes[i].Name]] = &workflow.Nodes[i]
+	}
+
+	kept := edges[:0]
+	for _, edge := range edges {
+		target := names[edge.Target]
+		if target.Type == "n8n-nodes-base.splitInBatches" && reachable(next, edge.Target, edge.Source) {
+			c.node = target
+			c.warn("the connection back from %q was removed: For Each runs its loop branch for every item", names[edge.Source].Name)
+			continue
+		}
+		kept = append(kept, edge)
+	}
+	c.node = nil
+	return kept
+}
+
+func reachable(next map[string][]string, from, to string) bool {
+	seen := map[string]bool{from: true}
+	queue := []string{from}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if id == to {
+			return true
+		}
+		for _, n := range next[id] {
+			if !seen[n] {
+				seen[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	return false
+}
+
+// placeholder stands in for a node that wasn't converted, for reason (e.g.
+// "is disabled in n8n"). The comment passes its input through; the n8n
+// parameters are kept in the config to rebuild it from.
+func (c *n8nConverter) placeholder(node *n8nNode, reason string) *importMapping {
+	comment := fmt.Sprintf("n8n node %q (%s) %s and wasn't converted.", node.Name, node.Type, reason)
+	p := importParams(node.Parameters)
+	if code := firstString(p.str("jsCode"), p.str("pythonCode"), p.str("functionCode")); code != "" {
+		comment += "\n\n" + code
+	}
+	c.report.Placeholders = append(c.report.Placeholders, ImportNote{
+		Node:    node.Name,
+		Type:    node.Type,
+		Message: reason + "; imported as a comment",
+	})
+	return &importMapping{
+		nodeType: "util_comment",
+		config: map[string]interface{}{
+			"comment":       comment,
+			"color":         "#f59e0b",
+			"n8nType":       node.Type,
+			"n8nParameters": node.Parameters,
+		},
+	}
+}
+
+func (c *n8nConverter) warn(format string, args ...interface{}) {
+	note := ImportNote{Message: fmt.Sprintf(format, args...)}
+	if c.node != nil {
+		note.Node = c.node.Name
+		note.Type = c.node.Type
+	}
+	c.report.Warnings = append(c.report.Warnings, note)
+}
+
+var (
+	n8nExpressionBlock = regexp.MustCompile(`\{\{\s*(.*?)\s*\}\}`)
+	n8nPathKey         = regexp.MustCompile(`\[(?:"([^"]*)"|'([^']*)')\]`)
+)
+
+const n8nPathTail = `((?:\.[A-Za-z_$][\w$]*|\[\d+\]|\[(?:"[^"]*"|'[^']*')\])*)`
+
+var (
+	n8nJSONRef = regexp.MustCompile(`^\$(?:json|input\.(?:item|first\(\)|last\(\))\.json)` + n8nPathTail + `$`)
+	n8nNodeRef = regexp.MustCompile(`^(?:\$node\[(?:"([^"]+)"|'([^']+)')\]|\$\((?:"([^"]+)"|'([^']+)')\)\.(?:item|first\(\)|last\(\)))\.json` + n8nPathTail + `$`)
+	n8nEnvRef  = regexp.MustCompile(`^\$(?:env|vars)` + n8nPathTail + `$`)
+)
+
+// expression converts an n8n parameter value. Values starting with "=" are
+// expressions: references to the incoming item, another node's output and
+// environment variables become ForgeFlow variables, anything else is kept
+// as it is and reported.
+func (c *n8nConverter) expression(value string) string {
+	expr, ok := strings.CutPrefix(value, "=")
+	if !ok {
+		return value
+	}
+	return n8nExpressionBlock.ReplaceAllStringFunc(expr, func(block string) string {
+		inner := n8nExpressionBlock.FindStringSubmatch(block)[1]
+		if variable, ok := c.variable(inner); ok {
+			return "{{" + variable + "}}"
+		}
+		c.warn("the expression {{ %s }} was kept as it is; rewrite it with ForgeFlow variables", inner)
+		return block
+	})
+}
+
+func (c *n8nConverter) variable(expr string) (string, bool) {
+	if m := n8nJSONRef.FindStringSubmatch(expr); m != nil {
+		return joinVariablePath("output", n8nPath(m[1])), true
+	}
+	if m := n8nNodeRef.FindStringSubmatch(expr); m != nil {
+		id := c.ids[m[1]+m[2]+m[3]+m[4]]
+		if id == "" {
+			return "", false
+		}
+		return joinVariablePath("node_"+id, n8nPath(m[5])), true
+	}
+	if m := n8nEnvRef.FindStringSubmatch(expr); m != nil && m[1] != "" {
+		return n8nPath(m[1]), true
+	}
+	return "", false
+}
+
+// n8nPath turns a JavaScript member chain such as .a["b"][0] into a
+// ForgeFlow path, a.b[0]
+func n8nPath(tail string) string {
+	return strings.TrimPrefix(n8nPathKey.ReplaceAllString(tail, ".$1$2"), ".")
+}
+
+func joinVariablePath(base, path string) string {
+	switch {
+	case path == "":
+		return base
+	case strings.HasPrefix(path, "["):
+		return base + path
+	default:
+		return base + "." + path
+	}
+}
+
+// text is a converted string parameter
+func (c *n8nConverter) text(p importParams, key string) string {
+	return c.expression(p.str(key))
+}
+
+// importParams are the settings of a node from another tool, read
+// leniently: a missing or mistyped value reads as empty
+type importParams map[string]interface{}
+
+func (p importParams) str(key string) string {
+	switch v := p[key].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
+
+func (p importParams) num(key string, def float64) float64 {
+	switch v := p[key].(type) {
+	case float64:
+		return v
+	case string:
+		if n, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return n
+		}
+	}
+	return def
+}
+
+func (p importParams) obj(key string) importParams {
+	m, _ := p[key].(map[string]interface{})
+	return m
+}
+
+func (p importParams) list(key string) []importParams {
+	items, _ := p[key].([]interface{})
+	list := make([]importParams, 0, len(items))
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			list = append(list, m)
+		}
+	}
+	return list
+}
+
+func firstString(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func n8nManualTrigger(c *n8nConverter, node *n8nNode, p importParams) *importMapping {
+	return &importMapping{nodeType: "trigger_manual"}
+}
+
+func n8nScheduleTrigger(c *n8nConverter, node *n8nNode, p importParams) *importMapping {
+	rules := p.obj("rule").list("interval")
+	if len(rules) > 1 {
+		c.warn("only the first of %d schedule rules was converted", len(rules))
+	}
+	rule := importParams{}
+	if len(rules) > 0 {
+		rule = rules[0]
+	}
+	minute := int(rule.num("triggerAtMinute", 0))
+	hour := int(rule.num("triggerAtHour", 0))
+
+	var cron string
+	switch rule.str("field") {
+	case "cronExpression":
+		cron = c.cronExpression(rule.str("expression"))
+	case "seconds":
+		cron = "* * * * *"
+		c.warn("runs every minute: cron schedules can't repeat more often")
+	case "minutes":
+		cron = fmt.Sprintf("%s * * * *", cronEvery(rule.num("minutesInterval", 5)))
+	case "hours":
+		cron = fmt.Sprintf("%d %s * * *", minute, cronEvery(rule.num("hoursInterval", 1)))
+	case "weeks":
+		days := []string{}
+		if values, ok := rule["triggerAtDay"].([]interface{}); ok {
+			for _, day := range values {
+				days = append(days, fmt.Sprint(day))
+			}
+		}
+		if len(days) == 0 {
+			days = []string{"0"}
+		}
+		if rule.num("weeksInterval", 1) > 1 {
+			c.warn("runs every week: cron schedules can't skip weeks")
+		}
+		cron = fmt.Sprintf("%d %d * * %s", minute, hour, strings.Join(days, ","))
+	case "months":
+		cron = fmt.Sprintf("%d %d %d %s *", minute, hour, int(rule.num("triggerAtDayOfMonth", 1)), cronEvery(rule.num("monthsInterval", 1)))
+	default: // days
+		cron = fmt.Sprintf("%d %d %s * *", minute, hour, cronEvery(rule.num("daysInterval", 1)))
+	}
+	return &importMapping{nodeType: "trigger_schedule", config: map[string]interface{}{"cron": cron}}
+}
+
+// n8nCronTrigger converts the legacy Cron node
+func n8nCronTrigger(c *n8nConverter, node *n8nNode, p importParams) *importMapping {
+	times := p.obj("triggerTimes").list("item")
+	if len(times) == 0 {
+		return nil
+	}
+	if len(times) > 1 {
+		c.warn("only the first of %d trigger times was converted", len(times))
+	}
+	t := times[0]
+	minute := int(t.num("minute", 0))
+	hour := int(t.num("hour", 14))
+
+	var cron string
+	switch t.str("mode") {
+	case "everyMinute":
+		cron = "* * * * *"
+	case "everyHour":
+		cron = fmt.Sprintf("%d * * * *", minute)
+	case "everyWeek":
+		cron = fmt.Sprintf("%d %d * * %d", minute, hour, int(t.num("weekday", 1)))
+	case "everyMonth":
+		cron = fmt.Sprintf("%d %d %d * *", minute, hour, int(t.num("dayOfMonth", 1)))
+	case "everyX":
+		if t.str("unit") == "hours" {
+			cron = fmt.Sprintf("0 %s * * *", cronEvery(t.num("value", 2)))
+		} else {
+			cron = fmt.Sprintf("%s * * * *", cronEvery(t.num("value", 2)))
+		}
+	case "custom":
+		cron = c.cronExpression(t.str("cronExpression"))
+	default: // everyDay
+		cron = fmt.Sprintf("%d %d * * *", minute, hour)
+	}
+	return &importMapping{nodeType: "trigger_schedule", config: map[string]interface{}{"cron": cron}}
+}
+
+// cronExpression drops the seconds field n8n allows
+func (c *n8nConverter) cronExpression(expr string) string {
+	fields := strings.Fields(expr)
+	if len(fields) == 6 {
+		c.warn("the seconds field of %q was dropped", expr)
+		fields = fields[1:]
+	}
+	return strings.Join(fields, " ")
+}
+
+func cronEvery(n float64) string {
+	if n <= 1 {
+		return "*"
+	}
+	return fmt.Sprintf("*/%d", int(n))
+}
+
+func n8nWebhook(c *n8nConverter, node *n8nNode, p importParams) *importMapping {
+	method := strings.ToUpper(firstString(p.str("httpMethod"), "GET"))
+	switch method {
+	case "GET", "POST", "PUT":
+	default:
+		c.warn("webhooks can't use %s; changed to POST", method)
+		method = "POST"
+	}
+	return &importMapping{nodeType: "trigger_webhook", config: map[string]interface{}{
+		"method": method,
+		"path":   "/" + strings.TrimPrefix(p.str("path"), "/"),
+	}}
+}
+
+func n8nHTTPRequest(c *n8nConverter, node *n8nNode, p importParams) *importMapping {
+	method := strings.ToUpper(firstString(p.str("method"), p.str("requestMethod"), "GET"))
+	switch method {
+	case "GET", "POST", "PUT", "DELETE":
+	default:
+		c.warn("HTTP requests can't use %s; changed to POST", method)
+		method = "POST"
+	}
+	if auth := p.str("authentication"); auth != "" && auth != "none" {
+		c.warn("%s authentication isn't imported; add the headers it needs", auth)
+	}
+
+	url := c.text(p, "url")
+	var query []string
+	for _, param := range append(p.obj("queryParameters").list("parameters"), p.obj("queryParametersUi").list("parameter")...) {
+		query = append(query, param.str("name")+"="+c.text(param, "value"))
+	}
+	if len(query) > 0 {
+		sep := "?"
+		if strings.Contains(url, "?") {
+			sep = "&"
+		}
+		url += sep + strings.Join(query, "&")
+	}
+
+	headers := "{}"
+	if p.str("specifyHeaders") == "json" {
+		headers = c.text(p, "jsonHeaders")
+	} else if params := append(p.obj("headerParameters").list("parameters"), p.obj("headerParametersUi").list("parameter")...); len(params) > 0 {
+		values := make(map[string]string, len(params))
+		for _, param := range params {
+			values[param.str("name")] = c.text(param, "value")
+		}
+		data, _ := json.Marshal(values)
+		headers = string(data)
+	}
+
+	body := firstString(c.text(p, "jsonBody"), c.text(p, "bodyParametersJson"), c.text(p, "body"))
+	if params := append(p.obj("bodyParameters").list("parameters"), p.obj("bodyParametersUi").list("parameter")...); body == "" && len(params) > 0 {
+		values := make(map[string]string, len(params))
+		for _, param := range params {
+			values[param.str("name")] = c.text(param, "value")
+		}
+		data, _ := json.Marshal(values)
+		body = string(data)
+	}
+
+	return &importMapping{nodeType: "action_http", config: map[string]interface{}{
+		"method":  method,
+		"url":     url,
+		"headers": headers,
+		"body":    body,
+	}}
+}
+
+// n8nIf turns the node's conditions into a condition expression. Version 1
+// groups conditions by value type; version 2 gives each its own operator.
+func n8nIf(c *n8nConverter, node *n8nNode, p importParams) *importMapping {
+	conditions := p.obj("conditions")
+	var parts []string
+	join := " && "
+	add := func(kind, operation string, left, right interface{}) {
+		if part, ok := c.comparison(kind, operation, left, right); ok {
+			parts = append(parts, part)
+		} else {
+			c.warn("the %s condition %q wasn't converted", kind, operation)
+		}
+	}
+
+	if node.TypeVersion >= 2 {
+		if strings.EqualFold(conditions.str("combinator"), "or") {
+			join = " || "
+		}
+		for _, cond := range conditions.list("conditions") {
+			operator := cond.obj("operator")
+			add(operator.str("type"), operator.str("operation"), cond["leftValue"], cond["rightValue"])
+		}
+	} else {
+		if p.str("combineOperation") == "any" {
+			join = " || "
+		}
+		for _, kind := range []string{"boolean", "number", "string", "dateTime"} {
+			for _, cond := range conditions.list(kind) {
+				add(kind, firstString(cond.str("operation"), "equal"), cond["value1"], cond["value2"])
+			}
+		}
+	}
+
+	condition := strings.Join(parts, join)
+	if condition == "" {
+		condition = "true"
+		c.warn("no condition could be converted; the node always takes the true branch")
+	}
+	return &importMapping{nodeType: "condition_if", config: map[string]interface{}{"condition": condition}}
+}
+
+// comparison renders one n8n condition as JavaScript for condition_if,
+// with the operation names of both If node versions
+func (c *n8nConverter) comparison(kind, operation string, left, right interface{}) (string, bool) {
+	a := c.operand(kind, left)
+	b := c.operand(kind, right)
+	switch operation {
+	case "equal", "equals":
+		return a + " === " + b, true
+	case "notEqual", "notEquals":
+		return a + " !== " + b, true
+	case "larger", "gt", "after":
+		return a + " > " + b, true
+	case "largerEqual", "gte", "afterOrEquals":
+		return a + " >= " + b, true
+	case "smaller", "lt", "before":
+		return a + " < " + b, true
+	case "smallerEqual", "lte", "beforeOrEquals":
+		return a + " <= " + b, true
+	case "contains":
+		return a + ".includes(" + b + ")", true
+	case "notContains":
+		return "!" + a + ".includes(" + b + ")", true
+	case "startsWith", "endsWith":
+		return a + "." + operation + "(" + b + ")", true
+	case "notStartsWith":
+		return "!" + a + ".startsWith(" + b + ")", true
+	case "notEndsWith":
+		return "!" + a + ".endsWith(" + b + ")", true
+	case "regex":
+		return "new RegExp(" + b + ").test(" + a + ")", true
+	case "notRegex":
+		return "!new RegExp(" + b + ").test(" + a + ")", true
+	case "true":
+		return a + " === true", true
+	case "false":
+		return a + " === false", true
+	case "isEmpty", "empty", "isNotEmpty", "notEmpty":
+		empty := a + " == null"
+		switch kind {
+		case "string":
+			empty = a + ` === ""`
+		case "array":
+			empty = a + ".length === 0"
+		}
+		if operation == "isNotEmpty" || operation == "notEmpty" {
+			return "!(" + empty + ")", true
+		}
+		return empty, true
+	}
+	return "", false
+}
+
+func (c *n8nConverter) operand(kind string, value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		if kind == "string" {
+			return `""`
+		}
+		return "null"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case string:
+		s := c.expression(v)
+		switch kind {
+		case "string", "dateTime":
+			return strconv.Quote(s)
+		}
+		if s == "" {
+			return "null"
+		}
+		return s
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// n8nSwitch maps the outputs; the routing rules have no equivalent, as a
+// ForgeFlow switch takes the branch its value names
+func n8nSwitch(c *n8nConverter, node *n8nNode, p importParams) *importMapping {
+	c.warn("the routing rules weren't converted: set Value to an expression giving case1, case2, case3 or default")
+	return &importMapping{nodeType: "condition_switch", config: map[string]interface{}{"value": ""}}
+}
+
+// n8nSet converts the first field the node sets; a ForgeFlow variable node
+// sets one
+func n8nSet(c *n8nConverter, node *n8nNode, p importParams) *importMapping {
+	if p.str("mode") == "raw" {
+		return &importMapping{nodeType: "action_json_parse", config: map[string]interface{}{"json": c.text(p, "jsonOutput")}}
+	}
+
+	type field struct {
+		name  string
+		value interface{}
+	}
+	var fields []field
+	add := func(name string, value interface{}) {
+		if s, ok := value.(string); ok {
+			value = c.expression(s)
+		}
+		fields = append(fields, field{name, value})
+	}
+	for _, kind := range []string{"string", "number", "boolean"} {
+		for _, v := range p.obj("values").list(kind) {
+			add(v.str("name"), v["value"])
+		}
+	}
+	for _, v := range p.obj("fields").list("values") {
+		add(v.str("name"), firstValue(v["stringValue"], v["numberValue"], v["booleanValue"], v["arrayValue"], v["objectValue"]))
+	}
+	for _, v := range p.obj("assignments").list("assignments") {
+		add(v.str("name"), v["value"])
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	if len(fields) > 1 {
+		names := make([]string, 0, len(fields)-1)
+		for _, f := range fields[1:] {
+			names = append(names, f.name)
+		}
+		c.warn("only %q was converted; add a Set Variable node for each of %s", fields[0].name, strings.Join(names, ", "))
+	}
+	value := fields[0].value
+	if value == nil {
+		value = ""
+	}
+	return &importMapping{nodeType: "action_set_variable", config: map[string]interface{}{
+		"name":  fields[0].name,
+		"value": value,
+	}}
+}
+
+func firstValue(values ...interface{}) interface{} {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// n8nWait converts waiting for a time interval; waiting for a webhook, a
+// form or a date has no equivalent
+func n8nWait(c *n8nConverter, node *n8nNode, p importParams) *importMapping {
+	if resume := p.str("resume"); resume != "" && resume != "timeInterval" {
+		return nil
+	}
+	unit := map[string]float64{
+		"seconds": 1000,
+		"minutes": 60 * 1000,
+		"hours":   60 * 60 * 1000,
+		"days":    24 * 60 * 60 * 1000,
+	}[firstString(p.str("unit"), "hours")]
+	if unit == 0 {
+		return nil
+	}
+	return &importMapping{nodeType: "action_delay", config: map[string]interface{}{
+		"duration": p.num("amount", 1) * unit,
+	}}
+}
+
+func n8nExecuteCommand(c *n8nConverter, node *n8nNode, p importParams) *importMapping {
+	line := c.text(p, "command")
+	if strings.TrimSpace(line) == "" {
+		return nil
+	}
+	config, ok := importedCommand(line)
+	if !ok {
+		c.warn("the command needs a shell, which Run Script doesn't use")
+		return nil
+	}
+	return &importMapping{nodeType: "action_script", config: config}
+}
+
+// n8nSplitInBatches converts Loop Over Items. Version 3 has a done output
+// before the loop output; older versions only loop.
+func n8nSplitInBatches(c *n8nConverter, node *n8nNode, p importParams) *importMapping {
+	if p.num("batchSize", 1) > 1 {
+		c.warn("batches of %d items become single items", int(p.num("batchSize", 1)))
+	}
+	outputs := []string{"loop", "done"}
+	if node.TypeVersion >= 3 {
+		outputs = []string{"done", "loop"}
+	}
+	return &importMapping{nodeType: "loop_foreach", outputs: outputs, config: map[string]interface{}{
+		"array": "{{output}}",
+	}}
+}
+
+func n8nSplitOut(c *n8nConverter, node *n8nNode, p importParams) *importMapping {
+	array := "{{output}}"
+	if field := p.str("fieldToSplitOut"); field != "" && !strings.Contains(field, ",") {
+		array = "{{" + joinVariablePath("output", field) + "}}"
+	} else if field != "" {
+		return nil
+	}
+	return &importMapping{nodeType: "loop_foreach", outputs: []string{"loop"}, config: map[string]interface{}{
+		"array": array,
+	}}
+}
+
+func n8nMerge(c *n8nConverter, node *n8nNode, p importParams) *importMapping {
+	mode := "array"
+	switch p.str("mode") {
+	case "", "append":
+	case "mergeByIndex", "mergeByPosition":
+		mode = "object"
+	case "combine", "combineByPosition":
+		if p.str("combineBy") == "combineByPosition" || p.str("combinationMode") == "mergeByPosition" || p.str("mode") == "combineByPosition" {
+			mode = "object"
+		} else {
+			c.warn("combining by matching fields isn't supported; the inputs are merged into an object")
+			mode = "object"
+		}
+	default:
+		c.warn("the %s mode isn't supported; the inputs are merged into an array", p.str("mode"))
+	}
+	return &importMapping{nodeType: "util_merge", config: map[string]interface{}{"mode": mode}}
+}
+
+func n8nNoOp(c *n8nConverter, node *n8nNode, p importParams) *importMapping {
+	return &importMapping{nodeType: "util_comment"}
+}
+
+func n8nStickyNote(c *n8nConverter, node *n8nNode, p importParams) *importMapping {
+	return &importMapping{nodeType: "util_comment", config: map[string]interface{}{"comment": p.str("content")}}
+}
+
+func n8nEmailSend(c *n8nConverter, node *n8nNode, p importParams) *importMapping {
+	body, isHTML := c.text(p, "text"), false
+	if format := p.str("emailFormat"); format == "html" || format == "both" || (format == "" && p.str("html") != "") {
+		body, isHTML = c.text(p, "html"), true
+	}
+	if options := p.obj("options"); options.str("ccEmail") != "" || options.str("bccEmail") != "" || p.str("ccEmail") != "" || p.str("bccEmail") != "" {
+		c.warn("CC and BCC recipients aren't supported and were left out")
+	}
+	return &importMapping{nodeType: "action_email", config: map[string]interface{}{
+		"from":    c.text(p, "fromEmail"),
+		"to":      c.text(p, "toEmail"),
+		"subject": c.text(p, "subject"),
+		"body":    body,
+		"isHtml":  isHTML,
+	}}
+}
+
+func n8nSlack(c *n8nConverter, node *n8nNode, p importParams) *importMapping {
+	if resource := p.str("resource"); resource != "" && resource != "message" {
+		return nil
+	}
+	if operation := p.str("operation"); operation != "" && operation != "post" {
+		return nil
+	}
+	if channel := firstString(p.str("channel"), p.obj("channelId").str("value")); channel != "" {
+		c.warn("messages go to the channel of the webhook rather than %s", channel)
+	}
+	return &importMapping{nodeType: "action_slack", config: map[string]interface{}{"text": c.text(p, "text")}}
+}
+
+func n8nTelegram(c *n8nConverter, node *n8nNode, p importParams) *importMapping {
+	if resource := p.str("resource"); resource != "" && resource != "message" {
+		return nil
+	}
+	if operation := p.str("operation"); operation != "" && operation != "sendMessage" {
+		return nil
+	}
+	config := map[string]interface{}{
+		"chatId":  c.text(p, "chatId"),
+		"message": c.text(p, "text"),
+	}
+	if parseMode := p.obj("additionalFields").str("parse_mode"); parseMode != "" {
+		config["parseMode"] = parseMode
+	}
+	return &importMapping{nodeType: "action_telegram", config: config}
+}
+
+func n8nDiscord(c *n8nConverter, node *n8nNode, p importParams) *importMapping {
+	if resource := p.str("resource"); resource != "" && resource != "message" {
+		return nil
+	}
+	options := p.obj("options")
+	return &importMapping{nodeType: "action_discord", config: map[string]interface{}{
+		"webhookUrl": c.text(p, "webhookUri"),
+		"content":    firstString(c.text(p, "text"), c.text(p, "content")),
+		"username":   firstString(c.text(p, "username"), c.text(options, "username")),
+		"avatarUrl":  firstString(c.text(p, "avatarUrl"), c.text(options, "avatar_url")),
+	}}
+}
+
+func n8nRSSFeedRead(c *n8nConverter, node *n8nNode, p importParams) *importMapping {
+	return &importMapping{nodeType: "action_rss", config: map[string]interface{}{"url": c.text(p, "url")}}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// convertN8nNode converts a workflow holding node and returns it
+func convertN8nNode(t *testing.T, node string) (FlowNode, *FlowImportReport) {
+	t.Helper()
+	workflow := `{"name":"Test","nodes":[` + node + `],"connections":{}}`
+	if !isN8nWorkflow([]byte(workflow)) {
+		t.Fatalf("%s isn't recognised as an n8n workflow", workflow)
+	}
+	report := &FlowImportReport{}
+	data, err := convertN8nWorkflow([]byte(workflow), report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var flow Flow
+	if err := json.Unmarshal(data, &flow); err != nil {
+		t.Fatal(err)
+	}
+	if len(flow.Nodes) != 1 {
+		t.Fatalf("nodes = %+v", flow.Nodes)
+	}
+	return flow.Nodes[0], report
+}
+
+func TestConvertN8nNodes(t *testing.T) {
+	tests := []struct {
+		name        string
+		node        string
+		nodeType    string
+		config      map[string]interface{} // checked keys only
+		placeholder string
+		warning     string
+	}{
+		{
+			name:     "manual trigger",
+			node:     `{"name":"Start","type":"n8n-nodes-base.manualTrigger","parameters":{}}`,
+			nodeType: "trigger_manual",
+		},
+		{
+			name:     "http request with expressions",
+			node:     `{"name":"Get","type":"n8n-nodes-base.httpRequest","parameters":{"method":"post","url":"=https://api.example.com/{{ $json.id }}","queryParameters":{"parameters":[{"name":"q","value":"={{ $env.TERM }}"}]}}}`,
+			nodeType: "action_http",
+			config:   map[string]interface{}{"method": "POST", "url": "https://api.example.com/{{output.id}}?q={{TERM}}"},
+		},
+		{
+			name:     "http request with an unsupported method",
+			node:     `{"name":"Get","type":"n8n-nodes-base.httpRequest","parameters":{"method":"PATCH","url":"https://example.com"}}`,
+			nodeType: "action_http",
+			config:   map[string]interface{}{"method": "POST"},
+			warning:  "HTTP requests can't use PATCH; changed to POST",
+		},
+		{
+			name:     "unconvertible expression",
+			node:     `{"name":"Get","type":"n8n-nodes-base.httpRequest","parameters":{"url":"={{ $now.toISO() }}"}}`,
+			nodeType: "action_http",
+			config:   map[string]interface{}{"url": "{{ $now.toISO() }}"},
+			warning:  "the expression {{ $now.toISO() }} was kept as it is",
+		},
+		{
+			name:     "credentials",
+			node:     `{"name":"Send","type":"n8n-nodes-base.slack","parameters":{"text":"hi"},"credentials":{"slackApi":{"id":"1"}}}`,
+			nodeType: "action_slack",
+			config:   map[string]interface{}{"text": "hi"},
+			warning:  "n8n credentials (slackApi) aren't imported",
+		},
+		{
+			name:     "wait",
+			node:     `{"name":"Wait","type":"n8n-nodes-base.wait","parameters":{"amount":2,"unit":"minutes"}}`,
+			nodeType: "action_delay",
+			config:   map[string]interface{}{"duration": float64(120000)},
+		},
+		{
+			name:     "plain command",
+			node:     `{"name":"Run","type":"n8n-nodes-base.executeCommand","parameters":{"command":"backup.sh --full /data"}}`,
+			nodeType: "action_script",
+			config:   map[string]interface{}{"command": "backup.sh", "args": "--full /data"},
+		},
+		{
+			name:        "command using a shell",
+			node:        `{"name":"Run","type":"n8n-nodes-base.executeCommand","parameters":{"command":"ls | wc -l"}}`,
+			nodeType:    "util_comment",
+			placeholder: "has no ForgeFlow equivalent; imported as a comment",
+			warning:     "the command needs a shell, which Run Script doesn't use",
+		},
+		{
+			name:        "disabled node",
+			node:        `{"name":"Get","type":"n8n-nodes-base.httpRequest","disabled":true,"parameters":{"url":"https://example.com"}}`,
+			nodeType:    "util_comment",
+			placeholder: "is disabled in n8n; imported as a comment",
+		},
+		{
+			name:        "unknown node type",
+			node:        `{"name":"Code","type":"n8n-nodes-base.code","parameters":{"jsCode":"return items"}}`,
+			nodeType:    "util_comment",
+			config:      map[string]interface{}{"n8nType": "n8n-nodes-base.code"},
+			placeholder: "has no ForgeFlow equivalent; imported as a comment",
+		},
+		{
+			name:        "community node",
+			node:        `{"name":"Other","type":"n8n-nodes-community.slack","parameters":{}}`,
+			nodeType:    "util_comment",
+			placeholder: "has no ForgeFlow equivalent; imported as a comment",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, report := convertN8nNode(t, tt.node)
+			if node.Data.NodeType != tt.nodeType {
+				t.Fatalf("node type = %q, want %q", node.Data.NodeType, tt.nodeType)
+			}
+			for key, want := range tt.config {
+				if got := node.Data.Config[key]; !reflect.DeepEqual(got, want) {
+					t.Errorf("config.%s = %#v, want %#v", key, got, want)
+				}
+			}
+
+			if tt.placeholder == "" {
+				if len(report.Placeholders) != 0 || report.Nodes != 1 {
+					t.Errorf("report = %+v", report)
+				}
+			} else if len(report.Placeholders) != 1 || report.Placeholders[0].Message != tt.placeholder || report.Nodes != 0 {
+				t.Errorf("placeholders = %+v, want %q", report.Placeholders, tt.placeholder)
+			}
+
+			if tt.warning == "" {
+				if len(report.Warnings) != 0 {
+					t.Errorf("warnings = %+v", report.Warnings)
+				}
+			} else if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0].Message, tt.warning) {
+				t.Errorf("warnings = %+v, want %q", report.Warnings, tt.warning)
+			}
+		})
+	}
+}
+
+func TestImportN8nWorkflow(t *testing.T) {
+	s := NewStorageAt(t.TempDir())
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.close)
+
+	workflow := `{"name":"Check site","nodes":[
+		{"name":"Start","type":"n8n-nodes-base.manualTrigger","parameters":{},"position":[0,0]},
+		{"name":"Check","type":"n8n-nodes-base.if","typeVersion":1,"parameters":{"conditions":{"number":[{"value1":"={{ $json.status }}","operation":"equal","value2":200}]}},"position":[200,0]},
+		{"name":"Alert","type":"n8n-nodes-base.slack","parameters":{"text":"={{ $node[\"Check\"].json.status }}"},"position":[400,0]},
+		{"name":"Code","type":"n8n-nodes-base.code","parameters":{},"position":[400,200]}
+	],"connections":{
+		"Start":{"main":[[{"node":"Check","type":"main","index":0}]]},
+		"Check":{"main":[[{"node":"Alert","type":"main","index":0}],[{"node":"Code","type":"main","index":0}]]}
+	}}`
+	report, err := s.ImportFlowWithReport(workflow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Format != "n8n" || report.Nodes != 3 || len(report.Placeholders) != 1 || report.Placeholders[0].Node != "Code" {
+		t.Errorf("report = %+v", report)
+	}
+
+	data, err := s.LoadFlow(report.FlowID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var flow Flow
+	if err := json.Unmarshal([]byte(data), &flow); err != nil {
+		t.Fatal(err)
+	}
+	if flow.Name != "Check site" || len(flow.Nodes) != 4 {
+		t.Fatalf("flow = %+v", flow)
+	}
+	if got := flow.Nodes[2].Data.Config["text"]; got != "{{node_node-2.status}}" {
+		t.Errorf("node reference = %v", got)
+	}
+	var edges []string
+	for _, e := range flow.Edges {
+		edges = append(edges, e.Source+":"+e.SourceHandle+"->"+e.Target)
+	}
+	want := []string{"node-1:out->node-2", "node-2:true->node-3", "node-2:false->node-4"}
+	if !reflect.DeepEqual(edges, want) {
+		t.Errorf("edges = %q, want %q", edges, want)
+	}
+}
@@ -0,0 +1,2214 @@
+package main
+
+// builtinNodeTypes describes the node types in frontend/src/nodes. Keep the
+// two in step when a node type or one of its fields changes.
+var builtinNodeTypes = []NodeTypeSpec{
+	// Triggers
+	{
+		Type:        "trigger_manual",
+		Category:    "trigger",
+		Name:        "Manual",
+		Icon:        "▷",
+		Description: "Start workflow manually",
+		Outputs:     []NodeHandle{{ID: "out", Label: "Start"}},
+		SideEffect:  SideEffectNone,
+	},
+	{
+		Type:        "trigger_hotkey",
+		Category:    "trigger",
+		Name:        "Hotkey",
+		Icon:        "⌨️",
+		Description: "Trigger with keyboard shortcut",
+		Outputs:     []NodeHandle{{ID: "out", Label: "Triggered"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"hotkey":  "",
+			"enabled": true,
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "hotkey", Label: "Hotkey", Type: "hotkey", Placeholder: "Ctrl+Shift+A", Required: true},
+			{Key: "enabled", Label: "Enabled", Type: "boolean", Default: true},
+		},
+	},
+	{
+		Type:        "trigger_schedule",
+		Category:    "trigger",
+		Name:        "Schedule",
+		Icon:        "⏰",
+		Description: "Run on a schedule (cron)",
+		Outputs:     []NodeHandle{{ID: "out", Label: "Trigger"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"cron":    "0 * * * *",
+			"enabled": true,
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "cron", Label: "Cron Expression", Type: "cron", Placeholder: "0 * * * * (every hour)", Required: true},
+			{Key: "enabled", Label: "Enabled", Type: "boolean", Default: true},
+		},
+	},
+	{
+		Type:        "trigger_clipboard",
+		Category:    "trigger",
+		Name:        "Clipboard",
+		Icon:        "📋",
+		Description: "Trigger on clipboard change",
+		Outputs:     []NodeHandle{{ID: "out", Label: "Content"}},
+		SideEffect:  SideEffectRead,
+		Defaults: map[string]interface{}{
+			"textOnly": true,
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "textOnly", Label: "Text Only", Type: "boolean", Default: true},
+		},
+	},
+	{
+		Type:        "trigger_file_watch",
+		Category:    "trigger",
+		Name:        "File Watcher",
+		Icon:        "👁️",
+		Description: "Trigger when file changes",
+		Outputs:     []NodeHandle{{ID: "out", Label: "Changed"}},
+		SideEffect:  SideEffectRead,
+		Defaults: map[string]interface{}{
+			"path":   "",
+			"events": "all",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "path", Label: "File/Folder Path", Type: "file", Placeholder: "/path/to/watch", Required: true},
+			{Key: "events", Label: "Watch Events", Type: "select", Options: []NodeFieldOption{
+				{"all", "All Changes"},
+				{"create", "Created"},
+				{"modify", "Modified"},
+				{"delete", "Deleted"},
+			}},
+		},
+	},
+	{
+		Type:        "trigger_webhook",
+		Category:    "trigger",
+		Name:        "Webhook",
+		Icon:        "🔗",
+		Description: "Trigger via HTTP webhook",
+		Outputs:     []NodeHandle{{ID: "out", Label: "Request"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"method": "POST",
+			"path":   "/webhook",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "method", Label: "Method", Type: "select", Options: []NodeFieldOption{{"GET", "GET"}, {"POST", "POST"}, {"PUT", "PUT"}}},
+			{Key: "path", Label: "Path", Type: "text", Placeholder: "/my-webhook"},
+		},
+	},
+	{
+		Type:        "trigger_startup",
+		Category:    "trigger",
+		Name:        "App Startup",
+		Icon:        "🚀",
+		Description: "Run when app starts",
+		Outputs:     []NodeHandle{{ID: "out", Label: "Start"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"delay": 0.0,
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "delay", Label: "Delay (ms)", Type: "number", Placeholder: "0", Default: 0.0},
+		},
+	},
+	{
+		Type:        "trigger_telegram",
+		Category:    "trigger",
+		Name:        "Telegram",
+		Icon:        "✈️",
+		Description: "Trigger on Telegram message",
+		Outputs:     []NodeHandle{{ID: "out", Label: "Message"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"botToken": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "botToken", Label: "Bot Token", Type: "password", Placeholder: "123456:ABC-DEF...", Required: true},
+		},
+	},
+
+	// Actions
+	{
+		Type:        "action_set_variable",
+		Category:    "action",
+		Name:        "Set Variable",
+		Icon:        "📝",
+		Description: "Set a workflow variable",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Done"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"name":  "",
+			"value": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "name", Label: "Variable Name", Type: "text", Placeholder: "myVariable", Required: true},
+			{Key: "value", Label: "Value", Type: "textarea", Placeholder: "Value or {{expression}}"},
+		},
+	},
+	{
+		Type:        "action_http",
+		Category:    "action",
+		Name:        "HTTP Request",
+		Icon:        "🌐",
+		Description: "Make an HTTP request",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Response"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"method":  "GET",
+			"url":     "",
+			"headers": "{}",
+			"body":    "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "method", Label: "Method", Type: "select", Options: []NodeFieldOption{
+				{"GET", "GET"},
+				{"POST", "POST"},
+				{"PUT", "PUT"},
+				{"DELETE", "DELETE"},
+			}},
+			{Key: "url", Label: "URL", Type: "url", Placeholder: "https://api.example.com/data", Required: true},
+			{Key: "headers", Label: "Headers (JSON)", Type: "json", Placeholder: "{\"Authorization\": \"Bearer ...\"}"},
+			{Key: "body", Label: "Body", Type: "textarea", Placeholder: "Request body..."},
+		},
+	},
+	{
+		Type:        "action_template",
+		Category:    "action",
+		Name:        "Template",
+		Icon:        "📄",
+		Description: "Render a text template",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Text"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"template": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "template", Label: "Template", Type: "textarea", Placeholder: "Hello {{name}}! Today is {{date}}.", Required: true},
+		},
+	},
+	{
+		Type:        "action_clipboard_write",
+		Category:    "action",
+		Name:        "Copy to Clipboard",
+		Icon:        "📋",
+		Description: "Copy text to clipboard",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Done"}},
+		SideEffect:  SideEffectWrite,
+		Defaults: map[string]interface{}{
+			"content": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "content", Label: "Content", Type: "textarea", Placeholder: "Text to copy... Use {{output}}", Required: true},
+		},
+	},
+	{
+		Type:        "action_notification",
+		Category:    "action",
+		Name:        "Notification",
+		Icon:        "🔔",
+		Description: "Show a notification",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Done"}},
+		SideEffect:  SideEffectWrite,
+		Defaults: map[string]interface{}{
+			"title":   "",
+			"message": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "title", Label: "Title", Type: "text", Placeholder: "Notification title", Required: true},
+			{Key: "message", Label: "Message", Type: "text", Placeholder: "Notification message"},
+		},
+	},
+	{
+		Type:        "action_open_url",
+		Category:    "action",
+		Name:        "Open URL",
+		Icon:        "🔗",
+		Description: "Open URL in browser",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Done"}},
+		SideEffect:  SideEffectWrite,
+		Defaults: map[string]interface{}{
+			"url": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "url", Label: "URL", Type: "url", Placeholder: "https://example.com", Required: true},
+		},
+	},
+	{
+		Type:        "action_delay",
+		Category:    "action",
+		Name:        "Delay",
+		Icon:        "⏳",
+		Description: "Wait for a duration",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Continue"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"duration": 1000.0,
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "duration", Label: "Duration (ms)", Type: "number", Placeholder: "1000", Required: true},
+		},
+	},
+	{
+		Type:        "action_file",
+		Category:    "action",
+		Name:        "File",
+		Icon:        "📄",
+		Description: "Read, write, or append to file",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Result"}},
+		SideEffect:  SideEffectWrite,
+		Defaults: map[string]interface{}{
+			"mode":    "read",
+			"path":    "",
+			"content": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "mode", Label: "Mode", Type: "select", Options: []NodeFieldOption{{"read", "Read File"}, {"write", "Write File"}, {"append", "Append to File"}}},
+			{Key: "path", Label: "File Path", Type: "file", Placeholder: "/path/to/file.txt", Required: true},
+			{Key: "content", Label: "Content (for write/append)", Type: "textarea", Placeholder: "{{output}} or text"},
+		},
+	},
+	{
+		Type:        "action_file_manage",
+		Category:    "action",
+		Name:        "File Manager",
+		Icon:        "📁",
+		Description: "Copy, move, or delete files",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Done"}},
+		SideEffect:  SideEffectWrite,
+		Defaults: map[string]interface{}{
+			"operation":   "copy",
+			"source":      "",
+			"destination": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "operation", Label: "Operation", Type: "select", Options: []NodeFieldOption{
+				{"copy", "Copy File"},
+				{"move", "Move/Rename File"},
+				{"delete", "Delete File"},
+				{"exists", "Check if Exists"},
+			}},
+			{Key: "source", Label: "Source Path", Type: "file", Placeholder: "/path/to/source.txt", Required: true},
+			{Key: "destination", Label: "Destination (for copy/move)", Type: "file-save", Placeholder: "/path/to/dest.txt"},
+		},
+	},
+	{
+		Type:        "action_excel_write",
+		Category:    "action",
+		Name:        "Write Excel",
+		Icon:        "📊",
+		Description: "Write data to Excel file (.xlsx)",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Done"}},
+		SideEffect:  SideEffectWrite,
+		Defaults: map[string]interface{}{
+			"path":           "",
+			"data":           "",
+			"sheetName":      "Sheet1",
+			"includeHeaders": true,
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "path", Label: "File Path", Type: "file-save", Placeholder: "/path/to/file.xlsx", Required: true, FileTypes: []string{".xlsx"}},
+			{Key: "data", Label: "Data (JSON)", Type: "textarea", Placeholder: "[{\"name\": \"John\", \"age\": 30}] or {{output}}", Required: true},
+			{Key: "sheetName", Label: "Sheet Name", Type: "text", Placeholder: "Sheet1", Default: "Sheet1"},
+			{Key: "includeHeaders", Label: "Include Headers", Type: "boolean", Default: true},
+		},
+	},
+	{
+		Type:        "action_file_info",
+		Category:    "action",
+		Name:        "File Info",
+		Icon:        "ℹ️",
+		Description: "Get file metadata (size, mod time, etc)",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Info"}},
+		SideEffect:  SideEffectRead,
+		Defaults: map[string]interface{}{
+			"path": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "path", Label: "File Path", Type: "file", Placeholder: "/path/to/file.txt", Required: true},
+		},
+	},
+	{
+		Type:        "action_zip_compress",
+		Category:    "action",
+		Name:        "Compress (ZIP)",
+		Icon:        "🗜️",
+		Description: "Create a ZIP archive",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Done"}},
+		SideEffect:  SideEffectWrite,
+		Defaults: map[string]interface{}{
+			"sources": "",
+			"zipPath": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "sources", Label: "Source Paths", Type: "textarea", Placeholder: "/path1\n/path2 or {{output}} as array", Required: true},
+			{Key: "zipPath", Label: "Destination ZIP", Type: "file-save", Placeholder: "/path/to/archive.zip", Required: true, FileTypes: []string{".zip"}},
+		},
+	},
+	{
+		Type:        "action_zip_extract",
+		Category:    "action",
+		Name:        "Extract (ZIP)",
+		Icon:        "📂",
+		Description: "Extract a ZIP archive",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Done"}},
+		SideEffect:  SideEffectWrite,
+		Defaults: map[string]interface{}{
+			"zipPath":     "",
+			"destination": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "zipPath", Label: "ZIP File", Type: "file", Placeholder: "/path/to/archive.zip", Required: true, FileTypes: []string{".zip"}},
+			{Key: "destination", Label: "Extract To", Type: "folder", Placeholder: "/path/to/extract/dir", Required: true},
+		},
+	},
+	{
+		Type:        "action_file_list",
+		Category:    "action",
+		Name:        "List Directory",
+		Icon:        "📁",
+		Description: "List files and folders in a directory",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Items"}},
+		SideEffect:  SideEffectRead,
+		Defaults: map[string]interface{}{
+			"path":      "",
+			"pattern":   "*",
+			"recursive": false,
+			"include":   "all",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "path", Label: "Directory Path", Type: "folder", Placeholder: "/path/to/dir", Required: true},
+			{Key: "pattern", Label: "Filter Pattern", Type: "text", Placeholder: "*.txt or *", Default: "*"},
+			{Key: "recursive", Label: "Recursive", Type: "boolean", Default: false},
+			{Key: "include", Label: "Include", Type: "select", Options: []NodeFieldOption{{"all", "Files & Folders"}, {"files", "Files Only"}, {"folders", "Folders Only"}}},
+		},
+	},
+	{
+		Type:        "action_regex",
+		Category:    "action",
+		Name:        "Regex",
+		Icon:        "🔍",
+		Description: "Extract or replace with regex",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Result"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"text":        "",
+			"pattern":     "",
+			"replacement": "",
+			"mode":        "match",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "text", Label: "Input Text", Type: "textarea", Placeholder: "{{output}}", Required: true},
+			{Key: "pattern", Label: "Regex Pattern", Type: "text", Placeholder: "\\d+", Required: true},
+			{Key: "mode", Label: "Mode", Type: "select", Options: []NodeFieldOption{
+				{"match", "Match (extract)"},
+				{"matchAll", "Match All"},
+				{"replace", "Replace"},
+				{"test", "Test (true/false)"},
+			}},
+			{Key: "replacement", Label: "Replacement", Type: "text", Placeholder: "For replace mode"},
+		},
+	},
+	{
+		Type:        "action_csv_parse",
+		Category:    "action",
+		Name:        "Parse CSV",
+		Icon:        "📊",
+		Description: "Parse CSV text into an array of objects",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Array"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"csv":       "",
+			"delimiter": ",",
+			"headers":   true,
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "csv", Label: "CSV Content", Type: "textarea", Placeholder: "{{output}} or CSV text", Required: true},
+			{Key: "delimiter", Label: "Delimiter", Type: "text", Placeholder: ",", Default: ","},
+			{Key: "headers", Label: "First row as headers", Type: "boolean", Default: true},
+		},
+	},
+	{
+		Type:        "action_csv_write",
+		Category:    "action",
+		Name:        "Write CSV",
+		Icon:        "📊",
+		Description: "Convert an array of objects into CSV text",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "CSV"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"data":      "",
+			"delimiter": ",",
+			"headers":   true,
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "data", Label: "Data", Type: "textarea", Placeholder: "{{output}} or JSON array", Required: true},
+			{Key: "delimiter", Label: "Delimiter", Type: "text", Placeholder: ",", Default: ","},
+			{Key: "headers", Label: "Include header row", Type: "boolean", Default: true},
+		},
+	},
+	{
+		Type:        "action_math",
+		Category:    "action",
+		Name:        "Math",
+		Icon:        "🔢",
+		Description: "Perform math operations",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Result"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"operation": "add",
+			"a":         "",
+			"b":         "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "operation", Label: "Operation", Type: "select", Options: []NodeFieldOption{
+				{"add", "Add (+)"},
+				{"subtract", "Subtract (-)"},
+				{"multiply", "Multiply (×)"},
+				{"divide", "Divide (÷)"},
+				{"modulo", "Modulo (%)"},
+				{"power", "Power (^)"},
+				{"round", "Round"},
+				{"floor", "Floor"},
+				{"ceil", "Ceiling"},
+				{"abs", "Absolute"},
+				{"random", "Random (0-1)"},
+			}},
+			{Key: "a", Label: "Value A", Type: "text", Placeholder: "{{output}} or number"},
+			{Key: "b", Label: "Value B", Type: "text", Placeholder: "Second value (if needed)"},
+		},
+	},
+	{
+		Type:        "action_date",
+		Category:    "action",
+		Name:        "Date/Time",
+		Icon:        "📅",
+		Description: "Get or format date/time",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Date"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"operation": "now",
+			"format":    "YYYY-MM-DD",
+			"input":     "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "operation", Label: "Operation", Type: "select", Options: []NodeFieldOption{
+				{"now", "Current Date/Time"},
+				{"format", "Format Date"},
+				{"parse", "Parse Date"},
+				{"add", "Add Time"},
+				{"diff", "Difference"},
+			}},
+			{Key: "format", Label: "Format", Type: "text", Placeholder: "YYYY-MM-DD HH:mm:ss"},
+			{Key: "input", Label: "Input Date", Type: "text", Placeholder: "For format/parse operations"},
+		},
+	},
+	{
+		Type:        "action_json_parse",
+		Category:    "action",
+		Name:        "Parse JSON",
+		Icon:        "{ }",
+		Description: "Parse JSON string",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Object"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"json": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "json", Label: "JSON String", Type: "textarea", Placeholder: "{\"key\": \"value\"} or {{output}}", Required: true},
+		},
+	},
+	{
+		Type:        "action_json_stringify",
+		Category:    "action",
+		Name:        "Stringify JSON",
+		Icon:        "{ }",
+		Description: "Convert object to JSON string",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "String"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"object": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "object", Label: "Object", Type: "textarea", Placeholder: "{{output}}", Required: true},
+		},
+	},
+	{
+		Type:        "action_script",
+		Category:    "action",
+		Name:        "Run Script",
+		Icon:        "💻",
+		Description: "Execute a shell command",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Output"}},
+		SideEffect:  SideEffectWrite,
+		Defaults: map[string]interface{}{
+			"command": "",
+			"args":    "",
+			"workDir": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "command", Label: "Command", Type: "text", Placeholder: "node", Required: true},
+			{Key: "args", Label: "Arguments", Type: "text", Placeholder: "script.js --flag"},
+			{Key: "workDir", Label: "Working Directory", Type: "folder", Placeholder: "/path/to/dir"},
+		},
+	},
+	{
+		Type:        "action_log",
+		Category:    "action",
+		Name:        "Log",
+		Icon:        "📋",
+		Description: "Log message to console",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Done"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"message": "",
+			"level":   "info",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "message", Label: "Message", Type: "textarea", Placeholder: "Log: {{output}}", Required: true},
+			{Key: "level", Label: "Level", Type: "select", Options: []NodeFieldOption{{"info", "Info"}, {"warn", "Warning"}, {"error", "Error"}}},
+		},
+	},
+
+	// Conditions
+	{
+		Type:        "condition_if",
+		Category:    "condition",
+		Name:        "If/Else",
+		Icon:        "🔀",
+		Description: "Branch based on condition",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "true", Label: "True"}, {ID: "false", Label: "False"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"condition": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "condition", Label: "Condition", Type: "text", Placeholder: "{{output}} > 10", Required: true},
+		},
+	},
+	{
+		Type:        "condition_switch",
+		Category:    "condition",
+		Name:        "Switch",
+		Icon:        "🔄",
+		Description: "Multi-way branch",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "case1", Label: "Case 1"}, {ID: "case2", Label: "Case 2"}, {ID: "case3", Label: "Case 3"}, {ID: "default", Label: "Default"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"value": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "value", Label: "Value to Match", Type: "text", Placeholder: "{{output}}", Required: true},
+		},
+	},
+	{
+		Type:        "condition_manual_approval",
+		Category:    "condition",
+		Name:        "Manual Approval",
+		Icon:        "👤",
+		Description: "Wait for user approval",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "true", Label: "Approved"}, {ID: "false", Label: "Denied"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"title":   "Approval Required",
+			"message": "Please approve this action to continue.",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "title", Label: "Dialog Title", Type: "text", Placeholder: "Approval Required"},
+			{Key: "message", Label: "Dialog Message", Type: "textarea", Placeholder: "{{message}}"},
+		},
+	},
+	{
+		Type:        "condition_try_catch",
+		Category:    "condition",
+		Name:        "Try/Catch",
+		Icon:        "🛡️",
+		Description: "Handle errors gracefully",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "try", Label: "Try"}, {ID: "catch", Label: "Catch"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"continueOnError": true,
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "continueOnError", Label: "Continue on Error", Type: "boolean", Default: true},
+		},
+	},
+	{
+		Type:        "condition_filter",
+		Category:    "condition",
+		Name:        "Filter Array",
+		Icon:        "🔎",
+		Description: "Filter array items by condition",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "match", Label: "Matched"}, {ID: "nomatch", Label: "Not Matched"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"array":    "",
+			"field":    "",
+			"operator": "equals",
+			"value":    "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "array", Label: "Array", Type: "text", Placeholder: "{{output}} or [...]", Required: true},
+			{Key: "field", Label: "Field Path", Type: "text", Placeholder: "user.name or leave empty"},
+			{Key: "operator", Label: "Operator", Type: "select", Options: []NodeFieldOption{
+				{"equals", "Equals (==)"},
+				{"not_equals", "Not Equals (!=)"},
+				{"contains", "Contains"},
+				{"starts_with", "Starts With"},
+				{"ends_with", "Ends With"},
+				{"greater", "Greater Than (>)"},
+				{"less", "Less Than (<)"},
+				{"greater_eq", "Greater or Equal (>=)"},
+				{"less_eq", "Less or Equal (<=)"},
+				{"is_empty", "Is Empty"},
+				{"is_not_empty", "Is Not Empty"},
+				{"regex", "Matches Regex"},
+			}},
+			{Key: "value", Label: "Compare Value", Type: "text", Placeholder: "Value to compare against"},
+		},
+	},
+	{
+		Type:        "condition_type_check",
+		Category:    "condition",
+		Name:        "Type Check",
+		Icon:        "🔢",
+		Description: "Check value type",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "true", Label: "Matches"}, {ID: "false", Label: "No Match"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"value": "",
+			"type":  "string",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "value", Label: "Value", Type: "text", Placeholder: "{{output}}", Required: true},
+			{Key: "type", Label: "Expected Type", Type: "select", Options: []NodeFieldOption{
+				{"string", "String"},
+				{"number", "Number"},
+				{"boolean", "Boolean"},
+				{"array", "Array"},
+				{"object", "Object"},
+				{"null", "Null"},
+				{"undefined", "Undefined"},
+			}},
+		},
+	},
+	{
+		Type:        "condition_is_empty",
+		Category:    "condition",
+		Name:        "Is Empty",
+		Icon:        "❓",
+		Description: "Check if value is empty",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "true", Label: "Empty"}, {ID: "false", Label: "Not Empty"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"value": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "value", Label: "Value", Type: "text", Placeholder: "{{output}}", Required: true},
+		},
+	},
+	{
+		Type:        "condition_date_compare",
+		Category:    "condition",
+		Name:        "Date Compare",
+		Icon:        "📅",
+		Description: "Compare two dates",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "true", Label: "True"}, {ID: "false", Label: "False"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"a":        "",
+			"operator": "before",
+			"b":        "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "a", Label: "Date A", Type: "text", Placeholder: "{{date}} or 2023-01-01", Required: true},
+			{Key: "operator", Label: "Operator", Type: "select", Options: []NodeFieldOption{{"before", "Is Before"}, {"after", "Is After"}, {"same", "Is Same"}}},
+			{Key: "b", Label: "Date B", Type: "text", Placeholder: "{{date}} or 2023-01-01", Required: true},
+		},
+	},
+	{
+		Type:        "condition_array_contains",
+		Category:    "condition",
+		Name:        "Array Contains",
+		Icon:        "🔍",
+		Description: "Check if array contains a value",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "true", Label: "True"}, {ID: "false", Label: "False"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"array": "",
+			"value": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "array", Label: "Array", Type: "text", Placeholder: "{{items}}", Required: true},
+			{Key: "value", Label: "Value to Find", Type: "text", Placeholder: "search term", Required: true},
+		},
+	},
+
+	// Actions
+	{
+		Type:        "action_ai",
+		Category:    "ai",
+		Name:        "AI Prompt",
+		Icon:        "🤖",
+		Description: "Send prompt to AI (with optional memory)",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Response"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"provider":     "openai",
+			"prompt":       "",
+			"systemPrompt": "",
+			"model":        "",
+			"temperature":  0.7,
+			"enableMemory": false,
+			"memoryKey":    "",
+			"maxMessages":  20.0,
+			"memoryExpiry": 30.0,
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "provider", Label: "Provider", Type: "select", Default: "ollama", Options: []NodeFieldOption{
+				{"ollama", "Ollama (Local)"},
+				{"openai", "OpenAI"},
+				{"groq", "Groq"},
+				{"openrouter", "OpenRouter"},
+				{"custom", "Custom Compatible"},
+			}},
+			{Key: "model", Label: "Model", Type: "model-select"},
+			{Key: "systemPrompt", Label: "System Prompt", Type: "textarea", Placeholder: "You are a helpful assistant..."},
+			{Key: "prompt", Label: "User Prompt", Type: "textarea", Placeholder: "Your prompt... Use {{output}} for previous data", Required: true},
+			{Key: "temperature", Label: "Temperature (0-1)", Type: "number", Placeholder: "0.7", Default: 0.7},
+			{Key: "enableMemory", Label: "Enable Memory", Type: "boolean", Default: false},
+			{Key: "memoryKey", Label: "Memory Key", Type: "text", Placeholder: "{{chatId}} - unique ID per conversation"},
+			{Key: "maxMessages", Label: "Max Messages", Type: "select", Default: "20", Options: []NodeFieldOption{
+				{"5", "5 messages"},
+				{"10", "10 messages"},
+				{"20", "20 messages"},
+				{"50", "50 messages"},
+				{"100", "100 messages"},
+			}},
+			{Key: "memoryExpiry", Label: "Memory Expiry", Type: "select", Default: "30", Options: []NodeFieldOption{
+				{"5", "5 minutes"},
+				{"15", "15 minutes"},
+				{"30", "30 minutes"},
+				{"60", "1 hour"},
+				{"360", "6 hours"},
+				{"1440", "24 hours"},
+				{"0", "Never expire"},
+			}},
+		},
+	},
+
+	// Loops
+	{
+		Type:        "loop_foreach",
+		Category:    "loop",
+		Name:        "For Each",
+		Icon:        "🔁",
+		Description: "Loop over array items",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "loop", Label: "Each Item"}, {ID: "done", Label: "Done"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"array":    "",
+			"itemVar":  "item",
+			"indexVar": "index",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "array", Label: "Array", Type: "text", Placeholder: "{{items}} or [1,2,3]", Required: true},
+			{Key: "itemVar", Label: "Item Variable", Type: "text", Placeholder: "item"},
+			{Key: "indexVar", Label: "Index Variable", Type: "text", Placeholder: "index"},
+		},
+	},
+	{
+		Type:        "loop_repeat",
+		Category:    "loop",
+		Name:        "Repeat",
+		Icon:        "🔂",
+		Description: "Repeat N times",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "loop", Label: "Each"}, {ID: "done", Label: "Done"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"count":    5.0,
+			"indexVar": "i",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "count", Label: "Repeat Count", Type: "number", Placeholder: "5", Required: true},
+			{Key: "indexVar", Label: "Index Variable", Type: "text", Placeholder: "i"},
+		},
+	},
+	{
+		Type:        "loop_while",
+		Category:    "loop",
+		Name:        "While Loop",
+		Icon:        "🔄",
+		Description: "Loop while condition is true",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "loop", Label: "Loop"}, {ID: "done", Label: "Done"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"condition":     "",
+			"maxIterations": 100.0,
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "condition", Label: "Condition", Type: "text", Placeholder: "{{counter}} < 10", Required: true},
+			{Key: "maxIterations", Label: "Max Iterations", Type: "number", Placeholder: "100"},
+		},
+	},
+	{
+		Type:        "loop_parallel",
+		Category:    "loop",
+		Name:        "Parallel For Each",
+		Icon:        "⚡",
+		Description: "Process array items in parallel (concurrent)",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "loop", Label: "Each Item"}, {ID: "done", Label: "All Done"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"array":       "",
+			"itemVar":     "item",
+			"concurrency": 5.0,
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "array", Label: "Array", Type: "text", Placeholder: "{{items}} or [1,2,3]", Required: true},
+			{Key: "concurrency", Label: "Max Parallel", Type: "select", Options: []NodeFieldOption{
+				{"2", "2 concurrent"},
+				{"5", "5 concurrent"},
+				{"10", "10 concurrent"},
+				{"20", "20 concurrent"},
+			}},
+			{Key: "itemVar", Label: "Item Variable", Type: "text", Placeholder: "item"},
+		},
+	},
+	{
+		Type:        "loop_rate_limited",
+		Category:    "loop",
+		Name:        "Rate Limited Loop",
+		Icon:        "🕐",
+		Description: "Loop with delay between iterations (API-friendly)",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "loop", Label: "Each Item"}, {ID: "done", Label: "Done"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"array":   "",
+			"itemVar": "item",
+			"delayMs": 1000.0,
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "array", Label: "Array", Type: "text", Placeholder: "{{items}} or [1,2,3]", Required: true},
+			{Key: "delayMs", Label: "Delay Between Items", Type: "select", Options: []NodeFieldOption{
+				{"100", "100ms (fast)"},
+				{"500", "500ms"},
+				{"1000", "1 second"},
+				{"2000", "2 seconds"},
+				{"5000", "5 seconds"},
+			}},
+			{Key: "itemVar", Label: "Item Variable", Type: "text", Placeholder: "item"},
+		},
+	},
+
+	// Utilities
+	{
+		Type:        "util_string",
+		Category:    "utility",
+		Name:        "String",
+		Icon:        "📝",
+		Description: "Transform strings (case, trim, split)",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Result"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"mode":      "lower",
+			"text":      "",
+			"delimiter": ",",
+			"length":    "10",
+			"char":      " ",
+			"start":     "0",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "mode", Label: "Operation", Type: "select", Options: []NodeFieldOption{
+				{"lower", "lowercase"},
+				{"upper", "UPPERCASE"},
+				{"title", "Title Case"},
+				{"camel", "camelCase"},
+				{"snake", "snake_case"},
+				{"kebab", "kebab-case"},
+				{"trim", "Trim whitespace"},
+				{"padStart", "Pad start"},
+				{"padEnd", "Pad end"},
+				{"split", "Split to array"},
+				{"replace", "Replace text"},
+				{"substring", "Substring"},
+			}},
+			{Key: "text", Label: "Text", Type: "textarea", Placeholder: "{{output}}", Required: true},
+			{Key: "delimiter", Label: "Delimiter/Search", Type: "text", Placeholder: "For split/replace"},
+			{Key: "replacement", Label: "Replacement", Type: "text", Placeholder: "For replace"},
+			{Key: "length", Label: "Length", Type: "number", Placeholder: "For pad/substring"},
+			{Key: "char", Label: "Pad Char", Type: "text", Placeholder: "0 or space"},
+			{Key: "start", Label: "Start Index", Type: "number", Placeholder: "For substring"},
+		},
+	},
+	{
+		Type:        "util_array",
+		Category:    "utility",
+		Name:        "Array",
+		Icon:        "📋",
+		Description: "Array operations (map, filter, sort, join)",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Result"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"mode":      "length",
+			"array":     "",
+			"field":     "",
+			"separator": ", ",
+			"item":      "",
+			"start":     "0",
+			"end":       "",
+			"order":     "asc",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "mode", Label: "Operation", Type: "select", Options: []NodeFieldOption{
+				{"length", "Length"},
+				{"push", "Push (add item)"},
+				{"slice", "Slice (portion)"},
+				{"join", "Join (to string)"},
+				{"map", "Map (extract field)"},
+				{"sort", "Sort"},
+				{"reverse", "Reverse"},
+				{"unique", "Unique"},
+				{"flatten", "Flatten"},
+				{"first", "First item"},
+				{"last", "Last item"},
+			}},
+			{Key: "array", Label: "Array", Type: "text", Placeholder: "{{output}}", Required: true},
+			{Key: "field", Label: "Field", Type: "text", Placeholder: "For map/sort/unique"},
+			{Key: "item", Label: "Item", Type: "text", Placeholder: "For push"},
+			{Key: "separator", Label: "Separator", Type: "text", Placeholder: "For join"},
+			{Key: "start", Label: "Start", Type: "number", Placeholder: "For slice"},
+			{Key: "end", Label: "End", Type: "number", Placeholder: "For slice"},
+			{Key: "order", Label: "Order", Type: "select", Options: []NodeFieldOption{{"asc", "Ascending"}, {"desc", "Descending"}}},
+		},
+	},
+	{
+		Type:        "util_field",
+		Category:    "utility",
+		Name:        "Field",
+		Icon:        "🔑",
+		Description: "Get or set field in object",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Result"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"mode":  "get",
+			"path":  "",
+			"value": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "mode", Label: "Mode", Type: "select", Options: []NodeFieldOption{{"get", "Get Field"}, {"set", "Set Field"}}},
+			{Key: "path", Label: "Field Path", Type: "text", Placeholder: "user.name or items[0].id", Required: true},
+			{Key: "value", Label: "Value", Type: "text", Placeholder: "For set mode"},
+		},
+	},
+	{
+		Type:        "util_merge",
+		Category:    "utility",
+		Name:        "Merge",
+		Icon:        "🔗",
+		Description: "Merge multiple inputs",
+		Inputs:      []NodeHandle{{ID: "in1", Label: "Input 1"}, {ID: "in2", Label: "Input 2"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Merged"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"mode": "array",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "mode", Label: "Merge Mode", Type: "select", Options: []NodeFieldOption{{"array", "As Array"}, {"object", "As Object"}, {"concat", "Concatenate"}}},
+		},
+	},
+	{
+		Type:        "util_generate",
+		Category:    "utility",
+		Name:        "Generate",
+		Icon:        "🎲",
+		Description: "Generate UUID, random values",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Result"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"mode":   "uuid",
+			"min":    "0",
+			"max":    "100",
+			"length": "8",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "mode", Label: "Type", Type: "select", Options: []NodeFieldOption{{"uuid", "UUID"}, {"number", "Random Number"}, {"string", "Random String"}}},
+			{Key: "min", Label: "Min", Type: "number", Placeholder: "For number"},
+			{Key: "max", Label: "Max", Type: "number", Placeholder: "For number"},
+			{Key: "length", Label: "Length", Type: "number", Placeholder: "For string"},
+		},
+	},
+	{
+		Type:        "util_encode",
+		Category:    "utility",
+		Name:        "Encode/Decode",
+		Icon:        "🔐",
+		Description: "Base64, URL encode, hashing (MD5, SHA)",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Result"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"mode": "base64_encode",
+			"text": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "mode", Label: "Operation", Type: "select", Options: []NodeFieldOption{
+				{"base64_encode", "Base64 Encode"},
+				{"base64_decode", "Base64 Decode"},
+				{"url_encode", "URL Encode"},
+				{"url_decode", "URL Decode"},
+				{"hex_encode", "Hex Encode"},
+				{"hex_decode", "Hex Decode"},
+				{"html_encode", "HTML Encode"},
+				{"html_decode", "HTML Decode"},
+			}},
+			{Key: "text", Label: "Text", Type: "textarea", Placeholder: "{{output}}", Required: true},
+		},
+	},
+	{
+		Type:        "util_object",
+		Category:    "utility",
+		Name:        "Object",
+		Icon:        "{}",
+		Description: "Object operations (keys, values, entries, pick, omit)",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Result"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"mode":   "keys",
+			"object": "",
+			"fields": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "mode", Label: "Operation", Type: "select", Options: []NodeFieldOption{
+				{"keys", "Get Keys (array)"},
+				{"values", "Get Values (array)"},
+				{"entries", "Get Entries ([key, value] pairs)"},
+				{"pick", "Pick Fields (keep only specified)"},
+				{"omit", "Omit Fields (remove specified)"},
+				{"delete", "Delete Field"},
+				{"has", "Has Key (boolean)"},
+				{"size", "Size (number of keys)"},
+			}},
+			{Key: "object", Label: "Object", Type: "textarea", Placeholder: "{{output}}", Required: true},
+			{Key: "fields", Label: "Fields (comma-separated)", Type: "text", Placeholder: "field1, field2, nested.field"},
+		},
+	},
+	{
+		Type:        "util_comment",
+		Category:    "utility",
+		Name:        "Comment",
+		Icon:        "💬",
+		Description: "Add notes to your workflow (no execution)",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Pass"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"comment": "",
+			"color":   "#64748b",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "comment", Label: "Comment", Type: "textarea", Placeholder: "Add notes about this workflow section..."},
+			{Key: "color", Label: "Color", Type: "select", Options: []NodeFieldOption{
+				{"#64748b", "Gray"},
+				{"#3b82f6", "Blue"},
+				{"#22c55e", "Green"},
+				{"#f59e0b", "Orange"},
+				{"#ef4444", "Red"},
+				{"#a855f7", "Purple"},
+			}},
+		},
+	},
+	{
+		Type:        "util_json",
+		Category:    "utility",
+		Name:        "JSON",
+		Icon:        "{ }",
+		Description: "Parse or stringify JSON",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Result"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"mode":   "parse",
+			"input":  "",
+			"pretty": true,
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "mode", Label: "Mode", Type: "select", Options: []NodeFieldOption{{"parse", "Parse (string → object)"}, {"stringify", "Stringify (object → string)"}}},
+			{Key: "input", Label: "Input", Type: "textarea", Placeholder: "{{output}}", Required: true},
+			{Key: "pretty", Label: "Pretty Print", Type: "boolean", Default: true},
+		},
+	},
+	{
+		Type:        "util_counter",
+		Category:    "utility",
+		Name:        "Counter",
+		Icon:        "🔢",
+		Description: "Increment/decrement a counter variable",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Value"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"name":      "counter",
+			"operation": "increment",
+			"amount":    1.0,
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "name", Label: "Counter Name", Type: "text", Placeholder: "counter", Required: true},
+			{Key: "operation", Label: "Operation", Type: "select", Options: []NodeFieldOption{
+				{"increment", "Increment (+)"},
+				{"decrement", "Decrement (-)"},
+				{"reset", "Reset to 0"},
+				{"get", "Get Value"},
+				{"set", "Set Value"},
+			}},
+			{Key: "amount", Label: "Amount", Type: "number", Placeholder: "1", Default: 1.0},
+		},
+	},
+	{
+		Type:        "util_hash",
+		Category:    "utility",
+		Name:        "Hash",
+		Icon:        "#️⃣",
+		Description: "Generate cryptographic hashes (MD5, SHA)",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Hash"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"algorithm": "sha256",
+			"text":      "",
+			"uppercase": false,
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "algorithm", Label: "Algorithm", Type: "select", Options: []NodeFieldOption{
+				{"md5", "MD5 (32 chars)"},
+				{"sha1", "SHA-1 (40 chars)"},
+				{"sha256", "SHA-256 (64 chars)"},
+				{"sha512", "SHA-512 (128 chars)"},
+			}},
+			{Key: "text", Label: "Text", Type: "textarea", Placeholder: "{{output}}", Required: true},
+			{Key: "uppercase", Label: "Uppercase", Type: "boolean", Default: false},
+		},
+	},
+	{
+		Type:        "util_encrypt",
+		Category:    "utility",
+		Name:        "Encrypt/Decrypt",
+		Icon:        "🔒",
+		Description: "AES encryption and decryption",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Result"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"mode":      "encrypt",
+			"algorithm": "aes-256",
+			"text":      "",
+			"key":       "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "mode", Label: "Mode", Type: "select", Options: []NodeFieldOption{{"encrypt", "Encrypt"}, {"decrypt", "Decrypt"}}},
+			{Key: "algorithm", Label: "Algorithm", Type: "select", Options: []NodeFieldOption{{"aes-128", "AES-128"}, {"aes-256", "AES-256"}}},
+			{Key: "text", Label: "Text", Type: "textarea", Placeholder: "{{output}}", Required: true},
+			{Key: "key", Label: "Secret Key", Type: "password", Placeholder: "Your encryption key", Required: true},
+		},
+	},
+	{
+		Type:        "util_wait_all",
+		Category:    "utility",
+		Name:        "Wait All",
+		Icon:        "⏸️",
+		Description: "Wait for all inputs before continuing",
+		Inputs:      []NodeHandle{{ID: "in1", Label: "Input 1"}, {ID: "in2", Label: "Input 2"}, {ID: "in3", Label: "Input 3"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "All Done"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"mode": "all",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "mode", Label: "Wait Mode", Type: "select", Options: []NodeFieldOption{{"all", "Wait for ALL inputs"}, {"any", "Wait for ANY input"}}},
+		},
+	},
+	{
+		Type:        "util_switch",
+		Category:    "utility",
+		Name:        "Switch/Router",
+		Icon:        "🔀",
+		Description: "Route data to different outputs based on value",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "case1", Label: "Case 1"}, {ID: "case2", Label: "Case 2"}, {ID: "case3", Label: "Case 3"}, {ID: "default", Label: "Default"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"value": "",
+			"case1": "",
+			"case2": "",
+			"case3": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "value", Label: "Value to Check", Type: "text", Placeholder: "{{output}}", Required: true},
+			{Key: "case1", Label: "Case 1 Value", Type: "text", Placeholder: "Match value for Case 1"},
+			{Key: "case2", Label: "Case 2 Value", Type: "text", Placeholder: "Match value for Case 2"},
+			{Key: "case3", Label: "Case 3 Value", Type: "text", Placeholder: "Match value for Case 3"},
+		},
+	},
+	{
+		Type:        "util_debounce",
+		Category:    "utility",
+		Name:        "Debounce",
+		Icon:        "⏱️",
+		Description: "Limit execution frequency (wait for quiet period)",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Output"}},
+		SideEffect:  SideEffectNone,
+		Defaults: map[string]interface{}{
+			"delay": 500.0,
+			"key":   "default",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "delay", Label: "Wait Time (ms)", Type: "select", Options: []NodeFieldOption{
+				{"100", "100ms"},
+				{"250", "250ms"},
+				{"500", "500ms"},
+				{"1000", "1 second"},
+				{"2000", "2 seconds"},
+			}},
+			{Key: "key", Label: "Debounce Key", Type: "text", Placeholder: "Unique key for this debounce"},
+		},
+	},
+
+	// Actions
+	{
+		Type:        "action_pexels",
+		Category:    "apps",
+		Name:        "Pexels Image",
+		Icon:        "📷",
+		Description: "Search for images on Pexels",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Image URL"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"apiKey":      "",
+			"query":       "",
+			"size":        "large",
+			"orientation": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "apiKey", Label: "API Key", Type: "password", Placeholder: "Your Pexels API key", Required: true},
+			{Key: "query", Label: "Search Query", Type: "text", Placeholder: "{{topic}} or keywords", Required: true},
+			{Key: "size", Label: "Image Size", Type: "select", Options: []NodeFieldOption{
+				{"original", "Original"},
+				{"large2x", "Large 2x"},
+				{"large", "Large"},
+				{"medium", "Medium"},
+				{"small", "Small"},
+			}},
+			{Key: "orientation", Label: "Orientation", Type: "select", Options: []NodeFieldOption{
+				{"", "Any"},
+				{"landscape", "Landscape"},
+				{"portrait", "Portrait"},
+				{"square", "Square"},
+			}},
+		},
+	},
+	{
+		Type:        "action_unsplash",
+		Category:    "apps",
+		Name:        "Unsplash Image",
+		Icon:        "🖼️",
+		Description: "Search for images on Unsplash",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Image URL"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"apiKey":      "",
+			"query":       "",
+			"size":        "regular",
+			"orientation": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "apiKey", Label: "Access Key", Type: "password", Placeholder: "Your Unsplash Access Key", Required: true},
+			{Key: "query", Label: "Search Query", Type: "text", Placeholder: "{{topic}} or keywords", Required: true},
+			{Key: "size", Label: "Image Size", Type: "select", Options: []NodeFieldOption{
+				{"raw", "Raw (Original)"},
+				{"full", "Full"},
+				{"regular", "Regular (1080px)"},
+				{"small", "Small (400px)"},
+				{"thumb", "Thumbnail (200px)"},
+			}},
+			{Key: "orientation", Label: "Orientation", Type: "select", Options: []NodeFieldOption{
+				{"", "Any"},
+				{"landscape", "Landscape"},
+				{"portrait", "Portrait"},
+				{"squarish", "Square"},
+			}},
+		},
+	},
+	{
+		Type:        "action_telegram",
+		Category:    "apps",
+		Name:        "Telegram Message",
+		Icon:        "✈️",
+		Description: "Send message via Telegram Bot",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Result"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"botToken":  "",
+			"chatId":    "",
+			"message":   "",
+			"parseMode": "HTML",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "botToken", Label: "Bot Token", Type: "password", Placeholder: "123456:ABC-DEF...", Required: true},
+			{Key: "chatId", Label: "Chat ID", Type: "text", Placeholder: "@channel or user ID", Required: true},
+			{Key: "message", Label: "Message", Type: "textarea", Placeholder: "{{output}} or text", Required: true},
+			{Key: "parseMode", Label: "Parse Mode", Type: "select", Options: []NodeFieldOption{{"HTML", "HTML"}, {"Markdown", "Markdown"}, {"", "Plain Text"}}},
+		},
+	},
+	{
+		Type:        "action_discord",
+		Category:    "apps",
+		Name:        "Discord Webhook",
+		Icon:        "💬",
+		Description: "Send message to Discord channel",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Result"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"webhookUrl": "",
+			"content":    "",
+			"username":   "",
+			"avatarUrl":  "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "webhookUrl", Label: "Webhook URL", Type: "password", Placeholder: "https://discord.com/api/webhooks/...", Required: true},
+			{Key: "content", Label: "Message", Type: "textarea", Placeholder: "{{output}} or text", Required: true},
+			{Key: "username", Label: "Bot Name (optional)", Type: "text", Placeholder: "Custom bot name"},
+			{Key: "avatarUrl", Label: "Avatar URL (optional)", Type: "text", Placeholder: "https://..."},
+		},
+	},
+	{
+		Type:        "action_slack",
+		Category:    "apps",
+		Name:        "Slack Webhook",
+		Icon:        "📢",
+		Description: "Send message to Slack channel",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Result"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"webhookUrl": "",
+			"text":       "",
+			"username":   "",
+			"iconEmoji":  "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "webhookUrl", Label: "Webhook URL", Type: "password", Placeholder: "https://hooks.slack.com/services/...", Required: true},
+			{Key: "text", Label: "Message", Type: "textarea", Placeholder: "{{output}} or text", Required: true},
+			{Key: "username", Label: "Bot Name (optional)", Type: "text", Placeholder: "Custom bot name"},
+			{Key: "iconEmoji", Label: "Icon Emoji (optional)", Type: "text", Placeholder: ":robot_face:"},
+		},
+	},
+	{
+		Type:        "action_email",
+		Category:    "apps",
+		Name:        "Send Email",
+		Icon:        "📧",
+		Description: "Send email via SMTP",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Result"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"smtpHost": "",
+			"smtpPort": "587",
+			"username": "",
+			"password": "",
+			"from":     "",
+			"to":       "",
+			"subject":  "",
+			"body":     "",
+			"isHtml":   true,
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "smtpHost", Label: "SMTP Host", Type: "text", Placeholder: "smtp.gmail.com", Required: true},
+			{Key: "smtpPort", Label: "SMTP Port", Type: "text", Placeholder: "587", Default: "587"},
+			{Key: "username", Label: "Username", Type: "text", Placeholder: "your@email.com", Required: true},
+			{Key: "password", Label: "Password", Type: "password", Placeholder: "App password", Required: true},
+			{Key: "from", Label: "From", Type: "text", Placeholder: "sender@email.com", Required: true},
+			{Key: "to", Label: "To", Type: "text", Placeholder: "recipient@email.com", Required: true},
+			{Key: "subject", Label: "Subject", Type: "text", Placeholder: "{{topic}}", Required: true},
+			{Key: "body", Label: "Body", Type: "textarea", Placeholder: "{{output}}", Required: true},
+			{Key: "isHtml", Label: "HTML Email", Type: "boolean", Default: true},
+		},
+	},
+	{
+		Type:        "action_translate",
+		Category:    "apps",
+		Name:        "Google Translate",
+		Icon:        "🌐",
+		Description: "Translate text using Google Translate",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Translated"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"text": "",
+			"from": "auto",
+			"to":   "en",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "text", Label: "Text", Type: "textarea", Placeholder: "{{output}} or text to translate", Required: true},
+			{Key: "from", Label: "From Language", Type: "select", Options: []NodeFieldOption{
+				{"auto", "Auto Detect"},
+				{"en", "English"},
+				{"es", "Spanish"},
+				{"fr", "French"},
+				{"de", "German"},
+				{"it", "Italian"},
+				{"pt", "Portuguese"},
+				{"ru", "Russian"},
+				{"ja", "Japanese"},
+				{"ko", "Korean"},
+				{"zh", "Chinese"},
+				{"ar", "Arabic"},
+				{"hi", "Hindi"},
+			}},
+			{Key: "to", Label: "To Language", Type: "select", Options: []NodeFieldOption{
+				{"en", "English"},
+				{"es", "Spanish"},
+				{"fr", "French"},
+				{"de", "German"},
+				{"it", "Italian"},
+				{"pt", "Portuguese"},
+				{"ru", "Russian"},
+				{"ja", "Japanese"},
+				{"ko", "Korean"},
+				{"zh", "Chinese"},
+				{"ar", "Arabic"},
+				{"hi", "Hindi"},
+			}},
+		},
+	},
+	{
+		Type:        "action_weather",
+		Category:    "apps",
+		Name:        "Weather",
+		Icon:        "🌤️",
+		Description: "Get current weather data",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Weather"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"apiKey": "",
+			"city":   "",
+			"units":  "metric",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "apiKey", Label: "OpenWeather API Key", Type: "password", Placeholder: "Your API key", Required: true},
+			{Key: "city", Label: "City", Type: "text", Placeholder: "London or {{city}}", Required: true},
+			{Key: "units", Label: "Units", Type: "select", Options: []NodeFieldOption{{"metric", "Celsius"}, {"imperial", "Fahrenheit"}}},
+		},
+	},
+	{
+		Type:        "action_rss",
+		Category:    "apps",
+		Name:        "RSS Feed",
+		Icon:        "📰",
+		Description: "Fetch RSS/Atom feed",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Items"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"url":   "",
+			"limit": 10.0,
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "url", Label: "Feed URL", Type: "text", Placeholder: "https://example.com/feed.xml", Required: true},
+			{Key: "limit", Label: "Max Items", Type: "number", Placeholder: "10", Default: 10.0},
+		},
+	},
+	{
+		Type:        "action_shorten_url",
+		Category:    "apps",
+		Name:        "Shorten URL",
+		Icon:        "🔗",
+		Description: "Shorten URL using TinyURL",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Short URL"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"url": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "url", Label: "Long URL", Type: "text", Placeholder: "https://example.com/very/long/url", Required: true},
+		},
+	},
+	{
+		Type:        "action_qrcode",
+		Category:    "apps",
+		Name:        "QR Code Pro",
+		Icon:        "📱",
+		Description: "Generate customizable QR codes",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "QR Result"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"data":    "",
+			"size":    "300",
+			"format":  "png",
+			"color":   "000000",
+			"bgcolor": "ffffff",
+			"margin":  "1",
+			"ecc":     "M",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "data", Label: "Data/URL", Type: "textarea", Placeholder: "https://example.com, text, vCard, WiFi config...", Required: true},
+			{Key: "size", Label: "Size (px)", Type: "select", Options: []NodeFieldOption{
+				{"150", "150x150 (Small)"},
+				{"200", "200x200"},
+				{"300", "300x300 (Default)"},
+				{"400", "400x400"},
+				{"500", "500x500"},
+				{"600", "600x600"},
+				{"800", "800x800 (Large)"},
+				{"1000", "1000x1000 (HD)"},
+			}},
+			{Key: "format", Label: "Format", Type: "select", Options: []NodeFieldOption{
+				{"png", "PNG (Recommended)"},
+				{"svg", "SVG (Vector)"},
+				{"eps", "EPS (Print)"},
+				{"gif", "GIF"},
+				{"jpg", "JPG"},
+			}},
+			{Key: "ecc", Label: "Error Correction", Type: "select", Options: []NodeFieldOption{
+				{"L", "Low (7% recovery)"},
+				{"M", "Medium (15% recovery)"},
+				{"Q", "Quartile (25% recovery)"},
+				{"H", "High (30% recovery)"},
+			}},
+			{Key: "color", Label: "QR Color (hex)", Type: "text", Placeholder: "000000 (black)", Default: "000000"},
+			{Key: "bgcolor", Label: "Background (hex)", Type: "text", Placeholder: "ffffff (white)", Default: "ffffff"},
+			{Key: "margin", Label: "Margin (modules)", Type: "select", Options: []NodeFieldOption{
+				{"0", "None"},
+				{"1", "1 (Minimal)"},
+				{"2", "2 (Default)"},
+				{"4", "4 (Large)"},
+			}},
+		},
+	},
+	{
+		Type:        "action_github",
+		Category:    "apps",
+		Name:        "GitHub API",
+		Icon:        "🐙",
+		Description: "Interact with GitHub API",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Response"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"token":  "",
+			"action": "repos",
+			"owner":  "",
+			"repo":   "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "token", Label: "Personal Access Token", Type: "password", Placeholder: "ghp_...", Required: true},
+			{Key: "action", Label: "Action", Type: "select", Options: []NodeFieldOption{
+				{"repos", "List Repositories"},
+				{"repo", "Get Repository Info"},
+				{"issues", "List Issues"},
+				{"commits", "List Commits"},
+				{"releases", "List Releases"},
+			}},
+			{Key: "owner", Label: "Owner/Org", Type: "text", Placeholder: "username or org"},
+			{Key: "repo", Label: "Repository", Type: "text", Placeholder: "repo-name"},
+		},
+	},
+	{
+		Type:        "action_notion",
+		Category:    "apps",
+		Name:        "Notion",
+		Icon:        "📓",
+		Description: "Add page to Notion database",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Page"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"apiKey":     "",
+			"databaseId": "",
+			"title":      "",
+			"content":    "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "apiKey", Label: "Integration Token", Type: "password", Placeholder: "secret_...", Required: true},
+			{Key: "databaseId", Label: "Database ID", Type: "text", Placeholder: "Database ID from URL", Required: true},
+			{Key: "title", Label: "Page Title", Type: "text", Placeholder: "{{topic}}", Required: true},
+			{Key: "content", Label: "Content", Type: "textarea", Placeholder: "{{output}}"},
+		},
+	},
+	{
+		Type:        "action_youtube",
+		Category:    "apps",
+		Name:        "YouTube Search",
+		Icon:        "▶️",
+		Description: "Search YouTube videos",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Videos"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"apiKey":     "",
+			"query":      "",
+			"maxResults": 5.0,
+			"type":       "video",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "apiKey", Label: "YouTube API Key", Type: "password", Placeholder: "Your API key", Required: true},
+			{Key: "query", Label: "Search Query", Type: "text", Placeholder: "{{topic}}", Required: true},
+			{Key: "maxResults", Label: "Max Results", Type: "number", Placeholder: "5", Default: 5.0},
+			{Key: "type", Label: "Type", Type: "select", Options: []NodeFieldOption{{"video", "Videos"}, {"channel", "Channels"}, {"playlist", "Playlists"}}},
+		},
+	},
+	{
+		Type:        "action_google_sheets",
+		Category:    "apps",
+		Name:        "Google Sheets",
+		Icon:        "📊",
+		Description: "Read/Write Google Sheets",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Data"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"apiKey":        "",
+			"spreadsheetId": "",
+			"range":         "Sheet1!A1:Z100",
+			"action":        "read",
+			"data":          "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "apiKey", Label: "API Key", Type: "password", Placeholder: "Your API key", Required: true},
+			{Key: "spreadsheetId", Label: "Spreadsheet ID", Type: "text", Placeholder: "From URL: /d/{ID}/edit", Required: true},
+			{Key: "action", Label: "Action", Type: "select", Options: []NodeFieldOption{{"read", "Read Data"}, {"append", "Append Row"}}},
+			{Key: "range", Label: "Range", Type: "text", Placeholder: "Sheet1!A1:Z100", Default: "Sheet1!A1:Z100"},
+			{Key: "data", Label: "Data (for append)", Type: "textarea", Placeholder: "[\"col1\", \"col2\"] or {{output}}"},
+		},
+	},
+	{
+		Type:        "action_twitter",
+		Category:    "apps",
+		Name:        "Twitter/X Post",
+		Icon:        "🐦",
+		Description: "Post tweet to Twitter/X",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Tweet"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"apiKey":       "",
+			"apiSecret":    "",
+			"accessToken":  "",
+			"accessSecret": "",
+			"text":         "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "apiKey", Label: "API Key", Type: "password", Placeholder: "Consumer Key", Required: true},
+			{Key: "apiSecret", Label: "API Secret", Type: "password", Placeholder: "Consumer Secret", Required: true},
+			{Key: "accessToken", Label: "Access Token", Type: "password", Placeholder: "Access Token", Required: true},
+			{Key: "accessSecret", Label: "Access Secret", Type: "password", Placeholder: "Access Token Secret", Required: true},
+			{Key: "text", Label: "Tweet Text", Type: "textarea", Placeholder: "{{output}} (max 280 chars)", Required: true},
+		},
+	},
+	{
+		Type:        "action_linkedin",
+		Category:    "apps",
+		Name:        "LinkedIn Post",
+		Icon:        "💼",
+		Description: "Post to LinkedIn",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Post"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"accessToken": "",
+			"text":        "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "accessToken", Label: "Access Token", Type: "password", Placeholder: "OAuth Access Token", Required: true},
+			{Key: "text", Label: "Post Text", Type: "textarea", Placeholder: "{{output}}", Required: true},
+		},
+	},
+	{
+		Type:        "action_airtable",
+		Category:    "apps",
+		Name:        "Airtable",
+		Icon:        "📋",
+		Description: "Read/Write Airtable records",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Records"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"apiKey":    "",
+			"baseId":    "",
+			"tableName": "",
+			"action":    "list",
+			"recordId":  "",
+			"fields":    "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "apiKey", Label: "API Key", Type: "password", Placeholder: "pat...", Required: true},
+			{Key: "baseId", Label: "Base ID", Type: "text", Placeholder: "app...", Required: true},
+			{Key: "tableName", Label: "Table Name", Type: "text", Placeholder: "Table 1", Required: true},
+			{Key: "action", Label: "Action", Type: "select", Options: []NodeFieldOption{
+				{"list", "List Records"},
+				{"get", "Get Record"},
+				{"create", "Create Record"},
+				{"update", "Update Record"},
+				{"delete", "Delete Record"},
+			}},
+			{Key: "recordId", Label: "Record ID (for get/update/delete)", Type: "text", Placeholder: "rec..."},
+			{Key: "fields", Label: "Fields JSON (for create/update)", Type: "textarea", Placeholder: "{\"Name\": \"{{name}}\"}"},
+		},
+	},
+	{
+		Type:        "action_supabase",
+		Category:    "apps",
+		Name:        "Supabase",
+		Icon:        "⚡",
+		Description: "Supabase database operations",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Data"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"url":     "",
+			"apiKey":  "",
+			"table":   "",
+			"action":  "select",
+			"data":    "",
+			"filters": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "url", Label: "Project URL", Type: "text", Placeholder: "https://xxx.supabase.co", Required: true},
+			{Key: "apiKey", Label: "API Key (anon)", Type: "password", Placeholder: "eyJ...", Required: true},
+			{Key: "table", Label: "Table Name", Type: "text", Placeholder: "users", Required: true},
+			{Key: "action", Label: "Action", Type: "select", Options: []NodeFieldOption{
+				{"select", "Select (Read)"},
+				{"insert", "Insert (Create)"},
+				{"update", "Update"},
+				{"delete", "Delete"},
+			}},
+			{Key: "data", Label: "Data JSON (for insert/update)", Type: "textarea", Placeholder: "{\"name\": \"{{name}}\"}"},
+			{Key: "filters", Label: "Filters (for select/update/delete)", Type: "text", Placeholder: "id=eq.1"},
+		},
+	},
+	{
+		Type:        "action_zapier",
+		Category:    "apps",
+		Name:        "Zapier Webhook",
+		Icon:        "⚡",
+		Description: "Trigger Zapier webhook",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Result"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"webhookUrl": "",
+			"data":       "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "webhookUrl", Label: "Webhook URL", Type: "password", Placeholder: "https://hooks.zapier.com/...", Required: true},
+			{Key: "data", Label: "Data (JSON)", Type: "textarea", Placeholder: "{{output}} or {\"key\": \"value\"}"},
+		},
+	},
+	{
+		Type:        "action_make",
+		Category:    "apps",
+		Name:        "Make Webhook",
+		Icon:        "🔄",
+		Description: "Trigger Make (Integromat) webhook",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Result"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"webhookUrl": "",
+			"data":       "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "webhookUrl", Label: "Webhook URL", Type: "password", Placeholder: "https://hook.make.com/...", Required: true},
+			{Key: "data", Label: "Data (JSON)", Type: "textarea", Placeholder: "{{output}} or {\"key\": \"value\"}"},
+		},
+	},
+	{
+		Type:        "action_dropbox",
+		Category:    "apps",
+		Name:        "Dropbox",
+		Icon:        "📦",
+		Description: "Upload/Download from Dropbox",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Result"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"accessToken": "",
+			"action":      "list",
+			"path":        "",
+			"content":     "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "accessToken", Label: "Access Token", Type: "password", Placeholder: "sl.B...", Required: true},
+			{Key: "action", Label: "Action", Type: "select", Options: []NodeFieldOption{{"list", "List Files"}, {"download", "Download File"}, {"upload", "Upload Text File"}}},
+			{Key: "path", Label: "Path", Type: "text", Placeholder: "/folder/file.txt", Required: true},
+			{Key: "content", Label: "Content (for upload)", Type: "textarea", Placeholder: "{{output}}"},
+		},
+	},
+	{
+		Type:        "action_mixpanel",
+		Category:    "apps",
+		Name:        "Mixpanel Track",
+		Icon:        "📈",
+		Description: "Track event in Mixpanel",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Result"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"token":      "",
+			"event":      "",
+			"distinctId": "",
+			"properties": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "token", Label: "Project Token", Type: "password", Placeholder: "Your project token", Required: true},
+			{Key: "event", Label: "Event Name", Type: "text", Placeholder: "Button Clicked", Required: true},
+			{Key: "distinctId", Label: "User ID", Type: "text", Placeholder: "{{userId}}", Required: true},
+			{Key: "properties", Label: "Properties (JSON)", Type: "textarea", Placeholder: "{\"plan\": \"pro\"}"},
+		},
+	},
+	{
+		Type:        "action_litterbox",
+		Category:    "apps",
+		Name:        "Litterbox Upload",
+		Icon:        "🐱",
+		Description: "Upload temporary file to Litterbox (1h-72h)",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "URL"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"filePath": "",
+			"expiry":   "24h",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "filePath", Label: "File Path", Type: "file", Placeholder: "/path/to/file.png", Required: true},
+			{Key: "expiry", Label: "Expiry Time", Type: "select", Options: []NodeFieldOption{
+				{"1h", "1 Hour"},
+				{"12h", "12 Hours"},
+				{"24h", "24 Hours"},
+				{"72h", "72 Hours (3 Days)"},
+			}},
+		},
+	},
+	{
+		Type:        "action_catbox",
+		Category:    "apps",
+		Name:        "Catbox",
+		Icon:        "📦",
+		Description: "Upload file to Catbox (permanent)",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Result"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"action":     "upload",
+			"filePath":   "",
+			"url":        "",
+			"userhash":   "",
+			"files":      "",
+			"albumShort": "",
+			"title":      "",
+			"desc":       "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "action", Label: "Action", Type: "select", Options: []NodeFieldOption{
+				{"upload", "Upload File"},
+				{"urlupload", "Upload from URL"},
+				{"delete", "Delete Files"},
+				{"createalbum", "Create Album"},
+				{"editalbum", "Edit Album"},
+				{"addtoalbum", "Add to Album"},
+				{"removefromalbum", "Remove from Album"},
+				{"deletealbum", "Delete Album"},
+			}},
+			{Key: "userhash", Label: "User Hash (optional)", Type: "password", Placeholder: "For account uploads"},
+			{Key: "filePath", Label: "File Path (for upload)", Type: "file", Placeholder: "/path/to/file.png"},
+			{Key: "url", Label: "URL (for urlupload)", Type: "text", Placeholder: "https://example.com/image.jpg"},
+			{Key: "files", Label: "Files (space-separated)", Type: "text", Placeholder: "abc123.png def456.gif"},
+			{Key: "albumShort", Label: "Album Short (6 chars)", Type: "text", Placeholder: "pd412w"},
+			{Key: "title", Label: "Album Title", Type: "text", Placeholder: "My Album"},
+			{Key: "desc", Label: "Album Description", Type: "text", Placeholder: "Album description"},
+		},
+	},
+	{
+		Type:        "action_hubspot",
+		Category:    "apps",
+		Name:        "HubSpot",
+		Icon:        "🧡",
+		Description: "HubSpot CRM operations",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Result"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"apiKey":     "",
+			"action":     "contacts",
+			"contactId":  "",
+			"properties": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "apiKey", Label: "Private App Token", Type: "password", Placeholder: "pat-...", Required: true},
+			{Key: "action", Label: "Action", Type: "select", Options: []NodeFieldOption{
+				{"contacts", "List Contacts"},
+				{"contact", "Get Contact"},
+				{"create_contact", "Create Contact"},
+				{"deals", "List Deals"},
+				{"companies", "List Companies"},
+			}},
+			{Key: "contactId", Label: "Contact ID (optional)", Type: "text", Placeholder: "123"},
+			{Key: "properties", Label: "Properties (for create)", Type: "textarea", Placeholder: "{\"email\": \"{{email}}\"}"},
+		},
+	},
+	{
+		Type:        "action_openai_image",
+		Category:    "apps",
+		Name:        "OpenAI Image (DALL-E)",
+		Icon:        "🎨",
+		Description: "Generate images with DALL-E",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Image URL"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"apiKey":  "",
+			"prompt":  "",
+			"size":    "1024x1024",
+			"model":   "dall-e-3",
+			"quality": "standard",
+			"style":   "vivid",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "apiKey", Label: "OpenAI API Key", Type: "password", Placeholder: "sk-...", Required: true},
+			{Key: "prompt", Label: "Image Description", Type: "textarea", Placeholder: "A futuristic city at sunset...", Required: true},
+			{Key: "model", Label: "Model", Type: "select", Options: []NodeFieldOption{{"dall-e-3", "DALL-E 3 (Best)"}, {"dall-e-2", "DALL-E 2 (Faster)"}}},
+			{Key: "size", Label: "Size", Type: "select", Options: []NodeFieldOption{{"1024x1024", "1024x1024 (Square)"}, {"1792x1024", "1792x1024 (Landscape)"}, {"1024x1792", "1024x1792 (Portrait)"}}},
+			{Key: "quality", Label: "Quality", Type: "select", Options: []NodeFieldOption{{"standard", "Standard"}, {"hd", "HD (More Detail)"}}},
+			{Key: "style", Label: "Style", Type: "select", Options: []NodeFieldOption{{"vivid", "Vivid (Vibrant)"}, {"natural", "Natural (Realistic)"}}},
+		},
+	},
+	{
+		Type:        "action_stability_image",
+		Category:    "apps",
+		Name:        "Stable Diffusion",
+		Icon:        "🖼️",
+		Description: "Generate images with Stable Diffusion API",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Image"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"apiKey":         "",
+			"prompt":         "",
+			"negativePrompt": "",
+			"size":           "1024x1024",
+			"steps":          30.0,
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "apiKey", Label: "Stability AI Key", Type: "password", Placeholder: "sk-...", Required: true},
+			{Key: "prompt", Label: "Prompt", Type: "textarea", Placeholder: "A beautiful landscape...", Required: true},
+			{Key: "negativePrompt", Label: "Negative Prompt", Type: "textarea", Placeholder: "blurry, low quality..."},
+			{Key: "size", Label: "Size", Type: "select", Options: []NodeFieldOption{{"512x512", "512x512"}, {"1024x1024", "1024x1024"}}},
+			{Key: "steps", Label: "Steps", Type: "select", Options: []NodeFieldOption{{"20", "20 (Fast)"}, {"30", "30 (Balanced)"}, {"50", "50 (Quality)"}}},
+		},
+	},
+	{
+		Type:        "action_screenshot",
+		Category:    "apps",
+		Name:        "Screenshot",
+		Icon:        "📸",
+		Description: "Capture screenshot of screen or window",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Image Path"}},
+		SideEffect:  SideEffectWrite,
+		Defaults: map[string]interface{}{
+			"savePath": "",
+			"display":  "0",
+			"format":   "png",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "savePath", Label: "Save Path", Type: "file-save", Placeholder: "/path/to/screenshot.png", Required: true, FileTypes: []string{".png", ".jpg"}},
+			{Key: "display", Label: "Display", Type: "select", Options: []NodeFieldOption{
+				{"0", "Primary Display"},
+				{"1", "Display 2"},
+				{"2", "Display 3"},
+				{"all", "All Displays"},
+			}},
+			{Key: "format", Label: "Format", Type: "select", Options: []NodeFieldOption{{"png", "PNG"}, {"jpg", "JPEG"}}},
+		},
+	},
+	{
+		Type:        "action_play_sound",
+		Category:    "apps",
+		Name:        "Play Sound",
+		Icon:        "🔊",
+		Description: "Play a sound notification",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Done"}},
+		SideEffect:  SideEffectWrite,
+		Defaults: map[string]interface{}{
+			"sound":      "beep",
+			"customPath": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "sound", Label: "Sound", Type: "select", Options: []NodeFieldOption{
+				{"beep", "Beep"},
+				{"success", "Success"},
+				{"error", "Error"},
+				{"notification", "Notification"},
+				{"custom", "Custom File"},
+			}},
+			{Key: "customPath", Label: "Custom Sound File", Type: "file", Placeholder: "/path/to/sound.mp3", FileTypes: []string{".mp3", ".wav"}},
+		},
+	},
+	{
+		Type:        "action_tts",
+		Category:    "apps",
+		Name:        "Text to Speech",
+		Icon:        "🗣️",
+		Description: "Convert text to speech audio",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Audio"}},
+		SideEffect:  SideEffectExternal,
+		Defaults: map[string]interface{}{
+			"provider": "openai",
+			"apiKey":   "",
+			"text":     "",
+			"voice":    "alloy",
+			"savePath": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "provider", Label: "Provider", Type: "select", Options: []NodeFieldOption{{"openai", "OpenAI TTS"}, {"elevenlabs", "ElevenLabs"}}},
+			{Key: "apiKey", Label: "API Key", Type: "password", Placeholder: "sk-... or xi-...", Required: true},
+			{Key: "text", Label: "Text", Type: "textarea", Placeholder: "{{output}} or text to speak", Required: true},
+			{Key: "voice", Label: "Voice", Type: "select", Options: []NodeFieldOption{
+				{"alloy", "Alloy (Neutral)"},
+				{"echo", "Echo (Male)"},
+				{"fable", "Fable (British)"},
+				{"onyx", "Onyx (Deep)"},
+				{"nova", "Nova (Female)"},
+				{"shimmer", "Shimmer (Soft)"},
+			}},
+			{Key: "savePath", Label: "Save To (optional)", Type: "file-save", Placeholder: "/path/to/audio.mp3", FileTypes: []string{".mp3"}},
+		},
+	},
+
+	// App settings and secrets
+	{
+		Type:        "app_settings_get",
+		Category:    "apps",
+		Name:        "Get App Setting",
+		Icon:        "⚙️",
+		Description: "Get a configuration setting from ForgeFlow",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Value"}},
+		SideEffect:  SideEffectRead,
+		Defaults: map[string]interface{}{
+			"key": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "key", Label: "Setting Key", Type: "text", Placeholder: "openai_api_key", Required: true},
+		},
+	},
+	{
+		Type:        "app_settings_set",
+		Category:    "apps",
+		Name:        "Set App Setting",
+		Icon:        "⚙️",
+		Description: "Update a configuration setting in ForgeFlow",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Done"}},
+		SideEffect:  SideEffectWrite,
+		Defaults: map[string]interface{}{
+			"key":   "",
+			"value": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "key", Label: "Setting Key", Type: "text", Placeholder: "openai_api_key", Required: true},
+			{Key: "value", Label: "New Value", Type: "textarea", Placeholder: "{{output}} or text", Required: true},
+		},
+	},
+	{
+		Type:        "app_secret_get",
+		Category:    "apps",
+		Name:        "Get Secret",
+		Icon:        "🔒",
+		Description: "Retrieve a secret from the secure vault (Requires Auth Key)",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Secret"}},
+		SideEffect:  SideEffectRead,
+		Defaults: map[string]interface{}{
+			"key": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "key", Label: "Secret Name", Type: "text", Placeholder: "my_api_key", Required: true},
+		},
+	},
+	{
+		Type:        "app_secret_set",
+		Category:    "apps",
+		Name:        "Set Secret",
+		Icon:        "🔒",
+		Description: "Store a secret in the secure vault",
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Done"}},
+		SideEffect:  SideEffectWrite,
+		Defaults: map[string]interface{}{
+			"key":   "",
+			"value": "",
+		},
+		Fields: []NodeFieldSpec{
+			{Key: "key", Label: "Secret Name", Type: "text", Placeholder: "my_api_key", Required: true},
+			{Key: "value", Label: "Secret Value", Type: "password", Placeholder: "...", Required: true},
+		},
+	},
+}
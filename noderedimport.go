@@ -0,0 +1,822 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Node-RED flows.json exports are converted on import, one ForgeFlow flow
+// per tab. Function nodes become JavaScript custom nodes carrying their
+// code; other nodes without an equivalent become comment placeholders, as
+// for n8n.
+
+type nodeRedMapper func(c *nodeRedConverter, n importParams) *importMapping
+
+var nodeRedMappers = map[string]nodeRedMapper{
+	"inject":       nodeRedInject,
+	"http in":      nodeRedHTTPIn,
+	"http request": nodeRedHTTPRequest,
+	"switch":       nodeRedSwitch,
+	"change":       nodeRedChange,
+	"file":         nodeRedFile,
+	"file in":      nodeRedFileIn,
+	"exec":         nodeRedExec,
+	"debug":        nodeRedDebug,
+	"template":     nodeRedTemplate,
+	"delay":        nodeRedDelay,
+	"comment":      nodeRedComment,
+	"junction":     nodeRedPassThrough,
+	"link in":      nodeRedPassThrough,
+	"link out":     nodeRedPassThrough,
+}
+
+// nodeRedNodes decodes a Node-RED export: an array of nodes, or the
+// {"flows": [...]} form of the admin API
+func nodeRedNodes(data []byte) ([]importParams, bool) {
+	var nodes []importParams
+	if err := json.Unmarshal(data, &nodes); err != nil {
+		var doc struct {
+			Flows []importParams `json:"flows"`
+		}
+		if err := json.Unmarshal(data, &doc); err != nil || doc.Flows == nil {
+			return nil, false
+		}
+		nodes = doc.Flows
+	}
+
+	wired := false
+	for _, node := range nodes {
+		if node.str("id") == "" || node.str("type") == "" {
+			return nil, false
+		}
+		if _, ok := node["wires"]; ok || node.str("type") == "tab" {
+			wired = true
+		}
+	}
+	return nodes, wired
+}
+
+func isNodeRedFlows(data []byte) bool {
+	_, ok := nodeRedNodes(data)
+	return ok
+}
+
+type nodeRedConverter struct {
+	report      *FlowImportReport
+	flow        string       // being converted, for notes
+	node        importParams // being converted, for notes
+	customNodes []json.RawMessage
+}
+
+// convertedFlow is a flow along with the custom nodes it brings, in the
+// form ImportFlow takes
+type convertedFlow struct {
+	Flow
+	CustomNodes []json.RawMessage `json:"customNodes,omitempty"`
+}
+
+// convertNodeRedFlows returns a Node-RED export as ForgeFlow flows, adding
+// what couldn't be converted to report. Nodes exported without their tab
+// are grouped into flows of their own.
+func convertNodeRedFlows(data []byte, report *FlowImportReport) ([][]byte, error) {
+	nodes, _ := nodeRedNodes(data)
+	report.Format = "node-red"
+	c := &nodeRedConverter{report: report}
+
+	type tab struct {
+		label, info string
+		disabled    bool
+		nodes       []importParams
+	}
+	tabs := make(map[string]*tab)
+	var order []string
+	subflows := make(map[string]bool)
+	for _, node := range nodes {
+		switch node.str("type") {
+		case "tab":
+			tabs[node.str("id")] = &tab{label: node.str("label"), info: node.str("info"), disabled: node["disabled"] == true}
+			order = append(order, node.str("id"))
+		case "subflow":
+			subflows[node.str("id")] = true
+			c.node = node
+			c.warn("subflows aren't supported; the subflow %q was left out", firstString(node.str("name"), node.str("id")))
+		}
+	}
+	c.node = nil
+
+	for _, node := range nodes {
+		nodeType, z := node.str("type"), node.str("z")
+		_, wired := node["wires"]
+		_, placed := node["x"]
+		switch {
+		case nodeType == "tab" || nodeType == "subflow" || nodeType == "group" || subflows[z]:
+		case !wired && !placed:
+			c.node = node
+			c.warn("configuration nodes aren't imported; fill in the settings of the nodes that used it")
+			c.node = nil
+		default:
+			t, ok := tabs[z]
+			if !ok {
+				t = &tab{}
+				tabs[z] = t
+				order = append(order, z)
+			}
+			t.nodes = append(t.nodes, node)
+		}
+	}
+
+	var flows [][]byte
+	for _, id := range order {
+		t := tabs[id]
+		if len(t.nodes) == 0 {
+			continue
+		}
+		c.flow = firstString(t.label, "Imported Node-RED flow")
+		flow, err := c.convertFlow(t.label, t.info, t.disabled, t.nodes)
+		if err != nil {
+			return nil, err
+		}
+		flows = append(flows, flow)
+	}
+	if len(flows) == 0 {
+		return nil, errors.New("the Node-RED export has no nodes to import")
+	}
+	return flows, nil
+}
+
+func (c *nodeRedConverter) convertFlow(label, info string, disabled bool, nodes []importParams) ([]byte, error) {
+	c.customNodes = nil
+	ids := make(map[string]string, len(nodes))
+	for i, node := range nodes {
+		ids[node.str("id")] = fmt.Sprintf("node-%d", i+1)
+	}
+
+	flowNodes := make([]FlowNode, 0, len(nodes))
+	mappings := make(map[string]*importMapping, len(nodes))
+	// A disabled node neither runs nor passes messages on in Node-RED, so
+	// it is left out along with its wires
+	left := make(map[string]bool)
+	for _, node := range nodes {
+		c.node = node
+		if node["d"] == true {
+			c.warn("the node is disabled in Node-RED; it was left out with its connections")
+			left[node.str("id")] = true
+			continue
+		}
+		id := ids[node.str("id")]
+		name := firstString(node.str("name"), node.str("type"))
+		x, y := node.num("x", 0), node.num("y", 0)
+
+		var mapping *importMapping
+		if node.str("type") == "function" {
+			flowNode := c.function(node, id, name)
+			// Every output of the function becomes the custom node's one output
+			outputs := make([]string, max(int(node.num("outputs", 1)), 1))
+			for i := range outputs {
+				outputs[i] = "out"
+			}
+			mapping = &importMapping{nodeType: flowNode.Data.NodeType, outputs: outputs}
+			flowNodes = append(flowNodes, flowNode)
+			c.report.Nodes++
+		} else {
+			if mapper, ok := nodeRedMappers[node.str("type")]; ok {
+				mapping = mapper(c, node)
+			}
+			if mapping == nil {
+				mapping = c.placeholder(node)
+			} else {
+				c.report.Nodes++
+			}
+			flowNodes = append(flowNodes, importedFlowNode(id, name, x, y, mapping.nodeType, mapping.config))
+		}
+		mappings[node.str("id")] = mapping
+	}
+
+	edges := []FlowEdge{}
+	connected := make(map[FlowEdge]bool)
+	connect := func(source importParams, output int, target string) {
+		mapping, ok := mappings[target]
+		if left[target] {
+			return
+		}
+		if !ok {
+			c.warn("the connection to node %s on another tab was left out", target)
+			return
+		}
+		targetHandle := "in"
+		if spec, ok := builtinNodeTypeIndex[mapping.nodeType]; ok {
+			if len(spec.Inputs) == 0 {
+				c.warn("the connection to %q was left out: it takes no input", target)
+				return
+			}
+			targetHandle = spec.Inputs[0].ID
+		}
+		sourceHandle, ok := mappings[source.str("id")].outputHandle(output)
+		if !ok {
+			c.warn("output %d doesn't exist in ForgeFlow; its connections start from %q", output+1, sourceHandle)
+		}
+		edge := FlowEdge{
+			Source:       ids[source.str("id")],
+			Target:       ids[target],
+			SourceHandle: sourceHandle,
+			TargetHandle: targetHandle,
+		}
+		// Outputs sharing a handle can wire the same node twice
+		if connected[edge] {
+			return
+		}
+		connected[edge] = true
+		edge.ID = fmt.Sprintf("edge-%d", len(edges)+1)
+		edges = append(edges, edge)
+	}
+	for _, node := range nodes {
+		if left[node.str("id")] {
+			continue
+		}
+		c.node = node
+		for output, targets := range nodeRedWires(node) {
+			for _, target := range targets {
+				connect(node, output, target)
+			}
+		}
+		// Link nodes connect without wires; links to other tabs are left out
+		if node.str("type") == "link out" && node.str("mode") != "return" {
+			links, _ := node["links"].([]interface{})
+			for _, link := range links {
+				connect(node, 0, fmt.Sprint(link))
+			}
+		}
+	}
+	c.node = nil
+
+	// A disabled tab stays off: its triggers are imported disabled
+	if disabled {
+		for i := range flowNodes {
+			if flowNodes[i].Data.Category == "trigger" {
+				flowNodes[i].Data.Config["enabled"] = false
+			}
+		}
+	}
+
+	name := label
+	if name == "" {
+		name = "Imported Node-RED flow"
+	}
+	if info == "" {
+		info = "Imported from Node-RED"
+	}
+	return json.Marshal(convertedFlow{
+		Flow: Flow{
+			SchemaVersion: FlowSchemaVersion,
+			Name:          name,
+			Description:   info,
+			Nodes:         flowNodes,
+			Edges:         edges,
+		},
+		CustomNodes: c.customNodes,
+	})
+}
+
+// nodeRedWires returns the node IDs wired to each output
+func nodeRedWires(node importParams) [][]string {
+	outputs, _ := node["wires"].([]interface{})
+	wires := make([][]string, len(outputs))
+	for i, output := range outputs {
+		targets, _ := output.([]interface{})
+		for _, target := range targets {
+			if id, ok := target.(string); ok {
+				wires[i] = append(wires[i], id)
+			}
+		}
+	}
+	return wires
+}
+
+func (c *nodeRedConverter) placeholder(node importParams) *importMapping {
+	comment := fmt.Sprintf("Node-RED node %q (%s) has no ForgeFlow equivalent and wasn't converted.", firstString(node.str("name"), node.str("id")), node.str("type"))
+	c.report.Placeholders = append(c.report.Placeholders, ImportNote{
+		Flow:    c.flow,
+		Node:    firstString(node.str("name"), node.str("type")),
+		Type:    node.str("type"),
+		Message: "no equivalent ForgeFlow node; imported as a comment",
+	})
+	settings := make(map[string]interface{}, len(node))
+	for key, value := range node {
+		switch key {
+		case "id", "type", "z", "name", "x", "y", "wires":
+		default:
+			settings[key] = value
+		}
+	}
+	return &importMapping{
+		nodeType: "util_comment",
+		config: map[string]interface{}{
+			"comment":           comment,
+			"color":             "#f59e0b",
+			"nodeRedType":       node.str("type"),
+			"nodeRedProperties": settings,
+		},
+	}
+}
+
+func (c *nodeRedConverter) warn(format string, args ...interface{}) {
+	note := ImportNote{Flow: c.flow, Message: fmt.Sprintf(format, args...)}
+	if c.node != nil {
+		note.Node = firstString(c.node.str("name"), c.node.str("type"))
+		note.Type = c.node.str("type")
+	}
+	c.report.Warnings = append(c.report.Warnings, note)
+}
+
+// nodeRedFunctionScript runs the body of a Node-RED function as a custom
+// node script. The previous node's output is msg.payload, and the payload
+// of the message the function returns is the node's output. Context stores
+// only last for the run.
+const nodeRedFunctionScript = `const msg = { payload: input._previousOutput, topic: "" };
+const store = {};
+const context = { get: (key) => store[key], set: (key, value) => { store[key] = value; }, keys: () => Object.keys(store) };
+const node = { id: %s, name: %s, log: console.log, warn: console.warn, error: console.error, status: () => {}, send: () => {}, done: () => {} };
+const env = { get: () => undefined };
+const result = (function (msg, node, context, flow, global, env) {
+%s
+})(msg, node, context, context, context, env);
+const out = Array.isArray(result) ? result.flat().find((m) => m != null) : result;
+return out == null ? null : out.payload;`
+
+var customNodeTypeChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// function converts a function node to a node of a new custom node type
+// running its code. The type is named after the node's ID, so importing the
+// same export again reuses it.
+func (c *nodeRedConverter) function(node importParams, id, name string) FlowNode {
+	nodeType := customNodePrefix + "nodered_" + customNodeTypeChars.ReplaceAllString(strings.ToLower(node.str("id")), "_")
+	code := node.str("func")
+	if node.num("outputs", 1) > 1 {
+		c.warn("only the first message the function returns is passed on; ForgeFlow nodes have one output")
+	}
+	if strings.Contains(code, "node.send(") {
+		c.warn("messages sent with node.send() are dropped; return them instead")
+	}
+	if strings.TrimSpace(node.str("initialize")) != "" || strings.TrimSpace(node.str("finalize")) != "" {
+		c.warn("the setup and close code wasn't imported")
+	}
+	if libs, _ := node["libs"].([]interface{}); len(libs) > 0 {
+		c.warn("modules can't be loaded by scripts and were left out")
+	}
+
+	definition := map[string]interface{}{
+		"type":        nodeType,
+		"name":        name,
+		"icon":        "ƒ",
+		"category":    "action",
+		"color":       "#6366f1",
+		"description": "Node-RED function, imported",
+		"inputs":      []map[string]interface{}{{"id": "in", "type": "input"}},
+		"outputs":     []map[string]interface{}{{"id": "out", "type": "output", "label": "Result"}},
+		"defaultData": map[string]interface{}{},
+		"fields":      []interface{}{},
+		"actionType":  "script",
+		"actionConfig": map[string]interface{}{
+			"script": fmt.Sprintf(nodeRedFunctionScript, strconv.Quote(node.str("id")), strconv.Quote(name), code),
+		},
+	}
+	data, _ := json.Marshal(definition)
+	c.customNodes = append(c.customNodes, data)
+
+	var flowNode FlowNode
+	flowNode.ID = id
+	flowNode.Type = "custom"
+	flowNode.Position.X = node.num("x", 0)
+	flowNode.Position.Y = node.num("y", 0)
+	flowNode.Data.Label = name
+	flowNode.Data.Category = "action"
+	flowNode.Data.Icon = "ƒ"
+	flowNode.Data.Description = "Node-RED function, imported"
+	flowNode.Data.NodeType = nodeType
+	flowNode.Data.Config = map[string]interface{}{}
+	return flowNode
+}
+
+var nodeRedMustache = regexp.MustCompile(`\{\{\{?\s*([^{}\s]+)\s*\}?\}\}`)
+
+// mustache converts the {{msg property}} placeholders of Node-RED templates
+// and URLs
+func (c *nodeRedConverter) mustache(text string) string {
+	return nodeRedMustache.ReplaceAllStringFunc(text, func(match string) string {
+		name := nodeRedMustache.FindStringSubmatch(match)[1]
+		if variable, ok := nodeRedVariable("msg", name); ok {
+			return "{{" + variable + "}}"
+		}
+		for _, scope := range []string{"flow", "global", "env"} {
+			if rest, ok := strings.CutPrefix(name, scope+"."); ok {
+				return "{{" + rest + "}}"
+			}
+		}
+		c.warn("{{%s}} was kept as it is; ForgeFlow only passes on msg.payload", name)
+		return match
+	})
+}
+
+// nodeRedVariable returns the ForgeFlow variable for a property: the
+// payload of a message is the previous node's output, and flow, global and
+// environment variables are flow variables
+func nodeRedVariable(propertyType, property string) (string, bool) {
+	switch propertyType {
+	case "", "msg":
+		if property == "payload" {
+			return "output", true
+		}
+		if rest, ok := strings.CutPrefix(property, "payload"); ok && (strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "[")) {
+			return "output" + rest, true
+		}
+	case "flow", "global", "env":
+		return property, property != ""
+	}
+	return "", false
+}
+
+// value returns a typed Node-RED value (a rule operand or a change target)
+// as config text
+func (c *nodeRedConverter) value(valueType string, value interface{}) (string, bool) {
+	text := fmt.Sprint(value)
+	if value == nil {
+		text = ""
+	}
+	switch valueType {
+	case "", "str", "num", "bool", "json", "re":
+		return text, true
+	case "msg", "flow", "global", "env":
+		if variable, ok := nodeRedVariable(valueType, text); ok {
+			return "{{" + variable + "}}", true
+		}
+	case "date":
+		return "{{now}}", false
+	}
+	return "", false
+}
+
+func nodeRedInject(c *nodeRedConverter, n importParams) *importMapping {
+	payloadType, payload := n.str("payloadType"), n.str("payload")
+	for _, prop := range n.list("props") {
+		if prop.str("p") == "payload" {
+			payloadType, payload = prop.str("vt"), prop.str("v")
+		}
+	}
+	if payloadType != "" && payloadType != "date" && payload != "" {
+		c.warn("the injected payload %q isn't sent; triggers start with no input", payload)
+	}
+
+	if crontab := strings.TrimSpace(n.str("crontab")); crontab != "" {
+		return &importMapping{nodeType: "trigger_schedule", config: map[string]interface{}{"cron": crontab}}
+	}
+	if seconds := int(n.num("repeat", 0)); seconds > 0 {
+		var cron string
+		switch {
+		case seconds%86400 == 0:
+			cron = fmt.Sprintf("0 0 %s * *", cronEvery(float64(seconds/86400)))
+		case seconds%3600 == 0:
+			cron = fmt.Sprintf("0 %s * * *", cronEvery(float64(seconds/3600)))
+		case seconds%60 == 0:
+			cron = fmt.Sprintf("%s * * * *", cronEvery(float64(seconds/60)))
+		default:
+			cron = "* * * * *"
+			c.warn("runs every minute: cron schedules can't repeat every %d seconds", seconds)
+		}
+		return &importMapping{nodeType: "trigger_schedule", config: map[string]interface{}{"cron": cron}}
+	}
+	if n["once"] == true {
+		return &importMapping{nodeType: "trigger_startup", config: map[string]interface{}{"delay": n.num("onceDelay", 0) * 1000}}
+	}
+	return &importMapping{nodeType: "trigger_manual"}
+}
+
+func nodeRedHTTPIn(c *nodeRedConverter, n importParams) *importMapping {
+	method := strings.ToUpper(firstString(n.str("method"), "GET"))
+	switch method {
+	case "GET", "POST", "PUT":
+	default:
+		c.warn("webhooks can't use %s; changed to POST", method)
+		method = "POST"
+	}
+	path := "/" + strings.TrimPrefix(n.str("url"), "/")
+	if strings.Contains(path, "/:") {
+		c.warn("route parameters in %s aren't supported", path)
+	}
+	return &importMapping{nodeType: "trigger_webhook", config: map[string]interface{}{
+		"method": method,
+		"path":   path,
+	}}
+}
+
+func nodeRedHTTPRequest(c *nodeRedConverter, n importParams) *importMapping {
+	method := strings.ToUpper(firstString(n.str("method"), "GET"))
+	switch method {
+	case "GET", "POST", "PUT", "DELETE":
+	case "USE":
+		c.warn("the method was set by msg.method; set it on the node")
+		method = "GET"
+	default:
+		c.warn("HTTP requests can't use %s; changed to POST", method)
+		method = "POST"
+	}
+	url := c.mustache(n.str("url"))
+	if url == "" {
+		c.warn("the URL was set by msg.url; set it on the node")
+	}
+	if auth := n.str("authType"); auth != "" {
+		c.warn("%s authentication isn't imported; add an Authorization header", auth)
+	}
+	if n.str("paytoqs") == "query" {
+		c.warn("the payload isn't added to the query string; add it to the URL")
+	}
+
+	headers := map[string]string{}
+	for _, h := range n.list("headers") {
+		name := h.str("keyType")
+		if name == "other" {
+			name = h.str("keyValue")
+		}
+		value := h.str("valueType")
+		if value == "other" {
+			value = h.str("valueValue")
+		}
+		if name == "msg" || value == "msg" {
+			c.warn("headers taken from the message were left out")
+			continue
+		}
+		headers[name] = value
+	}
+	headerJSON, _ := json.Marshal(headers)
+
+	body := ""
+	if method == "POST" || method == "PUT" {
+		body = "{{output}}"
+	}
+	return &importMapping{nodeType: "action_http", config: map[string]interface{}{
+		"method":  method,
+		"url":     url,
+		"headers": string(headerJSON),
+		"body":    body,
+	}}
+}
+
+// nodeRedSwitch converts a single rule, with or without an otherwise rule,
+// to an If node. Up to three rules route through a Switch node, whose
+// value has to be set again.
+func nodeRedSwitch(c *nodeRedConverter, n importParams) *importMapping {
+	rules := n.list("rules")
+	subject, ok := nodeRedVariable(n.str("propertyType"), firstString(n.str("property"), "payload"))
+	if !ok {
+		c.warn("msg.%s doesn't exist in ForgeFlow; only msg.payload is passed on", n.str("property"))
+		return nil
+	}
+
+	if len(rules) == 1 || (len(rules) == 2 && rules[1].str("t") == "else") {
+		if condition, ok := c.rule("{{"+subject+"}}", rules[0]); ok {
+			return &importMapping{nodeType: "condition_if", outputs: []string{"true", "false"}, config: map[string]interface{}{"condition": condition}}
+		}
+		c.warn("the %s rule wasn't converted", rules[0].str("t"))
+		return nil
+	}
+
+	outputs := make([]string, 0, len(rules))
+	cases := 0
+	for _, rule := range rules {
+		if rule.str("t") == "else" {
+			outputs = append(outputs, "default")
+			continue
+		}
+		cases++
+		if cases > 3 {
+			return nil
+		}
+		outputs = append(outputs, fmt.Sprintf("case%d", cases))
+	}
+	c.warn("the rules weren't converted: set Value to an expression giving case1, case2, case3 or default")
+	return &importMapping{nodeType: "condition_switch", outputs: outputs, config: map[string]interface{}{"value": ""}}
+}
+
+// rule renders a switch rule as JavaScript for condition_if. Interpolated
+// values are compared as strings unless the rule compares numbers or
+// booleans.
+func (c *nodeRedConverter) rule(subject string, rule importParams) (string, bool) {
+	valueType := rule.str("vt")
+	value, ok := c.value(valueType, rule["v"])
+	if !ok {
+		return "", false
+	}
+	a, b := subject, value
+	switch valueType {
+	case "str", "re", "msg", "flow", "global", "env":
+		a, b = strconv.Quote(a), strconv.Quote(b)
+	}
+
+	switch rule.str("t") {
+	case "eq":
+		return a + " == " + b, true
+	case "neq":
+		return a + " != " + b, true
+	case "lt":
+		return a + " < " + b, true
+	case "lte":
+		return a + " <= " + b, true
+	case "gt":
+		return a + " > " + b, true
+	case "gte":
+		return a + " >= " + b, true
+	case "btwn":
+		upper, ok := c.value(rule.str("v2t"), rule["v2"])
+		if !ok {
+			return "", false
+		}
+		return a + " >= " + b + " && " + a + " <= " + upper, true
+	case "cont":
+		return strconv.Quote(subject) + ".includes(" + b + ")", true
+	case "regex":
+		flags := ""
+		if rule["case"] == true {
+			flags = ", \"i\""
+		}
+		return "new RegExp(" + b + flags + ").test(" + strconv.Quote(subject) + ")", true
+	case "true":
+		return subject + " === true", true
+	case "false":
+		return subject + " === false", true
+	case "null":
+		return subject + " == null", true
+	case "nnull":
+		return subject + " != null", true
+	case "empty":
+		return strconv.Quote(subject) + ` === ""`, true
+	case "nempty":
+		return strconv.Quote(subject) + ` !== ""`, true
+	}
+	return "", false
+}
+
+// nodeRedChange converts the first rule of a Change node that sets the
+// payload, a payload field or a flow variable, or deletes a payload field
+func nodeRedChange(c *nodeRedConverter, n importParams) *importMapping {
+	rules := n.list("rules")
+	if len(rules) == 0 {
+		return nil
+	}
+	if len(rules) > 1 {
+		c.warn("only the first of %d rules was converted; add a node for each of the others", len(rules))
+	}
+	rule := rules[0]
+	property, propertyType := rule.str("p"), rule.str("pt")
+
+	switch rule.str("t") {
+	case "set":
+		value, ok := c.value(rule.str("tot"), rule["to"])
+		if !ok {
+			return nil
+		}
+		switch {
+		case propertyType == "flow" || propertyType == "global":
+			return &importMapping{nodeType: "action_set_variable", config: map[string]interface{}{"name": property, "value": value}}
+		case propertyType != "" && propertyType != "msg":
+		case property == "payload" && rule.str("tot") == "json":
+			return &importMapping{nodeType: "action_json_parse", config: map[string]interface{}{"json": value}}
+		case property == "payload":
+			return &importMapping{nodeType: "action_template", config: map[string]interface{}{"template": value}}
+		case strings.HasPrefix(property, "payload."):
+			return &importMapping{nodeType: "util_field", config: map[string]interface{}{
+				"mode":  "set",
+				"path":  strings.TrimPrefix(property, "payload."),
+				"value": value,
+			}}
+		}
+	case "delete":
+		if (propertyType == "" || propertyType == "msg") && strings.HasPrefix(property, "payload.") {
+			return &importMapping{nodeType: "util_object", config: map[string]interface{}{
+				"mode":   "delete",
+				"object": "{{output}}",
+				"fields": strings.TrimPrefix(property, "payload."),
+			}}
+		}
+	}
+	return nil
+}
+
+// nodeRedFile converts writing, appending to and deleting a file
+func nodeRedFile(c *nodeRedConverter, n importParams) *importMapping {
+	path := n.str("filename")
+	if path == "" || n.str("filenameType") == "msg" {
+		c.warn("the file name was set by the message; set it on the node")
+		path = ""
+	}
+	switch n.str("overwriteFile") {
+	case "delete":
+		return &importMapping{nodeType: "action_file_manage", config: map[string]interface{}{"operation": "delete", "source": path}}
+	case "true":
+		return &importMapping{nodeType: "action_file", config: map[string]interface{}{"mode": "write", "path": path, "content": nodeRedFileContent(n)}}
+	default:
+		return &importMapping{nodeType: "action_file", config: map[string]interface{}{"mode": "append", "path": path, "content": nodeRedFileContent(n)}}
+	}
+}
+
+func nodeRedFileContent(n importParams) string {
+	if n["appendNewline"] == true {
+		return "{{output}}\n"
+	}
+	return "{{output}}"
+}
+
+func nodeRedFileIn(c *nodeRedConverter, n importParams) *importMapping {
+	path := n.str("filename")
+	if path == "" || n.str("filenameType") == "msg" {
+		c.warn("the file name was set by the message; set it on the node")
+		path = ""
+	}
+	if format := n.str("format"); format != "utf8" {
+		c.warn("the file is read as text rather than %s", firstString(format, "a buffer"))
+	}
+	return &importMapping{nodeType: "action_file", config: map[string]interface{}{"mode": "read", "path": path}}
+}
+
+// nodeRedExec converts the command; Run Script returns stdout, stderr and
+// the exit code together, so every output becomes its one output
+func nodeRedExec(c *nodeRedConverter, n importParams) *importMapping {
+	parts := []string{n.str("command")}
+	if addpay := n.str("addpay"); addpay == "true" || addpay == "payload" {
+		parts = append(parts, "{{output}}")
+	}
+	parts = append(parts, n.str("append"))
+	if strings.TrimSpace(n.str("command")) == "" {
+		return nil
+	}
+	config, ok := importedCommand(strings.Join(parts, " "))
+	if !ok {
+		c.warn("the command needs a shell, which Run Script doesn't use")
+		return nil
+	}
+	wires := nodeRedWires(n)
+	for _, i := range []int{1, 2} {
+		if i < len(wires) && len(wires[i]) > 0 {
+			c.warn("the stderr and return code outputs are combined with stdout: Run Script returns them together")
+			break
+		}
+	}
+	return &importMapping{nodeType: "action_script", outputs: []string{"out", "out", "out"}, config: config}
+}
+
+func nodeRedDebug(c *nodeRedConverter, n importParams) *importMapping {
+	message := "{{output}}"
+	switch complete := n.str("complete"); complete {
+	case "", "false", "true", "payload":
+	default:
+		if variable, ok := nodeRedVariable(n.str("targetType"), complete); ok {
+			message = "{{" + variable + "}}"
+		} else {
+			c.warn("msg.%s doesn't exist in ForgeFlow; the output is logged instead", complete)
+		}
+	}
+	if n["active"] == false {
+		c.warn("the node was turned off in Node-RED, but logs in ForgeFlow")
+	}
+	return &importMapping{nodeType: "action_log", config: map[string]interface{}{"message": message, "level": "info"}}
+}
+
+func nodeRedTemplate(c *nodeRedConverter, n importParams) *importMapping {
+	if field := n.str("field"); field != "" && field != "payload" {
+		c.warn("the result goes to the output rather than msg.%s", field)
+	}
+	template := n.str("template")
+	if n.str("syntax") != "plain" {
+		template = c.mustache(template)
+	}
+	return &importMapping{nodeType: "action_template", config: map[string]interface{}{"template": template}}
+}
+
+// nodeRedDelay converts a fixed delay; rate limits have no equivalent
+func nodeRedDelay(c *nodeRedConverter, n importParams) *importMapping {
+	if pause := n.str("pauseType"); pause != "" && pause != "delay" {
+		return nil
+	}
+	unit := map[string]float64{
+		"milliseconds": 1,
+		"seconds":      1000,
+		"minutes":      60 * 1000,
+		"hours":        60 * 60 * 1000,
+		"days":         24 * 60 * 60 * 1000,
+	}[firstString(n.str("timeoutUnits"), "seconds")]
+	if unit == 0 {
+		return nil
+	}
+	return &importMapping{nodeType: "action_delay", config: map[string]interface{}{"duration": n.num("timeout", 5) * unit}}
+}
+
+func nodeRedComment(c *nodeRedConverter, n importParams) *importMapping {
+	comment := strings.TrimSpace(n.str("name") + "\n\n" + n.str("info"))
+	return &importMapping{nodeType: "util_comment", config: map[string]interface{}{"comment": comment}}
+}
+
+func nodeRedPassThrough(c *nodeRedConverter, n importParams) *importMapping {
+	return &importMapping{nodeType: "util_comment"}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// convertNodeRed converts a Node-RED export, returning its flows
+func convertNodeRed(t *testing.T, export string) ([]convertedFlow, *FlowImportReport) {
+	t.Helper()
+	if !isNodeRedFlows([]byte(export)) {
+		t.Fatalf("%s isn't recognised as a Node-RED export", export)
+	}
+	report := &FlowImportReport{}
+	converted, err := convertNodeRedFlows([]byte(export), report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flows := make([]convertedFlow, len(converted))
+	for i, data := range converted {
+		if err := json.Unmarshal(data, &flows[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return flows, report
+}
+
+func flowEdges(flow Flow) []string {
+	edges := []string{}
+	for _, e := range flow.Edges {
+		edges = append(edges, e.Source+":"+e.SourceHandle+"->"+e.Target)
+	}
+	return edges
+}
+
+func TestConvertNodeRedNodes(t *testing.T) {
+	tests := []struct {
+		name        string
+		node        string
+		nodeType    string
+		config      map[string]interface{} // checked keys only
+		placeholder bool
+		warning     string
+	}{
+		{
+			name:     "inject",
+			node:     `{"id":"a","type":"inject","z":"t","x":0,"y":0,"wires":[[]]}`,
+			nodeType: "trigger_manual",
+		},
+		{
+			name:     "repeating inject",
+			node:     `{"id":"a","type":"inject","z":"t","repeat":"300","wires":[[]]}`,
+			nodeType: "trigger_schedule",
+			config:   map[string]interface{}{"cron": "*/5 * * * *"},
+		},
+		{
+			name:     "inject every few seconds",
+			node:     `{"id":"a","type":"inject","z":"t","repeat":"30","wires":[[]]}`,
+			nodeType: "trigger_schedule",
+			config:   map[string]interface{}{"cron": "* * * * *"},
+			warning:  "runs every minute: cron schedules can't repeat every 30 seconds",
+		},
+		{
+			name:     "debug",
+			node:     `{"id":"a","type":"debug","z":"t","wires":[]}`,
+			nodeType: "action_log",
+			config:   map[string]interface{}{"message": "{{output}}"},
+		},
+		{
+			name:     "delay",
+			node:     `{"id":"a","type":"delay","z":"t","pauseType":"delay","timeout":"2","timeoutUnits":"minutes","wires":[[]]}`,
+			nodeType: "action_delay",
+			config:   map[string]interface{}{"duration": float64(120000)},
+		},
+		{
+			name:     "template",
+			node:     `{"id":"a","type":"template","z":"t","template":"Hi {{payload.name}}","wires":[[]]}`,
+			nodeType: "action_template",
+			config:   map[string]interface{}{"template": "Hi {{output.name}}"},
+		},
+		{
+			name:     "plain exec",
+			node:     `{"id":"a","type":"exec","z":"t","command":"backup.sh","addpay":"","append":"--full","wires":[[],[],[]]}`,
+			nodeType: "action_script",
+			config:   map[string]interface{}{"command": "backup.sh", "args": "--full"},
+		},
+		{
+			name:     "exec appending the payload",
+			node:     `{"id":"a","type":"exec","z":"t","command":"notify","addpay":"payload","append":"","wires":[[],[],[]]}`,
+			nodeType: "action_script",
+			config:   map[string]interface{}{"command": "notify", "args": "{{output}}"},
+		},
+		{
+			name:        "exec using a shell",
+			node:        `{"id":"a","type":"exec","z":"t","command":"ls -l | grep x","wires":[[],[],[]]}`,
+			nodeType:    "util_comment",
+			placeholder: true,
+			warning:     "the command needs a shell, which Run Script doesn't use",
+		},
+		{
+			name:        "unknown node type",
+			node:        `{"id":"a","type":"mqtt in","z":"t","topic":"sensors","wires":[[]]}`,
+			nodeType:    "util_comment",
+			config:      map[string]interface{}{"nodeRedType": "mqtt in"},
+			placeholder: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flows, report := convertNodeRed(t, `[{"id":"t","type":"tab","label":"Main"},`+tt.node+`]`)
+			if len(flows) != 1 || len(flows[0].Nodes) != 1 {
+				t.Fatalf("flows = %+v", flows)
+			}
+			node := flows[0].Nodes[0]
+			if node.Data.NodeType != tt.nodeType {
+				t.Fatalf("node type = %q, want %q", node.Data.NodeType, tt.nodeType)
+			}
+			for key, want := range tt.config {
+				if got := node.Data.Config[key]; !reflect.DeepEqual(got, want) {
+					t.Errorf("config.%s = %#v, want %#v", key, got, want)
+				}
+			}
+
+			if tt.placeholder != (len(report.Placeholders) == 1) || tt.placeholder == (report.Nodes == 1) {
+				t.Errorf("report = %+v", report)
+			}
+			if tt.warning == "" {
+				if len(report.Warnings) != 0 {
+					t.Errorf("warnings = %+v", report.Warnings)
+				}
+			} else if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0].Message, tt.warning) || report.Warnings[0].Flow != "Main" {
+				t.Errorf("warnings = %+v, want %q", report.Warnings, tt.warning)
+			}
+		})
+	}
+}
+
+func TestConvertNodeRedDisabledNode(t *testing.T) {
+	flows, report := convertNodeRed(t, `[
+		{"id":"t","type":"tab","label":"Main"},
+		{"id":"a","type":"inject","z":"t","name":"Start","wires":[["b","c"]]},
+		{"id":"b","type":"debug","z":"t","name":"Off","d":true,"wires":[]},
+		{"id":"c","type":"delay","z":"t","name":"Wait","wires":[["b"]]}
+	]`)
+	if len(flows) != 1 {
+		t.Fatalf("flows = %+v", flows)
+	}
+	var labels []string
+	for _, n := range flows[0].Nodes {
+		labels = append(labels, n.Data.Label)
+	}
+	if !reflect.DeepEqual(labels, []string{"Start", "Wait"}) {
+		t.Errorf("nodes = %q", labels)
+	}
+	if got := flowEdges(flows[0].Flow); !reflect.DeepEqual(got, []string{"node-1:out->node-3"}) {
+		t.Errorf("edges = %q", got)
+	}
+	want := []ImportNote{{Flow: "Main", Node: "Off", Type: "debug", Message: "the node is disabled in Node-RED; it was left out with its connections"}}
+	if !reflect.DeepEqual(report.Warnings, want) {
+		t.Errorf("warnings = %+v", report.Warnings)
+	}
+}
+
+func TestConvertNodeRedTabs(t *testing.T) {
+	flows, report := convertNodeRed(t, `[
+		{"id":"t1","type":"tab","label":"First","info":"Does the first thing"},
+		{"id":"t2","type":"tab","label":"Second","disabled":true},
+		{"id":"t3","type":"tab","label":"Empty"},
+		{"id":"broker","type":"mqtt-broker","name":"Local"},
+		{"id":"a","type":"inject","z":"t1","wires":[["b"]]},
+		{"id":"b","type":"exec","z":"t1","command":"date","wires":[["x"],["c"],[]]},
+		{"id":"c","type":"debug","z":"t1","wires":[]},
+		{"id":"x","type":"inject","z":"t2","once":true,"wires":[[]]}
+	]`)
+	if len(flows) != 2 {
+		t.Fatalf("flows = %+v", flows)
+	}
+	if flows[0].Name != "First" || flows[0].Description != "Does the first thing" || flows[1].Name != "Second" || flows[1].Description != "Imported from Node-RED" {
+		t.Errorf("flows = %q %q, %q %q", flows[0].Name, flows[0].Description, flows[1].Name, flows[1].Description)
+	}
+	// The exec outputs share one handle, and the wire to the other tab is dropped
+	if got := flowEdges(flows[0].Flow); !reflect.DeepEqual(got, []string{"node-1:out->node-2", "node-2:out->node-3"}) {
+		t.Errorf("edges = %q", got)
+	}
+	if got := flows[1].Nodes[0].Data.Config["enabled"]; got != false {
+		t.Errorf("trigger of a disabled tab: enabled = %v", got)
+	}
+
+	var warnings []string
+	for _, w := range report.Warnings {
+		warnings = append(warnings, w.Message)
+	}
+	want := []string{
+		"configuration nodes aren't imported; fill in the settings of the nodes that used it",
+		"the stderr and return code outputs are combined with stdout: Run Script returns them together",
+		"the connection to node x on another tab was left out",
+	}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("warnings = %q", warnings)
+	}
+}
+
+func TestImportNodeRedFlows(t *testing.T) {
+	s := NewStorageAt(t.TempDir())
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.close)
+
+	report, err := s.ImportFlowWithReport(`[
+		{"id":"t1","type":"tab","label":"First"},
+		{"id":"t2","type":"tab","label":"Second"},
+		{"id":"a","type":"inject","z":"t1","wires":[["f"]]},
+		{"id":"f","type":"function","z":"t1","name":"Double","func":"msg.payload = msg.payload * 2;\nreturn msg;","outputs":1,"wires":[[]]},
+		{"id":"b","type":"inject","z":"t2","wires":[[]]}
+	]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Format != "node-red" || len(report.FlowIDs) != 2 || report.FlowID != report.FlowIDs[0] || report.Nodes != 3 {
+		t.Fatalf("report = %+v", report)
+	}
+	for i, name := range []string{"First", "Second"} {
+		if got := flowName(t, s, report.FlowIDs[i]); got != name {
+			t.Errorf("flow %d = %q, want %q", i, got, name)
+		}
+	}
+
+	data, err := s.LoadFlow(report.FlowIDs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var flow Flow
+	if err := json.Unmarshal([]byte(data), &flow); err != nil {
+		t.Fatal(err)
+	}
+	nodeType := flow.Nodes[1].Data.NodeType
+	if !isCustomNodeType(nodeType) {
+		t.Fatalf("function node type = %q", nodeType)
+	}
+	def, err := s.LoadCustomNode(nodeType)
+	if err != nil {
+		t.Fatalf("the function's custom node wasn't stored: %v", err)
+	}
+	if !strings.Contains(def, "msg.payload * 2") {
+		t.Errorf("custom node = %s", def)
+	}
+}
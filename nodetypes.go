@@ -0,0 +1,286 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Side-effect classes say what running a node may touch, so callers can
+// tell safe nodes (e.g. for previews or dry runs) from ones with effects
+const (
+	SideEffectNone     = "none"     // output depends only on config and input
+	SideEffectRead     = "read"     // reads local files, the clipboard, settings or secrets
+	SideEffectWrite    = "write"    // changes local files, the clipboard, settings or the desktop
+	SideEffectExternal = "external" // calls services over the network
+)
+
+// NodeTypeSpec describes a node type: its config schema, handles and
+// side-effect class
+type NodeTypeSpec struct {
+	Type        string                 `json:"type"`
+	Category    string                 `json:"category"`
+	Name        string                 `json:"name"`
+	Icon        string                 `json:"icon,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Inputs      []NodeHandle           `json:"inputs"`
+	Outputs     []NodeHandle           `json:"outputs"`
+	Fields      []NodeFieldSpec        `json:"fields"`
+	Defaults    map[string]interface{} `json:"defaults"`
+	SideEffect  string                 `json:"sideEffect"`
+	Source      string                 `json:"source"`           // "builtin", "plugin" or "custom"
+	Plugin      string                 `json:"plugin,omitempty"` // providing plugin
+}
+
+type NodeHandle struct {
+	ID    string `json:"id"`
+	Label string `json:"label,omitempty"`
+}
+
+// NodeFieldSpec is a config field, in the shape of the frontend's NodeField
+type NodeFieldSpec struct {
+	Key         string            `json:"key"`
+	Label       string            `json:"label"`
+	Type        string            `json:"type"` // text, number, boolean, select, json, ...
+	Placeholder string            `json:"placeholder,omitempty"`
+	Required    bool              `json:"required,omitempty"`
+	Default     interface{}       `json:"defaultValue,omitempty"`
+	Options     []NodeFieldOption `json:"options,omitempty"`
+	FileTypes   []string          `json:"fileTypes,omitempty"`
+}
+
+type NodeFieldOption struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+}
+
+// UnmarshalJSON accepts numeric and boolean option values, which custom
+// nodes and plugins may use
+func (o *NodeFieldOption) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Value interface{} `json:"value"`
+		Label string      `json:"label"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	o.Label = raw.Label
+	if raw.Value != nil {
+		o.Value = fmt.Sprint(raw.Value)
+	}
+	return nil
+}
+
+var builtinNodeTypeIndex = func() map[string]*NodeTypeSpec {
+	index := make(map[string]*NodeTypeSpec, len(builtinNodeTypes))
+	for i := range builtinNodeTypes {
+		builtinNodeTypes[i].Source = "builtin"
+		index[builtinNodeTypes[i].Type] = &builtinNodeTypes[i]
+	}
+	return index
+}()
+
+// GetNodeTypes lists every node type the engine can run: built-in types,
+// then plugin and custom node types
+func (e *Engine) GetNodeTypes() []NodeTypeSpec {
+	types := make([]NodeTypeSpec, 0, len(builtinNodeTypes))
+	types = append(types, builtinNodeTypes...)
+
+	if pm := e.pluginManager(); pm != nil {
+		types = append(types, pm.nodeTypeSpecs()...)
+	}
+
+	if nodes, err := e.storage.ListCustomNodes(); err == nil {
+		for _, node := range nodes {
+			data, _ := json.Marshal(node)
+			var def CustomNodeDefinition
+			if err := json.Unmarshal(data, &def); err == nil {
+				types = append(types, def.nodeTypeSpec())
+			}
+		}
+	}
+	return types
+}
+
+// nodeTypeSpec looks up a plugin, built-in or custom node type
+func (e *Engine) nodeTypeSpec(nodeType string) (*NodeTypeSpec, bool) {
+	// Same precedence as runNode: plugins first
+	if pm := e.pluginManager(); pm.Has(nodeType) {
+		for _, spec := range pm.nodeTypeSpecs() {
+			if spec.Type == nodeType {
+				return &spec, true
+			}
+		}
+	}
+	if spec, ok := builtinNodeTypeIndex[nodeType]; ok {
+		return spec, true
+	}
+	if isCustomNodeType(nodeType) {
+		if def, err := e.storage.loadCustomNodeDefinition(nodeType); err == nil {
+			spec := def.nodeTypeSpec()
+			return &spec, true
+		}
+	}
+	return nil, false
+}
+
+func (def *CustomNodeDefinition) nodeTypeSpec() NodeTypeSpec {
+	sideEffect := SideEffectNone
+	switch def.ActionType {
+	case "shell":
+		sideEffect = SideEffectWrite
+	case "http":
+		sideEffect = SideEffectExternal
+	}
+	return NodeTypeSpec{
+		Type:        def.Type,
+		Category:    def.Category,
+		Name:        def.Name,
+		Icon:        def.Icon,
+		Description: def.Description,
+		Inputs:      []NodeHandle{{ID: "in"}},
+		Outputs:     []NodeHandle{{ID: "out", Label: "Result"}},
+		Fields:      def.Fields,
+		Defaults:    def.DefaultData,
+		SideEffect:  sideEffect,
+		Source:      "custom",
+	}
+}
+
+// nodeTypeSpecs describes the node types of the loaded plugins. Process
+// plugins run with the user's permissions, so their nodes count as writing.
+func (pm *PluginManager) nodeTypeSpecs() []NodeTypeSpec {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	var specs []NodeTypeSpec
+	for _, p := range pm.plugins {
+		if p.err != nil {
+			continue
+		}
+		sideEffect := SideEffectNone
+		switch {
+		case p.manifest.Runtime == "process":
+			sideEffect = SideEffectWrite
+		case len(p.manifest.Permissions.HTTP) > 0:
+			sideEffect = SideEffectExternal
+		case len(p.manifest.Permissions.ReadPaths) > 0:
+			sideEffect = SideEffectRead
+		}
+		for _, node := range p.manifest.Nodes {
+			if pm.nodes[node.Type] != p {
+				continue // shadowed by another plugin
+			}
+			var fields []NodeFieldSpec
+			if data, err := json.Marshal(node.Fields); err == nil {
+				json.Unmarshal(data, &fields)
+			}
+			category := node.Category
+			if category == "" {
+				category = "utility"
+			}
+			specs = append(specs, NodeTypeSpec{
+				Type:        node.Type,
+				Category:    category,
+				Name:        node.Name,
+				Icon:        node.Icon,
+				Description: node.Description,
+				Inputs:      []NodeHandle{{ID: "in"}},
+				Outputs:     []NodeHandle{{ID: "out", Label: "Output"}},
+				Fields:      fields,
+				Defaults:    node.DefaultData,
+				SideEffect:  sideEffect,
+				Source:      "plugin",
+				Plugin:      p.manifest.Name,
+			})
+		}
+	}
+	return specs
+}
+
+// applyNodeDefaults fills config keys a node leaves unset from its type's
+// defaults
+func (e *Engine) applyNodeDefaults(flow *Flow) {
+	applyNodeDefaults(flow, e.nodeTypeSpec)
+}
+
+func applyNodeDefaults(flow *Flow, nodeTypeSpec func(string) (*NodeTypeSpec, bool)) {
+	for i := range flow.Nodes {
+		node := &flow.Nodes[i]
+		spec, ok := nodeTypeSpec(node.Data.NodeType)
+		if !ok || len(spec.Defaults) == 0 {
+			continue
+		}
+		if node.Data.Config == nil {
+			node.Data.Config = make(map[string]interface{}, len(spec.Defaults))
+		}
+		for key, value := range spec.Defaults {
+			if _, set := node.Data.Config[key]; !set {
+				node.Data.Config[key] = value
+			}
+		}
+	}
+}
+
+// checkNodeConfig returns the problems with a node's config: missing
+// required fields and values that don't fit a field's type. Values holding
+// {{expressions}} are resolved at run time and not checked.
+func checkNodeConfig(spec *NodeTypeSpec, config map[string]interface{}) []string {
+	var problems []string
+	for _, field := range spec.Fields {
+		value, set := config[field.Key]
+		if !set || value == nil || value == "" {
+			if field.Required {
+				problems = append(problems, field.Label+" is required")
+			}
+			continue
+		}
+		if s, ok := value.(string); ok && strings.Contains(s, "{{") {
+			continue
+		}
+
+		switch field.Type {
+		case "number":
+			switch v := value.(type) {
+			case float64:
+			case string:
+				if _, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err != nil {
+					problems = append(problems, fmt.Sprintf("%s must be a number, got %q", field.Label, v))
+				}
+			default:
+				problems = append(problems, field.Label+" must be a number")
+			}
+		case "boolean":
+			switch v := value.(type) {
+			case bool:
+			case string:
+				if v != "true" && v != "false" {
+					problems = append(problems, fmt.Sprintf("%s must be true or false, got %q", field.Label, v))
+				}
+			default:
+				problems = append(problems, field.Label+" must be true or false")
+			}
+		case "select":
+			if len(field.Options) == 0 {
+				continue
+			}
+			selected := fmt.Sprint(value)
+			valid := false
+			for _, option := range field.Options {
+				if option.Value == selected {
+					valid = true
+					break
+				}
+			}
+			if !valid {
+				problems = append(problems, fmt.Sprintf("%s has unknown option %q", field.Label, selected))
+			}
+		case "json":
+			if s, ok := value.(string); ok && !json.Valid([]byte(s)) {
+				problems = append(problems, field.Label+" is not valid JSON")
+			}
+		}
+	}
+	return problems
+}
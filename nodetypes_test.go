@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCheckNodeConfig(t *testing.T) {
+	spec := &NodeTypeSpec{Fields: []NodeFieldSpec{
+		{Key: "url", Label: "URL", Type: "url", Required: true},
+		{Key: "retries", Label: "Retries", Type: "number"},
+		{Key: "verbose", Label: "Verbose", Type: "boolean"},
+		{Key: "method", Label: "Method", Type: "select", Options: []NodeFieldOption{{Value: "GET"}, {Value: "POST"}}},
+		{Key: "body", Label: "Body", Type: "json"},
+	}}
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{"valid", `{"url":"https://example.com","retries":3,"verbose":true,"method":"POST","body":"{\"a\":1}"}`, nil},
+		{"values as strings", `{"url":"https://example.com","retries":" 2.5 ","verbose":"false"}`, nil},
+		{"missing required", `{"retries":1}`, []string{"URL is required"}},
+		{"empty required", `{"url":""}`, []string{"URL is required"}},
+		{"expressions", `{"url":"{{input.url}}","retries":"{{input.n}}","method":"{{input.method}}","body":"{{input}}"}`, nil},
+		{"wrong types", `{"url":"x","retries":"many","verbose":"yes","method":"DELETE","body":"{oops"}`, []string{
+			`Retries must be a number, got "many"`,
+			`Verbose must be true or false, got "yes"`,
+			`Method has unknown option "DELETE"`,
+			"Body is not valid JSON",
+		}},
+		{"wrong JSON types", `{"url":"x","retries":[1],"verbose":1}`, []string{"Retries must be a number", "Verbose must be true or false"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config map[string]interface{}
+			json.Unmarshal([]byte(tt.config), &config)
+			got := checkNodeConfig(spec, config)
+			if msg := compareValues(got, tt.want); msg != "" {
+				t.Error(msg)
+			}
+		})
+	}
+}
+
+// The built-in catalog's defaults must pass its own schema, or a node
+// dropped onto the canvas would be reported as invalid before it's edited
+func TestBuiltinNodeTypes(t *testing.T) {
+	sideEffects := map[string]bool{SideEffectNone: true, SideEffectRead: true, SideEffectWrite: true, SideEffectExternal: true}
+	for _, spec := range builtinNodeTypes {
+		if builtinNodeTypeIndex[spec.Type] == nil || spec.Source != "builtin" {
+			t.Errorf("%s is missing from the index", spec.Type)
+		}
+		if !sideEffects[spec.SideEffect] {
+			t.Errorf("%s has side effect %q", spec.Type, spec.SideEffect)
+		}
+		spec := spec
+		for _, problem := range checkNodeConfig(&spec, spec.Defaults) {
+			if !strings.HasSuffix(problem, " is required") {
+				t.Errorf("%s defaults: %s", spec.Type, problem)
+			}
+		}
+		if booleanBranchTypes[spec.Type] {
+			handles := map[string]bool{}
+			for _, h := range spec.Outputs {
+				handles[h.ID] = true
+			}
+			if !handles["true"] || !handles["false"] {
+				t.Errorf("%s branches on true/false but has outputs %v", spec.Type, spec.Outputs)
+			}
+		}
+	}
+	if len(builtinNodeTypeIndex) != len(builtinNodeTypes) {
+		t.Errorf("%d node types share a type name", len(builtinNodeTypes)-len(builtinNodeTypeIndex))
+	}
+}
+
+func TestValidateFlow(t *testing.T) {
+	s := newBundleStorage(t)
+	if _, err := s.SaveCustomNode(`{"type":"custom_greet","name":"Greet","actionType":"script","fields":[{"key":"name","label":"Name","type":"text","required":true}],"defaultData":{"name":"World"}}`); err != nil {
+		t.Fatal(err)
+	}
+	flow := `{"id":"flow-1","nodes":[
+		{"id":"a","data":{"label":"Greet","nodeType":"custom_greet","config":{}}},
+		{"id":"b","data":{"label":"Fetch","nodeType":"action_http","config":{"url":""}}},
+		{"id":"c","data":{"label":"Gone","nodeType":"custom_gone"}},
+		{"id":"d","data":{"label":"Odd","nodeType":"action_teleport"}},
+		{"id":"e","data":{"label":"Frame"}}
+	],"edges":[]}`
+	problems, err := NewEngine(s).ValidateFlow(flow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`node "Fetch": URL is required`,
+		`node "Gone" uses unknown custom node type custom_gone`,
+		`node "Odd" has unknown node type action_teleport`,
+	}
+	if msg := compareValues(problems, want); msg != "" {
+		t.Error(msg)
+	}
+}
+
+func TestNodeFieldOptionValues(t *testing.T) {
+	var options []NodeFieldOption
+	if err := json.Unmarshal([]byte(`[{"value":"a","label":"A"},{"value":2,"label":"Two"},{"value":true,"label":"Yes"},{"label":"None"}]`), &options); err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, o := range options {
+		got = append(got, o.Value)
+	}
+	if msg := compareValues(got, []string{"a", "2", "true", ""}); msg != "" {
+		t.Error(msg)
+	}
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	pluginManifestFile   = "plugin.json"
+	defaultPluginTimeout = 30 * time.Second
+)
+
+// PluginManifest is the plugin.json file of a plugin directory under
+// <dataDir>/plugins. It lists the node types the plugin provides and the
+// host capabilities it is granted.
+type PluginManifest struct {
+	Name        string            `json:"name"`
+	Version     string            `json:"version"`
+	Description string            `json:"description,omitempty"`
+	Runtime     string            `json:"runtime"`           // "wasm" or "process"
+	Module      string            `json:"module,omitempty"`  // wasm
+	Command     string            `json:"command,omitempty"` // process
+	Args        []string          `json:"args,omitempty"`
+	Timeout     int               `json:"timeout,omitempty"`     // per node run, in milliseconds
+	MemoryLimit int               `json:"memoryLimit,omitempty"` // in MB
+	Nodes       []PluginNode      `json:"nodes"`
+	Permissions PluginPermissions `json:"permissions"`
+}
+
+// PluginNode describes a node type provided by a plugin, in the shape of the
+// frontend's node definitions
+type PluginNode struct {
+	Type        string                   `json:"type"`
+	Name        string                   `json:"name"`
+	Category    string                   `json:"category"`
+	Icon        string                   `json:"icon,omitempty"`
+	Description string                   `json:"description,omitempty"`
+	Fields      []map[string]interface{} `json:"fields,omitempty"`
+	DefaultData map[string]interface{}   `json:"defaultData,omitempty"`
+	Plugin      string                   `json:"plugin"`
+}
+
+// PluginPermissions are the host functions a plugin may call. HTTP lists the
+// allowed URL prefixes ("*" for any); ReadPaths lists directories the plugin
+// may read files under, relative paths being resolved against the plugin
+// directory.
+type PluginPermissions struct {
+	Log       bool     `json:"log"`
+	HTTP      []string `json:"http,omitempty"`
+	ReadPaths []string `json:"readPaths,omitempty"`
+}
+
+type PluginInfo struct {
+	Name        string       `json:"name"`
+	Version     string       `json:"version"`
+	Description string       `json:"description,omitempty"`
+	Runtime     string       `json:"runtime"`
+	Dir         string       `json:"dir"`
+	Nodes       []PluginNode `json:"nodes"`
+	Error       string       `json:"error,omitempty"`
+}
+
+// PluginResult is a plugin node's output and the log lines it wrote
+type PluginResult struct {
+	Output interface{} `json:"output"`
+	Logs   []LogEntry  `json:"logs"`
+}
+
+// pluginRunner executes the node types of one loaded plugin
+type pluginRunner interface {
+	Execute(ctx context.Context, nodeType string, config map[string]interface{}, input interface{}, log func(level, message string)) (interface{}, error)
+	Close() error
+}
+
+type plugin struct {
+	manifest PluginManifest
+	dir      string
+	runner   pluginRunner
+	err      error
+}
+
+// PluginManager loads plugins from the plugins directory and routes node
+// executions to them
+type PluginManager struct {
+	mu      sync.RWMutex
+	dir     string
+	plugins []*plugin
+	nodes   map[string]*plugin
+}
+
+func NewPluginManager(dir string) *PluginManager {
+	return &PluginManager{dir: dir, nodes: make(map[string]*plugin)}
+}
+
+// Load (re)loads every plugin directory. A plugin that fails to load is
+// reported by List with its error; the others still load.
+func (pm *PluginManager) Load() error {
+	if err := os.MkdirAll(pm.dir, 0755); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(pm.dir)
+	if err != nil {
+		return err
+	}
+
+	var plugins []*plugin
+	nodes := make(map[string]*plugin)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		p := loadPlugin(filepath.Join(pm.dir, entry.Name()))
+		plugins = append(plugins, p)
+		if p.err != nil {
+			fmt.Printf("Failed to load plugin %s: %v\n", entry.Name(), p.err)
+			continue
+		}
+		for _, node := range p.manifest.Nodes {
+			if other, ok := nodes[node.Type]; ok {
+				fmt.Printf("Plugin %s: node type %s is already provided by %s\n", p.manifest.Name, node.Type, other.manifest.Name)
+				continue
+			}
+			nodes[node.Type] = p
+		}
+	}
+
+	pm.mu.Lock()
+	old := pm.plugins
+	pm.plugins = plugins
+	pm.nodes = nodes
+	pm.mu.Unlock()
+
+	for _, p := range old {
+		if p.runner != nil {
+			p.runner.Close()
+		}
+	}
+	return nil
+}
+
+func loadPlugin(dir string) *plugin {
+	p := &plugin{dir: dir}
+	data, err := os.ReadFile(filepath.Join(dir, pluginManifestFile))
+	if err != nil {
+		p.err = fmt.Errorf("failed to read manifest: %w", err)
+		return p
+	}
+	if err := json.Unmarshal(data, &p.manifest); err != nil {
+		p.err = fmt.Errorf("invalid manifest: %w", err)
+		return p
+	}
+	if p.manifest.Name == "" {
+		p.manifest.Name = filepath.Base(dir)
+	}
+
+	switch p.manifest.Runtime {
+	case "wasm":
+		p.runner, p.err = newWasmPlugin(dir, p.manifest)
+	case "process":
+		// Process plugins report their node types in the handshake
+		var proc *processPlugin
+		if proc, p.err = newProcessPlugin(dir, p.manifest); p.err == nil {
+			p.runner = proc
+			p.manifest.Nodes = proc.nodes
+		}
+	default:
+		p.err = fmt.Errorf("unknown plugin runtime: %q", p.manifest.Runtime)
+	}
+	if p.err != nil {
+		return p
+	}
+
+	for i := range p.manifest.Nodes {
+		if p.manifest.Nodes[i].Type == "" {
+			p.runner.Close()
+			p.err = fmt.Errorf("node %d has no type", i)
+			return p
+		}
+		p.manifest.Nodes[i].Plugin = p.manifest.Name
+	}
+	return p
+}
+
+// List returns the loaded plugins, including those that failed to load
+func (pm *PluginManager) List() []PluginInfo {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	infos := make([]PluginInfo, 0, len(pm.plugins))
+	for _, p := range pm.plugins {
+		info := PluginInfo{
+			Name:        p.manifest.Name,
+			Version:     p.manifest.Version,
+			Description: p.manifest.Description,
+			Runtime:     p.manifest.Runtime,
+			Dir:         p.dir,
+			Nodes:       p.manifest.Nodes,
+		}
+		if info.Nodes == nil {
+			info.Nodes = []PluginNode{}
+		}
+		if p.err != nil {
+			info.Error = p.err.Error()
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Has reports whether a loaded plugin provides nodeType
+func (pm *PluginManager) Has(nodeType string) bool {
+	if pm == nil {
+		return false
+	}
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	_, ok := pm.nodes[nodeType]
+	return ok
+}
+
+// Execute runs a plugin node, bounded by the plugin's timeout
+func (pm *PluginManager) Execute(ctx context.Context, nodeType string, config map[string]interface{}, input interface{}, log func(level, message string)) (interface{}, error) {
+	pm.mu.RLock()
+	p, ok := pm.nodes[nodeType]
+	pm.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no plugin provides node type: %s", nodeType)
+	}
+
+	timeout := defaultPluginTimeout
+	if p.manifest.Timeout > 0 {
+		timeout = time.Duration(p.manifest.Timeout) * time.Millisecond
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	output, err := p.runner.Execute(runCtx, nodeType, config, input, log)
+	if err != nil && ctx.Err() == nil && runCtx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("plugin %s exceeded time limit of %v", p.manifest.Name, timeout)
+	}
+	return output, err
+}
+
+// Close releases every loaded plugin
+func (pm *PluginManager) Close() {
+	if pm == nil {
+		return
+	}
+	pm.mu.Lock()
+	plugins := pm.plugins
+	pm.plugins = nil
+	pm.nodes = make(map[string]*plugin)
+	pm.mu.Unlock()
+
+	for _, p := range plugins {
+		if p.runner != nil {
+			p.runner.Close()
+		}
+	}
+}
+
+// initPlugins loads plugins from the data directory ahead of first use
+func (e *Engine) initPlugins() {
+	e.pluginManager()
+}
+
+// pluginManager returns the plugin manager, loading plugins on first use.
+// It returns nil when the plugins directory can't be read.
+func (e *Engine) pluginManager() *PluginManager {
+	e.pluginsOnce.Do(func() {
+		e.storage.Init()
+		pm := NewPluginManager(e.storage.getPluginsDir())
+		if err := pm.Load(); err != nil {
+			fmt.Printf("Plugins disabled: %v\n", err)
+			return
+		}
+		e.plugins = pm
+	})
+	return e.plugins
+}
+
+// closePlugins stops all plugins on shutdown
+func (e *Engine) closePlugins() {
+	e.pluginsOnce.Do(func() {}) // don't start loading while shutting down
+	e.plugins.Close()
+}
+
+// GetPlugins lists installed plugins and the node types they provide
+func (e *Engine) GetPlugins() []PluginInfo {
+	pm := e.pluginManager()
+	if pm == nil {
+		return []PluginInfo{}
+	}
+	return pm.List()
+}
+
+// ReloadPlugins rescans the plugins directory
+func (e *Engine) ReloadPlugins() ([]PluginInfo, error) {
+	pm := e.pluginManager()
+	if pm == nil {
+		return nil, fmt.Errorf("plugins directory is not available")
+	}
+	if err := pm.Load(); err != nil {
+		return nil, err
+	}
+	return pm.List(), nil
+}
+
+// RunPluginNode runs a plugin-provided node for the frontend executor.
+// Secret references in the config are resolved here and masked in the
+// result.
+func (e *Engine) RunPluginNode(nodeType string, config map[string]interface{}, input interface{}) (*PluginResult, error) {
+	red := newRedactor()
+	ctx := contextWithRedactor(context.Background(), red)
+	config, err := e.resolveSecretRefs(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	result, err := e.executePlugin(ctx, nodeType, config, input)
+	if result != nil {
+		result.Output = red.Value(result.Output)
+	}
+	return result, red.Error(err)
+}
+
+// executePlugin runs a plugin node, copying its log lines into the
+// execution log
+func (e *Engine) executePlugin(ctx context.Context, nodeType string, config map[string]interface{}, input interface{}) (*PluginResult, error) {
+	pm := e.pluginManager()
+	if pm == nil {
+		return nil, fmt.Errorf("no plugin provides node type: %s", nodeType)
+	}
+
+	lg := loggerFromContext(ctx)
+	red := redactorFromContext(ctx)
+	result := &PluginResult{Logs: []LogEntry{}}
+	log := func(level, message string) {
+		if _, ok := logSeverity[level]; !ok {
+			level = LogInfo
+		}
+		message = red.String(message)
+		lg.Log(level, message, "source", "plugin")
+		result.Logs = append(result.Logs, LogEntry{
+			Timestamp: time.Now().Format(time.RFC3339Nano),
+			Level:     level,
+			Message:   message,
+		})
+	}
+
+	output, err := pm.Execute(ctx, nodeType, config, input, log)
+	if err != nil {
+		return result, err
+	}
+	result.Output = output
+	return result, nil
+}
@@ -0,0 +1,443 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Process plugins are long-lived executables speaking JSON-RPC 2.0 over
+// stdin/stdout, one message per line. The engine calls:
+//
+//	initialize {"protocolVersion"}                   -> {"name", "version", "nodes"}
+//	execute    {"nodeType", "config", "input"}       -> {"output"}
+//
+// and sends the notifications "cancel" {"id"} when the executing context is
+// done and "shutdown" before stopping the process. Plugins may send "log"
+// notifications {"id", "level", "message"} tied to an execute request.
+const (
+	pluginProtocolVersion  = 1
+	pluginHandshakeTimeout = 10 * time.Second
+	pluginCancelGrace      = 5 * time.Second
+	pluginShutdownGrace    = 3 * time.Second
+	pluginMaxRestartDelay  = 30 * time.Second
+	pluginMaxMessageSize   = 64 << 20
+)
+
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+type processPlugin struct {
+	name    string
+	dir     string
+	command string
+	args    []string
+	nodes   []PluginNode
+
+	mu           sync.Mutex
+	proc         *pluginProcess
+	closed       bool
+	restartDelay time.Duration
+}
+
+// pluginProcess is one running instance of a process plugin
+type pluginProcess struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]*rpcCall
+
+	done chan struct{} // closed once the process has exited
+	err  error
+}
+
+type rpcCall struct {
+	response chan rpcMessage
+	log      func(level, message string)
+}
+
+func newProcessPlugin(dir string, manifest PluginManifest) (*processPlugin, error) {
+	if manifest.Command == "" {
+		return nil, errors.New("manifest has no command")
+	}
+	command := manifest.Command
+	if strings.ContainsAny(command, `/\`) && !filepath.IsAbs(command) {
+		command = filepath.Join(dir, command)
+	}
+
+	p := &processPlugin{
+		name:    manifest.Name,
+		dir:     dir,
+		command: command,
+		args:    manifest.Args,
+		nodes:   manifest.Nodes,
+	}
+	if _, err := p.ensureProcess(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ensureProcess returns the running process, starting it (and handshaking)
+// if it isn't running
+func (p *processPlugin) ensureProcess() (*pluginProcess, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, fmt.Errorf("plugin %s is stopped", p.name)
+	}
+	if p.proc != nil {
+		return p.proc, nil
+	}
+
+	proc, err := startPluginProcess(p.name, p.dir, p.command, p.args)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pluginHandshakeTimeout)
+	defer cancel()
+	var hello struct {
+		Name    string       `json:"name"`
+		Version string       `json:"version"`
+		Nodes   []PluginNode `json:"nodes"`
+	}
+	if err := proc.call(ctx, "initialize", map[string]interface{}{"protocolVersion": pluginProtocolVersion}, nil, &hello); err != nil {
+		proc.kill()
+		return nil, fmt.Errorf("plugin handshake failed: %w", err)
+	}
+	if len(hello.Nodes) > 0 {
+		p.nodes = hello.Nodes
+	}
+
+	p.proc = proc
+	go p.monitor(proc)
+	return proc, nil
+}
+
+// monitor restarts the plugin with backoff when its process exits on its own
+func (p *processPlugin) monitor(proc *pluginProcess) {
+	started := time.Now()
+	<-proc.done
+
+	p.mu.Lock()
+	if p.proc == proc {
+		p.proc = nil
+	}
+	closed := p.closed
+	if time.Since(started) > time.Minute {
+		p.restartDelay = 0
+	}
+	if p.restartDelay == 0 {
+		p.restartDelay = time.Second
+	} else if p.restartDelay < pluginMaxRestartDelay {
+		p.restartDelay *= 2
+	}
+	delay := p.restartDelay
+	p.mu.Unlock()
+
+	if closed {
+		return
+	}
+	fmt.Printf("Plugin %s exited (%v), restarting in %v\n", p.name, proc.exitErr(), delay)
+	time.AfterFunc(delay, func() {
+		if _, err := p.ensureProcess(); err != nil {
+			fmt.Printf("Failed to restart plugin %s: %v\n", p.name, err)
+		}
+	})
+}
+
+func (p *processPlugin) Execute(ctx context.Context, nodeType string, config map[string]interface{}, input interface{}, log func(level, message string)) (interface{}, error) {
+	proc, err := p.ensureProcess()
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Output interface{} `json:"output"`
+	}
+	params := map[string]interface{}{
+		"nodeType": nodeType,
+		"config":   config,
+		"input":    input,
+	}
+	if err := proc.call(ctx, "execute", params, log, &result); err != nil {
+		return nil, err
+	}
+	return result.Output, nil
+}
+
+// Close asks the plugin to shut down and kills it if it doesn't exit in time
+func (p *processPlugin) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	proc := p.proc
+	p.proc = nil
+	p.mu.Unlock()
+
+	if proc == nil {
+		return nil
+	}
+	proc.notify("shutdown", nil)
+	proc.stdin.Close()
+	select {
+	case <-proc.done:
+	case <-time.After(pluginShutdownGrace):
+		proc.kill()
+	}
+	return nil
+}
+
+func startPluginProcess(name, dir, command string, args []string) (*pluginProcess, error) {
+	cmd := exec.Command(command, args...)
+	cmd.Dir = dir
+	hidePluginWindow(cmd)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start plugin: %w", err)
+	}
+
+	proc := &pluginProcess{
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: make(map[int64]*rpcCall),
+		done:    make(chan struct{}),
+	}
+
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			fmt.Printf("Plugin %s: %s\n", name, scanner.Text())
+		}
+	}()
+
+	go func() {
+		proc.readLoop(name, stdout)
+		err := cmd.Wait()
+		if err == nil {
+			err = errors.New("process exited")
+		}
+		proc.mu.Lock()
+		proc.err = err
+		pending := proc.pending
+		proc.pending = nil
+		proc.mu.Unlock()
+		for _, call := range pending {
+			close(call.response)
+		}
+		close(proc.done)
+	}()
+
+	return proc, nil
+}
+
+func (proc *pluginProcess) readLoop(name string, stdout io.Reader) {
+	reader := bufio.NewReaderSize(stdout, 64<<10)
+	for {
+		line, err := readLine(reader, pluginMaxMessageSize)
+		if err != nil {
+			if err != io.EOF {
+				fmt.Printf("Plugin %s: %v\n", name, err)
+				proc.kill()
+			}
+			return
+		}
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+
+		var msg rpcMessage
+		if err := json.Unmarshal(line, &msg); err != nil {
+			fmt.Printf("Plugin %s: invalid message: %v\n", name, err)
+			continue
+		}
+
+		switch {
+		case msg.Method == "log":
+			proc.handleLog(name, msg.Params)
+		case msg.ID != nil && msg.Method == "":
+			proc.mu.Lock()
+			call := proc.pending[*msg.ID]
+			delete(proc.pending, *msg.ID)
+			proc.mu.Unlock()
+			if call != nil {
+				call.response <- msg
+			}
+		}
+	}
+}
+
+// readLine reads one newline-terminated message, refusing oversized ones
+func readLine(reader *bufio.Reader, limit int) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := reader.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, chunk...)
+		if len(line) > limit {
+			return nil, fmt.Errorf("message exceeds %d bytes", limit)
+		}
+		if !isPrefix {
+			return line, nil
+		}
+	}
+}
+
+func (proc *pluginProcess) handleLog(name string, params json.RawMessage) {
+	var entry struct {
+		ID      *int64 `json:"id"`
+		Level   string `json:"level"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(params, &entry); err != nil {
+		return
+	}
+
+	var call *rpcCall
+	if entry.ID != nil {
+		proc.mu.Lock()
+		call = proc.pending[*entry.ID]
+		proc.mu.Unlock()
+	}
+	if call == nil || call.log == nil {
+		fmt.Printf("Plugin %s: [%s] %s\n", name, entry.Level, entry.Message)
+		return
+	}
+	call.log(entry.Level, entry.Message)
+}
+
+// call sends a request and waits for its response. When ctx is done the
+// plugin is sent a cancel notification; if it doesn't answer within the
+// grace period the process is killed (and later restarted).
+func (proc *pluginProcess) call(ctx context.Context, method string, params interface{}, log func(level, message string), result interface{}) error {
+	c := &rpcCall{response: make(chan rpcMessage, 1), log: log}
+
+	proc.mu.Lock()
+	if proc.pending == nil {
+		proc.mu.Unlock()
+		return fmt.Errorf("plugin exited: %v", proc.err)
+	}
+	proc.nextID++
+	id := proc.nextID
+	proc.pending[id] = c
+	proc.mu.Unlock()
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		proc.forget(id)
+		return fmt.Errorf("invalid plugin input: %w", err)
+	}
+	if err := proc.send(rpcMessage{JSONRPC: "2.0", ID: &id, Method: method, Params: data}); err != nil {
+		proc.forget(id)
+		return err
+	}
+
+	var msg rpcMessage
+	var ok bool
+	select {
+	case msg, ok = <-c.response:
+	case <-ctx.Done():
+		proc.notify("cancel", map[string]interface{}{"id": id})
+		select {
+		case <-c.response:
+		case <-time.After(pluginCancelGrace):
+			proc.forget(id)
+			proc.kill()
+		}
+		return ctx.Err()
+	}
+	if !ok {
+		return fmt.Errorf("plugin exited: %v", proc.exitErr())
+	}
+
+	if msg.Error != nil {
+		return msg.Error
+	}
+	if result != nil && len(msg.Result) > 0 {
+		if err := json.Unmarshal(msg.Result, result); err != nil {
+			return fmt.Errorf("invalid plugin response: %w", err)
+		}
+	}
+	return nil
+}
+
+func (proc *pluginProcess) notify(method string, params interface{}) {
+	var data json.RawMessage
+	if params != nil {
+		data, _ = json.Marshal(params)
+	}
+	proc.send(rpcMessage{JSONRPC: "2.0", Method: method, Params: data})
+}
+
+func (proc *pluginProcess) send(msg rpcMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	proc.writeMu.Lock()
+	defer proc.writeMu.Unlock()
+	if _, err := proc.stdin.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write to plugin: %w", err)
+	}
+	return nil
+}
+
+func (proc *pluginProcess) forget(id int64) {
+	proc.mu.Lock()
+	delete(proc.pending, id)
+	proc.mu.Unlock()
+}
+
+func (proc *pluginProcess) exitErr() error {
+	proc.mu.Lock()
+	defer proc.mu.Unlock()
+	return proc.err
+}
+
+func (proc *pluginProcess) kill() {
+	if proc.cmd.Process != nil {
+		proc.cmd.Process.Kill()
+	}
+}
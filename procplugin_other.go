@@ -0,0 +1,8 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import "os/exec"
+
+func hidePluginWindow(cmd *exec.Cmd) {}
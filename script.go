@@ -0,0 +1,343 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	rtmetrics "runtime/metrics"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// Script limits applied when settings don't override them
+const (
+	defaultScriptTimeout     = 5 * time.Second
+	defaultScriptMemoryLimit = 64 << 20
+	maxScriptCallStack       = 1024
+	maxScriptConsoleLines    = 1000
+)
+
+// scriptGlobals is the standard library visible to scripts. Everything else
+// the interpreter defines is removed from the global object; there is no
+// I/O, timers or module loading to begin with.
+var scriptGlobals = map[string]bool{
+	"JSON": true, "Math": true, "Date": true, "String": true, "Array": true,
+	"Object": true, "Number": true, "Boolean": true, "RegExp": true, "Symbol": true,
+	"Map": true, "Set": true, "Promise": true,
+	"Error": true, "TypeError": true, "RangeError": true, "SyntaxError": true, "ReferenceError": true,
+	"parseInt": true, "parseFloat": true, "isNaN": true, "isFinite": true,
+	"encodeURIComponent": true, "decodeURIComponent": true, "encodeURI": true, "decodeURI": true,
+	"undefined": true, "NaN": true, "Infinity": true, "globalThis": true,
+}
+
+// ScriptLimits bounds a single script run. Memory is measured as heap
+// growth, which the runtime can't attribute to a script, so scripts running
+// at once share a budget, see scriptMemory.
+type ScriptLimits struct {
+	Timeout     time.Duration
+	MemoryBytes uint64
+}
+
+// ScriptResult is the value returned by a script and what it printed
+type ScriptResult struct {
+	Output  interface{} `json:"output"`
+	Console []LogEntry  `json:"console"`
+}
+
+// scriptLimitsFromSettings reads "scriptTimeout" (ms) and
+// "scriptMemoryLimit" (MB), falling back to the defaults
+func scriptLimitsFromSettings(storage *Storage) ScriptLimits {
+	limits := ScriptLimits{Timeout: defaultScriptTimeout, MemoryBytes: defaultScriptMemoryLimit}
+
+	settingsJSON, _ := storage.LoadSettings()
+	var settings struct {
+		Timeout     int `json:"scriptTimeout"`
+		MemoryLimit int `json:"scriptMemoryLimit"`
+	}
+	json.Unmarshal([]byte(settingsJSON), &settings)
+
+	if settings.Timeout > 0 {
+		limits.Timeout = time.Duration(settings.Timeout) * time.Millisecond
+	}
+	if settings.MemoryLimit > 0 {
+		limits.MemoryBytes = uint64(settings.MemoryLimit) << 20
+	}
+	return limits
+}
+
+// RunScript runs a JavaScript snippet in the sandboxed runtime. The script
+// is a function body receiving `input` and returning the node output.
+func (e *Engine) RunScript(script string, input map[string]interface{}) (*ScriptResult, error) {
+	return e.executeScript(context.Background(), script, input)
+}
+
+// executeScript runs a script on behalf of the current node, copying console
+// output into the execution log
+func (e *Engine) executeScript(ctx context.Context, script string, input map[string]interface{}) (*ScriptResult, error) {
+	lg := loggerFromContext(ctx)
+	result := &ScriptResult{Console: []LogEntry{}}
+	dropped := false
+
+	console := func(level, message string) {
+		lg.Log(level, message, "source", "console")
+		if len(result.Console) >= maxScriptConsoleLines {
+			if !dropped {
+				lg.Warn("Script console output truncated", "limit", maxScriptConsoleLines)
+				dropped = true
+			}
+			return
+		}
+		result.Console = append(result.Console, LogEntry{
+			Timestamp: time.Now().Format(time.RFC3339Nano),
+			Level:     level,
+			Message:   message,
+		})
+	}
+
+	output, err := runScript(ctx, script, input, scriptLimitsFromSettings(e.storage), console)
+	if err != nil {
+		return result, err
+	}
+	result.Output = output
+	return result, nil
+}
+
+// runScript evaluates script as the body of `function (input) { ... }` and
+// returns its result as decoded JSON. Promises returned by async scripts are
+// unwrapped. The run is interrupted when ctx is done or a limit is exceeded.
+func runScript(ctx context.Context, script string, input map[string]interface{}, limits ScriptLimits, console func(level, message string)) (interface{}, error) {
+	vm := goja.New()
+	vm.SetMaxCallStackSize(maxScriptCallStack)
+
+	jsonObj := vm.Get("JSON").ToObject(vm)
+	parse, _ := goja.AssertFunction(jsonObj.Get("parse"))
+	stringify, _ := goja.AssertFunction(jsonObj.Get("stringify"))
+
+	global := vm.GlobalObject()
+	for _, name := range global.GetOwnPropertyNames() {
+		if !scriptGlobals[name] {
+			global.Delete(name)
+		}
+	}
+	installConsole(vm, stringify, console)
+
+	// Pass input as plain JS objects rather than wrapped Go maps
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script input: %w", err)
+	}
+	inputValue, err := parse(goja.Undefined(), vm.ToValue(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid script input: %w", err)
+	}
+
+	// Keep the wrapper on the first line so error positions match the source
+	program, err := goja.Compile("script", "(function (input) { \"use strict\"; "+script+"\n})", false)
+	if err != nil {
+		return nil, scriptError(err)
+	}
+	fnValue, err := vm.RunProgram(program)
+	if err != nil {
+		return nil, scriptError(err)
+	}
+	fn, ok := goja.AssertFunction(fnValue)
+	if !ok {
+		return nil, errors.New("script did not compile to a function")
+	}
+
+	stop := watchScript(ctx, vm, limits)
+	value, err := fn(goja.Undefined(), inputValue)
+	stop()
+	if err != nil {
+		return nil, scriptError(err)
+	}
+
+	if p, ok := value.Export().(*goja.Promise); ok {
+		switch p.State() {
+		case goja.PromiseStateFulfilled:
+			value = p.Result()
+		case goja.PromiseStateRejected:
+			return nil, fmt.Errorf("script rejected: %s", p.Result().String())
+		default:
+			return nil, errors.New("script returned a promise that never settled")
+		}
+	}
+
+	if goja.IsUndefined(value) || goja.IsNull(value) {
+		return nil, nil
+	}
+	encoded, err := stringify(goja.Undefined(), value)
+	if err != nil {
+		return nil, scriptError(err)
+	}
+	if goja.IsUndefined(encoded) {
+		// Functions and symbols have no JSON form
+		return nil, nil
+	}
+	var output interface{}
+	if err := json.Unmarshal([]byte(encoded.String()), &output); err != nil {
+		return nil, fmt.Errorf("script returned an unsupported value: %w", err)
+	}
+	return output, nil
+}
+
+// watchScript interrupts vm when ctx is done, the time limit passes or the
+// heap grows past the memory budget of the running scripts. The returned
+// func stops the watchdog.
+func watchScript(ctx context.Context, vm *goja.Runtime, limits ScriptLimits) func() {
+	done := make(chan struct{})
+	release := func() {}
+	if limits.MemoryBytes > 0 {
+		release = scriptMemory.start(limits.MemoryBytes)
+	}
+
+	go func() {
+		defer release()
+		var deadline <-chan time.Time
+		if limits.Timeout > 0 {
+			timer := time.NewTimer(limits.Timeout)
+			defer timer.Stop()
+			deadline = timer.C
+		}
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				vm.Interrupt(fmt.Sprintf("script cancelled: %v", ctx.Err()))
+				return
+			case <-deadline:
+				vm.Interrupt(fmt.Sprintf("script exceeded time limit of %v", limits.Timeout))
+				return
+			case <-ticker.C:
+				if limits.MemoryBytes > 0 && scriptMemory.exceeded() {
+					// The sample includes garbage; only a collection tells
+					// whether the scripts really hold on to that much
+					runtime.GC()
+					if !scriptMemory.exceeded() {
+						continue
+					}
+					vm.Interrupt(fmt.Sprintf("script exceeded memory limit of %d MB", limits.MemoryBytes>>20))
+					return
+				}
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// scriptMemory is the heap budget of the scripts running at once. Each adds
+// its limit while it runs, and the heap growth since the first of them
+// started is checked against the sum, so a script within its limit isn't
+// stopped because another one runs next to it. When the scripts together
+// exceed the budget, every one of them is stopped.
+var scriptMemory scriptMemoryBudget
+
+type scriptMemoryBudget struct {
+	mu       sync.Mutex
+	running  int
+	baseline uint64
+	budget   uint64
+}
+
+// start adds a running script's limit to the budget and returns the func
+// that removes it
+func (b *scriptMemoryBudget) start(limit uint64) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.running == 0 {
+		b.baseline = heapBytes()
+	}
+	b.running++
+	b.budget += limit
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.running--
+		b.budget -= limit
+	}
+}
+
+func (b *scriptMemoryBudget) exceeded() bool {
+	b.mu.Lock()
+	baseline, budget := b.baseline, b.budget
+	b.mu.Unlock()
+	return heapBytes() > baseline+budget
+}
+
+// heapBytes reads the live heap size without stopping the world
+func heapBytes() uint64 {
+	sample := []rtmetrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	rtmetrics.Read(sample)
+	if sample[0].Value.Kind() != rtmetrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
+
+func installConsole(vm *goja.Runtime, stringify goja.Callable, console func(level, message string)) {
+	obj := vm.NewObject()
+	levels := map[string]string{
+		"log":   LogInfo,
+		"info":  LogInfo,
+		"debug": LogDebug,
+		"warn":  LogWarn,
+		"error": LogError,
+	}
+	for method, level := range levels {
+		level := level
+		obj.Set(method, func(call goja.FunctionCall) goja.Value {
+			parts := make([]string, 0, len(call.Arguments))
+			for _, arg := range call.Arguments {
+				parts = append(parts, formatConsoleArg(arg, stringify))
+			}
+			console(level, strings.Join(parts, " "))
+			return goja.Undefined()
+		})
+	}
+	vm.Set("console", obj)
+}
+
+func formatConsoleArg(arg goja.Value, stringify goja.Callable) string {
+	if goja.IsUndefined(arg) || goja.IsNull(arg) {
+		return arg.String()
+	}
+	if _, isObject := arg.(*goja.Object); isObject {
+		if _, isFunc := goja.AssertFunction(arg); !isFunc {
+			if s, err := stringify(goja.Undefined(), arg); err == nil && !goja.IsUndefined(s) {
+				return s.String()
+			}
+		}
+	}
+	return arg.String()
+}
+
+// scriptError turns interpreter errors into plain messages
+func scriptError(err error) error {
+	var interrupted *goja.InterruptedError
+	if errors.As(err, &interrupted) {
+		return fmt.Errorf("%v", interrupted.Value())
+	}
+	var overflow *goja.StackOverflowError
+	if errors.As(err, &overflow) {
+		return errors.New("script error: maximum call stack size exceeded")
+	}
+	var exception *goja.Exception
+	if errors.As(err, &exception) {
+		return fmt.Errorf("script error: %s", exception.Error())
+	}
+	var syntax *goja.CompilerSyntaxError
+	if errors.As(err, &syntax) {
+		return errors.New(syntax.Error())
+	}
+	return err
+}
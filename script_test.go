@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func runTestScript(script string, limits ScriptLimits) (interface{}, error) {
+	return runScript(context.Background(), script, map[string]interface{}{"n": 2}, limits, func(level, message string) {})
+}
+
+func TestScriptSandbox(t *testing.T) {
+	limits := ScriptLimits{Timeout: 2 * time.Second, MemoryBytes: 64 << 20}
+	tests := []struct {
+		name   string
+		script string
+		want   interface{}
+	}{
+		{"input and output", `return { doubled: input.n * 2 }`, map[string]interface{}{"doubled": float64(4)}},
+		{"no require", `return typeof require`, "undefined"},
+		{"no process", `return typeof process`, "undefined"},
+		{"no timers", `return [typeof setTimeout, typeof setInterval].join()`, "undefined,undefined"},
+		{"no eval", `return [typeof eval, typeof Function].join()`, "undefined,undefined"},
+		{"async", `return Promise.resolve(input.n + 1)`, float64(3)},
+		{"no result", `input.n++`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runTestScript(tt.script, limits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msg := compareValues(got, tt.want); msg != "" {
+				t.Error(msg)
+			}
+		})
+	}
+}
+
+func TestScriptGlobals(t *testing.T) {
+	got, err := runTestScript(`return Object.getOwnPropertyNames(globalThis)`, ScriptLimits{Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	names, _ := got.([]interface{})
+	if len(names) == 0 {
+		t.Fatalf("globals = %v", got)
+	}
+	for _, name := range names {
+		if name, _ := name.(string); !scriptGlobals[name] && name != "console" {
+			t.Errorf("global %q isn't in the whitelist", name)
+		}
+	}
+}
+
+func TestScriptErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		limits ScriptLimits
+		want   string
+	}{
+		{"time limit", `while (true) {}`, ScriptLimits{Timeout: 100 * time.Millisecond}, "exceeded time limit of 100ms"},
+		{"memory limit", `const a = []; while (true) { a.push(new Array(1000).fill(a.length)) }`, ScriptLimits{Timeout: 10 * time.Second, MemoryBytes: 16 << 20}, "exceeded memory limit of 16 MB"},
+		{"call stack", `const f = () => f(); return f()`, ScriptLimits{Timeout: time.Second}, "maximum call stack size exceeded"},
+		{"exception", `throw new TypeError("bad input")`, ScriptLimits{Timeout: time.Second}, "TypeError: bad input"},
+		{"rejected", `return Promise.reject("nope")`, ScriptLimits{Timeout: time.Second}, "script rejected: nope"},
+		{"syntax", `return {`, ScriptLimits{Timeout: time.Second}, "SyntaxError"},
+		{"require", `return require("fs")`, ScriptLimits{Timeout: time.Second}, "ReferenceError: require is not defined"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runTestScript(tt.script, tt.limits)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestScriptCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := runScript(ctx, `while (true) {}`, nil, ScriptLimits{Timeout: 10 * time.Second}, func(level, message string) {})
+	if err == nil || !strings.Contains(err.Error(), "script cancelled") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+// Scripts running at once share their memory budget, so one within its
+// limit isn't stopped because of another
+func TestScriptMemoryLimitConcurrent(t *testing.T) {
+	// Each holds about 15 MB for a while, well within 32 MB alone but
+	// together over a single limit
+	script := `
+		const a = [];
+		for (let i = 0; i < 100; i++) a.push(new Array(5000).fill(i));
+		const until = Date.now() + 500;
+		while (Date.now() < until) {}
+		return a.length`
+	limits := ScriptLimits{Timeout: 10 * time.Second, MemoryBytes: 32 << 20}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = runTestScript(script, limits)
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("script %d: %v", i+1, err)
+		}
+	}
+}
+
+func TestScriptConsole(t *testing.T) {
+	var lines []string
+	_, err := runScript(context.Background(), `console.log("n is", input.n, {a: 1}); console.warn(null)`, map[string]interface{}{"n": 2},
+		ScriptLimits{Timeout: time.Second}, func(level, message string) { lines = append(lines, level+": "+message) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{LogInfo + `: n is 2 {"a":1}`, LogWarn + ": null"}
+	if len(lines) != len(want) || lines[0] != want[0] || lines[1] != want[1] {
+		t.Errorf("console = %q, want %q", lines, want)
+	}
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Secret provider types. A reference such as {{secret.team:github/token}}
+// is looked up in the provider named "team"; names without a provider come
+// from the local encrypted store.
+const (
+	ProviderEnv     = "env"     // environment variables
+	ProviderPass    = "pass"    // a pass/gopass store of GPG-encrypted files
+	ProviderCommand = "command" // the output of a command
+	ProviderVault   = "vault"   // a HashiCorp Vault compatible KV engine
+)
+
+const (
+	defaultSecretTTL      = 5 * time.Minute
+	secretProviderTimeout = 15 * time.Second
+)
+
+var secretProviderName = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// SecretProviderConfig is one entry of the secretProviders setting. Only
+// the fields of its type are used.
+type SecretProviderConfig struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	TTL  int    `json:"ttl,omitempty"` // seconds to cache values; 0 for 5 minutes, -1 to never cache
+
+	Prefix string `json:"prefix,omitempty"` // env: prepended to the name, e.g. "FORGEFLOW_"; required
+
+	Dir string `json:"dir,omitempty"` // pass: store directory, defaults to $PASSWORD_STORE_DIR or ~/.password-store
+
+	Args []string `json:"args,omitempty"` // command: e.g. ["op", "read", "op://team/{{name}}"]
+
+	Address     string `json:"address,omitempty"`     // vault: e.g. http://127.0.0.1:8200
+	Mount       string `json:"mount,omitempty"`       // vault: KV mount, defaults to "secret"
+	KVVersion   int    `json:"kvVersion,omitempty"`   // vault: 1 or 2 (default)
+	Namespace   string `json:"namespace,omitempty"`   // vault: enterprise namespace
+	TokenSecret string `json:"tokenSecret,omitempty"` // vault: local secret holding the token; $VAULT_TOKEN otherwise
+}
+
+// secretProvider looks up secrets by name in an external store
+type secretProvider interface {
+	get(ctx context.Context, name string) (string, error)
+}
+
+// secretCache keeps provider values until their TTL runs out
+type secretCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedSecret
+}
+
+type cachedSecret struct {
+	value   string
+	expires time.Time
+}
+
+func (c *secretCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return "", false
+	}
+	return entry.value, true
+}
+
+func (c *secretCache) put(key, value string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]cachedSecret)
+	}
+	c.entries[key] = cachedSecret{value: value, expires: time.Now().Add(ttl)}
+}
+
+func (c *secretCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+}
+
+// secretProviderConfigs reads the configured providers
+func (s *Storage) secretProviderConfigs() (map[string]SecretProviderConfig, error) {
+	settingsJSON, err := s.LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+	var settings struct {
+		Providers []SecretProviderConfig `json:"secretProviders"`
+	}
+	json.Unmarshal([]byte(settingsJSON), &settings)
+
+	configs := map[string]SecretProviderConfig{}
+	for _, config := range settings.Providers {
+		if !secretProviderName.MatchString(config.Name) {
+			return nil, fmt.Errorf("invalid secret provider name %q", config.Name)
+		}
+		configs[config.Name] = config
+	}
+	return configs, nil
+}
+
+// getProviderSecret resolves "provider:name", from the cache when possible
+func (s *Storage) getProviderSecret(providerName, name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("missing secret name for provider %s", providerName)
+	}
+	configs, err := s.secretProviderConfigs()
+	if err != nil {
+		return "", err
+	}
+	config, ok := configs[providerName]
+	if !ok {
+		return "", fmt.Errorf("unknown secret provider: %s", providerName)
+	}
+
+	cacheKey := providerName + ":" + name
+	if value, ok := s.providerCache.get(cacheKey); ok {
+		return value, nil
+	}
+	provider, err := s.newSecretProvider(config)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), secretProviderTimeout)
+	defer cancel()
+	value, err := provider.get(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	ttl := defaultSecretTTL
+	if config.TTL > 0 {
+		ttl = time.Duration(config.TTL) * time.Second
+	}
+	if config.TTL >= 0 {
+		s.providerCache.put(cacheKey, value, ttl)
+	}
+	return value, nil
+}
+
+func (s *Storage) newSecretProvider(config SecretProviderConfig) (secretProvider, error) {
+	switch config.Type {
+	case ProviderEnv:
+		// Without a prefix any flow could read every variable of the
+		// process, e.g. FORGEFLOW_BACKUP_PASSPHRASE or VAULT_TOKEN
+		if config.Prefix == "" {
+			return nil, fmt.Errorf("secret provider %s has no prefix", config.Name)
+		}
+		return envSecrets{prefix: config.Prefix}, nil
+	case ProviderPass:
+		dir := config.Dir
+		if dir == "" {
+			dir = os.Getenv("PASSWORD_STORE_DIR")
+		}
+		if dir == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, err
+			}
+			dir = filepath.Join(home, ".password-store")
+		}
+		return passSecrets{dir: dir}, nil
+	case ProviderCommand:
+		if len(config.Args) == 0 || config.Args[0] == "" {
+			return nil, fmt.Errorf("secret provider %s has no command", config.Name)
+		}
+		return commandSecrets{args: config.Args}, nil
+	case ProviderVault:
+		if config.Address == "" {
+			return nil, fmt.Errorf("secret provider %s has no address", config.Name)
+		}
+		token := os.Getenv("VAULT_TOKEN")
+		if config.TokenSecret != "" {
+			var err error
+			if token, err = s.GetSecret(config.TokenSecret); err != nil {
+				return nil, fmt.Errorf("failed to read the token of secret provider %s: %w", config.Name, err)
+			}
+		}
+		return vaultSecrets{config: config, token: token}, nil
+	default:
+		return nil, fmt.Errorf("unknown secret provider type %q for %s", config.Type, config.Name)
+	}
+}
+
+type envSecrets struct {
+	prefix string
+}
+
+func (p envSecrets) get(ctx context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(p.prefix + name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", p.prefix+name)
+	}
+	return value, nil
+}
+
+// passSecrets reads a password store: <dir>/<name>.gpg, decrypted with gpg
+// and its agent. As with pass, the first line is the secret.
+type passSecrets struct {
+	dir string
+}
+
+func (p passSecrets) get(ctx context.Context, name string) (string, error) {
+	path := filepath.Join(p.dir, filepath.FromSlash(name)+".gpg")
+	if rel, err := filepath.Rel(p.dir, path); err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("invalid password store entry: %s", name)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("password store entry %s not found", name)
+	}
+
+	cmd := exec.CommandContext(ctx, "gpg", "--quiet", "--batch", "--decrypt", path)
+	hidePluginWindow(cmd)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("gpg failed to decrypt %s: %s", name, strings.TrimSpace(stderr.String()))
+	}
+	line, _, _ := strings.Cut(stdout.String(), "\n")
+	return strings.TrimSuffix(line, "\r"), nil
+}
+
+// commandSecrets runs a command and uses its output, without the trailing
+// newline. {{name}} in the arguments is replaced by the secret name; the
+// command is not run through a shell, so names can't inject commands.
+type commandSecrets struct {
+	args []string
+}
+
+func (p commandSecrets) get(ctx context.Context, name string) (string, error) {
+	args := make([]string, len(p.args))
+	for i, arg := range p.args {
+		args[i] = strings.ReplaceAll(arg, "{{name}}", name)
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	hidePluginWindow(cmd)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("secret command failed for %s: %s", name, msg)
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+// vaultSecrets reads a KV secrets engine over Vault's HTTP API. Names are
+// "path#field", e.g. "apps/github#token"; the field defaults to "value".
+type vaultSecrets struct {
+	config SecretProviderConfig
+	token  string
+}
+
+func (p vaultSecrets) get(ctx context.Context, name string) (string, error) {
+	path, field, _ := strings.Cut(name, "#")
+	if field == "" {
+		field = "value"
+	}
+	mount := strings.Trim(p.config.Mount, "/")
+	if mount == "" {
+		mount = "secret"
+	}
+
+	endpoint := strings.TrimRight(p.config.Address, "/") + "/v1/" + mount + "/"
+	if p.config.KVVersion != 1 {
+		endpoint += "data/"
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if segment == ".." {
+			return "", fmt.Errorf("invalid vault path: %s", path)
+		}
+		segments[i] = url.PathEscape(segment)
+	}
+	endpoint += strings.Join(segments, "/")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	if p.token != "" {
+		req.Header.Set("X-Vault-Token", p.token)
+	}
+	if p.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.config.Namespace)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("vault secret %s not found", path)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		json.Unmarshal(body, &vaultErr)
+		return "", fmt.Errorf("vault returned %s: %s", resp.Status, strings.Join(vaultErr.Errors, "; "))
+	}
+
+	// KV v1 has the fields in data, v2 nests them in data.data
+	var payload struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", fmt.Errorf("invalid vault response: %w", err)
+	}
+	data := payload.Data
+	if p.config.KVVersion != 1 {
+		data, _ = payload.Data["data"].(map[string]interface{})
+	}
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no field %q", path, field)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", errors.New("unsupported vault value")
+	}
+	return string(encoded), nil
+}
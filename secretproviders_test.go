@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// newProviderStorage opens a storage in a temp dir with the given secret
+// providers configured
+func newProviderStorage(t *testing.T, providers ...SecretProviderConfig) *Storage {
+	t.Helper()
+	s := NewStorageAt(t.TempDir())
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.close)
+	settings, _ := json.Marshal(map[string]interface{}{"secretProviders": providers})
+	if err := s.SaveSettings(string(settings)); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestVaultProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.test" || r.Header.Get("X-Vault-Namespace") != "team" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/apps/github":
+			w.Write([]byte(`{"data":{"data":{"token":"gh-123","value":"v2-default"}}}`))
+		case "/v1/kv/apps/db":
+			w.Write([]byte(`{"data":{"value":"db-456"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	s := newProviderStorage(t,
+		SecretProviderConfig{Name: "ops", Type: ProviderVault, Address: server.URL, Namespace: "team", TokenSecret: "vault_token"},
+		SecretProviderConfig{Name: "old", Type: ProviderVault, Address: server.URL, Mount: "kv", KVVersion: 1, Namespace: "team", TokenSecret: "vault_token"},
+		SecretProviderConfig{Name: "anon", Type: ProviderVault, Address: server.URL},
+	)
+	if err := s.SaveSecret("vault_token", "s.test"); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, want := range map[string]string{
+		"ops:apps/github#token": "gh-123",
+		"ops:apps/github":       "v2-default",
+		"old:apps/db":           "db-456",
+	} {
+		got, err := s.GetSecret(key)
+		if err != nil {
+			t.Fatalf("%s: %v", key, err)
+		}
+		if got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+
+	for key, want := range map[string]string{
+		"ops:apps/missing":     "not found",
+		"ops:apps/github#nope": `no field "nope"`,
+		"ops:../sys/seal":      "invalid vault path",
+		"anon:apps/github":     "permission denied",
+	} {
+		if _, err := s.GetSecret(key); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got error %v, want %q", key, err, want)
+		}
+	}
+}
+
+func TestEnvProvider(t *testing.T) {
+	t.Setenv("FLOW_SECRET_TOKEN", "env-123")
+	t.Setenv("FORGEFLOW_BACKUP_PASSPHRASE", "hunter2")
+
+	s := newProviderStorage(t,
+		SecretProviderConfig{Name: "env", Type: ProviderEnv, Prefix: "FLOW_SECRET_"},
+		SecretProviderConfig{Name: "all", Type: ProviderEnv},
+	)
+	if got, err := s.GetSecret("env:TOKEN"); err != nil || got != "env-123" {
+		t.Errorf("env:TOKEN = %q, %v", got, err)
+	}
+	if _, err := s.GetSecret("env:MISSING"); err == nil {
+		t.Error("env:MISSING read an unset variable")
+	}
+	if _, err := s.GetSecret("all:FORGEFLOW_BACKUP_PASSPHRASE"); err == nil {
+		t.Error("an env provider without a prefix read the process environment")
+	}
+
+	// No provider is available without configuration
+	s = newProviderStorage(t)
+	if _, err := s.GetSecret("env:FORGEFLOW_BACKUP_PASSPHRASE"); err == nil {
+		t.Error("the env provider is available without configuration")
+	}
+}
+
+func TestCommandProvider(t *testing.T) {
+	if _, err := exec.LookPath("printf"); err != nil {
+		t.Skip("printf is not installed")
+	}
+	s := newProviderStorage(t,
+		SecretProviderConfig{Name: "cmd", Type: ProviderCommand, Args: []string{"printf", "%s\n", "value of {{name}}"}},
+		SecretProviderConfig{Name: "none", Type: ProviderCommand},
+	)
+	// The argument keeps its spaces, and a name can't add arguments
+	if got, err := s.GetSecret("cmd:a b; echo c"); err != nil || got != "value of a b; echo c" {
+		t.Errorf("cmd = %q, %v", got, err)
+	}
+	if _, err := s.GetSecret("none:x"); err == nil || !strings.Contains(err.Error(), "no command") {
+		t.Errorf("provider without a command: %v", err)
+	}
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// secretRef matches {{secret.NAME}} and {{secret.PROVIDER:NAME}} references
+// in node configs. Only the engine resolves them, right before a node runs,
+// so secret values never end up in flow files or exports.
+var secretRef = regexp.MustCompile(`\{\{\s*secret\.((?:[a-z][a-z0-9_-]*:)?[A-Za-z0-9_][A-Za-z0-9_./#@-]*)\s*\}\}`)
+
+// redactedMarker replaces resolved secret values in outputs, errors, logs
+// and stored results
+const redactedMarker = "[REDACTED]"
+
+// minMaskedSecretLength is the shortest value a redactor masks. Shorter
+// values, such as "1", would mask ordinary text wherever it appears.
+const minMaskedSecretLength = 4
+
+// redactor collects the secret values resolved during one execution and
+// masks them wherever they show up afterwards
+type redactor struct {
+	mu     sync.RWMutex
+	values map[string]bool
+}
+
+func newRedactor() *redactor {
+	return &redactor{values: make(map[string]bool)}
+}
+
+// add registers a value to mask. It reports false for values too short to
+// mask safely, which are left as they are.
+func (r *redactor) add(value string) bool {
+	if r == nil || value == "" {
+		return true
+	}
+	if len(value) < minMaskedSecretLength {
+		return false
+	}
+	r.mu.Lock()
+	r.values[value] = true
+	r.mu.Unlock()
+	return true
+}
+
+// String masks every known secret value in s. Longer values are replaced
+// first, so a secret containing another one is masked as a whole.
+func (r *redactor) String(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.values) == 0 {
+		return s
+	}
+	values := make([]string, 0, len(r.values))
+	for v := range r.values {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	for _, v := range values {
+		s = strings.ReplaceAll(s, v, redactedMarker)
+	}
+	return s
+}
+
+// Value returns a copy of a decoded JSON value with secrets masked in every
+// string, including map keys
+func (r *redactor) Value(value interface{}) interface{} {
+	if r == nil {
+		return value
+	}
+	switch v := value.(type) {
+	case string:
+		return r.String(v)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[r.String(key)] = r.Value(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = r.Value(item)
+		}
+		return out
+	case nil, bool, float64, int, int64, json.Number:
+		return value
+	default:
+		// Typed values (e.g. []map[string]interface{} from a custom node)
+		// are masked through their JSON form
+		data, err := json.Marshal(v)
+		if err != nil || r.String(string(data)) == string(data) {
+			return value
+		}
+		var decoded interface{}
+		if json.Unmarshal(data, &decoded) != nil {
+			return value
+		}
+		return r.Value(decoded)
+	}
+}
+
+func (r *redactor) Error(err error) error {
+	if r == nil || err == nil {
+		return err
+	}
+	if masked := r.String(err.Error()); masked != err.Error() {
+		return errors.New(masked)
+	}
+	return err
+}
+
+type redactorContextKey struct{}
+
+func contextWithRedactor(ctx context.Context, r *redactor) context.Context {
+	return context.WithValue(ctx, redactorContextKey{}, r)
+}
+
+// redactorFromContext returns the execution's redactor, or nil
+func redactorFromContext(ctx context.Context) *redactor {
+	r, _ := ctx.Value(redactorContextKey{}).(*redactor)
+	return r
+}
+
+// resolveSecretRefs returns a copy of config with {{secret.NAME}} references
+// replaced by the secret values, registering each value with the
+// execution's redactor. The config is returned as is when it has none.
+func (e *Engine) resolveSecretRefs(ctx context.Context, config map[string]interface{}) (map[string]interface{}, error) {
+	if !hasSecretRefs(config) {
+		return config, nil
+	}
+	red := redactorFromContext(ctx)
+	cache := make(map[string]string)
+	resolved, err := e.resolveSecretValue(ctx, config, red, cache)
+	if err != nil {
+		return nil, err
+	}
+	return resolved.(map[string]interface{}), nil
+}
+
+func (e *Engine) resolveSecretValue(ctx context.Context, value interface{}, red *redactor, cache map[string]string) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		var resolveErr error
+		out := secretRef.ReplaceAllStringFunc(v, func(ref string) string {
+			name := secretRef.FindStringSubmatch(ref)[1]
+			secret, ok := cache[name]
+			if !ok {
+				var err error
+				secret, err = e.storage.GetSecret(name)
+				if err != nil {
+					if resolveErr == nil {
+						resolveErr = secretRefError(name, err)
+					}
+					return ref
+				}
+				cache[name] = secret
+				if !red.add(secret) {
+					loggerFromContext(ctx).Warn("Secret is too short to be masked in outputs and logs", "secret", name)
+				}
+			}
+			return secret
+		})
+		return out, resolveErr
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			resolved, err := e.resolveSecretValue(ctx, item, red, cache)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = resolved
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved, err := e.resolveSecretValue(ctx, item, red, cache)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	default:
+		return value, nil
+	}
+}
+
+func secretRefError(name string, err error) error {
+	if os.IsNotExist(err) {
+		return fmt.Errorf("secret %q is not set", name)
+	}
+	return fmt.Errorf("secret %q: %w", name, err)
+}
+
+func hasSecretRefs(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return secretRef.MatchString(v)
+	case map[string]interface{}:
+		for _, item := range v {
+			if hasSecretRefs(item) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if hasSecretRefs(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// editorRedactor returns the redactor of an execution the editor is
+// running, creating it when create is set. Without one it returns nil,
+// which masks nothing.
+func (s *Storage) editorRedactor(execID string, create bool) *redactor {
+	s.editorRunsMu.Lock()
+	defer s.editorRunsMu.Unlock()
+	red := s.editorRuns[execID]
+	if red == nil && create {
+		if s.editorRuns == nil {
+			s.editorRuns = make(map[string]*redactor)
+		}
+		red = newRedactor()
+		s.editorRuns[execID] = red
+	}
+	return red
+}
+
+func (s *Storage) endEditorRun(execID string) {
+	s.editorRunsMu.Lock()
+	delete(s.editorRuns, execID)
+	s.editorRunsMu.Unlock()
+}
+
+// RunEditorNode runs a node of the editor's execution execID whose config
+// references secrets, so that only the engine sees their values. The output
+// comes back masked, and the values are also masked in the execution and
+// logs the editor saves for execID. Custom nodes and HTTP requests can run
+// here; plugin nodes resolve their references in RunPluginNode.
+func (e *Engine) RunEditorNode(execID, traceparent, nodeType string, config map[string]interface{}, input interface{}) (*PluginResult, error) {
+	if err := checkFlowID(execID); err != nil {
+		return nil, fmt.Errorf("invalid execution ID: %q", execID)
+	}
+	red := e.storage.editorRedactor(execID, true)
+	result := &PluginResult{Logs: []LogEntry{}}
+	execLog := &ExecutionLog{onWrite: func(entry LogEntry) {
+		result.Logs = append(result.Logs, entry)
+	}}
+	ctx := contextWithRedactor(context.Background(), red)
+	ctx = contextWithLogger(ctx, execLog.Logger().withRedactor(red))
+	ctx, done := e.editorSpan(ctx, traceparent)
+	defer done()
+
+	config, err := e.resolveSecretRefs(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	output, err := e.runEditorNode(ctx, nodeType, config, input)
+	result.Output = red.Value(output)
+	return result, red.Error(err)
+}
+
+func (e *Engine) runEditorNode(ctx context.Context, nodeType string, config map[string]interface{}, input interface{}) (interface{}, error) {
+	switch {
+	case isCustomNodeType(nodeType):
+		def, err := e.storage.loadCustomNodeDefinition(nodeType)
+		if err != nil {
+			return nil, err
+		}
+		return e.executeCustomNode(ctx, def, config, input)
+	case nodeType == "action_http":
+		return runHTTPNode(ctx, config)
+	}
+	return nil, fmt.Errorf("%s nodes can't use secret references; use an HTTP Request or a custom node", nodeType)
+}
+
+// runHTTPNode sends the request of an HTTP Request node the way its
+// frontend handler does, returning the JSON response or the body
+func runHTTPNode(ctx context.Context, config map[string]interface{}) (interface{}, error) {
+	method, _ := config["method"].(string)
+	if method == "" {
+		method = "GET"
+	}
+	url, _ := config["url"].(string)
+	if url == "" {
+		return nil, errors.New("no URL specified")
+	}
+	headers := map[string]string{}
+	switch h := config["headers"].(type) {
+	case string:
+		if strings.TrimSpace(h) != "" {
+			if err := json.Unmarshal([]byte(h), &headers); err != nil {
+				loggerFromContext(ctx).Warn("Invalid headers JSON, using empty headers", "error", err.Error())
+				headers = map[string]string{}
+			}
+		}
+	case map[string]interface{}:
+		for key, value := range h {
+			headers[key] = fmt.Sprint(value)
+		}
+	}
+	var body string
+	switch b := config["body"].(type) {
+	case string:
+		body = b
+	case nil:
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return nil, err
+		}
+		body = string(data)
+	}
+
+	resp, err := doHTTPRequest(ctx, method, url, headers, body)
+	if err != nil {
+		return nil, err
+	}
+	if data, ok := resp["json"]; ok && data != nil {
+		return data, nil
+	}
+	return resp["body"], nil
+}
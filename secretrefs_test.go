@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedactorShortValues(t *testing.T) {
+	red := newRedactor()
+	if red.add("1") {
+		t.Error("a one-character value was accepted")
+	}
+	if !red.add("s3cret") {
+		t.Error("a secret was refused")
+	}
+	if got := red.String("1 s3cret 1"); got != "1 "+redactedMarker+" 1" {
+		t.Errorf("masked %q", got)
+	}
+}
+
+func TestRunEditorNode(t *testing.T) {
+	// The server echoes the token it was sent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.NewEncoder(w).Encode(map[string]string{"auth": r.Header.Get("Authorization"), "body": string(body)})
+	}))
+	defer server.Close()
+
+	s := NewStorageAt(t.TempDir())
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.close)
+	if err := s.SaveSecret("api_token", "tok-12345"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveSecret("pin", "7"); err != nil {
+		t.Fatal(err)
+	}
+	e := NewEngine(s)
+
+	config := map[string]interface{}{
+		"method":  "POST",
+		"url":     server.URL,
+		"headers": `{"Authorization": "Bearer {{secret.api_token}}"}`,
+		"body":    "pin={{secret.pin}}",
+	}
+	result, err := e.RunEditorNode("run-1", "", "action_http", config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, _ := json.Marshal(result.Output)
+	if strings.Contains(string(output), "tok-12345") || !strings.Contains(string(output), "Bearer "+redactedMarker) {
+		t.Errorf("output not masked: %s", output)
+	}
+	if len(result.Logs) == 0 || !strings.Contains(result.Logs[0].Message, "too short") {
+		t.Errorf("no warning for the short secret: %+v", result.Logs)
+	}
+
+	// The value is masked in what the editor saves for the run, and only there
+	execution, _ := json.Marshal(map[string]interface{}{"id": "run-1", "flowId": "f", "status": "success", "results": []interface{}{map[string]interface{}{"output": "tok-12345"}}})
+	if err := s.SaveExecution(string(execution)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AppendExecutionLogs("run-1", []LogEntry{{Message: "sent tok-12345"}}); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := s.loadExecution("run-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(saved)
+	logs, err := s.QueryExecutionLogs("run-1", "", "", "", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "tok-12345") || len(logs.Entries) != 1 || logs.Entries[0].Message != "sent "+redactedMarker {
+		t.Errorf("saved run not masked: %s %+v", data, logs.Entries)
+	}
+	if s.editorRedactor("run-1", false) != nil {
+		t.Error("the run's redactor outlived its logs")
+	}
+
+	if _, err := e.RunEditorNode("run-2", "", "action_file", map[string]interface{}{"content": "{{secret.api_token}}"}, nil); err == nil {
+		t.Error("a built-in node the engine can't run got the secret")
+	}
+}
@@ -0,0 +1,655 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Secrets are sealed with AES-256-GCM under a master key. The key either
+// lives in a key file next to the data (the default, unlocked on demand) or
+// is derived from a passphrase with Argon2id and must be unlocked once per
+// session.
+const (
+	SecretsKeyFile    = "keyfile"
+	SecretsPassphrase = "passphrase"
+
+	secretsVaultFile = "vault.json" // vault settings: mode, salt, key check
+	secretsKeyFile   = "vault.key"  // master key in keyfile mode
+
+	// secretsPassphraseEnv unlocks a passphrase vault without the UI, e.g.
+	// for headless runs
+	secretsPassphraseEnv = "FORGEFLOW_SECRETS_PASSPHRASE"
+)
+
+// Argon2id parameters for new passphrase vaults (RFC 9106, second
+// recommended option); existing vaults keep the ones they were created with
+const (
+	argon2Time    = 3
+	argon2Memory  = 64 * 1024 // KiB
+	argon2Threads = 4
+)
+
+var ErrSecretsLocked = errors.New("secrets are locked; unlock them with the master passphrase")
+
+// secretNamePattern keeps names usable as file names in any backend: no
+// path separators and no leading dot
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+
+// vaultConfig is stored in vault.json. Check is a known value sealed under
+// the master key, to tell a wrong passphrase from a corrupt secret.
+type vaultConfig struct {
+	Version int    `json:"version"`
+	Mode    string `json:"mode"`
+	KeyID   string `json:"keyId"`
+	Salt    []byte `json:"salt,omitempty"`
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+	Check   []byte `json:"check"`
+
+	// Pending is the key a rekey is moving the secrets to. It is saved
+	// before any secret is re-encrypted, so an interrupted rekey leaves
+	// every secret readable.
+	Pending *pendingVaultKey `json:"pending,omitempty"`
+}
+
+// pendingVaultKey is the next master key, sealed under the current one so
+// it needs no file or passphrase of its own until the vault switches to it
+type pendingVaultKey struct {
+	Config *vaultConfig `json:"config"`
+	Key    []byte       `json:"key"`
+}
+
+// vaultKeys opens secrets during a rekey, when some are still sealed under
+// the current key and the others under the pending one
+type vaultKeys struct {
+	current, pending     []byte
+	currentID, pendingID string
+}
+
+// sealedSecret is the stored form of a secret. The name is bound to the
+// ciphertext as additional data, so values can't be swapped between names.
+type sealedSecret struct {
+	Version   int    `json:"v"`
+	KeyID     string `json:"keyId"`
+	Nonce     []byte `json:"nonce"`
+	Data      []byte `json:"data"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// SecretInfo describes a stored secret without its value
+type SecretInfo struct {
+	Name      string `json:"name"`
+	CreatedAt string `json:"createdAt,omitempty"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+	Encrypted bool   `json:"encrypted"` // false for plaintext left by older versions
+}
+
+type SecretsStatus struct {
+	Initialized bool   `json:"initialized"`
+	Mode        string `json:"mode,omitempty"`
+	Locked      bool   `json:"locked"`
+	Count       int    `json:"count"`
+}
+
+// secretVault holds the unlocked master key for the session
+type secretVault struct {
+	mu    sync.Mutex
+	key   []byte
+	keyID string // of the vault the key was unlocked for
+}
+
+func checkSecretName(name string) error {
+	if !secretNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q: use letters, digits, '_', '-' and '.', not starting with '.'", name)
+	}
+	return nil
+}
+
+func (s *Storage) readVaultConfig() (*vaultConfig, error) {
+	data, err := os.ReadFile(filepath.Join(s.dataDir, secretsVaultFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var config vaultConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", secretsVaultFile, err)
+	}
+
+	// A rekey to a key file that stopped after writing the new key file
+	// has moved every secret already; only the settings are left to switch
+	if pending := config.Pending; pending != nil && pending.Config != nil && pending.Config.Mode == SecretsKeyFile {
+		key, err := os.ReadFile(filepath.Join(s.dataDir, secretsKeyFile))
+		if err == nil && verifyVaultKey(pending.Config, key) == nil {
+			if err := s.writeVaultConfig(pending.Config); err != nil {
+				return nil, err
+			}
+			return pending.Config, nil
+		}
+	}
+	return &config, nil
+}
+
+func (s *Storage) writeVaultConfig(config *vaultConfig) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(s.dataDir, secretsVaultFile), data, 0600)
+}
+
+// vaultKey returns the master key, loading it from the key file or the
+// passphrase environment variable when the vault isn't unlocked yet
+func (s *Storage) vaultKey(config *vaultConfig) ([]byte, error) {
+	s.secrets.mu.Lock()
+	defer s.secrets.mu.Unlock()
+	// Another process may have rekeyed the vault since it was unlocked
+	if s.secrets.key != nil && s.secrets.keyID == config.KeyID {
+		return s.secrets.key, nil
+	}
+
+	var key []byte
+	switch config.Mode {
+	case SecretsKeyFile:
+		data, err := os.ReadFile(filepath.Join(s.dataDir, secretsKeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the secrets key file: %w", err)
+		}
+		key = data
+	case SecretsPassphrase:
+		passphrase := os.Getenv(secretsPassphraseEnv)
+		if passphrase == "" {
+			return nil, ErrSecretsLocked
+		}
+		key = deriveVaultKey(config, passphrase)
+	default:
+		return nil, fmt.Errorf("unknown secrets mode: %q", config.Mode)
+	}
+
+	if err := verifyVaultKey(config, key); err != nil {
+		return nil, err
+	}
+	s.secrets.key, s.secrets.keyID = key, config.KeyID
+	return key, nil
+}
+
+// vaultKeys returns the master key and, while a rekey is pending, the key
+// it is moving the secrets to
+func (s *Storage) vaultKeys(config *vaultConfig) (*vaultKeys, error) {
+	key, err := s.vaultKey(config)
+	if err != nil {
+		return nil, err
+	}
+	keys := &vaultKeys{current: key, currentID: config.KeyID}
+	if pending := config.Pending; pending != nil && pending.Config != nil {
+		pendingKey, err := openSecret(key, config.KeyID, "vault-pending", pending.Key)
+		if err != nil {
+			return nil, fmt.Errorf("the pending secrets key can't be opened: %w", err)
+		}
+		if err := verifyVaultKey(pending.Config, pendingKey); err != nil {
+			return nil, err
+		}
+		keys.pending, keys.pendingID = pendingKey, pending.Config.KeyID
+	}
+	return keys, nil
+}
+
+// open decrypts a stored secret with whichever key sealed it
+func (k *vaultKeys) open(name string, stored []byte) ([]byte, error) {
+	var sealed sealedSecret
+	if k.pending != nil && json.Unmarshal(stored, &sealed) == nil && sealed.KeyID == k.pendingID {
+		return openSecret(k.pending, k.pendingID, name, stored)
+	}
+	return openSecret(k.current, k.currentID, name, stored)
+}
+
+func deriveVaultKey(config *vaultConfig, passphrase string) []byte {
+	return argon2.IDKey([]byte(passphrase), config.Salt, config.Time, config.Memory, config.Threads, 32)
+}
+
+func verifyVaultKey(config *vaultConfig, key []byte) error {
+	if _, err := openSecret(key, config.KeyID, "vault-check", config.Check); err != nil {
+		if config.Mode == SecretsPassphrase {
+			return errors.New("wrong secrets passphrase")
+		}
+		return errors.New("the secrets key file doesn't match the vault")
+	}
+	return nil
+}
+
+// newVaultKey creates a master key and the vault settings for it. An empty
+// passphrase creates a random key for the key file.
+func newVaultKey(passphrase string) ([]byte, *vaultConfig, error) {
+	config := &vaultConfig{Version: 1}
+	var key []byte
+	if passphrase == "" {
+		config.Mode = SecretsKeyFile
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		config.Mode = SecretsPassphrase
+		config.Salt = make([]byte, 16)
+		if _, err := rand.Read(config.Salt); err != nil {
+			return nil, nil, err
+		}
+		config.Time, config.Memory, config.Threads = argon2Time, argon2Memory, argon2Threads
+		key = deriveVaultKey(config, passphrase)
+	}
+
+	sum := sha256.Sum256(key)
+	config.KeyID = hex.EncodeToString(sum[:4])
+	check, err := sealSecret(key, config.KeyID, "vault-check", []byte("forgeflow"), "")
+	if err != nil {
+		return nil, nil, err
+	}
+	config.Check = check
+	return key, config, nil
+}
+
+// installVaultKey makes a new master key current: the key file (in keyfile
+// mode) is written before the settings that refer to it
+func (s *Storage) installVaultKey(key []byte, config *vaultConfig) error {
+	keyPath := filepath.Join(s.dataDir, secretsKeyFile)
+	if config.Mode == SecretsKeyFile {
+		if err := writeFileAtomic(keyPath, key, 0600); err != nil {
+			return err
+		}
+	}
+	if err := s.writeVaultConfig(config); err != nil {
+		return err
+	}
+	if config.Mode != SecretsKeyFile {
+		os.Remove(keyPath)
+	}
+
+	s.secrets.mu.Lock()
+	s.secrets.key, s.secrets.keyID = key, config.KeyID
+	s.secrets.mu.Unlock()
+	return nil
+}
+
+// ensureVault returns the master key and settings under the storage write
+// lock, creating a keyfile vault the first time and encrypting plaintext
+// secrets left by older versions
+func (s *Storage) ensureVault(store StorageBackend) ([]byte, *vaultConfig, error) {
+	config, err := s.readVaultConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	if config != nil {
+		key, err := s.vaultKey(config)
+		return key, config, err
+	}
+
+	key, config, err := newVaultKey("")
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.installVaultKey(key, config); err != nil {
+		return nil, nil, err
+	}
+	names, err := store.ListSecrets()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, name := range names {
+		value, err := store.GetSecret(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if isSealedSecret(value) {
+			continue
+		}
+		sealed, err := sealSecret(key, config.KeyID, name, value, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := store.PutSecret(name, sealed); err != nil {
+			return nil, nil, fmt.Errorf("failed to encrypt secret %s: %w", name, err)
+		}
+	}
+	return key, config, nil
+}
+
+// sealSecret encrypts a value for the given name. createdAt carries over
+// the creation time of the value it replaces.
+func sealSecret(key []byte, keyID, name string, value []byte, createdAt string) ([]byte, error) {
+	gcm, err := newSecretCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	now := time.Now().Format(time.RFC3339)
+	if createdAt == "" {
+		createdAt = now
+	}
+	return json.Marshal(sealedSecret{
+		Version:   1,
+		KeyID:     keyID,
+		Nonce:     nonce,
+		Data:      gcm.Seal(nil, nonce, value, []byte(name)),
+		CreatedAt: createdAt,
+		UpdatedAt: now,
+	})
+}
+
+// openSecret decrypts a stored secret. Values that aren't sealed are
+// plaintext from before encryption and returned as they are.
+func openSecret(key []byte, keyID, name string, stored []byte) ([]byte, error) {
+	var sealed sealedSecret
+	if json.Unmarshal(stored, &sealed) != nil || sealed.Version == 0 || len(sealed.Nonce) == 0 {
+		return stored, nil
+	}
+	if sealed.KeyID != keyID {
+		return nil, fmt.Errorf("secret %s is encrypted with a different master key", name)
+	}
+	gcm, err := newSecretCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("secret %s is corrupt", name)
+	}
+	value, err := gcm.Open(nil, sealed.Nonce, sealed.Data, []byte(name))
+	if err != nil {
+		return nil, fmt.Errorf("secret %s can't be decrypted: %w", name, err)
+	}
+	return value, nil
+}
+
+func isSealedSecret(stored []byte) bool {
+	var sealed sealedSecret
+	return json.Unmarshal(stored, &sealed) == nil && sealed.Version != 0 && len(sealed.Nonce) > 0
+}
+
+func newSecretCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *Storage) SaveSecret(key, value string) error {
+	if err := checkSecretName(key); err != nil {
+		return err
+	}
+	store, unlock, err := s.writer()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	masterKey, config, err := s.ensureVault(store)
+	if err != nil {
+		return err
+	}
+	createdAt := ""
+	if stored, err := store.GetSecret(key); err == nil {
+		var previous sealedSecret
+		if json.Unmarshal(stored, &previous) == nil {
+			createdAt = previous.CreatedAt
+		}
+	}
+	sealed, err := sealSecret(masterKey, config.KeyID, key, []byte(value), createdAt)
+	if err != nil {
+		return err
+	}
+	return store.PutSecret(key, sealed)
+}
+
+// GetSecret reads a local secret, or one from an external provider when
+// the key is "provider:name"
+func (s *Storage) GetSecret(key string) (string, error) {
+	if provider, name, ok := strings.Cut(key, ":"); ok {
+		return s.getProviderSecret(provider, name)
+	}
+	if err := checkSecretName(key); err != nil {
+		return "", err
+	}
+	if err := s.initVault(); err != nil {
+		return "", err
+	}
+	store, unlock, err := s.reader()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	config, err := s.readVaultConfig()
+	if err != nil {
+		return "", err
+	}
+	if config == nil {
+		return "", errors.New("the secrets vault is missing")
+	}
+	keys, err := s.vaultKeys(config)
+	if err != nil {
+		return "", err
+	}
+	stored, err := store.GetSecret(key)
+	if err != nil {
+		return "", err
+	}
+	value, err := keys.open(key, stored)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// initVault creates the vault if there is none yet, so reads never see
+// unencrypted secrets for long
+func (s *Storage) initVault() error {
+	if err := s.Init(); err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(s.dataDir, secretsVaultFile)); err == nil {
+		return nil
+	}
+	store, unlock, err := s.writer()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	_, _, err = s.ensureVault(store)
+	return err
+}
+
+// ListSecrets returns the names and timestamps of all secrets. It works
+// while the vault is locked, since only values are encrypted.
+func (s *Storage) ListSecrets() ([]SecretInfo, error) {
+	store, unlock, err := s.reader()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	names, err := store.ListSecrets()
+	if err != nil {
+		return nil, err
+	}
+	secrets := []SecretInfo{}
+	for _, name := range names {
+		info := SecretInfo{Name: name}
+		if stored, err := store.GetSecret(name); err == nil && isSealedSecret(stored) {
+			var sealed sealedSecret
+			json.Unmarshal(stored, &sealed)
+			info.CreatedAt, info.UpdatedAt, info.Encrypted = sealed.CreatedAt, sealed.UpdatedAt, true
+		}
+		secrets = append(secrets, info)
+	}
+	return secrets, nil
+}
+
+func (s *Storage) DeleteSecret(key string) error {
+	if err := checkSecretName(key); err != nil {
+		return err
+	}
+	store, unlock, err := s.writer()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return store.DeleteSecret(key)
+}
+
+func (s *Storage) GetSecretsStatus() (*SecretsStatus, error) {
+	store, unlock, err := s.reader()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	status := &SecretsStatus{}
+	if names, err := store.ListSecrets(); err == nil {
+		status.Count = len(names)
+	}
+	config, err := s.readVaultConfig()
+	if err != nil || config == nil {
+		return status, err
+	}
+	status.Initialized = true
+	status.Mode = config.Mode
+	_, err = s.vaultKey(config)
+	status.Locked = errors.Is(err, ErrSecretsLocked)
+	return status, nil
+}
+
+// UnlockSecrets unlocks a passphrase vault for the rest of the session
+func (s *Storage) UnlockSecrets(passphrase string) error {
+	if err := s.Init(); err != nil {
+		return err
+	}
+	config, err := s.readVaultConfig()
+	if err != nil {
+		return err
+	}
+	if config == nil || config.Mode != SecretsPassphrase {
+		return errors.New("secrets aren't protected by a passphrase")
+	}
+	key := deriveVaultKey(config, passphrase)
+	if err := verifyVaultKey(config, key); err != nil {
+		return err
+	}
+	s.secrets.mu.Lock()
+	s.secrets.key, s.secrets.keyID = key, config.KeyID
+	s.secrets.mu.Unlock()
+	return nil
+}
+
+// LockSecrets forgets the master key until the vault is unlocked again. A
+// keyfile vault unlocks itself on the next use.
+func (s *Storage) LockSecrets() {
+	s.secrets.mu.Lock()
+	defer s.secrets.mu.Unlock()
+	s.secrets.key, s.secrets.keyID = nil, ""
+}
+
+// RekeySecrets re-encrypts every secret under a new master key, derived
+// from the passphrase or, when it's empty, kept in a new random key file.
+// The vault must be unlocked.
+func (s *Storage) RekeySecrets(passphrase string) error {
+	store, unlock, err := s.writer()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	key, config, err := s.ensureVault(store)
+	if err != nil {
+		return err
+	}
+	// Finish an interrupted rekey first, so no secret is left under a key
+	// the vault no longer knows
+	if config.Pending != nil {
+		if err := s.moveSecrets(store, config); err != nil {
+			return err
+		}
+		if key, config, err = s.ensureVault(store); err != nil {
+			return err
+		}
+	}
+
+	newKey, newConfig, err := newVaultKey(passphrase)
+	if err != nil {
+		return err
+	}
+	sealedKey, err := sealSecret(key, config.KeyID, "vault-pending", newKey, "")
+	if err != nil {
+		return err
+	}
+	config.Pending = &pendingVaultKey{Config: newConfig, Key: sealedKey}
+	return s.moveSecrets(store, config)
+}
+
+// moveSecrets re-encrypts every secret under the pending key of the vault
+// and then switches to it. The pending key is saved before any secret is
+// written, and secrets are opened with either key until the switch.
+func (s *Storage) moveSecrets(store StorageBackend, config *vaultConfig) error {
+	keys, err := s.vaultKeys(config)
+	if err != nil {
+		return err
+	}
+
+	// Decrypt everything before writing anything, so a bad secret stops the
+	// rekey with the vault unchanged
+	names, err := store.ListSecrets()
+	if err != nil {
+		return err
+	}
+	values := make(map[string][]byte, len(names))
+	created := make(map[string]string, len(names))
+	for _, name := range names {
+		stored, err := store.GetSecret(name)
+		if err != nil {
+			return err
+		}
+		value, err := keys.open(name, stored)
+		if err != nil {
+			return err
+		}
+		values[name] = value
+		var sealed sealedSecret
+		if json.Unmarshal(stored, &sealed) == nil {
+			created[name] = sealed.CreatedAt
+		}
+	}
+
+	if err := s.writeVaultConfig(config); err != nil {
+		return err
+	}
+	for _, name := range names {
+		sealed, err := sealSecret(keys.pending, keys.pendingID, name, values[name], created[name])
+		if err != nil {
+			return err
+		}
+		if err := store.PutSecret(name, sealed); err != nil {
+			return fmt.Errorf("failed to re-encrypt secret %s: %w", name, err)
+		}
+	}
+	return s.installVaultKey(keys.pending, config.Pending.Config)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// failingSecrets fails every PutSecret after the first n
+type failingSecrets struct {
+	StorageBackend
+	n int
+}
+
+func (f *failingSecrets) PutSecret(key string, value []byte) error {
+	if f.n == 0 {
+		return errors.New("disk full")
+	}
+	f.n--
+	return f.StorageBackend.PutSecret(key, value)
+}
+
+var testSecrets = map[string]string{"API_KEY": "a-1", "DB_PASSWORD": "b-2", "TOKEN": "c-3"}
+
+// newSecretStorage opens a storage in dir holding testSecrets
+func newSecretStorage(t *testing.T, dir string) *Storage {
+	t.Helper()
+	s := NewStorageAt(dir)
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.close)
+	for name, value := range testSecrets {
+		if err := s.SaveSecret(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+// interruptRekey runs a rekey that fails after re-encrypting one secret
+func interruptRekey(t *testing.T, s *Storage, passphrase string) {
+	t.Helper()
+	backend := s.backend
+	s.backend = &failingSecrets{StorageBackend: backend, n: 1}
+	err := s.RekeySecrets(passphrase)
+	s.backend = backend
+	if err == nil {
+		t.Fatal("rekey succeeded despite the failing backend")
+	}
+}
+
+func checkSecrets(t *testing.T, s *Storage) {
+	t.Helper()
+	for name, want := range testSecrets {
+		got, err := s.GetSecret(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestRekeyInterrupted(t *testing.T) {
+	dir := t.TempDir()
+	s := newSecretStorage(t, dir)
+	interruptRekey(t, s, "new passphrase")
+	s.close()
+
+	// Secrets sealed under either key stay readable after a restart
+	s = NewStorageAt(dir)
+	t.Cleanup(s.close)
+	checkSecrets(t, s)
+
+	// The next rekey finishes the interrupted one and then switches again
+	if err := s.RekeySecrets("new passphrase"); err != nil {
+		t.Fatalf("rekey: %v", err)
+	}
+	config, err := s.readVaultConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Mode != SecretsPassphrase || config.Pending != nil {
+		t.Fatalf("vault is %s with pending %v after the rekey", config.Mode, config.Pending)
+	}
+	s.LockSecrets()
+	if _, err := s.GetSecret("API_KEY"); !errors.Is(err, ErrSecretsLocked) {
+		t.Fatalf("locked vault read: %v", err)
+	}
+	if err := s.UnlockSecrets("new passphrase"); err != nil {
+		t.Fatal(err)
+	}
+	checkSecrets(t, s)
+}
+
+func TestRekeyInterruptedBeforeSwitch(t *testing.T) {
+	dir := t.TempDir()
+	s := newSecretStorage(t, dir)
+	interruptRekey(t, s, "")
+
+	// Stop as if after moving every secret and writing the new key file,
+	// but before the settings switched to it
+	config, err := s.readVaultConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := s.vaultKeys(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.moveSecretsOnly(keys); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, secretsKeyFile), keys.pending, 0600); err != nil {
+		t.Fatal(err)
+	}
+	s.close()
+
+	s = NewStorageAt(dir)
+	t.Cleanup(s.close)
+	checkSecrets(t, s)
+	config, err = s.readVaultConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.KeyID != keys.pendingID || config.Pending != nil {
+		t.Fatalf("vault is on key %s, want the new key %s", config.KeyID, keys.pendingID)
+	}
+}
+
+// moveSecretsOnly re-encrypts the secrets under the pending key without
+// switching the vault to it
+func (s *Storage) moveSecretsOnly(keys *vaultKeys) error {
+	for name := range testSecrets {
+		stored, err := s.backend.GetSecret(name)
+		if err != nil {
+			return err
+		}
+		value, err := keys.open(name, stored)
+		if err != nil {
+			return err
+		}
+		sealed, err := sealSecret(keys.pending, keys.pendingID, name, value, "")
+		if err != nil {
+			return err
+		}
+		if err := s.backend.PutSecret(name, sealed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+const sqliteDatabaseFile = "forgeflow.db"
+
+// sqliteSchema is applied when a database is created, followed by the
+// upgrade steps. Change the schema by adding a step that bumps PRAGMA
+// user_version, and run it in openSQLiteBackend.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS flows (
+	id          TEXT PRIMARY KEY,
+	name        TEXT NOT NULL DEFAULT '',
+	description TEXT NOT NULL DEFAULT '',
+	enabled     INTEGER NOT NULL DEFAULT 0,
+	node_count  INTEGER NOT NULL DEFAULT 0,
+	created_at  TEXT NOT NULL DEFAULT '',
+	updated_at  TEXT NOT NULL DEFAULT '',
+	data        BLOB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS flows_updated_at ON flows (updated_at);
+
+CREATE TABLE IF NOT EXISTS executions (
+	id            TEXT PRIMARY KEY,
+	flow_id       TEXT NOT NULL DEFAULT '',
+	flow_name     TEXT NOT NULL DEFAULT '',
+	trigger_type  TEXT NOT NULL DEFAULT '',
+	status        TEXT NOT NULL DEFAULT '',
+	started_at    TEXT NOT NULL DEFAULT '',
+	ended_at      TEXT NOT NULL DEFAULT '',
+	node_count    INTEGER NOT NULL DEFAULT 0,
+	success_count INTEGER NOT NULL DEFAULT 0,
+	error_count   INTEGER NOT NULL DEFAULT 0,
+	data          BLOB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS executions_flow_id ON executions (flow_id, started_at);
+CREATE INDEX IF NOT EXISTS executions_status ON executions (status, started_at);
+CREATE INDEX IF NOT EXISTS executions_started_at ON executions (started_at);
+
+CREATE TABLE IF NOT EXISTS settings (
+	id   INTEGER PRIMARY KEY CHECK (id = 1),
+	data BLOB NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS secrets (
+	key   TEXT PRIMARY KEY,
+	value BLOB NOT NULL
+);
+
+PRAGMA user_version = 1;
+`
+
+// sqliteSchemaV2 orders and filters executions by start time in unix
+// milliseconds, since the stored strings carry local time zones and don't
+// sort across them, and adds the duration for range queries
+const sqliteSchemaV2 = `
+BEGIN;
+ALTER TABLE executions ADD COLUMN started_ms INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE executions ADD COLUMN duration_ms INTEGER NOT NULL DEFAULT 0;
+UPDATE executions SET started_ms = CAST(ROUND((julianday(started_at) - 2440587.5) * 86400000) AS INTEGER)
+	WHERE julianday(started_at) IS NOT NULL;
+UPDATE executions SET duration_ms = MAX(0, CAST(ROUND((julianday(ended_at) - julianday(started_at)) * 86400000) AS INTEGER))
+	WHERE ended_at != '' AND julianday(ended_at) IS NOT NULL AND julianday(started_at) IS NOT NULL;
+DROP INDEX IF EXISTS executions_flow_id;
+DROP INDEX IF EXISTS executions_status;
+DROP INDEX IF EXISTS executions_started_at;
+CREATE INDEX executions_flow_id ON executions (flow_id, started_ms);
+CREATE INDEX executions_status ON executions (status, started_ms);
+CREATE INDEX executions_trigger ON executions (trigger_type, started_ms);
+CREATE INDEX executions_started_ms ON executions (started_ms, id);
+PRAGMA user_version = 2;
+COMMIT;
+`
+
+// sqliteBackend keeps everything in one SQLite database, with the summary
+// fields in indexed columns so listing doesn't decode any documents
+type sqliteBackend struct {
+	db *sql.DB
+}
+
+func openSQLiteBackend(path string) (*sqliteBackend, error) {
+	// WAL lets readers (e.g. the GUI) run alongside a writer, and the busy
+	// timeout makes a second process wait for locks instead of failing
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, err
+	}
+
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if version > 2 {
+		db.Close()
+		return nil, fmt.Errorf("database schema version %d is newer than this version of ForgeFlow supports", version)
+	}
+	if version == 0 {
+		if _, err := db.Exec(sqliteSchema); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create database: %w", err)
+		}
+		version = 1
+	}
+	if version == 1 {
+		if _, err := db.Exec(sqliteSchemaV2); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to upgrade database: %w", err)
+		}
+	}
+	return &sqliteBackend{db: db}, nil
+}
+
+func (b *sqliteBackend) Name() string { return BackendSQLite }
+
+// notFound maps a missing row to os.ErrNotExist, as the JSON backend reports
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return os.ErrNotExist
+	}
+	return err
+}
+
+func (b *sqliteBackend) PutFlow(summary FlowSummary, data []byte) error {
+	_, err := b.db.Exec(`INSERT INTO flows (id, name, description, enabled, node_count, created_at, updated_at, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET name = excluded.name, description = excluded.description,
+			enabled = excluded.enabled, node_count = excluded.node_count, created_at = excluded.created_at,
+			updated_at = excluded.updated_at, data = excluded.data`,
+		summary.ID, summary.Name, summary.Description, summary.Enabled, summary.NodeCount,
+		summary.CreatedAt, summary.UpdatedAt, data)
+	return err
+}
+
+func (b *sqliteBackend) GetFlow(id string) ([]byte, error) {
+	var data []byte
+	err := b.db.QueryRow(`SELECT data FROM flows WHERE id = ?`, id).Scan(&data)
+	return data, notFound(err)
+}
+
+func (b *sqliteBackend) ListFlows() ([]FlowSummary, error) {
+	rows, err := b.db.Query(`SELECT id, name, description, enabled, node_count, created_at, updated_at
+		FROM flows ORDER BY updated_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	flows := []FlowSummary{}
+	for rows.Next() {
+		var f FlowSummary
+		if err := rows.Scan(&f.ID, &f.Name, &f.Description, &f.Enabled, &f.NodeCount, &f.CreatedAt, &f.UpdatedAt); err != nil {
+			return nil, err
+		}
+		flows = append(flows, f)
+	}
+	return flows, rows.Err()
+}
+
+func (b *sqliteBackend) DeleteFlow(id string) error {
+	return b.deleteRow(`DELETE FROM flows WHERE id = ?`, id)
+}
+
+func (b *sqliteBackend) PutExecution(summary ExecutionSummary, data []byte) error {
+	_, err := b.db.Exec(`INSERT INTO executions (id, flow_id, flow_name, trigger_type, status, started_at, ended_at,
+			started_ms, duration_ms, node_count, success_count, error_count, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET flow_id = excluded.flow_id, flow_name = excluded.flow_name,
+			trigger_type = excluded.trigger_type, status = excluded.status, started_at = excluded.started_at,
+			ended_at = excluded.ended_at, started_ms = excluded.started_ms, duration_ms = excluded.duration_ms,
+			node_count = excluded.node_count, success_count = excluded.success_count,
+			error_count = excluded.error_count, data = excluded.data`,
+		summary.ID, summary.FlowID, summary.FlowName, summary.Trigger, summary.Status, summary.StartedAt,
+		summary.EndedAt, executionTime(summary.StartedAt), summary.DurationMs, summary.NodeCount,
+		summary.SuccessCount, summary.ErrorCount, data)
+	return err
+}
+
+func (b *sqliteBackend) GetExecution(id string) ([]byte, error) {
+	var data []byte
+	err := b.db.QueryRow(`SELECT data FROM executions WHERE id = ?`, id).Scan(&data)
+	return data, notFound(err)
+}
+
+func (b *sqliteBackend) ListExecutions(limit int) ([]ExecutionSummary, error) {
+	if limit <= 0 {
+		limit = -1 // no limit
+	}
+	return b.selectExecutions(`ORDER BY started_ms DESC, id DESC LIMIT ?`, limit)
+}
+
+const executionColumns = `id, flow_id, flow_name, trigger_type, status, started_at, ended_at,
+	duration_ms, node_count, success_count, error_count`
+
+// selectExecutions reads summaries from executions rows; clause follows
+// the FROM
+func (b *sqliteBackend) selectExecutions(clause string, args ...interface{}) ([]ExecutionSummary, error) {
+	rows, err := b.db.Query(`SELECT `+executionColumns+` FROM executions `+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	executions := []ExecutionSummary{}
+	for rows.Next() {
+		var e ExecutionSummary
+		if err := rows.Scan(&e.ID, &e.FlowID, &e.FlowName, &e.Trigger, &e.Status, &e.StartedAt, &e.EndedAt,
+			&e.DurationMs, &e.NodeCount, &e.SuccessCount, &e.ErrorCount); err != nil {
+			return nil, err
+		}
+		executions = append(executions, e)
+	}
+	return executions, rows.Err()
+}
+
+func (b *sqliteBackend) QueryExecutions(filter executionFilter) (*ExecutionPage, error) {
+	// Every filter but the status and the cursor, shared by the counts and
+	// the page
+	var where []string
+	var args []interface{}
+	add := func(cond string, arg ...interface{}) {
+		where = append(where, cond)
+		args = append(args, arg...)
+	}
+	if filter.flowID != "" {
+		add(`flow_id = ?`, filter.flowID)
+	}
+	if filter.trigger != "" {
+		add(`trigger_type = ?`, filter.trigger)
+	}
+	if filter.after != 0 {
+		add(`started_ms >= ?`, filter.after)
+	}
+	if filter.before != 0 {
+		add(`started_ms < ?`, filter.before)
+	}
+	if filter.minDuration > 0 || filter.maxDuration > 0 {
+		add(`ended_at != '' AND duration_ms >= ?`, filter.minDuration)
+		if filter.maxDuration > 0 {
+			add(`duration_ms <= ?`, filter.maxDuration)
+		}
+	}
+	whereClause := func() string {
+		if len(where) == 0 {
+			return ""
+		}
+		return ` WHERE ` + strings.Join(where, ` AND `)
+	}
+
+	page := &ExecutionPage{StatusCounts: map[string]int{}}
+	rows, err := b.db.Query(`SELECT status, COUNT(*) FROM executions`+whereClause()+` GROUP BY status`, args...)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		page.StatusCounts[status] = count
+		if filter.status == "" || status == filter.status {
+			page.Total += count
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if filter.status != "" {
+		add(`status = ?`, filter.status)
+	}
+	if filter.cursor != nil {
+		add(`(started_ms < ? OR (started_ms = ? AND id < ?))`, filter.cursor.startedMs, filter.cursor.startedMs, filter.cursor.id)
+	}
+	// One extra row tells whether there is a next page
+	executions, err := b.selectExecutions(whereClause()+` ORDER BY started_ms DESC, id DESC LIMIT ?`, append(args, filter.limit+1)...)
+	if err != nil {
+		return nil, err
+	}
+	if len(executions) > filter.limit {
+		executions = executions[:filter.limit]
+		page.NextCursor = lastExecutionCursor(executions)
+	}
+	page.Executions = executions
+	return page, nil
+}
+
+func (b *sqliteBackend) DeleteExecution(id string) error {
+	return b.deleteRow(`DELETE FROM executions WHERE id = ?`, id)
+}
+
+func (b *sqliteBackend) GetSettings() ([]byte, error) {
+	var data []byte
+	err := b.db.QueryRow(`SELECT data FROM settings WHERE id = 1`).Scan(&data)
+	return data, notFound(err)
+}
+
+func (b *sqliteBackend) PutSettings(data []byte) error {
+	_, err := b.db.Exec(`INSERT INTO settings (id, data) VALUES (1, ?)
+		ON CONFLICT (id) DO UPDATE SET data = excluded.data`, data)
+	return err
+}
+
+func (b *sqliteBackend) GetSecret(key string) ([]byte, error) {
+	var value []byte
+	err := b.db.QueryRow(`SELECT value FROM secrets WHERE key = ?`, key).Scan(&value)
+	return value, notFound(err)
+}
+
+func (b *sqliteBackend) PutSecret(key string, value []byte) error {
+	_, err := b.db.Exec(`INSERT INTO secrets (key, value) VALUES (?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value`, key, value)
+	return err
+}
+
+func (b *sqliteBackend) ListSecrets() ([]string, error) {
+	rows, err := b.db.Query(`SELECT key FROM secrets ORDER BY key`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []string{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (b *sqliteBackend) DeleteSecret(key string) error {
+	return b.deleteRow(`DELETE FROM secrets WHERE key = ?`, key)
+}
+
+func (b *sqliteBackend) deleteRow(query, id string) error {
+	result, err := b.db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return os.ErrNotExist
+	}
+	return nil
+}
+
+func (b *sqliteBackend) Close() error {
+	return b.db.Close()
+}
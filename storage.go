@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -13,26 +14,157 @@ import (
 type Storage struct {
 	mu      sync.RWMutex
 	dataDir string
+	backend StorageBackend
+
+	search        flowSearchIndex
+	secrets       secretVault
+	providerCache secretCache
+
+	// editorRuns holds a redactor per execution the editor is running, with
+	// the secret values the engine resolved for its nodes
+	editorRunsMu sync.Mutex
+	editorRuns   map[string]*redactor
+
+	// onFlowsChanged is called with the IDs of flows changed outside the
+	// app, e.g. by a git pull
+	onFlowsChanged func(flowIDs []string)
+	// nodeTypeSpec is the engine's lookup of node types, used to check
+	// imported bundles. Without an engine only built-in and custom node
+	// types are known.
+	nodeTypeSpec func(nodeType string) (*NodeTypeSpec, bool)
 }
 
 func NewStorage() *Storage {
 	return &Storage{}
 }
 
+// NewStorageAt creates a storage rooted at an explicit data directory,
+// used by headless commands instead of the user config dir
+func NewStorageAt(dataDir string) *Storage {
+	return &Storage{dataDir: dataDir}
+}
+
+// Init resolves the data directory and opens the storage backend recorded
+// in storage.json (JSON files by default)
 func (s *Storage) Init() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.dataDir != "" {
+	if s.backend != nil {
 		return nil
 	}
 
-	configDir, err := os.UserConfigDir()
+	if s.dataDir == "" {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return err
+		}
+		s.dataDir = filepath.Join(configDir, "ForgeFlow")
+	}
+	if err := os.MkdirAll(s.dataDir, 0755); err != nil {
+		return err
+	}
+
+	name, err := storageBackendName(s.dataDir)
 	if err != nil {
 		return err
 	}
-	s.dataDir = filepath.Join(configDir, "ForgeFlow")
-	return os.MkdirAll(s.dataDir, 0755)
+	backend, err := openStorageBackend(s.dataDir, name)
+	if err != nil {
+		return fmt.Errorf("failed to open %s storage: %w", name, err)
+	}
+	// Execution files changed while ForgeFlow wasn't running are indexed once
+	if b, ok := backend.(*jsonBackend); ok {
+		unlockDir, err := lockDataDir(s.dataDir)
+		if err != nil {
+			return err
+		}
+		b.reconcile()
+		unlockDir()
+	}
+	s.backend = backend
+	return nil
+}
+
+// reader returns the backend with the storage read-locked. Files are
+// replaced atomically, so reads only need to exclude this process's writers.
+func (s *Storage) reader() (StorageBackend, func(), error) {
+	if err := s.Init(); err != nil {
+		return nil, nil, err
+	}
+	s.mu.RLock()
+	return s.backend, s.mu.RUnlock, nil
+}
+
+// writer returns the backend with the storage locked for writing, both in
+// this process and against other ForgeFlow processes (e.g. a headless
+// runner next to the GUI) using the same data directory
+func (s *Storage) writer() (StorageBackend, func(), error) {
+	if err := s.Init(); err != nil {
+		return nil, nil, err
+	}
+	s.mu.Lock()
+	unlockDir, err := lockDataDir(s.dataDir)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, nil, err
+	}
+	return s.backend, func() {
+		unlockDir()
+		s.mu.Unlock()
+	}, nil
+}
+
+// close releases the backend on shutdown
+func (s *Storage) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.backend != nil {
+		s.backend.Close()
+		s.backend = nil
+	}
+}
+
+// GetStorageBackend returns the name of the active storage backend
+func (s *Storage) GetStorageBackend() (string, error) {
+	store, unlock, err := s.reader()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+	return store.Name(), nil
+}
+
+// MigrateStorage copies all flows, executions, settings and secrets into
+// the target backend and switches to it. The old data is left in place, so
+// switching back with another migration overwrites it with current data.
+func (s *Storage) MigrateStorage(target string) (*StorageMigrationReport, error) {
+	current, unlock, err := s.writer()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if target == current.Name() {
+		return nil, fmt.Errorf("storage already uses the %s backend", target)
+	}
+
+	next, err := openStorageBackend(s.dataDir, target)
+	if err != nil {
+		return nil, err
+	}
+	report, err := copyStorage(current, next)
+	if err != nil {
+		next.Close()
+		return nil, fmt.Errorf("migration to %s failed: %w", target, err)
+	}
+	if err := writeStorageBackendName(s.dataDir, target); err != nil {
+		next.Close()
+		return nil, err
+	}
+
+	s.backend.Close()
+	s.backend = next
+	return report, nil
 }
 
 func (s *Storage) getFlowsDir() string {
@@ -41,8 +173,24 @@ func (s *Storage) getFlowsDir() string {
 	return flowsDir
 }
 
+func (s *Storage) getPluginsDir() string {
+	pluginsDir := filepath.Join(s.dataDir, "plugins")
+	os.MkdirAll(pluginsDir, 0755)
+	return pluginsDir
+}
+
 func (s *Storage) SaveFlow(flowJSON string) (string, error) {
-	s.Init()
+	return s.SaveFlowWithMessage(flowJSON, "")
+}
+
+// SaveFlowWithMessage saves a flow and records it in the flow's revision
+// history with an optional message describing the change
+func (s *Storage) SaveFlowWithMessage(flowJSON, message string) (string, error) {
+	store, unlock, err := s.writer()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
 
 	// Parse as generic map to avoid struct issues
 	var flowData map[string]interface{}
@@ -51,37 +199,103 @@ func (s *Storage) SaveFlow(flowJSON string) (string, error) {
 	}
 
 	// Get or generate ID
+	action := "Update"
 	flowID, ok := flowData["id"].(string)
 	if !ok || flowID == "" {
+		action = "Add"
 		flowID = fmt.Sprintf("flow-%d", time.Now().UnixNano())
 		flowData["id"] = flowID
 		flowData["createdAt"] = time.Now().Format(time.RFC3339)
 	}
+	if err := checkFlowID(flowID); err != nil {
+		return "", err
+	}
 	flowData["updatedAt"] = time.Now().Format(time.RFC3339)
 
+	// Flows are saved by the running app, so they're in the current format
+	if version := flowSchemaVersion(flowData); version > FlowSchemaVersion {
+		return "", fmt.Errorf("flow uses schema version %d, but this version of ForgeFlow only supports up to %d", version, FlowSchemaVersion)
+	}
+	flowData["schemaVersion"] = FlowSchemaVersion
+
 	// Save as-is without re-marshaling through structs
 	data, err := json.MarshalIndent(flowData, "", "  ")
 	if err != nil {
 		return "", err
 	}
 
-	filePath := filepath.Join(s.getFlowsDir(), flowID+".json")
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
+	if err := store.PutFlow(flowSummary(flowData), data); err != nil {
 		return "", err
 	}
+	s.indexFlow(flowData)
+	if err := s.recordFlowRevision(flowData, data, message); err != nil {
+		fmt.Printf("Failed to record revision of flow %s: %v\n", flowID, err)
+	}
+	commitMessage := gitFlowMessage(action, flowData)
+	if message != "" {
+		commitMessage += "\n\n" + message
+	}
+	s.commitFlowChange(store, flowID, commitMessage)
 
 	return flowID, nil
 }
 
+// LoadFlow returns a flow in the current schema version. Older files are
+// migrated and rewritten, keeping the original under flows/backups.
 func (s *Storage) LoadFlow(flowID string) (string, error) {
-	s.Init()
+	store, unlock, err := s.reader()
+	if err != nil {
+		return "", err
+	}
+	data, err := store.GetFlow(flowID)
+	unlock()
+	if err != nil {
+		return "", fmt.Errorf("flow not found: %s", flowID)
+	}
+
+	migrated, _, changed, err := upgradeFlowJSON(data)
+	if err != nil {
+		return "", fmt.Errorf("flow %s: %w", flowID, err)
+	}
+	if changed {
+		return s.migrateStoredFlow(flowID)
+	}
+	return string(migrated), nil
+}
 
-	filePath := filepath.Join(s.getFlowsDir(), flowID+".json")
-	data, err := os.ReadFile(filePath)
+// migrateStoredFlow rewrites an old flow in the current schema version. The
+// flow is read again under the write lock, so a save that happened since
+// LoadFlow read it isn't overwritten with the older content.
+func (s *Storage) migrateStoredFlow(flowID string) (string, error) {
+	store, unlock, err := s.writer()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	data, err := store.GetFlow(flowID)
 	if err != nil {
 		return "", fmt.Errorf("flow not found: %s", flowID)
 	}
-	return string(data), nil
+	migrated, from, changed, err := upgradeFlowJSON(data)
+	if err != nil {
+		return "", fmt.Errorf("flow %s: %w", flowID, err)
+	}
+	if !changed {
+		return string(migrated), nil
+	}
+
+	if err := s.backupFlow(flowID, from, data); err != nil {
+		return "", fmt.Errorf("failed to back up flow %s before migrating it: %w", flowID, err)
+	}
+	var flowData map[string]interface{}
+	json.Unmarshal(migrated, &flowData)
+	if err := store.PutFlow(flowSummary(flowData), migrated); err != nil {
+		return "", err
+	}
+	s.indexFlow(flowData)
+	fmt.Printf("Migrated flow %s from schema version %d to %d\n", flowID, from, FlowSchemaVersion)
+	return string(migrated), nil
 }
 
 func (s *Storage) GetFlow(flowID string) (string, error) {
@@ -89,74 +303,386 @@ func (s *Storage) GetFlow(flowID string) (string, error) {
 }
 
 func (s *Storage) ListFlows() ([]map[string]interface{}, error) {
-	s.Init()
+	store, unlock, err := s.reader()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
-	flowsDir := s.getFlowsDir()
-	entries, err := os.ReadDir(flowsDir)
+	summaries, err := store.ListFlows()
 	if err != nil {
-		return []map[string]interface{}{}, nil
+		return nil, err
 	}
 
 	var flows []map[string]interface{}
+	for _, f := range summaries {
+		flows = append(flows, map[string]interface{}{
+			"id":          f.ID,
+			"name":        f.Name,
+			"description": f.Description,
+			"enabled":     f.Enabled,
+			"createdAt":   f.CreatedAt,
+			"updatedAt":   f.UpdatedAt,
+			"nodeCount":   f.NodeCount,
+		})
+	}
+
+	return flows, nil
+}
+
+func (s *Storage) DeleteFlow(flowID string) error {
+	store, unlock, err := s.writer()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := store.DeleteFlow(flowID); err != nil {
+		return err
+	}
+	os.Remove(s.getFlowTestsPath(flowID))
+	s.unindexFlow(flowID)
+	s.commitFlowChange(store, flowID, fmt.Sprintf("Delete flow %s", flowID))
+	return nil
+}
+
+const flowTestsSuffix = ".tests.json"
+
+func (s *Storage) getFlowTestsPath(flowID string) string {
+	return filepath.Join(s.getFlowsDir(), flowID+flowTestsSuffix)
+}
+
+// SaveFlowTests stores the test cases for a flow next to the flow file
+func (s *Storage) SaveFlowTests(flowID, testsJSON string) error {
+	var suite FlowTestSuite
+	if err := json.Unmarshal([]byte(testsJSON), &suite); err != nil {
+		return fmt.Errorf("invalid flow tests JSON: %w", err)
+	}
+	suite.FlowID = flowID
+
+	data, err := json.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, unlock, err := s.writer()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return writeFileAtomic(s.getFlowTestsPath(flowID), data, 0644)
+}
+
+// LoadFlowTests returns the test cases for a flow, or an empty suite
+func (s *Storage) LoadFlowTests(flowID string) (string, error) {
+	suite, err := s.loadFlowTestSuite(flowID)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (s *Storage) loadFlowTestSuite(flowID string) (*FlowTestSuite, error) {
+	_, unlock, err := s.reader()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	suite := &FlowTestSuite{FlowID: flowID, Cases: []FlowTestCase{}}
+	data, err := os.ReadFile(s.getFlowTestsPath(flowID))
+	if os.IsNotExist(err) {
+		return suite, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, suite); err != nil {
+		return nil, fmt.Errorf("invalid flow tests for %s: %w", flowID, err)
+	}
+	return suite, nil
+}
+
+func (s *Storage) getCustomNodesDir() string {
+	customNodesDir := filepath.Join(s.dataDir, "custom-nodes")
+	os.MkdirAll(customNodesDir, 0755)
+	return customNodesDir
+}
+
+// getCustomNodeVersionsDir holds the superseded versions of a custom node,
+// one <version>.json per save
+func (s *Storage) getCustomNodeVersionsDir(nodeType string) string {
+	return filepath.Join(s.getCustomNodesDir(), "versions", nodeType)
+}
+
+// checkCustomNodeType rejects types that can't be used as a file name
+func checkCustomNodeType(nodeType string) error {
+	if nodeType == "" || nodeType == "." || nodeType == ".." || strings.ContainsAny(nodeType, `/\:*?"<>|`) {
+		return fmt.Errorf("invalid custom node type: %q", nodeType)
+	}
+	return nil
+}
+
+// ListCustomNodes returns every custom node definition, sorted by name
+func (s *Storage) ListCustomNodes() ([]map[string]interface{}, error) {
+	_, unlock, err := s.reader()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	dir := s.getCustomNodesDir()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []map[string]interface{}{}
 	for _, entry := range entries {
 		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
 			continue
 		}
-
-		filePath := filepath.Join(flowsDir, entry.Name())
-		data, err := os.ReadFile(filePath)
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
 		if err != nil {
 			continue
 		}
-
-		// Parse as generic map
-		var flowData map[string]interface{}
-		if err := json.Unmarshal(data, &flowData); err != nil {
+		var node map[string]interface{}
+		if err := json.Unmarshal(data, &node); err != nil {
+			fmt.Printf("Skipping invalid custom node %s: %v\n", entry.Name(), err)
 			continue
 		}
+		nodes = append(nodes, node)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		n1, _ := nodes[i]["name"].(string)
+		n2, _ := nodes[j]["name"].(string)
+		return strings.ToLower(n1) < strings.ToLower(n2)
+	})
+	return nodes, nil
+}
+
+func (s *Storage) LoadCustomNode(nodeType string) (string, error) {
+	if err := checkCustomNodeType(nodeType); err != nil {
+		return "", err
+	}
+	_, unlock, err := s.reader()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(filepath.Join(s.getCustomNodesDir(), nodeType+".json"))
+	if err != nil {
+		return "", fmt.Errorf("custom node not found: %s", nodeType)
+	}
+	return string(data), nil
+}
+
+// parseCustomNode decodes and checks a custom node definition, returning it
+// with its type
+func parseCustomNode(nodeJSON []byte) (map[string]interface{}, string, error) {
+	var node map[string]interface{}
+	if err := json.Unmarshal(nodeJSON, &node); err != nil {
+		return nil, "", fmt.Errorf("invalid custom node JSON: %w", err)
+	}
+	nodeType, _ := node["type"].(string)
+	if err := checkCustomNodeType(nodeType); err != nil {
+		return nil, "", err
+	}
+	switch node["actionType"] {
+	case "shell", "http", "script":
+	default:
+		return nil, "", fmt.Errorf("unknown action type: %v", node["actionType"])
+	}
+	return node, nodeType, nil
+}
+
+// SaveCustomNode creates or updates a custom node definition. Each save
+// bumps "version"; the version it replaces is kept for ListCustomNodeVersions.
+func (s *Storage) SaveCustomNode(nodeJSON string) (map[string]interface{}, error) {
+	node, nodeType, err := parseCustomNode([]byte(nodeJSON))
+	if err != nil {
+		return nil, err
+	}
 
-		// Count nodes
-		nodeCount := 0
-		if nodes, ok := flowData["nodes"].([]interface{}); ok {
-			nodeCount = len(nodes)
+	_, unlock, err := s.writer()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	now := time.Now().Format(time.RFC3339)
+	filePath := filepath.Join(s.getCustomNodesDir(), nodeType+".json")
+	node["version"] = 1
+	node["createdAt"] = now
+	if previous, err := os.ReadFile(filePath); err == nil {
+		var old map[string]interface{}
+		if err := json.Unmarshal(previous, &old); err == nil {
+			oldVersion, _ := old["version"].(float64)
+			if oldVersion < 1 {
+				oldVersion = 1
+			}
+			versionsDir := s.getCustomNodeVersionsDir(nodeType)
+			if err := os.MkdirAll(versionsDir, 0755); err != nil {
+				return nil, err
+			}
+			archived := filepath.Join(versionsDir, fmt.Sprintf("%d.json", int(oldVersion)))
+			if err := writeFileAtomic(archived, previous, 0644); err != nil {
+				return nil, err
+			}
+			node["version"] = int(oldVersion) + 1
+			if createdAt, ok := old["createdAt"].(string); ok && createdAt != "" {
+				node["createdAt"] = createdAt
+			}
 		}
+	}
+	node["updatedAt"] = now
 
-		flows = append(flows, map[string]interface{}{
-			"id":          flowData["id"],
-			"name":        flowData["name"],
-			"description": flowData["description"],
-			"enabled":     flowData["enabled"],
-			"createdAt":   flowData["createdAt"],
-			"updatedAt":   flowData["updatedAt"],
-			"nodeCount":   nodeCount,
-		})
+	data, err := json.MarshalIndent(node, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(filePath, data, 0644); err != nil {
+		return nil, err
 	}
 
-	sort.Slice(flows, func(i, j int) bool {
-		t1, _ := flows[i]["updatedAt"].(string)
-		t2, _ := flows[j]["updatedAt"].(string)
-		return t1 > t2
+	// Return the stored form, with numbers as the frontend will see them
+	var saved map[string]interface{}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// DeleteCustomNode removes a custom node and its version history
+func (s *Storage) DeleteCustomNode(nodeType string) error {
+	if err := checkCustomNodeType(nodeType); err != nil {
+		return err
+	}
+
+	_, unlock, err := s.writer()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := os.Remove(filepath.Join(s.getCustomNodesDir(), nodeType+".json")); err != nil {
+		return err
+	}
+	return os.RemoveAll(s.getCustomNodeVersionsDir(nodeType))
+}
+
+// ListCustomNodeVersions returns every stored version of a custom node,
+// newest first, starting with the current definition
+func (s *Storage) ListCustomNodeVersions(nodeType string) ([]map[string]interface{}, error) {
+	current, err := s.LoadCustomNode(nodeType)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []map[string]interface{}
+	var node map[string]interface{}
+	if err := json.Unmarshal([]byte(current), &node); err != nil {
+		return nil, fmt.Errorf("invalid custom node %s: %w", nodeType, err)
+	}
+	versions = append(versions, node)
+
+	_, unlock, err := s.reader()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	entries, _ := os.ReadDir(s.getCustomNodeVersionsDir(nodeType))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.getCustomNodeVersionsDir(nodeType), entry.Name()))
+		if err != nil {
+			continue
+		}
+		var old map[string]interface{}
+		if err := json.Unmarshal(data, &old); err != nil {
+			continue
+		}
+		versions = append(versions, old)
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		v1, _ := versions[i]["version"].(float64)
+		v2, _ := versions[j]["version"].(float64)
+		return v1 > v2
 	})
+	return versions, nil
+}
 
-	return flows, nil
+// ImportCustomNodes adds custom node definitions from a JSON array, skipping
+// types that already exist. It returns how many were added.
+func (s *Storage) ImportCustomNodes(nodesJSON string) (int, error) {
+	var nodes []json.RawMessage
+	if err := json.Unmarshal([]byte(nodesJSON), &nodes); err != nil {
+		return 0, fmt.Errorf("invalid custom nodes JSON: %w", err)
+	}
+	return s.importCustomNodes(nodes)
 }
 
-func (s *Storage) DeleteFlow(flowID string) error {
-	s.Init()
-	filePath := filepath.Join(s.getFlowsDir(), flowID+".json")
-	return os.Remove(filePath)
+func (s *Storage) importCustomNodes(nodes []json.RawMessage) (int, error) {
+	added := 0
+	for _, raw := range nodes {
+		var node struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(raw, &node); err != nil {
+			return added, fmt.Errorf("invalid custom node JSON: %w", err)
+		}
+		if _, err := s.LoadCustomNode(node.Type); err == nil {
+			continue
+		}
+		if _, err := s.SaveCustomNode(string(raw)); err != nil {
+			return added, fmt.Errorf("failed to import custom node %s: %w", node.Type, err)
+		}
+		added++
+	}
+	return added, nil
+}
+
+// loadCustomNodeDefinition reads a custom node in the shape the engine
+// executes
+func (s *Storage) loadCustomNodeDefinition(nodeType string) (*CustomNodeDefinition, error) {
+	data, err := s.LoadCustomNode(nodeType)
+	if err != nil {
+		return nil, err
+	}
+	var def CustomNodeDefinition
+	if err := json.Unmarshal([]byte(data), &def); err != nil {
+		return nil, fmt.Errorf("invalid custom node %s: %w", nodeType, err)
+	}
+	return &def, nil
 }
 
 func (s *Storage) SaveSettings(settingsJSON string) error {
-	s.Init()
-	filePath := filepath.Join(s.dataDir, "settings.json")
-	return os.WriteFile(filePath, []byte(settingsJSON), 0644)
+	store, unlock, err := s.writer()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	// Provider settings may have changed
+	s.providerCache.clear()
+	return store.PutSettings([]byte(settingsJSON))
 }
 
 func (s *Storage) LoadSettings() (string, error) {
-	s.Init()
-	filePath := filepath.Join(s.dataDir, "settings.json")
-	data, err := os.ReadFile(filePath)
+	store, unlock, err := s.reader()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+	data, err := store.GetSettings()
 	if err != nil {
 		return "{}", nil
 	}
@@ -170,7 +696,11 @@ func (s *Storage) getExecutionsDir() string {
 }
 
 func (s *Storage) SaveExecution(executionJSON string) error {
-	s.Init()
+	store, unlock, err := s.writer()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	var execution map[string]interface{}
 	if err := json.Unmarshal([]byte(executionJSON), &execution); err != nil {
@@ -181,135 +711,403 @@ func (s *Storage) SaveExecution(executionJSON string) error {
 	if !ok || execID == "" {
 		return fmt.Errorf("execution ID is required")
 	}
+	execution = s.editorRedactor(execID, false).Value(execution).(map[string]interface{})
 
 	data, err := json.Marshal(execution)
 	if err != nil {
 		return err
 	}
 
-	filePath := filepath.Join(s.getExecutionsDir(), execID+".json")
-	return os.WriteFile(filePath, data, 0644)
+	return store.PutExecution(executionSummary(execution), data)
 }
 
+// loadExecution reads a saved execution with its full results
+func (s *Storage) loadExecution(execID string) (*FlowExecution, error) {
+	store, unlock, err := s.reader()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	data, err := store.GetExecution(execID)
+	if err != nil {
+		return nil, fmt.Errorf("execution not found: %s", execID)
+	}
+	var execution FlowExecution
+	if err := json.Unmarshal(data, &execution); err != nil {
+		return nil, fmt.Errorf("invalid execution %s: %w", execID, err)
+	}
+	return &execution, nil
+}
+
+// ListExecutions returns execution summaries, newest first, without the
+// per-node results
 func (s *Storage) ListExecutions(limit int) ([]map[string]interface{}, error) {
-	s.Init()
+	store, unlock, err := s.reader()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
-	execDir := s.getExecutionsDir()
-	entries, err := os.ReadDir(execDir)
+	summaries, err := store.ListExecutions(limit)
 	if err != nil {
-		return []map[string]interface{}{}, nil
+		return nil, err
 	}
 
 	var executions []map[string]interface{}
+	for _, e := range summaries {
+		executions = append(executions, executionSummaryMap(e))
+	}
+
+	return executions, nil
+}
+
+func executionSummaryMap(e ExecutionSummary) map[string]interface{} {
+	execution := map[string]interface{}{
+		"id":           e.ID,
+		"flowId":       e.FlowID,
+		"status":       e.Status,
+		"startedAt":    e.StartedAt,
+		"nodeCount":    e.NodeCount,
+		"successCount": e.SuccessCount,
+		"errorCount":   e.ErrorCount,
+	}
+	if e.FlowName != "" {
+		execution["flowName"] = e.FlowName
+	}
+	if e.Trigger != "" {
+		execution["trigger"] = e.Trigger
+	}
+	if e.EndedAt != "" {
+		execution["endedAt"] = e.EndedAt
+		execution["durationMs"] = e.DurationMs
+	}
+	return execution
+}
+
+func (s *Storage) DeleteExecution(execID string) error {
+	store, unlock, err := s.writer()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := store.DeleteExecution(execID); err != nil {
+		return err
+	}
+	os.Remove(s.getExecutionLogPath(execID))
+	return nil
+}
+
+func (s *Storage) getExecutionLogPath(execID string) string {
+	return filepath.Join(s.getExecutionsDir(), execID+".logs.jsonl")
+}
+
+// openExecutionLog creates the log stream file for an execution
+func (s *Storage) openExecutionLog(execID string) (*ExecutionLog, error) {
+	s.Init()
+	f, err := os.OpenFile(s.getExecutionLogPath(execID), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open execution log: %w", err)
+	}
+	return newExecutionLog(f), nil
+}
+
+// AppendExecutionLogs adds entries to an execution's log stream. The editor
+// executor runs node handlers in the frontend and persists their logs with
+// the execution it saves.
+func (s *Storage) AppendExecutionLogs(execID string, entries []LogEntry) error {
+	if err := checkFlowID(execID); err != nil {
+		return fmt.Errorf("invalid execution ID: %q", execID)
+	}
+	execLog, err := s.openExecutionLog(execID)
+	if err != nil {
+		return err
+	}
+	defer execLog.Close()
+
+	// The editor saves the logs last, which ends the run
+	red := s.editorRedactor(execID, false)
+	s.endEditorRun(execID)
 	for _, entry := range entries {
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
-			continue
+		if entry.Timestamp == "" {
+			entry.Timestamp = time.Now().Format(time.RFC3339Nano)
 		}
-
-		filePath := filepath.Join(execDir, entry.Name())
-		data, err := os.ReadFile(filePath)
-		if err != nil {
-			continue
+		if _, ok := logSeverity[entry.Level]; !ok {
+			entry.Level = LogInfo
 		}
-
-		var execution map[string]interface{}
-		if err := json.Unmarshal(data, &execution); err != nil {
-			continue
+		entry.Message = red.String(entry.Message)
+		if entry.Fields != nil {
+			entry.Fields = red.Value(entry.Fields).(map[string]interface{})
 		}
+		execLog.write(entry)
+	}
+	return nil
+}
 
-		// Calculate summary stats from results before removing them
-		if results, ok := execution["results"].([]interface{}); ok {
-			nodeCount := len(results)
-			successCount := 0
-			errorCount := 0
-			
-			for _, r := range results {
-				if result, ok := r.(map[string]interface{}); ok {
-					if status, ok := result["status"].(string); ok {
-						if status == "success" {
-							successCount++
-						} else if status == "error" {
-							errorCount++
-						}
-					}
-				}
-			}
-			
-			execution["nodeCount"] = nodeCount
-			execution["successCount"] = successCount
-			execution["errorCount"] = errorCount
-			
-			// Remove full results to reduce payload size
-			delete(execution, "results")
-		}
+// QueryExecutionLogs returns a page of an execution's log entries. level is
+// a minimum severity, nodeID and text (case-insensitive substring of the
+// message or fields) narrow the results; empty filters match everything.
+func (s *Storage) QueryExecutionLogs(execID, level, nodeID, text string, offset, limit int) (*LogPage, error) {
+	s.Init()
 
-		executions = append(executions, execution)
+	if limit <= 0 {
+		limit = 100
 	}
+	if offset < 0 {
+		offset = 0
+	}
+	minSeverity, ok := logSeverity[level]
+	if level != "" && !ok {
+		return nil, fmt.Errorf("unknown log level: %s", level)
+	}
+	text = strings.ToLower(text)
 
-	sort.Slice(executions, func(i, j int) bool {
-		t1, _ := executions[i]["startedAt"].(string)
-		t2, _ := executions[j]["startedAt"].(string)
-		return t1 > t2
-	})
+	page := &LogPage{Entries: []LogEntry{}, Offset: offset, Limit: limit}
 
-	if limit > 0 && len(executions) > limit {
-		executions = executions[:limit]
+	f, err := os.Open(s.getExecutionLogPath(execID))
+	if os.IsNotExist(err) {
+		return page, nil
 	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
 
-	return executions, nil
+	dec := json.NewDecoder(f)
+	for {
+		var entry LogEntry
+		if err := dec.Decode(&entry); err != nil {
+			break
+		}
+		if level != "" && logSeverity[entry.Level] < minSeverity {
+			continue
+		}
+		if nodeID != "" && entry.NodeID != nodeID {
+			continue
+		}
+		if text != "" && !logEntryContains(entry, text) {
+			continue
+		}
+		if page.Total >= offset && len(page.Entries) < limit {
+			page.Entries = append(page.Entries, entry)
+		}
+		page.Total++
+	}
+
+	return page, nil
 }
 
-func (s *Storage) DeleteExecution(execID string) error {
-	s.Init()
-	filePath := filepath.Join(s.getExecutionsDir(), execID+".json")
-	return os.Remove(filePath)
+func logEntryContains(entry LogEntry, text string) bool {
+	if strings.Contains(strings.ToLower(entry.Message), text) {
+		return true
+	}
+	if len(entry.Fields) == 0 {
+		return false
+	}
+	fields, _ := json.Marshal(entry.Fields)
+	return strings.Contains(strings.ToLower(string(fields)), text)
 }
 
+// ExportFlow returns a flow along with the definitions of the custom nodes
+// it uses, under "customNodes"
 func (s *Storage) ExportFlow(flowID string) (string, error) {
-	return s.LoadFlow(flowID)
-}
+	flowJSON, err := s.LoadFlow(flowID)
+	if err != nil {
+		return "", err
+	}
 
-func (s *Storage) ImportFlow(flowJSON string) (string, error) {
 	var flow Flow
+	var flowData map[string]interface{}
 	if err := json.Unmarshal([]byte(flowJSON), &flow); err != nil {
 		return "", fmt.Errorf("invalid flow JSON: %w", err)
 	}
+	if err := json.Unmarshal([]byte(flowJSON), &flowData); err != nil {
+		return "", fmt.Errorf("invalid flow JSON: %w", err)
+	}
 
-	flow.ID = fmt.Sprintf("flow-%d", time.Now().UnixNano())
-	flow.CreatedAt = time.Now().Format(time.RFC3339)
-	flow.UpdatedAt = time.Now().Format(time.RFC3339)
+	var customNodes []map[string]interface{}
+	for _, nodeType := range customNodeTypes(&flow) {
+		data, err := s.LoadCustomNode(nodeType)
+		if err != nil {
+			// Exported anyway; the flow reports the missing type when validated
+			continue
+		}
+		var node map[string]interface{}
+		if err := json.Unmarshal([]byte(data), &node); err == nil {
+			customNodes = append(customNodes, node)
+		}
+	}
+	if len(customNodes) == 0 {
+		return flowJSON, nil
+	}
+	flowData["customNodes"] = customNodes
 
-	data, err := json.MarshalIndent(flow, "", "  ")
+	data, err := json.MarshalIndent(flowData, "", "  ")
 	if err != nil {
 		return "", err
 	}
+	return string(data), nil
+}
+
+// FlowImportReport describes how ImportFlowWithReport converted a workflow
+// from another tool. ForgeFlow flows are imported as they are.
+type FlowImportReport struct {
+	FlowID       string       `json:"flowId"`
+	FlowIDs      []string     `json:"flowIds"`      // every flow created, when the workflow was split up
+	Format       string       `json:"format"`       // "forgeflow", "n8n" or "node-red"
+	Nodes        int          `json:"nodes"`        // imported with an equivalent node type
+	Placeholders []ImportNote `json:"placeholders"` // nodes without an equivalent, imported as comments
+	Warnings     []ImportNote `json:"warnings"`     // settings that were left out or need a look
+}
+
+type ImportNote struct {
+	Flow    string `json:"flow,omitempty"` // name of the flow, when the workflow was split up
+	Node    string `json:"node,omitempty"` // name in the imported workflow
+	Type    string `json:"type,omitempty"` // node type in the imported workflow
+	Message string `json:"message"`
+}
 
-	filePath := filepath.Join(s.getFlowsDir(), flow.ID+".json")
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
+// ImportFlow stores a flow under a new ID, migrating it to the current
+// schema version. Custom node definitions bundled by ExportFlow are added
+// unless a node of the same type already exists. n8n workflows are
+// converted first, and the first flow of a Node-RED export is returned.
+func (s *Storage) ImportFlow(flowJSON string) (string, error) {
+	report, err := s.ImportFlowWithReport(flowJSON)
+	if err != nil {
 		return "", err
 	}
+	return report.FlowID, nil
+}
 
-	return flow.ID, nil
+// ImportFlowWithReport is ImportFlow, also reporting how a workflow from
+// another tool was converted. Each tab of a Node-RED export becomes its own
+// flow. Every flow is checked before any is stored; when storing one fails,
+// the report lists the flows stored before it.
+func (s *Storage) ImportFlowWithReport(flowJSON string) (*FlowImportReport, error) {
+	report := &FlowImportReport{Format: "forgeflow", FlowIDs: []string{}, Placeholders: []ImportNote{}, Warnings: []ImportNote{}}
+	flows := [][]byte{[]byte(flowJSON)}
+	switch {
+	case isNodeRedFlows([]byte(flowJSON)):
+		converted, err := convertNodeRedFlows([]byte(flowJSON), report)
+		if err != nil {
+			return nil, err
+		}
+		flows = converted
+	case isN8nWorkflow([]byte(flowJSON)):
+		converted, err := convertN8nWorkflow([]byte(flowJSON), report)
+		if err != nil {
+			return nil, err
+		}
+		flows = [][]byte{converted}
+	}
+
+	// Convert and check every flow before storing any, so a bad tab of a
+	// Node-RED export doesn't leave the tabs before it imported
+	pending := make([]*pendingImport, 0, len(flows))
+	for i, data := range flows {
+		flow, err := prepareImport(data)
+		if err != nil {
+			if len(flows) > 1 {
+				err = fmt.Errorf("flow %d of %d: %w", i+1, len(flows), err)
+			}
+			return report, err
+		}
+		pending = append(pending, flow)
+	}
+
+	taken := make(map[string]bool)
+	for _, p := range pending {
+		id := fmt.Sprintf("flow-%d", time.Now().UnixNano())
+		// The clock can be coarse enough to repeat in this loop
+		for i := 1; taken[id]; i++ {
+			id = fmt.Sprintf("flow-%d-%d", time.Now().UnixNano(), i)
+		}
+		taken[id] = true
+		if _, err := s.importCustomNodes(p.customNodes); err != nil {
+			return report, err
+		}
+		p.flow.ID = id
+		if err := s.putImportedFlow(&p.flow, p.original, p.from, p.changed); err != nil {
+			return report, err
+		}
+		report.FlowIDs = append(report.FlowIDs, id)
+		report.FlowID = report.FlowIDs[0]
+		if report.Format == "forgeflow" {
+			report.Nodes += len(p.flow.Nodes)
+		}
+	}
+	return report, nil
 }
 
-func (s *Storage) getSecretsDir() string {
-	secretsDir := filepath.Join(s.dataDir, "secrets")
-	os.MkdirAll(secretsDir, 0700)
-	return secretsDir
+// pendingImport is a flow for ImportFlowWithReport, migrated and checked
+// but not stored yet
+type pendingImport struct {
+	flow        Flow
+	original    []byte
+	from        int
+	changed     bool
+	customNodes []json.RawMessage
 }
 
-func (s *Storage) SaveSecret(key, value string) error {
-	s.Init()
-	filePath := filepath.Join(s.getSecretsDir(), key)
-	return os.WriteFile(filePath, []byte(value), 0600)
+func prepareImport(flowJSON []byte) (*pendingImport, error) {
+	migrated, from, changed, err := upgradeFlowJSON(flowJSON)
+	if err != nil {
+		return nil, err
+	}
+	p := &pendingImport{original: flowJSON, from: from, changed: changed}
+	if err := json.Unmarshal(migrated, &p.flow); err != nil {
+		return nil, fmt.Errorf("invalid flow JSON: %w", err)
+	}
+
+	var bundle struct {
+		CustomNodes []json.RawMessage `json:"customNodes"`
+	}
+	json.Unmarshal(flowJSON, &bundle)
+	for _, raw := range bundle.CustomNodes {
+		if _, _, err := parseCustomNode(raw); err != nil {
+			return nil, err
+		}
+	}
+	p.customNodes = bundle.CustomNodes
+	return p, nil
 }
 
-func (s *Storage) GetSecret(key string) (string, error) {
-	s.Init()
-	filePath := filepath.Join(s.getSecretsDir(), key)
-	data, err := os.ReadFile(filePath)
+// putImportedFlow stores a migrated flow under the ID already set on it.
+// original is the document as imported, kept as a backup when it had to be
+// migrated.
+func (s *Storage) putImportedFlow(flow *Flow, original []byte, from int, changed bool) error {
+	store, unlock, err := s.writer()
 	if err != nil {
-		return "", err
+		return err
 	}
-	return string(data), nil
+	defer unlock()
+
+	if err := checkFlowID(flow.ID); err != nil {
+		return err
+	}
+	flow.SchemaVersion = FlowSchemaVersion
+	flow.CreatedAt = time.Now().Format(time.RFC3339)
+	flow.UpdatedAt = time.Now().Format(time.RFC3339)
+	if changed {
+		if err := s.backupFlow(flow.ID, from, original); err != nil {
+			return fmt.Errorf("failed to back up imported flow: %w", err)
+		}
+	}
+
+	data, err := json.MarshalIndent(flow, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	var flowData map[string]interface{}
+	json.Unmarshal(data, &flowData)
+	if err := store.PutFlow(flowSummary(flowData), data); err != nil {
+		return err
+	}
+	s.indexFlow(flowData)
+	if err := s.recordFlowRevision(flowData, data, "Imported"); err != nil {
+		fmt.Printf("Failed to record revision of flow %s: %v\n", flow.ID, err)
+	}
+	s.commitFlowChange(store, flow.ID, gitFlowMessage("Import", flowData))
+	return nil
 }
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Storage backends
+const (
+	BackendJSON   = "json"   // one JSON file per flow and execution
+	BackendSQLite = "sqlite" // a single SQLite database
+)
+
+// storageConfigFile records the active backend in the data directory. It
+// can't live in the settings, which are themselves kept by the backend.
+const storageConfigFile = "storage.json"
+
+// StorageBackend persists flows, executions, settings and secrets. Storage
+// keeps the document logic (IDs, timestamps, migrations) and hands the
+// backend encoded documents along with the summary fields it lists them by.
+// Lookups of missing items return an error matching os.ErrNotExist.
+type StorageBackend interface {
+	Name() string
+
+	PutFlow(summary FlowSummary, data []byte) error
+	GetFlow(id string) ([]byte, error)
+	ListFlows() ([]FlowSummary, error) // most recently updated first
+	DeleteFlow(id string) error
+
+	PutExecution(summary ExecutionSummary, data []byte) error
+	GetExecution(id string) ([]byte, error)
+	ListExecutions(limit int) ([]ExecutionSummary, error) // newest first; limit <= 0 for all
+	QueryExecutions(filter executionFilter) (*ExecutionPage, error)
+	DeleteExecution(id string) error
+
+	GetSettings() ([]byte, error)
+	PutSettings(data []byte) error
+
+	GetSecret(key string) ([]byte, error)
+	PutSecret(key string, value []byte) error
+	ListSecrets() ([]string, error)
+	DeleteSecret(key string) error
+
+	Close() error
+}
+
+type FlowSummary struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	CreatedAt   string `json:"createdAt"`
+	UpdatedAt   string `json:"updatedAt"`
+	NodeCount   int    `json:"nodeCount"`
+}
+
+type ExecutionSummary struct {
+	ID           string `json:"id"`
+	FlowID       string `json:"flowId"`
+	FlowName     string `json:"flowName,omitempty"`
+	Trigger      string `json:"trigger,omitempty"`
+	Status       string `json:"status"`
+	StartedAt    string `json:"startedAt"`
+	EndedAt      string `json:"endedAt,omitempty"`
+	DurationMs   int64  `json:"durationMs"` // 0 while running
+	NodeCount    int    `json:"nodeCount"`
+	SuccessCount int    `json:"successCount"`
+	ErrorCount   int    `json:"errorCount"`
+}
+
+// StorageMigrationReport describes a MigrateStorage run
+type StorageMigrationReport struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Flows      int    `json:"flows"`
+	Executions int    `json:"executions"`
+	Secrets    int    `json:"secrets"`
+	Settings   bool   `json:"settings"`
+}
+
+func flowSummary(flowData map[string]interface{}) FlowSummary {
+	summary := FlowSummary{}
+	summary.ID, _ = flowData["id"].(string)
+	summary.Name, _ = flowData["name"].(string)
+	summary.Description, _ = flowData["description"].(string)
+	summary.Enabled, _ = flowData["enabled"].(bool)
+	summary.CreatedAt, _ = flowData["createdAt"].(string)
+	summary.UpdatedAt, _ = flowData["updatedAt"].(string)
+	if nodes, ok := flowData["nodes"].([]interface{}); ok {
+		summary.NodeCount = len(nodes)
+	}
+	return summary
+}
+
+func executionSummary(execution map[string]interface{}) ExecutionSummary {
+	summary := ExecutionSummary{}
+	summary.ID, _ = execution["id"].(string)
+	summary.FlowID, _ = execution["flowId"].(string)
+	summary.FlowName, _ = execution["flowName"].(string)
+	summary.Trigger, _ = execution["trigger"].(string)
+	summary.Status, _ = execution["status"].(string)
+	summary.StartedAt, _ = execution["startedAt"].(string)
+	summary.EndedAt, _ = execution["endedAt"].(string)
+	summary.DurationMs = runDuration(summary.StartedAt, summary.EndedAt)
+	if results, ok := execution["results"].([]interface{}); ok {
+		summary.NodeCount = len(results)
+		for _, r := range results {
+			if result, ok := r.(map[string]interface{}); ok {
+				switch result["status"] {
+				case "success":
+					summary.SuccessCount++
+				case "error":
+					summary.ErrorCount++
+				}
+			}
+		}
+	}
+	return summary
+}
+
+// storageBackendName reads the configured backend, defaulting to JSON files
+func storageBackendName(dataDir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dataDir, storageConfigFile))
+	if os.IsNotExist(err) {
+		return BackendJSON, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	var config struct {
+		Backend string `json:"backend"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", fmt.Errorf("invalid %s: %w", storageConfigFile, err)
+	}
+	if config.Backend == "" {
+		return BackendJSON, nil
+	}
+	return config.Backend, nil
+}
+
+func writeStorageBackendName(dataDir, name string) error {
+	data, err := json.MarshalIndent(map[string]string{"backend": name}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dataDir, storageConfigFile), data, 0644)
+}
+
+func openStorageBackend(dataDir, name string) (StorageBackend, error) {
+	switch name {
+	case BackendJSON:
+		return newJSONBackend(dataDir), nil
+	case BackendSQLite:
+		return openSQLiteBackend(filepath.Join(dataDir, sqliteDatabaseFile))
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %q", name)
+	}
+}
+
+// copyStorage copies every flow, execution, secret and the settings from
+// one backend to another
+func copyStorage(from, to StorageBackend) (*StorageMigrationReport, error) {
+	report := &StorageMigrationReport{From: from.Name(), To: to.Name()}
+
+	flows, err := from.ListFlows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list flows: %w", err)
+	}
+	for _, summary := range flows {
+		data, err := from.GetFlow(summary.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read flow %s: %w", summary.ID, err)
+		}
+		if err := to.PutFlow(summary, data); err != nil {
+			return nil, fmt.Errorf("failed to write flow %s: %w", summary.ID, err)
+		}
+		report.Flows++
+	}
+
+	executions, err := from.ListExecutions(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list executions: %w", err)
+	}
+	for _, summary := range executions {
+		data, err := from.GetExecution(summary.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read execution %s: %w", summary.ID, err)
+		}
+		if err := to.PutExecution(summary, data); err != nil {
+			return nil, fmt.Errorf("failed to write execution %s: %w", summary.ID, err)
+		}
+		report.Executions++
+	}
+
+	settings, err := from.GetSettings()
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+	if err == nil {
+		if err := to.PutSettings(settings); err != nil {
+			return nil, fmt.Errorf("failed to write settings: %w", err)
+		}
+		report.Settings = true
+	}
+
+	keys, err := from.ListSecrets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	for _, key := range keys {
+		value, err := from.GetSecret(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secret %s: %w", key, err)
+		}
+		if err := to.PutSecret(key, value); err != nil {
+			return nil, fmt.Errorf("failed to write secret %s: %w", key, err)
+		}
+		report.Secrets++
+	}
+
+	return report, nil
+}
@@ -0,0 +1,405 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Span kinds and status codes, matching the OTLP enum values
+const (
+	SpanKindInternal = 1
+	SpanKindServer   = 2
+	SpanKindClient   = 3
+
+	SpanStatusUnset = 0
+	SpanStatusOK    = 1
+	SpanStatusError = 2
+)
+
+// Span is a timed operation within an execution trace. Each execution is a
+// trace whose root span covers the whole run, with one child span per node.
+type Span struct {
+	TraceID       string                 `json:"traceId"`
+	SpanID        string                 `json:"spanId"`
+	ParentSpanID  string                 `json:"parentSpanId,omitempty"`
+	Name          string                 `json:"name"`
+	Kind          int                    `json:"kind"`
+	StartTime     time.Time              `json:"startTime"`
+	EndTime       time.Time              `json:"endTime"`
+	Attributes    map[string]interface{} `json:"attributes,omitempty"`
+	Events        []SpanEvent            `json:"events,omitempty"`
+	StatusCode    int                    `json:"statusCode"`
+	StatusMessage string                 `json:"statusMessage,omitempty"`
+
+	mu    sync.Mutex
+	trace *traceRecorder
+}
+
+type SpanEvent struct {
+	Name       string                 `json:"name"`
+	Time       time.Time              `json:"time"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// traceRecorder collects the spans of one trace until it is flushed
+type traceRecorder struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+// SpanExporter sends finished spans somewhere
+type SpanExporter interface {
+	ExportSpans(spans []*Span) error
+}
+
+type Tracer struct {
+	exporter SpanExporter
+}
+
+func NewTracer(exporter SpanExporter) *Tracer {
+	return &Tracer{exporter: exporter}
+}
+
+// newTracerFromSettings builds a tracer from the "tracingExporter" setting
+// ("otlp" or "file"). It returns nil when tracing is disabled.
+func newTracerFromSettings(storage *Storage) (*Tracer, error) {
+	settingsJSON, _ := storage.LoadSettings()
+	var settings struct {
+		Exporter string `json:"tracingExporter"`
+		Endpoint string `json:"tracingEndpoint"`
+		File     string `json:"tracingFile"`
+	}
+	json.Unmarshal([]byte(settingsJSON), &settings)
+
+	switch settings.Exporter {
+	case "":
+		return nil, nil
+	case "otlp":
+		if settings.Endpoint == "" {
+			settings.Endpoint = "http://localhost:4318"
+		}
+		return NewTracer(NewOTLPExporter(settings.Endpoint)), nil
+	case "file":
+		if settings.File == "" {
+			settings.File = filepath.Join(storage.dataDir, "traces.jsonl")
+		}
+		return NewTracer(NewJSONLinesExporter(settings.File)), nil
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter: %s", settings.Exporter)
+	}
+}
+
+// StartTrace starts the root span of a new trace. A valid W3C traceparent
+// makes the trace a continuation of the caller's trace.
+func (t *Tracer) StartTrace(name, traceparent string, kind int) *Span {
+	span := &Span{
+		SpanID:     randomHex(8),
+		Name:       name,
+		Kind:       kind,
+		StartTime:  time.Now(),
+		Attributes: make(map[string]interface{}),
+		trace:      &traceRecorder{},
+	}
+	if traceID, parentID, ok := parseTraceparent(traceparent); ok {
+		span.TraceID = traceID
+		span.ParentSpanID = parentID
+	} else {
+		span.TraceID = randomHex(16)
+	}
+	span.trace.add(span)
+	return span
+}
+
+// remoteSpan stands for the caller's span of a W3C traceparent, so local
+// spans can be started as its children. It isn't recorded or exported.
+func remoteSpan(traceparent string) (*Span, bool) {
+	traceID, spanID, ok := parseTraceparent(traceparent)
+	if !ok {
+		return nil, false
+	}
+	return &Span{TraceID: traceID, SpanID: spanID, trace: &traceRecorder{}}, true
+}
+
+// editorSpan continues the trace of an editor run from its traceparent
+// when tracing is on. done exports the spans recorded under it.
+func (e *Engine) editorSpan(ctx context.Context, traceparent string) (context.Context, func()) {
+	tracer := e.activeTracer()
+	parent, ok := remoteSpan(traceparent)
+	if tracer == nil || !ok {
+		return ctx, func() {}
+	}
+	return contextWithSpan(ctx, parent), func() {
+		if err := tracer.Flush(parent); err != nil {
+			fmt.Printf("Failed to export trace: %v\n", err)
+		}
+	}
+}
+
+// Flush exports every span recorded in root's trace
+func (t *Tracer) Flush(root *Span) error {
+	root.trace.mu.Lock()
+	spans := root.trace.spans
+	root.trace.spans = nil
+	root.trace.mu.Unlock()
+
+	if len(spans) == 0 {
+		return nil
+	}
+	return t.exporter.ExportSpans(spans)
+}
+
+func (r *traceRecorder) add(span *Span) {
+	r.mu.Lock()
+	r.spans = append(r.spans, span)
+	r.mu.Unlock()
+}
+
+// StartChild starts a span parented to s in the same trace
+func (s *Span) StartChild(name string, kind int) *Span {
+	child := &Span{
+		TraceID:      s.TraceID,
+		SpanID:       randomHex(8),
+		ParentSpanID: s.SpanID,
+		Name:         name,
+		Kind:         kind,
+		StartTime:    time.Now(),
+		Attributes:   make(map[string]interface{}),
+		trace:        s.trace,
+	}
+	s.trace.add(child)
+	return child
+}
+
+func (s *Span) SetAttribute(key string, value interface{}) {
+	s.mu.Lock()
+	s.Attributes[key] = value
+	s.mu.Unlock()
+}
+
+func (s *Span) AddEvent(name string, attributes map[string]interface{}) {
+	s.mu.Lock()
+	s.Events = append(s.Events, SpanEvent{Name: name, Time: time.Now(), Attributes: attributes})
+	s.mu.Unlock()
+}
+
+// SetError marks the span failed and records the error as an exception event
+func (s *Span) SetError(message string) {
+	s.AddEvent("exception", map[string]interface{}{"exception.message": message})
+	s.mu.Lock()
+	s.StatusCode = SpanStatusError
+	s.StatusMessage = message
+	s.mu.Unlock()
+}
+
+func (s *Span) End() {
+	s.mu.Lock()
+	if s.StatusCode == SpanStatusUnset {
+		s.StatusCode = SpanStatusOK
+	}
+	s.EndTime = time.Now()
+	s.mu.Unlock()
+}
+
+// Traceparent formats the span as a W3C traceparent header value
+func (s *Span) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-01", s.TraceID, s.SpanID)
+}
+
+type spanContextKey struct{}
+
+func contextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, span)
+}
+
+func spanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanContextKey{}).(*Span)
+	return span
+}
+
+// injectTraceparent adds the current span's traceparent to outbound request
+// headers, unless the caller already set one
+func injectTraceparent(ctx context.Context, header http.Header) {
+	if span := spanFromContext(ctx); span != nil && header.Get("traceparent") == "" {
+		header.Set("traceparent", span.Traceparent())
+	}
+}
+
+// parseTraceparent extracts the trace and parent span IDs from a W3C
+// traceparent header ("00-<32 hex>-<16 hex>-<2 hex>")
+func parseTraceparent(value string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return "", "", false
+	}
+	traceID, spanID = strings.ToLower(parts[1]), strings.ToLower(parts[2])
+	if !isHex(traceID, 32) || !isHex(spanID, 16) ||
+		traceID == strings.Repeat("0", 32) || spanID == strings.Repeat("0", 16) {
+		return "", "", false
+	}
+	return traceID, spanID, true
+}
+
+func isHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// JSONLinesExporter appends one JSON span per line to a local file
+type JSONLinesExporter struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewJSONLinesExporter(path string) *JSONLinesExporter {
+	return &JSONLinesExporter{path: path}
+}
+
+func (x *JSONLinesExporter) ExportSpans(spans []*Span) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(x.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(x.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open trace file: %w", err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for _, span := range spans {
+		span.mu.Lock()
+		err := enc.Encode(span)
+		span.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OTLPExporter posts spans to an OTLP/HTTP collector using the JSON encoding
+type OTLPExporter struct {
+	endpoint string
+	client   *http.Client
+}
+
+func NewOTLPExporter(endpoint string) *OTLPExporter {
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	if !strings.HasSuffix(endpoint, "/v1/traces") {
+		endpoint += "/v1/traces"
+	}
+	return &OTLPExporter{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (x *OTLPExporter) ExportSpans(spans []*Span) error {
+	otlpSpans := make([]map[string]interface{}, 0, len(spans))
+	for _, span := range spans {
+		otlpSpans = append(otlpSpans, span.otlp())
+	}
+
+	payload := map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": otlpAttributes(map[string]interface{}{"service.name": "forgeflow"}),
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]interface{}{"name": "forgeflow/engine"},
+						"spans": otlpSpans,
+					},
+				},
+			},
+		},
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := x.client.Post(x.endpoint, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to export spans: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to export spans: collector returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (s *Span) otlp() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := make([]map[string]interface{}, 0, len(s.Events))
+	for _, ev := range s.Events {
+		events = append(events, map[string]interface{}{
+			"name":         ev.Name,
+			"timeUnixNano": strconv.FormatInt(ev.Time.UnixNano(), 10),
+			"attributes":   otlpAttributes(ev.Attributes),
+		})
+	}
+
+	out := map[string]interface{}{
+		"traceId":           s.TraceID,
+		"spanId":            s.SpanID,
+		"name":              s.Name,
+		"kind":              s.Kind,
+		"startTimeUnixNano": strconv.FormatInt(s.StartTime.UnixNano(), 10),
+		"endTimeUnixNano":   strconv.FormatInt(s.EndTime.UnixNano(), 10),
+		"attributes":        otlpAttributes(s.Attributes),
+		"events":            events,
+		"status":            map[string]interface{}{"code": s.StatusCode, "message": s.StatusMessage},
+	}
+	if s.ParentSpanID != "" {
+		out["parentSpanId"] = s.ParentSpanID
+	}
+	return out
+}
+
+func otlpAttributes(attrs map[string]interface{}) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(attrs))
+	for key, value := range attrs {
+		var v map[string]interface{}
+		switch val := value.(type) {
+		case bool:
+			v = map[string]interface{}{"boolValue": val}
+		case int:
+			v = map[string]interface{}{"intValue": strconv.Itoa(val)}
+		case int64:
+			v = map[string]interface{}{"intValue": strconv.FormatInt(val, 10)}
+		case float64:
+			v = map[string]interface{}{"doubleValue": val}
+		default:
+			v = map[string]interface{}{"stringValue": fmt.Sprintf("%v", val)}
+		}
+		out = append(out, map[string]interface{}{"key": key, "value": v})
+	}
+	return out
+}
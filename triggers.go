@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -25,6 +27,7 @@ type TriggerManager struct {
 	clipboardMon *ClipboardMonitor
 	hotkeyMon    *HotkeyMonitor
 	httpServer   *http.Server
+	metricsSrv   *http.Server
 }
 
 type WebhookTrigger struct {
@@ -68,6 +71,22 @@ func NewTriggerManager(engine *Engine, storage *Storage) *TriggerManager {
 		hotkeyMon:    &HotkeyMonitor{Hotkeys: make(map[string]string)},
 	}
 
+	metrics.gaugeFunc("forgeflow_active_triggers", "Registered triggers, by type.", func() map[string]float64 {
+		tm.mu.RLock()
+		defer tm.mu.RUnlock()
+		clipboard := 0
+		if tm.clipboardMon != nil {
+			clipboard = 1
+		}
+		return map[string]float64{
+			"schedule":    float64(len(tm.cronJobs)),
+			"webhook":     float64(len(tm.webhooks)),
+			"fileWatcher": float64(len(tm.fileWatchers)),
+			"hotkey":      float64(len(tm.hotkeyMon.Hotkeys)),
+			"clipboard":   float64(clipboard),
+		}
+	}, "type")
+
 	return tm
 }
 
@@ -90,7 +109,7 @@ func (tm *TriggerManager) RegisterScheduleTrigger(flowID, cronExpr string) error
 			return
 		}
 
-		_, err = tm.engine.RunFlow(flowJSON)
+		_, err = tm.engine.runFlow(flowJSON, runOptions{trigger: "schedule"})
 		if err != nil {
 			fmt.Printf("Failed to execute flow %s: %v\n", flowID, err)
 		}
@@ -201,6 +220,7 @@ func (tm *TriggerManager) watchFiles(ctx context.Context, fw *FileWatcher) {
 			if !ok {
 				return
 			}
+			metricFileWatcherEvents.Inc(fw.FlowID, event.Op.String())
 
 			// Check if event matches filter
 			shouldTrigger := false
@@ -223,7 +243,7 @@ func (tm *TriggerManager) watchFiles(ctx context.Context, fw *FileWatcher) {
 					continue
 				}
 
-				_, err = tm.engine.RunFlow(flowJSON)
+				_, err = tm.engine.runFlow(flowJSON, runOptions{trigger: "fileWatcher"})
 				if err != nil {
 					fmt.Printf("Failed to execute flow %s: %v\n", fw.FlowID, err)
 				}
@@ -344,12 +364,28 @@ func (tm *TriggerManager) UnregisterHotkey(hotkey string) error {
 func (tm *TriggerManager) startWebhookServer() {
 	mux := http.NewServeMux()
 
+	// /metrics is only served by the metrics server, so every path here is
+	// free for webhooks
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		w = rec
+
 		tm.mu.RLock()
 		webhookID := fmt.Sprintf("%s:%s", r.Method, r.URL.Path)
 		webhook, exists := tm.webhooks[webhookID]
 		tm.mu.RUnlock()
 
+		// Unknown paths share one label to keep series cardinality bounded
+		path := "unmatched"
+		if exists {
+			path = webhook.Path
+		}
+		defer func() {
+			metricWebhookRequests.Inc(r.Method, path, strconv.Itoa(rec.status))
+			metricWebhookLatency.ObserveSince(start, r.Method, path)
+		}()
+
 		if !exists {
 			http.NotFound(w, r)
 			return
@@ -362,7 +398,11 @@ func (tm *TriggerManager) startWebhookServer() {
 			return
 		}
 
-		_, err = tm.engine.RunFlow(flowJSON)
+		// Continue the caller's trace when it sent a W3C traceparent header
+		_, err = tm.engine.runFlow(flowJSON, runOptions{
+			trigger:     "webhook",
+			traceparent: r.Header.Get("traceparent"),
+		})
 		if err != nil {
 			http.Error(w, "Execution failed", http.StatusInternalServerError)
 			return
@@ -388,9 +428,88 @@ func (tm *TriggerManager) startWebhookServer() {
 	}
 }
 
+// statusRecorder captures the response code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// defaultMetricsAddress is where /metrics is served unless the
+// "metricsAddress" setting says otherwise
+const defaultMetricsAddress = "127.0.0.1:9464"
+
+// metricsAddress returns the listen address of the metrics server, or "" when
+// the "metricsAddress" setting is "off". A "metricsPort" saved by older
+// versions still applies when no address is set.
+func metricsAddress(settingsJSON string) string {
+	var settings struct {
+		MetricsAddress string `json:"metricsAddress"`
+		MetricsPort    int    `json:"metricsPort"`
+	}
+	json.Unmarshal([]byte(settingsJSON), &settings)
+	switch addr := strings.TrimSpace(settings.MetricsAddress); {
+	case strings.EqualFold(addr, "off"):
+		return ""
+	case addr != "":
+		return addr
+	case settings.MetricsPort > 0:
+		return fmt.Sprintf(":%d", settings.MetricsPort)
+	}
+	return defaultMetricsAddress
+}
+
+// startMetricsServer serves /metrics on its own listener, started with the
+// triggers whether or not any webhook is registered. It's the only place
+// metrics are served, so "off" disables them.
+func (tm *TriggerManager) startMetricsServer() {
+	settingsJSON, _ := tm.storage.LoadSettings()
+	addr := metricsAddress(settingsJSON)
+	if addr == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+
+	tm.mu.Lock()
+	tm.metricsSrv = &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+	srv := tm.metricsSrv
+	tm.mu.Unlock()
+
+	fmt.Printf("Metrics server started on %s\n", addr)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		fmt.Printf("Metrics server error: %v\n", err)
+	}
+}
+
+// scheduleBackups checks every 10 minutes whether a scheduled backup is due,
+// so changes to the backup settings apply without a restart
+func (tm *TriggerManager) scheduleBackups() {
+	check := func() {
+		path, err := tm.storage.backupIfDue()
+		if err != nil {
+			fmt.Printf("⚠️ Scheduled backup failed: %v\n", err)
+		} else if path != "" {
+			fmt.Printf("💾 Backed up data to %s\n", path)
+		}
+	}
+	tm.cron.AddFunc("@every 10m", check)
+	go check()
+}
+
 // StartAllTriggers loads all flows and registers their enabled triggers
 func (tm *TriggerManager) StartAllTriggers() error {
 	tm.cron.Start()
+	go tm.startMetricsServer()
+	tm.scheduleBackups()
 	flows, err := tm.storage.ListFlows()
 	if err != nil {
 		return fmt.Errorf("failed to list flows for trigger startup: %w", err)
@@ -409,75 +528,123 @@ func (tm *TriggerManager) StartAllTriggers() error {
 			fmt.Printf("⚠️ Failed to load flow %s: %v\n", flowID, err)
 			continue
 		}
+		tm.registerFlowTriggers(flowID, flowJSON)
+	}
+
+	return nil
+}
+
+// registerFlowTriggers registers the enabled trigger nodes of a flow
+func (tm *TriggerManager) registerFlowTriggers(flowID, flowJSON string) {
+	var flow map[string]interface{}
+	if err := json.Unmarshal([]byte(flowJSON), &flow); err != nil {
+		return
+	}
 
-		var flow map[string]interface{}
-		if err := json.Unmarshal([]byte(flowJSON), &flow); err != nil {
+	nodes, ok := flow["nodes"].([]interface{})
+	if !ok {
+		return
+	}
+
+	for _, n := range nodes {
+		node, ok := n.(map[string]interface{})
+		if !ok {
 			continue
 		}
 
-		nodes, ok := flow["nodes"].([]interface{})
+		data, ok := node["data"].(map[string]interface{})
 		if !ok {
 			continue
 		}
 
-		for _, n := range nodes {
-			node, ok := n.(map[string]interface{})
-			if !ok {
-				continue
-			}
-
-			data, ok := node["data"].(map[string]interface{})
-			if !ok {
-				continue
-			}
+		category, _ := data["category"].(string)
+		if category != "trigger" {
+			continue
+		}
 
-			category, _ := data["category"].(string)
-			if category != "trigger" {
-				continue
-			}
+		nodeType, _ := data["nodeType"].(string)
+		config, _ := data["config"].(map[string]interface{})
+		if config == nil {
+			config = make(map[string]interface{})
+		}
 
-			nodeType, _ := data["nodeType"].(string)
-			config, _ := data["config"].(map[string]interface{})
-			if config == nil {
-				config = make(map[string]interface{})
-			}
+		// Skip if explicitly disabled
+		if enabled, exists := config["enabled"].(bool); exists && !enabled {
+			continue
+		}
 
-			// Skip if explicitly disabled
-			if enabled, exists := config["enabled"].(bool); exists && !enabled {
-				continue
+		// Register based on type
+		switch nodeType {
+		case "trigger_schedule":
+			if cron, ok := config["cron"].(string); ok && cron != "" {
+				tm.RegisterScheduleTrigger(flowID, cron)
 			}
-
-			// Register based on type
-			switch nodeType {
-			case "trigger_schedule":
-				if cron, ok := config["cron"].(string); ok && cron != "" {
-					tm.RegisterScheduleTrigger(flowID, cron)
+		case "trigger_webhook":
+			path, _ := config["path"].(string)
+			method, _ := config["method"].(string)
+			if path != "" {
+				if method == "" {
+					method = "POST"
 				}
-			case "trigger_webhook":
-				path, _ := config["path"].(string)
-				method, _ := config["method"].(string)
-				if path != "" {
-					if method == "" {
-						method = "POST"
-					}
-					tm.RegisterWebhookTrigger(flowID, path, method)
-				}
-			case "trigger_file_watch":
-				path, _ := config["path"].(string)
-				events, _ := config["events"].(string)
-				if path != "" {
-					if events == "" {
-						events = "all"
-					}
-					tm.RegisterFileWatcher(flowID, path, events)
+				tm.RegisterWebhookTrigger(flowID, path, method)
+			}
+		case "trigger_file_watch":
+			path, _ := config["path"].(string)
+			events, _ := config["events"].(string)
+			if path != "" {
+				if events == "" {
+					events = "all"
 				}
-			case "trigger_clipboard":
-				tm.RegisterClipboardMonitor(flowID, true)
+				tm.RegisterFileWatcher(flowID, path, events)
 			}
+		case "trigger_clipboard":
+			tm.RegisterClipboardMonitor(flowID, true)
 		}
 	}
+}
 
-	return nil
+// unregisterFlowTriggers removes every trigger registered for a flow
+func (tm *TriggerManager) unregisterFlowTriggers(flowID string) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	if entryID, exists := tm.cronJobs[flowID]; exists {
+		tm.cron.Remove(entryID)
+		delete(tm.cronJobs, flowID)
+	}
+	for id, webhook := range tm.webhooks {
+		if webhook.FlowID == flowID {
+			delete(tm.webhooks, id)
+		}
+	}
+	if fw, exists := tm.fileWatchers[flowID]; exists {
+		fw.Cancel()
+		fw.Watcher.Close()
+		delete(tm.fileWatchers, flowID)
+	}
+	if tm.clipboardMon != nil && tm.clipboardMon.FlowID == flowID {
+		tm.clipboardMon.Cancel()
+		tm.clipboardMon = nil
+	}
+	for hotkey, id := range tm.hotkeyMon.Hotkeys {
+		if id == flowID {
+			delete(tm.hotkeyMon.Hotkeys, hotkey)
+		}
+	}
+}
+
+// reconcileFlowTriggers re-registers the triggers of flows changed outside
+// the editor, dropping those of flows that no longer exist
+func (tm *TriggerManager) reconcileFlowTriggers(flowIDs []string) {
+	for _, flowID := range flowIDs {
+		tm.unregisterFlowTriggers(flowID)
+		flowJSON, err := tm.storage.GetFlow(flowID)
+		if err != nil {
+			continue
+		}
+		tm.registerFlowTriggers(flowID, flowJSON)
+	}
+	fmt.Printf("🔄 Reconciled triggers for %d changed flows\n", len(flowIDs))
 }
 
 // GetActiveTriggers returns information about active triggers
@@ -553,9 +720,15 @@ func (tm *TriggerManager) Shutdown() {
 		tm.clipboardMon.Cancel()
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 	if tm.httpServer != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
 		tm.httpServer.Shutdown(ctx)
 	}
+	tm.mu.RLock()
+	metricsSrv := tm.metricsSrv
+	tm.mu.RUnlock()
+	if metricsSrv != nil {
+		metricsSrv.Shutdown(ctx)
+	}
 }
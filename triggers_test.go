@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestMetricsAddress(t *testing.T) {
+	tests := []struct {
+		settings string
+		want     string
+	}{
+		{`{}`, defaultMetricsAddress},
+		{`{"metricsAddress":""}`, defaultMetricsAddress},
+		{`{"metricsAddress":"off"}`, ""},
+		{`{"metricsAddress":" OFF "}`, ""},
+		{`{"metricsAddress":"0.0.0.0:9000"}`, "0.0.0.0:9000"},
+		{`{"metricsPort":9100}`, ":9100"},
+		{`{"metricsAddress":"off","metricsPort":9100}`, ""},
+		{`not json`, defaultMetricsAddress},
+	}
+	for _, tt := range tests {
+		if got := metricsAddress(tt.settings); got != tt.want {
+			t.Errorf("metricsAddress(%s) = %q, want %q", tt.settings, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// WASM plugin ABI
+//
+// The module exports its memory and:
+//
+//	forgeflow_alloc(size i32) -> ptr i32
+//	forgeflow_execute(ptr i32, len i32) -> i64
+//
+// forgeflow_execute receives the JSON request {"nodeType", "config", "input"}
+// and returns the location of a JSON response {"output"} or {"error"},
+// packed as ptr<<32 | len. The host module "forgeflow" provides:
+//
+//	log(level i32, ptr i32, len i32)         level 0-3: debug, info, warn, error
+//	http_request(ptr i32, len i32) -> i64    {"method","url","headers","body"}
+//	read_file(ptr i32, len i32) -> i64       path as UTF-8 text
+//
+// Host functions answer with JSON written into memory obtained from
+// forgeflow_alloc, using the same packed return. Calls that the manifest
+// doesn't grant get {"error": "permission denied: ..."}.
+const (
+	wasmHostModule     = "forgeflow"
+	wasmAllocExport    = "forgeflow_alloc"
+	wasmExecuteExport  = "forgeflow_execute"
+	defaultWasmMemory  = 64 // MB
+	wasmPageSize       = 64 << 10
+	maxWasmReadFileLen = 16 << 20
+)
+
+var wasmLogLevels = []string{LogDebug, LogInfo, LogWarn, LogError}
+
+type wasmPlugin struct {
+	name      string
+	dir       string
+	runtime   wazero.Runtime
+	compiled  wazero.CompiledModule
+	perms     PluginPermissions
+	readPaths []string
+}
+
+// wasmCall is the state of one forgeflow_execute call, reachable from host
+// functions through the context
+type wasmCall struct {
+	plugin *wasmPlugin
+	log    func(level, message string)
+}
+
+type wasmCallKey struct{}
+
+func newWasmPlugin(dir string, manifest PluginManifest) (*wasmPlugin, error) {
+	if manifest.Module == "" {
+		return nil, errors.New("manifest has no module")
+	}
+	code, err := os.ReadFile(filepath.Join(dir, manifest.Module))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module: %w", err)
+	}
+
+	memoryMB := manifest.MemoryLimit
+	if memoryMB <= 0 {
+		memoryMB = defaultWasmMemory
+	}
+	ctx := context.Background()
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithCloseOnContextDone(true).
+		WithMemoryLimitPages(uint32(memoryMB<<20/wasmPageSize)))
+
+	p := &wasmPlugin{name: manifest.Name, dir: dir, runtime: runtime, perms: manifest.Permissions}
+	for _, path := range manifest.Permissions.ReadPaths {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		p.readPaths = append(p.readPaths, filepath.Clean(path))
+	}
+
+	// WASI is provided so toolchains that expect it (Go, Rust wasip1) can
+	// run, but without filesystem, environment or arguments
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		runtime.Close(ctx)
+		return nil, err
+	}
+	_, err = runtime.NewHostModuleBuilder(wasmHostModule).
+		NewFunctionBuilder().WithFunc(wasmHostLog).Export("log").
+		NewFunctionBuilder().WithFunc(wasmHostHTTPRequest).Export("http_request").
+		NewFunctionBuilder().WithFunc(wasmHostReadFile).Export("read_file").
+		Instantiate(ctx)
+	if err != nil {
+		runtime.Close(ctx)
+		return nil, err
+	}
+
+	if p.compiled, err = runtime.CompileModule(ctx, code); err != nil {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("invalid module: %w", err)
+	}
+	for _, name := range []string{wasmAllocExport, wasmExecuteExport} {
+		if _, ok := p.compiled.ExportedFunctions()[name]; !ok {
+			runtime.Close(ctx)
+			return nil, fmt.Errorf("module does not export %s", name)
+		}
+	}
+	return p, nil
+}
+
+// Execute instantiates a fresh module for each run, so no state leaks
+// between executions
+func (p *wasmPlugin) Execute(ctx context.Context, nodeType string, config map[string]interface{}, input interface{}, log func(level, message string)) (interface{}, error) {
+	request, err := json.Marshal(map[string]interface{}{
+		"nodeType": nodeType,
+		"config":   config,
+		"input":    input,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid plugin input: %w", err)
+	}
+
+	ctx = context.WithValue(ctx, wasmCallKey{}, &wasmCall{plugin: p, log: log})
+	mod, err := p.runtime.InstantiateModule(ctx, p.compiled, wazero.NewModuleConfig().
+		WithName("").
+		WithStartFunctions("_initialize"))
+	if err != nil {
+		return nil, p.callError(ctx, err)
+	}
+	defer mod.Close(context.Background())
+
+	ptr, err := wasmWrite(ctx, mod, request)
+	if err != nil {
+		return nil, p.callError(ctx, err)
+	}
+	results, err := mod.ExportedFunction(wasmExecuteExport).Call(ctx, uint64(ptr), uint64(len(request)))
+	if err != nil {
+		return nil, p.callError(ctx, err)
+	}
+	data, ok := wasmRead(mod, results[0])
+	if !ok {
+		return nil, fmt.Errorf("plugin %s returned an invalid response location", p.name)
+	}
+
+	var response struct {
+		Output interface{} `json:"output"`
+		Error  string      `json:"error"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("plugin %s returned invalid JSON: %w", p.name, err)
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return response.Output, nil
+}
+
+func (p *wasmPlugin) callError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return fmt.Errorf("plugin %s failed: %w", p.name, err)
+}
+
+func (p *wasmPlugin) Close() error {
+	return p.runtime.Close(context.Background())
+}
+
+// wasmWrite copies data into guest memory allocated by forgeflow_alloc
+func wasmWrite(ctx context.Context, mod api.Module, data []byte) (uint32, error) {
+	results, err := mod.ExportedFunction(wasmAllocExport).Call(ctx, uint64(len(data)))
+	if err != nil {
+		return 0, err
+	}
+	ptr := uint32(results[0])
+	if !mod.Memory().Write(ptr, data) {
+		return 0, fmt.Errorf("%s returned an out of range pointer", wasmAllocExport)
+	}
+	return ptr, nil
+}
+
+// wasmRead reads the bytes at a packed ptr<<32|len location
+func wasmRead(mod api.Module, packed uint64) ([]byte, bool) {
+	ptr, length := uint32(packed>>32), uint32(packed)
+	data, ok := mod.Memory().Read(ptr, length)
+	if !ok {
+		return nil, false
+	}
+	return append([]byte(nil), data...), true
+}
+
+// wasmReply writes a JSON host function response into guest memory
+func wasmReply(ctx context.Context, mod api.Module, value interface{}) uint64 {
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+	ptr, err := wasmWrite(ctx, mod, data)
+	if err != nil {
+		return 0
+	}
+	return uint64(ptr)<<32 | uint64(len(data))
+}
+
+func wasmReplyError(ctx context.Context, mod api.Module, format string, args ...interface{}) uint64 {
+	return wasmReply(ctx, mod, map[string]string{"error": fmt.Sprintf(format, args...)})
+}
+
+func wasmHostLog(ctx context.Context, mod api.Module, level, ptr, length uint32) {
+	call, _ := ctx.Value(wasmCallKey{}).(*wasmCall)
+	if call == nil || !call.plugin.perms.Log {
+		return
+	}
+	data, ok := mod.Memory().Read(ptr, length)
+	if !ok {
+		return
+	}
+	if int(level) >= len(wasmLogLevels) {
+		level = 1
+	}
+	call.log(wasmLogLevels[level], string(data))
+}
+
+func wasmHostHTTPRequest(ctx context.Context, mod api.Module, ptr, length uint32) uint64 {
+	call, _ := ctx.Value(wasmCallKey{}).(*wasmCall)
+	data, ok := mod.Memory().Read(ptr, length)
+	if call == nil || !ok {
+		return wasmReplyError(ctx, mod, "invalid request")
+	}
+
+	var req struct {
+		Method  string            `json:"method"`
+		URL     string            `json:"url"`
+		Headers map[string]string `json:"headers"`
+		Body    string            `json:"body"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		return wasmReplyError(ctx, mod, "invalid request: %v", err)
+	}
+	if !call.plugin.allowsURL(req.URL) {
+		return wasmReplyError(ctx, mod, "permission denied: http %s", req.URL)
+	}
+	if req.Method == "" {
+		req.Method = "GET"
+	}
+
+	// Redirects are followed only to granted URLs
+	ctx = contextWithURLPolicy(ctx, call.plugin.allowsURL)
+	resp, err := doHTTPRequest(ctx, req.Method, req.URL, req.Headers, req.Body)
+	if err != nil {
+		return wasmReplyError(ctx, mod, "%v", err)
+	}
+	return wasmReply(ctx, mod, resp)
+}
+
+func wasmHostReadFile(ctx context.Context, mod api.Module, ptr, length uint32) uint64 {
+	call, _ := ctx.Value(wasmCallKey{}).(*wasmCall)
+	data, ok := mod.Memory().Read(ptr, length)
+	if call == nil || !ok {
+		return wasmReplyError(ctx, mod, "invalid path")
+	}
+
+	path, ok := call.plugin.resolveReadPath(string(data))
+	if !ok {
+		return wasmReplyError(ctx, mod, "permission denied: read %s", string(data))
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return wasmReplyError(ctx, mod, "%v", err)
+	}
+	if info.Size() > maxWasmReadFileLen {
+		return wasmReplyError(ctx, mod, "file too large: %d bytes", info.Size())
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return wasmReplyError(ctx, mod, "%v", err)
+	}
+	return wasmReply(ctx, mod, map[string]string{"content": string(content)})
+}
+
+// allowsURL reports whether the plugin was granted a URL. A grant matches
+// URLs with the same scheme and host (and port) whose path starts with the
+// grant's path, segment by segment; "*" grants any HTTP(S) URL.
+func (p *wasmPlugin) allowsURL(rawURL string) bool {
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return false
+	}
+	for _, grant := range p.perms.HTTP {
+		if grant == "*" || urlGrantMatches(grant, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func urlGrantMatches(grant string, target *url.URL) bool {
+	g, err := url.Parse(grant)
+	if err != nil || g.Host == "" {
+		return false
+	}
+	if !strings.EqualFold(g.Scheme, target.Scheme) ||
+		!strings.EqualFold(g.Hostname(), target.Hostname()) ||
+		urlPort(g) != urlPort(target) {
+		return false
+	}
+	if g.Path == "" || g.Path == "/" {
+		return true
+	}
+	// Dot segments are resolved the way servers will before comparing
+	targetPath := path.Clean("/" + target.Path)
+	if strings.HasSuffix(g.Path, "/") {
+		return strings.HasPrefix(targetPath+"/", g.Path)
+	}
+	return targetPath == g.Path || strings.HasPrefix(targetPath, g.Path+"/")
+}
+
+// urlPort returns the port of a URL, or the default port of its scheme
+func urlPort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	if strings.EqualFold(u.Scheme, "https") {
+		return "443"
+	}
+	return "80"
+}
+
+// resolveReadPath returns the absolute path for a read if it falls under one
+// of the granted directories, after resolving symlinks. Relative paths are
+// relative to the plugin directory.
+func (p *wasmPlugin) resolveReadPath(path string) (string, bool) {
+	if len(p.readPaths) == 0 {
+		return "", false
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(p.dir, path)
+	}
+	resolved, err := filepath.EvalSymlinks(filepath.Clean(path))
+	if err != nil {
+		return "", false
+	}
+	for _, dir := range p.readPaths {
+		root, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(root, resolved); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return resolved, true
+		}
+	}
+	return "", false
+}